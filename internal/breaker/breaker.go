@@ -0,0 +1,187 @@
+// Package breaker implements a circuit breaker for backend servers. After a
+// run of consecutive failures the circuit opens and calls fail fast without
+// reaching the server; once the open timeout passes a single trial call is let
+// through, and its outcome closes or reopens the circuit.
+package breaker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/vaayne/mcphub/internal/config"
+)
+
+// Circuit states
+const (
+	StateClosed   = "closed"
+	StateOpen     = "open"
+	StateHalfOpen = "half-open"
+)
+
+// OpenError is returned for calls rejected while the circuit is open
+type OpenError struct {
+	Scope      string        `json:"scope"`
+	RetryAfter time.Duration `json:"retryAfter"`
+}
+
+// Error implements the error interface
+func (e *OpenError) Error() string {
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("circuit open for %s after repeated failures, retry after %s", e.Scope, e.RetryAfter.Round(time.Millisecond))
+	}
+	return fmt.Sprintf("circuit open for %s after repeated failures, retry later", e.Scope)
+}
+
+// Stats describes the state of a breaker
+type Stats struct {
+	State     string     `json:"state"`
+	Failures  int        `json:"failures"`           // consecutive failures
+	Rejected  uint64     `json:"rejected"`           // calls fast-failed while open
+	OpenedAt  *time.Time `json:"openedAt,omitempty"` // when the circuit last opened
+	LastError string     `json:"lastError,omitempty"`
+}
+
+// Breaker tracks the failures of one server
+type Breaker struct {
+	scope       string
+	threshold   int
+	openTimeout time.Duration
+
+	// IsFailure decides which errors count as failures of the server (default:
+	// every error). Calls canceled by the caller are never counted.
+	IsFailure func(err error) bool
+	// OnStateChange, if set, is called (without locks held) when the state changes
+	OnStateChange func(from, to string)
+
+	mu        sync.Mutex
+	state     string
+	failures  int
+	openedAt  time.Time
+	trial     bool // a half-open trial call is in flight
+	lastError string
+	now       func() time.Time
+
+	rejected atomic.Uint64
+}
+
+// New creates a closed breaker for cfg. scope names the server in errors.
+func New(scope string, cfg config.CircuitBreakerConfig) *Breaker {
+	return &Breaker{
+		scope:       scope,
+		threshold:   cfg.GetFailureThreshold(),
+		openTimeout: cfg.GetOpenTimeout(),
+		state:       StateClosed,
+		now:         time.Now,
+	}
+}
+
+// Allow admits a call, or returns an *OpenError if the circuit is open. On
+// success the caller must report the call's outcome by passing its error (nil
+// on success) to done.
+func (b *Breaker) Allow() (done func(err error), err error) {
+	b.mu.Lock()
+	from := b.state
+
+	switch b.state {
+	case StateOpen:
+		wait := b.openedAt.Add(b.openTimeout).Sub(b.now())
+		if wait > 0 {
+			b.mu.Unlock()
+			b.rejected.Add(1)
+			return nil, &OpenError{Scope: b.scope, RetryAfter: wait}
+		}
+		b.state = StateHalfOpen
+		b.trial = true
+	case StateHalfOpen:
+		if b.trial {
+			b.mu.Unlock()
+			b.rejected.Add(1)
+			return nil, &OpenError{Scope: b.scope}
+		}
+		b.trial = true
+	}
+
+	isTrial := b.state == StateHalfOpen
+	to := b.state
+	b.mu.Unlock()
+	b.notify(from, to)
+
+	var once sync.Once
+	return func(err error) {
+		once.Do(func() { b.record(isTrial, err) })
+	}, nil
+}
+
+// Ready reports whether Allow would currently admit a call
+func (b *Breaker) Ready() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case StateOpen:
+		return !b.now().Before(b.openedAt.Add(b.openTimeout))
+	case StateHalfOpen:
+		return !b.trial
+	default:
+		return true
+	}
+}
+
+// record updates the breaker with the outcome of a call
+func (b *Breaker) record(isTrial bool, err error) {
+	b.mu.Lock()
+	from := b.state
+
+	if isTrial {
+		b.trial = false
+	}
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		// The caller gave up; the call says nothing about the server
+	case err == nil || (b.IsFailure != nil && !b.IsFailure(err)):
+		b.failures = 0
+		b.state = StateClosed
+	default:
+		b.failures++
+		b.lastError = err.Error()
+		// A failed trial reopens the circuit; a late failure from a call admitted
+		// before it opened doesn't extend the open period
+		if isTrial || (b.state == StateClosed && b.failures >= b.threshold) {
+			b.state = StateOpen
+			b.openedAt = b.now()
+		}
+	}
+
+	to := b.state
+	b.mu.Unlock()
+	b.notify(from, to)
+}
+
+func (b *Breaker) notify(from, to string) {
+	if from != to && b.OnStateChange != nil {
+		b.OnStateChange(from, to)
+	}
+}
+
+// Stats returns the breaker's state
+func (b *Breaker) Stats() Stats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	stats := Stats{
+		State:     b.state,
+		Failures:  b.failures,
+		Rejected:  b.rejected.Load(),
+		LastError: b.lastError,
+	}
+	if !b.openedAt.IsZero() {
+		openedAt := b.openedAt
+		stats.OpenedAt = &openedAt
+	}
+	return stats
+}
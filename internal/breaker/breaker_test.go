@@ -0,0 +1,112 @@
+package breaker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vaayne/mcphub/internal/config"
+)
+
+// newTestBreaker returns a breaker with a controllable clock
+func newTestBreaker(threshold int) (*Breaker, *time.Time) {
+	b := New("server 'test'", config.CircuitBreakerConfig{FailureThreshold: threshold, OpenTimeout: 10})
+	now := time.Unix(1_700_000_000, 0)
+	b.now = func() time.Time { return now }
+	return b, &now
+}
+
+// call runs one call through the breaker with the given outcome
+func call(b *Breaker, outcome error) error {
+	done, err := b.Allow()
+	if err != nil {
+		return err
+	}
+	done(outcome)
+	return nil
+}
+
+func TestBreaker_OpensAfterThreshold(t *testing.T) {
+	b, now := newTestBreaker(3)
+	failure := errors.New("connection reset")
+
+	// Successes reset the count of consecutive failures
+	require.NoError(t, call(b, failure))
+	require.NoError(t, call(b, failure))
+	require.NoError(t, call(b, nil))
+	assert.Equal(t, StateClosed, b.Stats().State)
+
+	for range 3 {
+		require.NoError(t, call(b, failure))
+	}
+	assert.Equal(t, StateOpen, b.Stats().State)
+
+	err := call(b, nil)
+	var openErr *OpenError
+	require.ErrorAs(t, err, &openErr)
+	assert.Equal(t, 10*time.Second, openErr.RetryAfter)
+	assert.Contains(t, err.Error(), "circuit open for server 'test'")
+
+	*now = now.Add(4 * time.Second)
+	require.ErrorAs(t, call(b, nil), &openErr)
+	assert.Equal(t, 6*time.Second, openErr.RetryAfter)
+
+	stats := b.Stats()
+	assert.Equal(t, 3, stats.Failures)
+	assert.Equal(t, uint64(2), stats.Rejected)
+	assert.Equal(t, "connection reset", stats.LastError)
+	assert.NotNil(t, stats.OpenedAt)
+}
+
+func TestBreaker_HalfOpenTrial(t *testing.T) {
+	b, now := newTestBreaker(1)
+	var transitions []string
+	b.OnStateChange = func(from, to string) {
+		transitions = append(transitions, from+"->"+to)
+	}
+
+	require.NoError(t, call(b, errors.New("boom")))
+	*now = now.Add(11 * time.Second)
+
+	// One trial call is let through; others are rejected while it runs
+	done, err := b.Allow()
+	require.NoError(t, err)
+	assert.Equal(t, StateHalfOpen, b.Stats().State)
+	_, err = b.Allow()
+	var openErr *OpenError
+	assert.ErrorAs(t, err, &openErr)
+
+	// A failed trial reopens the circuit
+	done(errors.New("still down"))
+	assert.Equal(t, StateOpen, b.Stats().State)
+
+	*now = now.Add(11 * time.Second)
+	require.NoError(t, call(b, nil))
+	assert.Equal(t, StateClosed, b.Stats().State)
+	assert.Equal(t, 0, b.Stats().Failures)
+
+	assert.Equal(t, []string{
+		"closed->open", "open->half-open", "half-open->open", "open->half-open", "half-open->closed",
+	}, transitions)
+}
+
+func TestBreaker_IgnoresNonFailures(t *testing.T) {
+	b, now := newTestBreaker(1)
+	invalid := errors.New("invalid params")
+	b.IsFailure = func(err error) bool { return !errors.Is(err, invalid) }
+
+	require.NoError(t, call(b, invalid))
+	require.NoError(t, call(b, context.Canceled))
+	assert.Equal(t, StateClosed, b.Stats().State)
+
+	// A canceled trial leaves the circuit half-open for the next caller
+	require.NoError(t, call(b, errors.New("boom")))
+	*now = now.Add(11 * time.Second)
+	require.NoError(t, call(b, context.Canceled))
+	assert.Equal(t, StateHalfOpen, b.Stats().State)
+	require.NoError(t, call(b, nil))
+	assert.Equal(t, StateClosed, b.Stats().State)
+}
@@ -0,0 +1,132 @@
+// Package cache stores results of idempotent tool calls so repeated calls with
+// the same arguments are answered without reaching the backend server. Results
+// are kept as JSON, so every hit returns a fresh copy the caller may modify.
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/vaayne/mcphub/internal/config"
+)
+
+// Cache is a store of tool call results
+type Cache interface {
+	// Get returns the result stored under key, if present and not expired
+	Get(key string) (*mcp.CallToolResult, bool)
+	// Set stores result under key for ttl
+	Set(key string, result *mcp.CallToolResult, ttl time.Duration) error
+	// Clear removes all entries
+	Clear() error
+	// Stats reports the cache size and hit rate
+	Stats() Stats
+}
+
+// Stats describes the contents and effectiveness of a cache
+type Stats struct {
+	Store   string `json:"store"`
+	Entries int    `json:"entries"`
+	Bytes   int64  `json:"bytes"`
+	Hits    uint64 `json:"hits"`
+	Misses  uint64 `json:"misses"`
+}
+
+// New creates the cache described by cfg, or returns nil if cfg is nil
+func New(cfg *config.CacheConfig) (Cache, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+
+	switch cfg.GetStore() {
+	case config.CacheStoreMemory:
+		return NewMemory(cfg.GetMaxEntries()), nil
+	case config.CacheStoreDisk:
+		return NewDisk(cfg.GetDir(), cfg.GetMaxEntries(), cfg.GetMaxBytes())
+	default:
+		return nil, fmt.Errorf("invalid cache store: %s", cfg.Store)
+	}
+}
+
+// Key derives the cache key of a call from the server, tool and arguments.
+// Arguments are canonicalized (object keys sorted) before hashing, so
+// {"a":1,"b":2} and {"b":2,"a":1} share an entry.
+func Key(serverID, toolName string, args map[string]any) (string, error) {
+	data, err := json.Marshal(struct {
+		Server string         `json:"server"`
+		Tool   string         `json:"tool"`
+		Args   map[string]any `json:"args"`
+	}{serverID, toolName, args})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode cache key: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Call returns the cached result for key if there is one. Otherwise it calls fn
+// and caches a successful, non-error result for ttl. A nil cache always calls fn.
+func Call(c Cache, key string, ttl time.Duration, fn func() (*mcp.CallToolResult, error)) (*mcp.CallToolResult, error) {
+	if c == nil {
+		return fn()
+	}
+
+	if result, ok := c.Get(key); ok {
+		return result, nil
+	}
+
+	result, err := fn()
+	if err != nil || result == nil || result.IsError {
+		return result, err
+	}
+
+	// A failed write only costs a future cache miss
+	_ = c.Set(key, result, ttl)
+	return result, nil
+}
+
+// counters tracks hits and misses of a cache
+type counters struct {
+	hits   atomic.Uint64
+	misses atomic.Uint64
+}
+
+func (c *counters) record(hit bool) {
+	if hit {
+		c.hits.Add(1)
+	} else {
+		c.misses.Add(1)
+	}
+}
+
+// entry is the stored form of a cached result
+type entry struct {
+	Expires time.Time       `json:"expires"`
+	Result  json.RawMessage `json:"result"`
+}
+
+// encode serializes a result for storage
+func encode(result *mcp.CallToolResult, ttl time.Duration) ([]byte, error) {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode result: %w", err)
+	}
+	return json.Marshal(entry{Expires: time.Now().Add(ttl), Result: data})
+}
+
+// decode parses a stored entry, reporting false if it is invalid or expired
+func decode(data []byte, now time.Time) (*mcp.CallToolResult, bool) {
+	var e entry
+	if err := json.Unmarshal(data, &e); err != nil || now.After(e.Expires) {
+		return nil, false
+	}
+	var result mcp.CallToolResult
+	if err := json.Unmarshal(e.Result, &result); err != nil {
+		return nil, false
+	}
+	return &result, true
+}
@@ -0,0 +1,175 @@
+package cache
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vaayne/mcphub/internal/config"
+)
+
+func textResult(text string) *mcp.CallToolResult {
+	return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: text}}}
+}
+
+func TestKey_Canonical(t *testing.T) {
+	a, err := Key("srv", "search", map[string]any{"q": "mcp", "opts": map[string]any{"x": 1, "y": 2}})
+	require.NoError(t, err)
+	b, err := Key("srv", "search", map[string]any{"opts": map[string]any{"y": 2, "x": 1}, "q": "mcp"})
+	require.NoError(t, err)
+	assert.Equal(t, a, b)
+
+	c, err := Key("srv", "other", map[string]any{"q": "mcp"})
+	require.NoError(t, err)
+	assert.NotEqual(t, a, c)
+}
+
+func TestCall(t *testing.T) {
+	c := NewMemory(10)
+	calls := 0
+	fn := func() (*mcp.CallToolResult, error) {
+		calls++
+		return textResult("ok"), nil
+	}
+
+	for range 3 {
+		result, err := Call(c, "k", time.Minute, fn)
+		require.NoError(t, err)
+		assert.Equal(t, "ok", result.Content[0].(*mcp.TextContent).Text)
+	}
+	assert.Equal(t, 1, calls)
+
+	// Error results are never cached
+	errorCalls := 0
+	for range 2 {
+		_, _ = Call(c, "err", time.Minute, func() (*mcp.CallToolResult, error) {
+			errorCalls++
+			return &mcp.CallToolResult{IsError: true}, nil
+		})
+	}
+	assert.Equal(t, 2, errorCalls)
+
+	// A nil cache always calls through
+	_, err := Call(nil, "k", time.Minute, fn)
+	require.NoError(t, err)
+	assert.Equal(t, 2, calls)
+}
+
+func TestMemory_ReturnsCopies(t *testing.T) {
+	c := NewMemory(10)
+	require.NoError(t, c.Set("k", textResult("ok"), time.Minute))
+
+	first, ok := c.Get("k")
+	require.True(t, ok)
+	first.Content = append(first.Content, &mcp.TextContent{Text: "note"})
+
+	second, ok := c.Get("k")
+	require.True(t, ok)
+	assert.Len(t, second.Content, 1)
+}
+
+func TestMemory_LRUAndExpiry(t *testing.T) {
+	c := NewMemory(2)
+	require.NoError(t, c.Set("a", textResult("a"), time.Minute))
+	require.NoError(t, c.Set("b", textResult("b"), time.Minute))
+
+	// Touch "a" so "b" is the least recently used
+	_, ok := c.Get("a")
+	require.True(t, ok)
+	require.NoError(t, c.Set("c", textResult("c"), time.Minute))
+
+	_, ok = c.Get("b")
+	assert.False(t, ok)
+	_, ok = c.Get("a")
+	assert.True(t, ok)
+
+	require.NoError(t, c.Set("expired", textResult("x"), -time.Second))
+	_, ok = c.Get("expired")
+	assert.False(t, ok)
+
+	stats := c.Stats()
+	assert.Equal(t, config.CacheStoreMemory, stats.Store)
+	assert.Equal(t, 1, stats.Entries)
+	assert.Equal(t, uint64(2), stats.Hits)
+	assert.Equal(t, uint64(2), stats.Misses)
+
+	require.NoError(t, c.Clear())
+	assert.Equal(t, 0, c.Stats().Entries)
+}
+
+func TestDisk(t *testing.T) {
+	dir := t.TempDir()
+	c, err := NewDisk(dir, 0, 0)
+	require.NoError(t, err)
+
+	image := &mcp.ImageContent{Data: []byte("png"), MIMEType: "image/png"}
+	result := &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "ok"}, image}}
+	require.NoError(t, c.Set("k", result, time.Minute))
+
+	// A second instance on the same directory sees the entry
+	other, err := NewDisk(dir, 0, 0)
+	require.NoError(t, err)
+	cached, ok := other.Get("k")
+	require.True(t, ok)
+	require.Len(t, cached.Content, 2)
+	assert.Equal(t, image.Data, cached.Content[1].(*mcp.ImageContent).Data)
+
+	stats := other.Stats()
+	assert.Equal(t, 1, stats.Entries)
+	assert.Positive(t, stats.Bytes)
+
+	require.NoError(t, c.Set("expired", textResult("x"), -time.Second))
+	_, ok = c.Get("expired")
+	assert.False(t, ok)
+
+	require.NoError(t, c.Clear())
+	assert.Equal(t, 0, c.Stats().Entries)
+}
+
+func TestDisk_LRUEviction(t *testing.T) {
+	dir := t.TempDir()
+	c, err := NewDisk(dir, 2, 0)
+	require.NoError(t, err)
+
+	require.NoError(t, c.Set("a", textResult("a"), time.Minute))
+	require.NoError(t, c.Set("b", textResult("b"), time.Minute))
+	old := time.Now().Add(-time.Hour)
+	for i, key := range []string{"a", "b"} {
+		at := old.Add(time.Duration(i) * time.Second)
+		require.NoError(t, os.Chtimes(c.path(key), at, at))
+	}
+
+	// Reading a marks it recently used, so b is evicted
+	_, ok := c.Get("a")
+	require.True(t, ok)
+	require.NoError(t, c.Set("c", textResult("c"), time.Minute))
+
+	_, ok = c.Get("b")
+	assert.False(t, ok)
+	_, ok = c.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 2, c.Stats().Entries)
+
+	// The size limit evicts too
+	small, err := NewDisk(t.TempDir(), 0, 1)
+	require.NoError(t, err)
+	require.NoError(t, small.Set("a", textResult("a"), time.Minute))
+	assert.Equal(t, 0, small.Stats().Entries)
+}
+
+func TestNew(t *testing.T) {
+	c, err := New(nil)
+	require.NoError(t, err)
+	assert.Nil(t, c)
+
+	c, err = New(&config.CacheConfig{})
+	require.NoError(t, err)
+	assert.IsType(t, &Memory{}, c)
+
+	c, err = New(&config.CacheConfig{Store: "disk", Dir: t.TempDir()})
+	require.NoError(t, err)
+	assert.IsType(t, &Disk{}, c)
+}
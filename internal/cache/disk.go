@@ -0,0 +1,187 @@
+package cache
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/vaayne/mcphub/internal/config"
+)
+
+// entryExt is the file extension of disk cache entries
+const entryExt = ".json"
+
+// Disk is a cache stored as one file per entry, shared by every hub and CLI
+// process using the same directory. When a write takes it over maxEntries or
+// maxBytes, the least recently used entries are removed.
+type Disk struct {
+	dir        string
+	maxEntries int
+	maxBytes   int64
+	counters
+}
+
+// NewDisk creates a disk cache in dir holding at most maxEntries results and
+// maxBytes of entries, creating the directory if needed. Non-positive limits
+// fall back to the defaults.
+func NewDisk(dir string, maxEntries int, maxBytes int64) (*Disk, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	if maxEntries <= 0 {
+		maxEntries = config.DefaultCacheMaxEntries
+	}
+	if maxBytes <= 0 {
+		maxBytes = config.DefaultCacheMaxBytes
+	}
+	return &Disk{dir: dir, maxEntries: maxEntries, maxBytes: maxBytes}, nil
+}
+
+// Dir returns the cache directory
+func (d *Disk) Dir() string {
+	return d.dir
+}
+
+func (d *Disk) path(key string) string {
+	return filepath.Join(d.dir, key+entryExt)
+}
+
+// Get implements Cache
+func (d *Disk) Get(key string) (*mcp.CallToolResult, bool) {
+	data, err := os.ReadFile(d.path(key))
+	if err != nil {
+		d.record(false)
+		return nil, false
+	}
+
+	result, ok := decode(data, time.Now())
+	if !ok {
+		_ = os.Remove(d.path(key))
+		d.record(false)
+		return nil, false
+	}
+
+	// The modification time orders entries for eviction
+	now := time.Now()
+	_ = os.Chtimes(d.path(key), now, now)
+	d.record(true)
+	return result, true
+}
+
+// Set implements Cache. Entries are written to a temporary file and renamed so
+// concurrent readers never see a partial entry.
+func (d *Disk) Set(key string, result *mcp.CallToolResult, ttl time.Duration) error {
+	data, err := encode(result, ttl)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(d.dir, key+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), d.path(key)); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	d.evict()
+	return nil
+}
+
+// evict removes the least recently used entries until the cache is within its
+// limits. Other processes may evict at the same time, so entries that are
+// already gone are skipped.
+func (d *Disk) evict() {
+	entries, err := os.ReadDir(d.dir)
+	if err != nil {
+		return
+	}
+	files := make([]os.FileInfo, 0, len(entries))
+	var size int64
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), entryExt) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, info)
+		size += info.Size()
+	}
+
+	sort.Slice(files, func(i, j int) bool { return files[i].ModTime().Before(files[j].ModTime()) })
+	count := len(files)
+	for _, info := range files {
+		if count <= d.maxEntries && size <= d.maxBytes {
+			return
+		}
+		if err := os.Remove(filepath.Join(d.dir, info.Name())); err != nil && !os.IsNotExist(err) {
+			continue
+		}
+		count--
+		size -= info.Size()
+	}
+}
+
+// Clear implements Cache
+func (d *Disk) Clear() error {
+	entries, err := os.ReadDir(d.dir)
+	if err != nil {
+		return fmt.Errorf("failed to read cache directory: %w", err)
+	}
+	for _, e := range entries {
+		if e.IsDir() || !isCacheFile(e.Name()) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(d.dir, e.Name())); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove cache entry: %w", err)
+		}
+	}
+	return nil
+}
+
+// Stats implements Cache. Entries and bytes cover every process sharing the
+// directory; hits and misses are counted by this process only.
+func (d *Disk) Stats() Stats {
+	stats := Stats{
+		Store:  config.CacheStoreDisk,
+		Hits:   d.hits.Load(),
+		Misses: d.misses.Load(),
+	}
+
+	entries, err := os.ReadDir(d.dir)
+	if err != nil {
+		return stats
+	}
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), entryExt) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		stats.Entries++
+		stats.Bytes += info.Size()
+	}
+	return stats
+}
+
+// isCacheFile reports whether name is an entry or a leftover temporary file
+func isCacheFile(name string) bool {
+	return strings.HasSuffix(name, entryExt) || strings.HasSuffix(name, ".tmp")
+}
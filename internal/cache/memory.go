@@ -0,0 +1,115 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/vaayne/mcphub/internal/config"
+)
+
+// Memory is an in-process LRU cache
+type Memory struct {
+	mu         sync.Mutex
+	items      map[string]*list.Element
+	lru        *list.List // most recently used at the front
+	maxEntries int
+	bytes      int64
+	counters
+}
+
+// memoryItem is a list element value
+type memoryItem struct {
+	key  string
+	data []byte
+}
+
+// NewMemory creates an LRU cache holding at most maxEntries results
+func NewMemory(maxEntries int) *Memory {
+	if maxEntries <= 0 {
+		maxEntries = config.DefaultCacheMaxEntries
+	}
+	return &Memory{
+		items:      make(map[string]*list.Element),
+		lru:        list.New(),
+		maxEntries: maxEntries,
+	}
+}
+
+// Get implements Cache
+func (m *Memory) Get(key string) (*mcp.CallToolResult, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	elem, ok := m.items[key]
+	if !ok {
+		m.record(false)
+		return nil, false
+	}
+
+	result, ok := decode(elem.Value.(*memoryItem).data, time.Now())
+	if !ok {
+		m.remove(elem)
+		m.record(false)
+		return nil, false
+	}
+
+	m.lru.MoveToFront(elem)
+	m.record(true)
+	return result, true
+}
+
+// Set implements Cache
+func (m *Memory) Set(key string, result *mcp.CallToolResult, ttl time.Duration) error {
+	data, err := encode(result, ttl)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if elem, ok := m.items[key]; ok {
+		m.remove(elem)
+	}
+	m.items[key] = m.lru.PushFront(&memoryItem{key: key, data: data})
+	m.bytes += int64(len(data))
+
+	for m.lru.Len() > m.maxEntries {
+		m.remove(m.lru.Back())
+	}
+	return nil
+}
+
+// remove deletes an element; callers must hold m.mu
+func (m *Memory) remove(elem *list.Element) {
+	item := m.lru.Remove(elem).(*memoryItem)
+	delete(m.items, item.key)
+	m.bytes -= int64(len(item.data))
+}
+
+// Clear implements Cache
+func (m *Memory) Clear() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.items = make(map[string]*list.Element)
+	m.lru.Init()
+	m.bytes = 0
+	return nil
+}
+
+// Stats implements Cache
+func (m *Memory) Stats() Stats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return Stats{
+		Store:   config.CacheStoreMemory,
+		Entries: m.lru.Len(),
+		Bytes:   m.bytes,
+		Hits:    m.hits.Load(),
+		Misses:  m.misses.Load(),
+	}
+}
@@ -0,0 +1,207 @@
+// Package catalog persists what backend servers offer (tools, prompts and
+// resources) on disk, so servers can be listed and inspected without starting
+// them. Entries are keyed by a hash of the server's connection settings and are
+// ignored once those change or the entry is older than the catalog's TTL.
+package catalog
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/vaayne/mcphub/internal/config"
+)
+
+// Listing is what a server offers
+type Listing struct {
+	Tools     []*mcp.Tool     `json:"tools"`
+	Prompts   []*mcp.Prompt   `json:"prompts,omitempty"`
+	Resources []*mcp.Resource `json:"resources,omitempty"`
+}
+
+// Entry is the stored catalog of one server
+type Entry struct {
+	Server    string    `json:"server"`
+	Hash      string    `json:"hash"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	Listing
+}
+
+// Store keeps one catalog entry per server in a directory
+type Store struct {
+	dir string
+	ttl time.Duration // 0 = entries never expire
+	now func() time.Time
+}
+
+// NewStore opens the catalog in dir, creating the directory if needed.
+// Entries older than ttl are treated as missing (0 = never expire).
+func NewStore(dir string, ttl time.Duration) (*Store, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create catalog directory: %w", err)
+	}
+	return &Store{dir: dir, ttl: ttl, now: time.Now}, nil
+}
+
+// Discover lists the tools, prompts and resources of a connected server,
+// following pagination. Prompts and resources are only listed if the server
+// advertises them, and failing to list them leaves them empty.
+func Discover(ctx context.Context, session *mcp.ClientSession) (*Listing, error) {
+	listing := &Listing{}
+
+	for tool, err := range session.Tools(ctx, nil) {
+		if err != nil {
+			return nil, err
+		}
+		listing.Tools = append(listing.Tools, tool)
+	}
+
+	var caps *mcp.ServerCapabilities
+	if init := session.InitializeResult(); init != nil {
+		caps = init.Capabilities
+	}
+	if caps == nil {
+		return listing, nil
+	}
+
+	if caps.Prompts != nil {
+		for prompt, err := range session.Prompts(ctx, nil) {
+			if err != nil {
+				listing.Prompts = nil
+				break
+			}
+			listing.Prompts = append(listing.Prompts, prompt)
+		}
+	}
+	if caps.Resources != nil {
+		for resource, err := range session.Resources(ctx, nil) {
+			if err != nil {
+				listing.Resources = nil
+				break
+			}
+			listing.Resources = append(listing.Resources, resource)
+		}
+	}
+
+	return listing, nil
+}
+
+// Dir returns the catalog directory
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+func (s *Store) path(serverID string) string {
+	return filepath.Join(s.dir, serverID+".json")
+}
+
+// Hash identifies the connection settings of a server. Settings that don't
+// change which tools the server offers (limits, retries, lazy startup) are
+// left out, so changing them keeps the entry valid.
+func Hash(serverCfg config.MCPServer) string {
+	data, _ := json.Marshal(struct {
+		Transport string            `json:"transport"`
+		Command   string            `json:"command,omitempty"`
+		Args      []string          `json:"args,omitempty"`
+		Env       map[string]string `json:"env,omitempty"`
+		URL       string            `json:"url,omitempty"`
+		Headers   map[string]string `json:"headers,omitempty"`
+		Replicas  []config.Replica  `json:"replicas,omitempty"`
+	}{
+		Transport: serverCfg.GetTransport(),
+		Command:   serverCfg.Command,
+		Args:      serverCfg.Args,
+		Env:       serverCfg.Env,
+		URL:       serverCfg.URL,
+		Headers:   serverCfg.Headers,
+		Replicas:  serverCfg.Replicas,
+	})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// Load returns the entry of serverID if it was stored for the same settings
+// and has not expired
+func (s *Store) Load(serverID string, serverCfg config.MCPServer) (*Entry, bool) {
+	data, err := os.ReadFile(s.path(serverID))
+	if err != nil {
+		return nil, false
+	}
+
+	var entry Entry
+	if err := json.Unmarshal(data, &entry); err != nil || entry.Hash != Hash(serverCfg) {
+		return nil, false
+	}
+	if s.ttl > 0 && s.now().Sub(entry.UpdatedAt) > s.ttl {
+		return nil, false
+	}
+	return &entry, true
+}
+
+// Save stores the listing of serverID. Entries are written to a temporary file
+// and renamed so concurrent readers never see a partial entry.
+func (s *Store) Save(serverID string, serverCfg config.MCPServer, listing *Listing) error {
+	data, err := json.Marshal(Entry{
+		Server:    serverID,
+		Hash:      Hash(serverCfg),
+		UpdatedAt: s.now(),
+		Listing:   *listing,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode catalog entry: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(s.dir, serverID+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write catalog entry: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write catalog entry: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write catalog entry: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path(serverID)); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write catalog entry: %w", err)
+	}
+	return nil
+}
+
+// Clear removes every entry
+func (s *Store) Clear() error {
+	return s.Prune(func(string) bool { return false })
+}
+
+// Prune removes the entries of servers keep rejects, and any temporary files
+// left by interrupted writes
+func (s *Store) Prune(keep func(serverID string) bool) error {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return fmt.Errorf("failed to read catalog directory: %w", err)
+	}
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() {
+			continue
+		}
+		serverID, isEntry := strings.CutSuffix(name, ".json")
+		if isEntry && keep(serverID) || !isEntry && !strings.HasSuffix(name, ".tmp") {
+			continue
+		}
+		if err := os.Remove(filepath.Join(s.dir, name)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove catalog entry: %w", err)
+		}
+	}
+	return nil
+}
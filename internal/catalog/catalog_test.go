@@ -0,0 +1,116 @@
+package catalog
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vaayne/mcphub/internal/config"
+)
+
+func TestStore_SaveLoad(t *testing.T) {
+	store, err := NewStore(t.TempDir(), 0)
+	require.NoError(t, err)
+
+	serverCfg := config.MCPServer{Command: "npx", Args: []string{"-y", "server-github"}}
+	listing := &Listing{
+		Tools:     []*mcp.Tool{{Name: "search_code", Description: "Search code", InputSchema: map[string]any{"type": "object"}}},
+		Prompts:   []*mcp.Prompt{{Name: "review"}},
+		Resources: []*mcp.Resource{{Name: "readme", URI: "repo://readme"}},
+	}
+	require.NoError(t, store.Save("github", serverCfg, listing))
+
+	entry, ok := store.Load("github", serverCfg)
+	require.True(t, ok)
+	assert.Equal(t, "github", entry.Server)
+	require.Len(t, entry.Tools, 1)
+	assert.Equal(t, "search_code", entry.Tools[0].Name)
+	assert.Equal(t, "review", entry.Prompts[0].Name)
+	assert.Equal(t, "repo://readme", entry.Resources[0].URI)
+	assert.False(t, entry.UpdatedAt.IsZero())
+
+	_, ok = store.Load("other", serverCfg)
+	assert.False(t, ok)
+
+	// Prune keeps the entries of the servers asked for
+	require.NoError(t, store.Save("other", serverCfg, listing))
+	require.NoError(t, store.Prune(func(serverID string) bool { return serverID == "github" }))
+	_, ok = store.Load("github", serverCfg)
+	assert.True(t, ok)
+	_, ok = store.Load("other", serverCfg)
+	assert.False(t, ok)
+
+	require.NoError(t, store.Clear())
+	_, ok = store.Load("github", serverCfg)
+	assert.False(t, ok)
+}
+
+func TestStore_KeyedByConnectionSettings(t *testing.T) {
+	store, err := NewStore(t.TempDir(), 0)
+	require.NoError(t, err)
+
+	serverCfg := config.MCPServer{Command: "npx", Args: []string{"-y", "server-github"}}
+	require.NoError(t, store.Save("github", serverCfg, &Listing{}))
+
+	// Settings that don't affect the listing keep the entry
+	tuned := serverCfg
+	tuned.Lazy = true
+	tuned.RateLimit = &config.RateLimitConfig{RequestsPerSecond: 1}
+	_, ok := store.Load("github", tuned)
+	assert.True(t, ok)
+
+	// Changing how the server is launched invalidates it
+	changed := serverCfg
+	changed.Args = []string{"-y", "server-github@2"}
+	_, ok = store.Load("github", changed)
+	assert.False(t, ok)
+}
+
+func TestStore_TTL(t *testing.T) {
+	store, err := NewStore(t.TempDir(), time.Hour)
+	require.NoError(t, err)
+	now := time.Now()
+	store.now = func() time.Time { return now }
+
+	serverCfg := config.MCPServer{Command: "npx"}
+	require.NoError(t, store.Save("github", serverCfg, &Listing{}))
+
+	now = now.Add(59 * time.Minute)
+	_, ok := store.Load("github", serverCfg)
+	assert.True(t, ok)
+
+	now = now.Add(2 * time.Minute)
+	_, ok = store.Load("github", serverCfg)
+	assert.False(t, ok)
+}
+
+func TestDiscover(t *testing.T) {
+	server := mcp.NewServer(&mcp.Implementation{Name: "backend", Version: "v1.0.0"}, nil)
+	server.AddTool(&mcp.Tool{Name: "echo", InputSchema: map[string]any{"type": "object"}},
+		func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return &mcp.CallToolResult{}, nil
+		})
+	server.AddPrompt(&mcp.Prompt{Name: "greet"},
+		func(ctx context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+			return &mcp.GetPromptResult{}, nil
+		})
+
+	ctx := context.Background()
+	clientTransport, serverTransport := mcp.NewInMemoryTransports()
+	_, err := server.Connect(ctx, serverTransport, nil)
+	require.NoError(t, err)
+	session, err := mcp.NewClient(&mcp.Implementation{Name: "test", Version: "v1.0.0"}, nil).Connect(ctx, clientTransport, nil)
+	require.NoError(t, err)
+	defer session.Close()
+
+	listing, err := Discover(ctx, session)
+	require.NoError(t, err)
+	require.Len(t, listing.Tools, 1)
+	assert.Equal(t, "echo", listing.Tools[0].Name)
+	require.Len(t, listing.Prompts, 1)
+	assert.Equal(t, "greet", listing.Prompts[0].Name)
+	assert.Empty(t, listing.Resources)
+}
@@ -0,0 +1,130 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/vaayne/mcphub/internal/cache"
+	"github.com/vaayne/mcphub/internal/config"
+
+	ucli "github.com/urfave/cli/v3"
+)
+
+// CacheCmd is the cache subcommand for inspecting the on-disk response cache
+var CacheCmd = &ucli.Command{
+	Name:  "cache",
+	Usage: "Inspect and clear the response cache",
+	Description: `Inspect and clear the on-disk response cache used for idempotent tool calls.
+
+The cache directory is taken from the "cache.dir" setting of --config, or the
+default user cache directory. The in-memory store lives inside a running hub
+and cannot be inspected from the CLI.
+
+Examples:
+  mh cache stats
+  mh cache stats -c config.json --json
+  mh cache clear`,
+	Commands: []*ucli.Command{
+		cacheStatsCmd,
+		cacheClearCmd,
+	},
+}
+
+var cacheStatsCmd = &ucli.Command{
+	Name:   "stats",
+	Usage:  "Show cache size",
+	Flags:  cacheFlags(),
+	Action: runCacheStats,
+}
+
+var cacheClearCmd = &ucli.Command{
+	Name:   "clear",
+	Usage:  "Remove all cached results",
+	Flags:  cacheFlags(),
+	Action: runCacheClear,
+}
+
+// cacheFlags are flags shared by the cache subcommands
+func cacheFlags() []ucli.Flag {
+	return []ucli.Flag{
+		&ucli.StringFlag{
+			Name:    "config",
+			Aliases: []string{"c"},
+			Usage:   "path to configuration file (for cache.dir)",
+		},
+		&ucli.StringFlag{
+			Name:  "dir",
+			Usage: "cache directory (overrides config)",
+		},
+		&ucli.BoolFlag{
+			Name:  "json",
+			Usage: "output as JSON",
+		},
+	}
+}
+
+// openDiskCache opens the disk cache selected by --dir or --config
+func openDiskCache(cmd *ucli.Command) (*cache.Disk, error) {
+	dir := cmd.String("dir")
+	cacheCfg := &config.CacheConfig{}
+	if dir == "" {
+		dir = config.DefaultCacheDir()
+		if configPath := cmd.String("config"); configPath != "" {
+			cfg, err := config.LoadConfig(configPath)
+			if err != nil {
+				return nil, err
+			}
+			if cfg.Cache != nil {
+				if cfg.Cache.GetStore() != config.CacheStoreDisk {
+					return nil, fmt.Errorf("config uses the %s cache store; only the disk store can be inspected", cfg.Cache.GetStore())
+				}
+				cacheCfg = cfg.Cache
+				dir = cfg.Cache.GetDir()
+			}
+		}
+	}
+	return cache.NewDisk(dir, cacheCfg.GetMaxEntries(), cacheCfg.GetMaxBytes())
+}
+
+func runCacheStats(ctx context.Context, cmd *ucli.Command) error {
+	disk, err := openDiskCache(cmd)
+	if err != nil {
+		return err
+	}
+
+	stats := disk.Stats()
+
+	if cmd.Bool("json") {
+		output, err := json.MarshalIndent(struct {
+			Dir     string `json:"dir"`
+			Entries int    `json:"entries"`
+			Bytes   int64  `json:"bytes"`
+		}{disk.Dir(), stats.Entries, stats.Bytes}, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		fmt.Println(string(output))
+		return nil
+	}
+
+	fmt.Printf("Directory: %s\n", disk.Dir())
+	fmt.Printf("Entries:   %d\n", stats.Entries)
+	fmt.Printf("Size:      %d bytes\n", stats.Bytes)
+	return nil
+}
+
+func runCacheClear(ctx context.Context, cmd *ucli.Command) error {
+	disk, err := openDiskCache(cmd)
+	if err != nil {
+		return err
+	}
+
+	entries := disk.Stats().Entries
+	if err := disk.Clear(); err != nil {
+		return err
+	}
+
+	fmt.Printf("Cleared %d cached results from %s\n", entries, disk.Dir())
+	return nil
+}
@@ -0,0 +1,173 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/vaayne/mcphub/internal/catalog"
+	"github.com/vaayne/mcphub/internal/config"
+	"github.com/vaayne/mcphub/internal/startup"
+	"github.com/vaayne/mcphub/internal/transport"
+
+	ucli "github.com/urfave/cli/v3"
+)
+
+// CatalogCmd is the catalog subcommand for managing the persistent tool catalog
+var CatalogCmd = &ucli.Command{
+	Name:  "catalog",
+	Usage: "Manage the persistent tool catalog",
+	Description: `Manage the on-disk catalog of the tools, prompts and resources offered by
+each server in --config.
+
+The hub and "mh list/inspect -c" read the catalog instead of connecting to
+servers whose entry is still valid. Entries are refreshed whenever a server
+connects, and ignored once the server's connection settings change or the
+entry is older than "catalog.ttl".
+
+Examples:
+  mh catalog sync -c config.json
+  mh catalog sync -c config.json --json`,
+	Commands: []*ucli.Command{
+		catalogSyncCmd,
+	},
+}
+
+var catalogSyncCmd = &ucli.Command{
+	Name:  "sync",
+	Usage: "Connect to every enabled server and refresh its catalog entry",
+	Flags: []ucli.Flag{
+		&ucli.StringFlag{
+			Name:     "config",
+			Aliases:  []string{"c"},
+			Usage:    "path to configuration file",
+			Required: true,
+		},
+		&ucli.IntFlag{
+			Name:  "timeout",
+			Usage: "connection timeout in seconds (default: startup.connectTimeout)",
+		},
+		&ucli.BoolFlag{
+			Name:  "json",
+			Usage: "output as JSON",
+		},
+		&ucli.BoolFlag{
+			Name:  "verbose",
+			Usage: "verbose logging",
+		},
+		&ucli.StringFlag{
+			Name:  "log-file",
+			Usage: "log file path (empty disables file logging)",
+		},
+	},
+	Action: runCatalogSync,
+}
+
+// catalogSyncResult is the outcome of syncing one server
+type catalogSyncResult struct {
+	Server    string        `json:"server"`
+	Tools     int           `json:"tools"`
+	Prompts   int           `json:"prompts"`
+	Resources int           `json:"resources"`
+	Duration  time.Duration `json:"duration"`
+	Error     string        `json:"error,omitempty"`
+}
+
+func runCatalogSync(ctx context.Context, cmd *ucli.Command) error {
+	cfg, err := config.LoadConfig(cmd.String("config"))
+	if err != nil {
+		return err
+	}
+
+	catalogCfg := cfg.GetCatalog()
+	if !catalogCfg.IsEnabled() {
+		return fmt.Errorf("the tool catalog is disabled in the config")
+	}
+	store, err := catalog.NewStore(catalogCfg.GetDir(), catalogCfg.GetTTL())
+	if err != nil {
+		return err
+	}
+	// Servers that fail to sync keep their last entry; only servers no longer
+	// in the config are dropped
+	if err := store.Prune(func(serverID string) bool {
+		_, ok := cfg.MCPServers[serverID]
+		return ok
+	}); err != nil {
+		return err
+	}
+
+	logger := getLogger(cmd)
+	factory := transport.NewDefaultFactory(logger)
+	timeout := connectTimeout(cmd)
+	if timeout <= 0 {
+		timeout = cfg.GetStartup().GetConnectTimeout()
+	}
+
+	var mu sync.Mutex
+	counts := make(map[string]catalogSyncResult)
+	report := startup.Run(ctx, cfg.MCPServers, cfg.GetStartup().GetParallelism(),
+		func(ctx context.Context, serverID string, serverCfg config.MCPServer) error {
+			session, listing, err := connectServer(ctx, factory, serverID, serverCfg, timeout, logger)
+			if err != nil {
+				return err
+			}
+			session.Close()
+			if err := store.Save(serverID, serverCfg, listing); err != nil {
+				return err
+			}
+
+			mu.Lock()
+			counts[serverID] = catalogSyncResult{
+				Tools:     len(listing.Tools),
+				Prompts:   len(listing.Prompts),
+				Resources: len(listing.Resources),
+			}
+			mu.Unlock()
+			return nil
+		})
+
+	results := make([]catalogSyncResult, 0, len(report.Results))
+	for _, r := range report.Results {
+		if r.Status == startup.StatusSkipped {
+			continue
+		}
+		result := counts[r.Server]
+		result.Server = r.Server
+		result.Duration = r.Duration
+		if r.Status == startup.StatusFailed {
+			logger.Debug("Failed to sync server", slog.String("serverID", r.Server), slog.String("error", r.Note))
+			result.Error = r.Note
+		}
+		results = append(results, result)
+	}
+	failed := report.Count(startup.StatusFailed)
+
+	if cmd.Bool("json") {
+		output, err := json.MarshalIndent(struct {
+			Dir     string              `json:"dir"`
+			Servers []catalogSyncResult `json:"servers"`
+		}{store.Dir(), results}, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		fmt.Println(string(output))
+	} else {
+		for _, r := range results {
+			if r.Error != "" {
+				fmt.Printf("%s: failed: %s\n", r.Server, r.Error)
+				continue
+			}
+			fmt.Printf("%s: %d tools, %d prompts, %d resources (%s)\n",
+				r.Server, r.Tools, r.Prompts, r.Resources, r.Duration.Round(time.Millisecond))
+		}
+		fmt.Printf("Catalog written to %s\n", store.Dir())
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d servers failed to sync", failed, len(results))
+	}
+	return nil
+}
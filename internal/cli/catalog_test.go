@@ -0,0 +1,42 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vaayne/mcphub/internal/catalog"
+	"github.com/vaayne/mcphub/internal/config"
+)
+
+func TestCatalogSync_KeepsEntriesOfFailedServers(t *testing.T) {
+	dir := t.TempDir()
+	catalogDir := filepath.Join(dir, "catalog")
+	configPath := filepath.Join(dir, "config.json")
+	require.NoError(t, os.WriteFile(configPath, []byte(fmt.Sprintf(`{
+		"catalog": {"dir": %q},
+		"mcpServers": {"missing": {"command": "mh-test-does-not-exist"}}
+	}`, catalogDir)), 0o600))
+
+	cfg, err := config.LoadConfig(configPath)
+	require.NoError(t, err)
+	store, err := catalog.NewStore(catalogDir, 0)
+	require.NoError(t, err)
+	listing := &catalog.Listing{Tools: []*mcp.Tool{{Name: "search", InputSchema: map[string]any{"type": "object"}}}}
+	require.NoError(t, store.Save("missing", cfg.MCPServers["missing"], listing))
+	require.NoError(t, store.Save("removed", config.MCPServer{Command: "old"}, listing))
+
+	err = catalogSyncCmd.Run(context.Background(), []string{"sync", "-c", configPath, "--timeout", "1"})
+	assert.ErrorContains(t, err, "1 of 1 servers failed to sync")
+
+	// The failed server keeps its last good entry; servers gone from the config are dropped
+	_, ok := store.Load("missing", cfg.MCPServers["missing"])
+	assert.True(t, ok)
+	_, ok = store.Load("removed", config.MCPServer{Command: "old"})
+	assert.False(t, ok)
+}
@@ -5,10 +5,18 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"os"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/vaayne/mcphub/internal/cache"
+	"github.com/vaayne/mcphub/internal/catalog"
 	"github.com/vaayne/mcphub/internal/config"
+	"github.com/vaayne/mcphub/internal/retry"
+	"github.com/vaayne/mcphub/internal/search"
+	"github.com/vaayne/mcphub/internal/startup"
+	"github.com/vaayne/mcphub/internal/toolname"
 	"github.com/vaayne/mcphub/internal/transport"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
@@ -16,17 +24,33 @@ import (
 
 type ConfigClient struct {
 	logger   *slog.Logger
+	ctx      context.Context // used to connect to servers listed from the catalog
+	factory  transport.Factory
+	timeout  time.Duration
+	mu       sync.Mutex // guards sessions
 	sessions map[string]*mcp.ClientSession
 	tools    map[string]*mcp.Tool
 	refs     map[string]toolRef
+	servers  map[string]config.MCPServer // serverID -> config of listed servers
+	catalog  *catalog.Store              // nil = catalog disabled
+	cache    cache.Cache                 // nil = tool results are not cached
+	cacheTTL time.Duration
+	searcher *search.Searcher
 }
 
 type toolRef struct {
-	serverID string
-	toolName string
+	serverID   string
+	name       string // name the server's config exposes the tool as
+	toolName   string // backend tool name
+	readOnly   bool   // tool is annotated readOnlyHint
+	idempotent bool   // tool is annotated readOnlyHint or idempotentHint
 }
 
-func NewConfigClient(ctx context.Context, configPath string, logger *slog.Logger, timeout time.Duration) (*ConfigClient, error) {
+// NewConfigClient lists the tools of every enabled server in the config file.
+// Servers with a fresh catalog entry are listed from the catalog and connected
+// only when one of their tools is called; refresh connects to every server.
+// A zero timeout uses the config's startup.connectTimeout.
+func NewConfigClient(ctx context.Context, configPath string, logger *slog.Logger, timeout time.Duration, refresh bool) (*ConfigClient, error) {
 	if configPath == "" {
 		return nil, fmt.Errorf("--config is required for config mode")
 	}
@@ -36,94 +60,237 @@ func NewConfigClient(ctx context.Context, configPath string, logger *slog.Logger
 		return nil, err
 	}
 
+	// Without --timeout, servers get the same connect timeout as under mh serve
+	if timeout <= 0 {
+		timeout = cfg.GetStartup().GetConnectTimeout()
+	}
+
+	resultCache, err := cache.New(cfg.Cache)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cache: %w", err)
+	}
+
+	searcher, err := search.New(cfg.Search)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create tool search: %w", err)
+	}
+
 	client := &ConfigClient{
 		logger:   logger,
+		ctx:      ctx,
+		factory:  transport.NewDefaultFactory(logger),
+		timeout:  timeout,
 		sessions: make(map[string]*mcp.ClientSession),
 		tools:    make(map[string]*mcp.Tool),
 		refs:     make(map[string]toolRef),
+		servers:  make(map[string]config.MCPServer),
+		cache:    resultCache,
+		searcher: searcher,
+	}
+	if cfg.Cache != nil {
+		client.cacheTTL = cfg.Cache.GetTTL()
+	}
+	if catalogCfg := cfg.GetCatalog(); catalogCfg.IsEnabled() {
+		store, err := catalog.NewStore(catalogCfg.GetDir(), catalogCfg.GetTTL())
+		if err != nil {
+			logger.Warn("Tool catalog unavailable", slog.String("error", err.Error()))
+		} else {
+			client.catalog = store
+		}
 	}
 
-	factory := transport.NewDefaultFactory(logger)
-	var optionalErrors []error
+	// Servers connect concurrently; their tools are added afterwards in a
+	// stable order
+	var mu sync.Mutex
+	listings := make(map[string][]*mcp.Tool)
+	report := startup.Run(ctx, cfg.MCPServers, cfg.GetStartup().GetParallelism(),
+		func(ctx context.Context, serverID string, serverCfg config.MCPServer) error {
+			if !refresh && client.catalog != nil {
+				if entry, ok := client.catalog.Load(serverID, serverCfg); ok {
+					logger.Debug("Listing server from catalog",
+						slog.String("serverID", serverID),
+						slog.Time("updatedAt", entry.UpdatedAt))
+					mu.Lock()
+					client.servers[serverID] = serverCfg
+					listings[serverID] = entry.Tools
+					mu.Unlock()
+					return startup.Skip("listed from catalog")
+				}
+			}
 
-	for serverID, serverCfg := range cfg.MCPServers {
-		if !serverCfg.IsEnabled() {
-			logger.Info("Skipping disabled server", slog.String("serverID", serverID))
-			continue
-		}
+			logger.Info("Connecting to server", slog.String("serverID", serverID))
+			session, listing, err := client.connect(ctx, serverID, serverCfg)
+			if err != nil {
+				return err
+			}
 
-		logger.Info("Connecting to server", slog.String("serverID", serverID))
-		transportName := strings.ToLower(serverCfg.GetTransport())
+			mu.Lock()
+			client.sessions[serverID] = session
+			client.servers[serverID] = serverCfg
+			listings[serverID] = listing.Tools
+			mu.Unlock()
+			return nil
+		})
+
+	failed := report.Count(startup.StatusFailed)
+	if failed > 0 || logger.Enabled(ctx, slog.LevelDebug) {
+		_ = report.WriteTable(os.Stderr)
+	}
+	if err := report.Err(); err != nil {
+		client.Close()
+		return nil, err
+	}
+	if failed > 0 {
+		logger.Warn("Some optional servers failed to connect", slog.Int("count", failed))
+	}
 
-		mcpTransport, err := factory.CreateTransport(serverCfg)
-		if err != nil {
-			if serverCfg.Required {
-				return nil, fmt.Errorf("required server %s failed to create transport: %w", serverID, err)
+	for _, result := range report.Results {
+		if tools, ok := listings[result.Server]; ok {
+			if err := client.addTools(result.Server, tools); err != nil {
+				client.Close()
+				return nil, err
 			}
-			optionalErrors = append(optionalErrors, fmt.Errorf("server %s: %w", serverID, err))
-			continue
 		}
+	}
 
-		clientInstance := mcp.NewClient(&mcp.Implementation{
-			Name:    "mh-cli",
-			Version: "v1.0.0",
-		}, nil)
-
-		connectCtx, cancel := context.WithTimeout(ctx, timeout)
-		session, err := clientInstance.Connect(connectCtx, mcpTransport, nil)
-		// For non-SSE transports, cancel immediately after connect.
-		// For SSE, the context is used by background goroutines, so we don't cancel it here.
-		// The context will be canceled when the parent ctx is canceled.
-		if transportName != "sse" {
-			cancel()
-		} else {
-			// Acknowledge that we're intentionally not canceling for SSE.
-			// The cancel func will be called when the parent context is done.
-			_ = cancel
+	return client, nil
+}
+
+// addTools registers the tools of a server under namespaced names, as the
+// server's config exposes them
+func (c *ConfigClient) addTools(serverID string, tools []*mcp.Tool) error {
+	serverCfg := c.servers[serverID]
+	exposed, err := toolname.Expose(serverCfg, tools)
+	if err != nil {
+		return fmt.Errorf("server %s: %w", serverID, err)
+	}
+	for _, tool := range exposed {
+		namespacedName := serverCfg.QualifiedToolName(serverID, tool.Name)
+		if _, exists := c.tools[namespacedName]; exists {
+			return fmt.Errorf("duplicate tool name detected: %s", namespacedName)
 		}
-		if err != nil {
-			cancel() // Always cancel on error
-			if serverCfg.Required {
-				return nil, fmt.Errorf("required server %s failed to connect: %w", serverID, err)
-			}
-			optionalErrors = append(optionalErrors, fmt.Errorf("server %s: %w", serverID, err))
-			continue
+
+		t := *tool
+		t.Name = namespacedName
+		c.tools[namespacedName] = &t
+		backendName, _ := serverCfg.BackendToolName(tool.Name)
+		ref := toolRef{
+			serverID: serverID,
+			name:     tool.Name,
+			toolName: backendName,
+		}
+		if tool.Annotations != nil {
+			ref.readOnly = tool.Annotations.ReadOnlyHint
+			ref.idempotent = tool.Annotations.ReadOnlyHint || tool.Annotations.IdempotentHint
 		}
+		c.refs[namespacedName] = ref
+	}
+	return nil
+}
 
-		toolsCtx, toolsCancel := context.WithTimeout(ctx, timeout)
-		toolsResult, err := session.ListTools(toolsCtx, nil)
-		toolsCancel()
-		if err != nil {
-			session.Close()
-			if serverCfg.Required {
-				return nil, fmt.Errorf("required server %s failed to list tools: %w", serverID, err)
-			}
-			optionalErrors = append(optionalErrors, fmt.Errorf("server %s: %w", serverID, err))
-			continue
+// connect connects to a server and records what it offers in the catalog
+func (c *ConfigClient) connect(ctx context.Context, serverID string, serverCfg config.MCPServer) (*mcp.ClientSession, *catalog.Listing, error) {
+	session, listing, err := connectServer(ctx, c.factory, serverID, serverCfg, c.timeout, c.logger)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if c.catalog != nil {
+		if err := c.catalog.Save(serverID, serverCfg, listing); err != nil {
+			c.logger.Warn("Failed to save tool catalog",
+				slog.String("serverID", serverID),
+				slog.String("error", err.Error()))
 		}
+	}
+	return session, listing, nil
+}
 
-		client.sessions[serverID] = session
+// session returns the session of a server, connecting to it if it was listed
+// from the catalog
+func (c *ConfigClient) session(serverID string) (*mcp.ClientSession, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
-		for _, tool := range toolsResult.Tools {
-			namespacedName := fmt.Sprintf("%s__%s", serverID, tool.Name)
-			if _, exists := client.tools[namespacedName]; exists {
-				return nil, fmt.Errorf("duplicate tool name detected: %s", namespacedName)
-			}
+	if session, ok := c.sessions[serverID]; ok {
+		return session, nil
+	}
 
-			client.tools[namespacedName] = &mcp.Tool{
-				Name:        namespacedName,
-				Description: tool.Description,
-				InputSchema: tool.InputSchema,
-			}
-			client.refs[namespacedName] = toolRef{serverID: serverID, toolName: tool.Name}
+	serverCfg, ok := c.servers[serverID]
+	if !ok {
+		return nil, fmt.Errorf("server not connected: %s", serverID)
+	}
+
+	c.logger.Info("Connecting to server", slog.String("serverID", serverID))
+	session, _, err := c.connect(c.ctx, serverID, serverCfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to server %s: %w", serverID, err)
+	}
+	c.sessions[serverID] = session
+	return session, nil
+}
+
+// connectServer connects to a server and lists what it offers, within the
+// server's connectTimeout or else timeout. Replicas serve the same tools, so
+// the first one that answers will do.
+func connectServer(ctx context.Context, factory transport.Factory, serverID string, serverCfg config.MCPServer, timeout time.Duration, logger *slog.Logger) (*mcp.ClientSession, *catalog.Listing, error) {
+	timeout = serverCfg.GetConnectTimeout(timeout)
+	var err error
+	for i, endpoint := range serverCfg.Endpoints() {
+		var session *mcp.ClientSession
+		var listing *catalog.Listing
+		session, listing, err = connectEndpoint(ctx, factory, endpoint, timeout)
+		if err == nil {
+			return session, listing, nil
 		}
+		if len(serverCfg.Replicas) > 0 {
+			logger.Warn("Failed to connect to replica",
+				slog.String("serverID", fmt.Sprintf("%s[%d]", serverID, i)),
+				slog.String("error", err.Error()))
+		}
+	}
+	return nil, nil, err
+}
+
+// connectEndpoint connects to a single server endpoint and lists what it offers
+func connectEndpoint(ctx context.Context, factory transport.Factory, serverCfg config.MCPServer, timeout time.Duration) (*mcp.ClientSession, *catalog.Listing, error) {
+	transportName := strings.ToLower(serverCfg.GetTransport())
+
+	mcpTransport, err := factory.CreateTransport(serverCfg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create transport: %w", err)
 	}
 
-	if len(optionalErrors) > 0 {
-		logger.Warn("Some optional servers failed to connect", slog.Int("count", len(optionalErrors)))
+	clientInstance := mcp.NewClient(&mcp.Implementation{
+		Name:    "mh-cli",
+		Version: "v1.0.0",
+	}, nil)
+
+	connectCtx, cancel := context.WithTimeout(ctx, timeout)
+	session, err := clientInstance.Connect(connectCtx, mcpTransport, nil)
+	// For non-SSE transports, cancel immediately after connect.
+	// For SSE, the context is used by background goroutines, so we don't cancel it here.
+	// The context will be canceled when the parent ctx is canceled.
+	if transportName != "sse" {
+		cancel()
+	} else {
+		// Acknowledge that we're intentionally not canceling for SSE.
+		// The cancel func will be called when the parent context is done.
+		_ = cancel
+	}
+	if err != nil {
+		cancel() // Always cancel on error
+		return nil, nil, fmt.Errorf("failed to connect: %w", err)
 	}
 
-	return client, nil
+	listCtx, listCancel := context.WithTimeout(ctx, timeout)
+	listing, err := catalog.Discover(listCtx, session)
+	listCancel()
+	if err != nil {
+		session.Close()
+		return nil, nil, fmt.Errorf("failed to list tools: %w", err)
+	}
+
+	return session, listing, nil
 }
 
 func (c *ConfigClient) ListTools(ctx context.Context) ([]*mcp.Tool, error) {
@@ -160,9 +327,9 @@ func (c *ConfigClient) CallTool(ctx context.Context, namespacedName string, para
 		return nil, fmt.Errorf("tool '%s' not found", namespacedName)
 	}
 
-	session, ok := c.sessions[ref.serverID]
-	if !ok {
-		return nil, fmt.Errorf("server not connected: %s", ref.serverID)
+	session, err := c.session(ref.serverID)
+	if err != nil {
+		return nil, err
 	}
 
 	var args map[string]any
@@ -172,12 +339,23 @@ func (c *ConfigClient) CallTool(ctx context.Context, namespacedName string, para
 		}
 	}
 
-	callParams := &mcp.CallToolParams{
-		Name:      ref.toolName,
-		Arguments: args,
+	serverCfg := c.servers[ref.serverID]
+	call := func() (*mcp.CallToolResult, error) {
+		return retry.Do(ctx, serverCfg.RetryPolicy(ref.toolName, ref.idempotent), func() (*mcp.CallToolResult, error) {
+			return session.CallTool(ctx, &mcp.CallToolParams{
+				Name:      ref.toolName,
+				Arguments: args,
+			})
+		}, func(attempt int, err error, delay time.Duration) {
+			c.logger.Warn("Retrying tool call",
+				slog.String("tool", namespacedName),
+				slog.Int("attempt", attempt),
+				slog.Duration("delay", delay),
+				slog.String("error", err.Error()))
+		})
 	}
 
-	result, err := session.CallTool(ctx, callParams)
+	result, err := c.callCached(ref, args, call)
 	if err != nil {
 		return nil, fmt.Errorf("failed to call tool '%s': %w", namespacedName, err)
 	}
@@ -185,7 +363,64 @@ func (c *ConfigClient) CallTool(ctx context.Context, namespacedName string, para
 	return result, nil
 }
 
+// callCached serves cacheable tool calls from the response cache
+func (c *ConfigClient) callCached(ref toolRef, args map[string]any, call func() (*mcp.CallToolResult, error)) (*mcp.CallToolResult, error) {
+	if c.cache == nil {
+		return call()
+	}
+
+	serverCfg := c.servers[ref.serverID]
+	ttl, cacheable := serverCfg.CachePolicy(ref.toolName, ref.readOnly, c.cacheTTL)
+	if !cacheable {
+		return call()
+	}
+
+	key, err := cache.Key(ref.serverID, ref.toolName, args)
+	if err != nil {
+		return call()
+	}
+	return cache.Call(c.cache, key, ttl, call)
+}
+
+// ResolveTool implements toolname.Resolver
+func (c *ConfigClient) ResolveTool(name string) (serverID, toolName string, ok bool) {
+	ref, ok := c.refs[name]
+	if !ok {
+		return "", "", false
+	}
+	return ref.serverID, ref.name, true
+}
+
+// Searcher implements tools.ToolSearcher
+func (c *ConfigClient) Searcher() *search.Searcher {
+	return c.searcher
+}
+
+// QualifiedToolName returns the name a server's tool is listed under
+func (c *ConfigClient) QualifiedToolName(serverID, toolName string) string {
+	serverCfg, ok := c.servers[serverID]
+	if !ok {
+		return serverID + config.DefaultSeparator + toolName
+	}
+	return serverCfg.QualifiedToolName(serverID, toolName)
+}
+
+// ValidatesArgs reports whether tool arguments for a server are validated
+func (c *ConfigClient) ValidatesArgs(serverID string) bool {
+	serverCfg, ok := c.servers[serverID]
+	return !ok || serverCfg.ShouldValidateArgs()
+}
+
+// CoercesArgs reports whether tool arguments for a server are coerced
+func (c *ConfigClient) CoercesArgs(serverID string) bool {
+	serverCfg, ok := c.servers[serverID]
+	return !ok || serverCfg.ShouldCoerceArgs()
+}
+
 func (c *ConfigClient) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	var errs []error
 	for serverID, session := range c.sessions {
 		if session == nil {
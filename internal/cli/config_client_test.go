@@ -2,16 +2,23 @@ package cli
 
 import (
 	"context"
+	"fmt"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
+	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vaayne/mcphub/internal/catalog"
+	"github.com/vaayne/mcphub/internal/config"
 	"github.com/vaayne/mcphub/internal/logging"
+	"github.com/vaayne/mcphub/internal/tools"
 )
 
 func TestNewConfigClient_RequiresConfigPath(t *testing.T) {
-	client, err := NewConfigClient(context.Background(), "", logging.NopLogger(), time.Second)
+	client, err := NewConfigClient(context.Background(), "", logging.NopLogger(), time.Second, false)
 	assert.Nil(t, client)
 	assert.Error(t, err)
 }
@@ -25,7 +32,106 @@ func TestNewConfigClient_InvalidConfig(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NoError(t, file.Close())
 
-	client, err := NewConfigClient(context.Background(), file.Name(), logging.NopLogger(), time.Second)
+	client, err := NewConfigClient(context.Background(), file.Name(), logging.NopLogger(), time.Second, false)
 	assert.Nil(t, client)
 	assert.Error(t, err)
 }
+
+func TestNewConfigClient_StartupConnectTimeout(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	require.NoError(t, os.WriteFile(configPath, []byte(`{
+		"startup": {"connectTimeout": 7},
+		"mcpServers": {"off": {"command": "mh-test-does-not-exist", "enable": false}}
+	}`), 0o600))
+
+	// Without --timeout the config's startup default applies, as under mh serve
+	client, err := NewConfigClient(context.Background(), configPath, logging.NopLogger(), 0, false)
+	require.NoError(t, err)
+	defer client.Close()
+	assert.Equal(t, 7*time.Second, client.timeout)
+
+	client, err = NewConfigClient(context.Background(), configPath, logging.NopLogger(), time.Second, false)
+	require.NoError(t, err)
+	defer client.Close()
+	assert.Equal(t, time.Second, client.timeout)
+}
+
+func TestNewConfigClient_ListsFromCatalog(t *testing.T) {
+	dir := t.TempDir()
+	catalogDir := filepath.Join(dir, "catalog")
+	configPath := filepath.Join(dir, "config.json")
+	require.NoError(t, os.WriteFile(configPath, []byte(fmt.Sprintf(`{
+		"catalog": {"dir": %q},
+		"mcpServers": {"missing": {"command": "mh-test-does-not-exist"}}
+	}`, catalogDir)), 0o600))
+
+	cfg, err := config.LoadConfig(configPath)
+	require.NoError(t, err)
+	store, err := catalog.NewStore(catalogDir, 0)
+	require.NoError(t, err)
+	require.NoError(t, store.Save("missing", cfg.MCPServers["missing"], &catalog.Listing{
+		Tools: []*mcp.Tool{{Name: "search", InputSchema: map[string]any{"type": "object"}}},
+	}))
+
+	// The server is never started to list its tools
+	client, err := NewConfigClient(context.Background(), configPath, logging.NopLogger(), time.Second, false)
+	require.NoError(t, err)
+	defer client.Close()
+
+	tool, err := client.GetTool(context.Background(), "missing__search")
+	require.NoError(t, err)
+	assert.Equal(t, "missing__search", tool.Name)
+
+	// Calling a tool connects on demand
+	_, err = client.CallTool(context.Background(), "missing__search", nil)
+	assert.ErrorContains(t, err, "failed to connect to server missing")
+
+	// Refresh ignores the catalog
+	client, err = NewConfigClient(context.Background(), configPath, logging.NopLogger(), time.Second, true)
+	require.NoError(t, err)
+	defer client.Close()
+	_, err = client.GetTool(context.Background(), "missing__search")
+	assert.Error(t, err)
+}
+
+func TestNewConfigClient_KeepsOutputSchema(t *testing.T) {
+	dir := t.TempDir()
+	catalogDir := filepath.Join(dir, "catalog")
+	configPath := filepath.Join(dir, "config.json")
+	require.NoError(t, os.WriteFile(configPath, []byte(fmt.Sprintf(`{
+		"catalog": {"dir": %q},
+		"mcpServers": {"missing": {"command": "mh-test-does-not-exist"}}
+	}`, catalogDir)), 0o600))
+
+	cfg, err := config.LoadConfig(configPath)
+	require.NoError(t, err)
+	store, err := catalog.NewStore(catalogDir, 0)
+	require.NoError(t, err)
+	require.NoError(t, store.Save("missing", cfg.MCPServers["missing"], &catalog.Listing{
+		Tools: []*mcp.Tool{{
+			Name:        "search",
+			InputSchema: map[string]any{"type": "object"},
+			OutputSchema: map[string]any{
+				"type":       "object",
+				"properties": map[string]any{"total": map[string]any{"type": "integer"}},
+			},
+			Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true},
+		}},
+	}))
+
+	client, err := NewConfigClient(context.Background(), configPath, logging.NopLogger(), time.Second, false)
+	require.NoError(t, err)
+	defer client.Close()
+
+	tool, err := client.GetTool(context.Background(), "missing__search")
+	require.NoError(t, err)
+	assert.NotNil(t, tool.OutputSchema)
+	require.NotNil(t, tool.Annotations)
+	assert.True(t, tool.Annotations.ReadOnlyHint)
+
+	// mh types -c declares the result interface
+	output, err := tools.GenerateTypes(context.Background(), client, tools.TypesOptions{})
+	require.NoError(t, err)
+	assert.Contains(t, output, "total?: number")
+	assert.NotContains(t, output, "= any;")
+}
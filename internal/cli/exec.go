@@ -7,8 +7,14 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"strings"
 
+	"github.com/vaayne/mcphub/internal/config"
 	"github.com/vaayne/mcphub/internal/js"
+	"github.com/vaayne/mcphub/internal/schema"
+	"github.com/vaayne/mcphub/internal/state"
+	"github.com/vaayne/mcphub/internal/textutil"
 	"github.com/vaayne/mcphub/internal/toolname"
 	"github.com/vaayne/mcphub/internal/tools"
 
@@ -20,7 +26,7 @@ import (
 var ExecCmd = &ucli.Command{
 	Name:      "exec",
 	Usage:     "Execute JavaScript code to orchestrate multiple MCP tool calls",
-	ArgsUsage: "<code | ->",
+	ArgsUsage: "<code | -> | --file <path>",
 	Description: `Execute JavaScript code that can call multiple MCP tools with logic.
 
 Use this when you need to:
@@ -43,15 +49,46 @@ Examples:
   # Read code from stdin
   cat script.js | mh exec -c config.json -
 
+  # Run a TypeScript file (types are removed before running)
+  mh exec -c config.json --file script.ts
+
   # With remote server (use tool names directly)
   mh exec -u http://localhost:3000 'const a = mcp.callTool("add", {x: 1, y: 2}); mcp.callTool("multiply", {x: a, y: 3})'
 
   # With stdio server (use tool names directly)
   mh exec --stdio 'mcp.callTool("echo", {message: "hello"})' -- npx @modelcontextprotocol/server-everything
 
+  # Run tool calls in parallel
+  mh exec -c config.json --max-concurrency 8 'Promise.all(["a", "b"].map(q => mcp.callToolAsync("exaSearch", {query: q})))'
+
+  # Trace the tool calls of a failing script
+  mh exec -c config.json --trace --file script.js
+
   # JSON output
   mh exec -c config.json --json 'mcp.callTool("githubListRepos", {})'`,
-	Flags:  MCPClientFlags(),
+	Flags: append(MCPClientFlags(),
+		&ucli.IntFlag{
+			Name:  "max-concurrency",
+			Usage: fmt.Sprintf("mcp.callToolAsync calls in flight at once (default %d, max %d)", js.DefaultMaxConcurrency, js.MaxConcurrencyLimit),
+		},
+		&ucli.StringFlag{
+			Name:    "file",
+			Aliases: []string{"f"},
+			Usage:   "read the code from a file; .ts, .mts and .cts files run as TypeScript",
+		},
+		&ucli.StringFlag{
+			Name:  "language",
+			Usage: "language of the code: javascript or typescript (default from the --file extension, else javascript)",
+		},
+		&ucli.StringFlag{
+			Name:  "scripts-dir",
+			Usage: "script library whose modules scripts require as lib/name (default from --config, else the user config directory)",
+		},
+		&ucli.BoolFlag{
+			Name:  "trace",
+			Usage: "print a timeline of the tool calls, and the source line and stack of a failure, to stderr",
+		},
+	),
 	Before: ValidateMCPClientFlags,
 	Action: runExec,
 }
@@ -64,6 +101,16 @@ type cliToolCaller struct {
 	defaultServer string
 	// mapper converts JS names back to original tool names
 	mapper *toolname.Mapper
+	// policy decides per-server argument validation and coercion (nil enables both)
+	policy interface {
+		schema.Policy
+		schema.CoercionPolicy
+	}
+	// names resolves and builds qualified tool names (nil = the default prefix and separator)
+	names interface {
+		toolname.Resolver
+		QualifiedToolName(serverID, toolName string) string
+	}
 }
 
 func (c *cliToolCaller) CallTool(ctx context.Context, serverID, toolName string, params map[string]any) (*mcp.CallToolResult, error) {
@@ -72,9 +119,11 @@ func (c *cliToolCaller) CallTool(ctx context.Context, serverID, toolName string,
 	if c.defaultServer != "" {
 		// Single server mode: ignore serverID, use toolName directly
 		fullName = toolName
+	} else if c.names != nil {
+		// Multi-server mode: use the name the ConfigClient lists the tool under
+		fullName = c.names.QualifiedToolName(serverID, toolName)
 	} else {
-		// Multi-server mode: use serverID__toolName format (already namespaced in ConfigClient)
-		fullName = serverID + "__" + toolName
+		fullName = (&config.MCPServer{}).QualifiedToolName(serverID, toolName)
 	}
 
 	// Convert params to JSON
@@ -95,6 +144,30 @@ func (c *cliToolCaller) CallTool(ctx context.Context, serverID, toolName string,
 	return c.callFn(ctx, fullName, paramsJSON)
 }
 
+// ValidatesArgs implements schema.Policy, deferring to the underlying client
+func (c *cliToolCaller) ValidatesArgs(serverID string) bool {
+	if c.policy == nil {
+		return true
+	}
+	return c.policy.ValidatesArgs(serverID)
+}
+
+// CoercesArgs implements schema.CoercionPolicy, deferring to the underlying client
+func (c *cliToolCaller) CoercesArgs(serverID string) bool {
+	if c.policy == nil {
+		return true
+	}
+	return c.policy.CoercesArgs(serverID)
+}
+
+// ResolveTool implements toolname.Resolver, deferring to the underlying client
+func (c *cliToolCaller) ResolveTool(name string) (serverID, toolName string, ok bool) {
+	if c.names == nil {
+		return "", "", false
+	}
+	return c.names.ResolveTool(name)
+}
+
 // ListTools implements js.ToolCaller interface
 func (c *cliToolCaller) ListTools(ctx context.Context) ([]*mcp.Tool, error) {
 	return c.listFn(ctx)
@@ -103,142 +176,285 @@ func (c *cliToolCaller) ListTools(ctx context.Context) ([]*mcp.Tool, error) {
 func runExec(ctx context.Context, cmd *ucli.Command) error {
 	args := cmd.Args().Slice()
 	filteredArgs := filterArgsBeforeDash(args)
-	if len(filteredArgs) != 1 {
-		return fmt.Errorf("accepts 1 arg (code or -), received %d", len(filteredArgs))
-	}
-
-	configPath := cmd.String("config")
-	stdio := cmd.Bool("stdio")
-	codeArg := filteredArgs[0]
+	file := cmd.String("file")
 
 	var code string
-	if codeArg == "-" {
-		// Check if stdin is a TTY
-		stat, _ := os.Stdin.Stat()
-		if (stat.Mode() & os.ModeCharDevice) != 0 {
-			return fmt.Errorf("stdin is a terminal; pipe code or use argument instead")
-		}
-		// Read from stdin
-		reader := bufio.NewReader(os.Stdin)
-		input, err := io.ReadAll(reader)
+	language := cmd.String("language")
+	if file != "" {
+		if len(filteredArgs) != 0 {
+			return fmt.Errorf("accepts no code argument with --file, received %d", len(filteredArgs))
+		}
+		data, err := os.ReadFile(file)
 		if err != nil {
-			return fmt.Errorf("failed to read from stdin: %w", err)
+			return fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		code = string(data)
+		if language == "" {
+			language = languageFromPath(file)
 		}
-		code = string(input)
 	} else {
-		code = codeArg
+		if len(filteredArgs) != 1 {
+			return fmt.Errorf("accepts 1 arg (code or -), received %d", len(filteredArgs))
+		}
+		codeArg := filteredArgs[0]
+		if codeArg == "-" {
+			// Check if stdin is a TTY
+			stat, _ := os.Stdin.Stat()
+			if (stat.Mode() & os.ModeCharDevice) != 0 {
+				return fmt.Errorf("stdin is a terminal; pipe code or use argument instead")
+			}
+			// Read from stdin
+			reader := bufio.NewReader(os.Stdin)
+			input, err := io.ReadAll(reader)
+			if err != nil {
+				return fmt.Errorf("failed to read from stdin: %w", err)
+			}
+			code = string(input)
+		} else {
+			code = codeArg
+		}
 	}
 
 	if code == "" {
 		return fmt.Errorf("code is required")
 	}
+	language, err := js.ParseLanguage(language)
+	if err != nil {
+		return err
+	}
 
-	jsonOutput := cmd.Bool("json")
+	settings, err := execSettings(cmd)
+	if err != nil {
+		return err
+	}
+	if !settings.GetExec().IsEnabled() {
+		return fmt.Errorf("exec is disabled by the config (exec.enable)")
+	}
+	dir, err := scriptsDir(cmd, "scripts-dir")
+	if err != nil {
+		return err
+	}
+	runCfg, err := execRuntimeConfig(settings, dir)
+	if err != nil {
+		return err
+	}
+	runCfg.MaxConcurrency = cmd.Int("max-concurrency")
+	runCfg.Language = language
+	runCfg.Trace = cmd.Bool("trace")
 
-	var caller js.ToolCaller
-	var cleanup func() error
+	caller, cleanup, err := newExecCaller(ctx, cmd)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	// Execute using shared implementation
+	logger := getLogger(cmd)
+	execResult, err := tools.ExecuteCode(ctx, logger, caller, code, runCfg)
+	if err != nil {
+		return err
+	}
+	return printExecResult(execResult, cmd.Bool("json"))
+}
+
+// execSettings returns the hub config of --config, or an empty config whose
+// sections give the defaults without one
+func execSettings(cmd *ucli.Command) (*config.Config, error) {
+	configPath := cmd.String("config")
+	if configPath == "" {
+		return &config.Config{}, nil
+	}
+	return config.LoadConfig(configPath)
+}
+
+// execRuntimeConfig returns the runtime settings of a script run from the
+// command line. The session namespace of mcp.state lasts for this run only;
+// the persistent namespace is shared with the hub when enabled.
+func execRuntimeConfig(cfg *config.Config, dir string) (*js.Config, error) {
+	store, err := state.New(cfg.GetState())
+	if err != nil {
+		return nil, fmt.Errorf("failed to open script state: %w", err)
+	}
+	runCfg := tools.ExecRuntimeConfig(cfg.GetExec(), dir)
+	runCfg.State = store.Session("")
+	runCfg.PersistentState = store.Persistent()
+	return runCfg, nil
+}
 
-	if configPath != "" {
+// newExecCaller connects to the MCP servers selected by the client flags and
+// returns a tool caller for scripts, with a function closing the connection
+func newExecCaller(ctx context.Context, cmd *ucli.Command) (js.ToolCaller, func() error, error) {
+	if cmd.String("config") != "" {
 		client, err := createConfigClient(ctx, cmd)
 		if err != nil {
-			return err
+			return nil, nil, err
 		}
-		cleanup = client.Close
 
 		mcpTools, err := client.ListTools(ctx)
 		if err != nil {
-			return fmt.Errorf("failed to list tools: %w", err)
+			client.Close()
+			return nil, nil, fmt.Errorf("failed to list tools: %w", err)
 		}
 		mapper, err := toolname.NewMapperWithCollisionCheck(mcpTools)
 		if err != nil {
-			return err
+			client.Close()
+			return nil, nil, err
 		}
 
-		caller = &cliToolCaller{
+		return &cliToolCaller{
 			callFn: client.CallTool,
 			listFn: client.ListTools,
 			mapper: mapper,
-		}
-	} else if stdio {
-		client, err := createStdioClientFromCmd(ctx, cmd)
-		if err != nil {
-			return err
-		}
-		cleanup = client.Close
-
-		mcpTools, err := client.ListTools(ctx)
-		if err != nil {
-			return fmt.Errorf("failed to list tools: %w", err)
-		}
-		mapper := toolname.NewMapper(mcpTools)
+			policy: client,
+			names:  client,
+		}, client.Close, nil
+	}
 
-		caller = &cliToolCaller{
-			callFn:        client.CallTool,
-			listFn:        client.ListTools,
-			defaultServer: "default",
-			mapper:        mapper,
-		}
+	var client interface {
+		CallTool(ctx context.Context, name string, params json.RawMessage) (*mcp.CallToolResult, error)
+		ListTools(ctx context.Context) ([]*mcp.Tool, error)
+		Close() error
+	}
+	var err error
+	if cmd.Bool("stdio") {
+		client, err = createStdioClientFromCmd(ctx, cmd)
 	} else {
-		client, err := createRemoteClient(ctx, cmd)
-		if err != nil {
-			return err
-		}
-		cleanup = client.Close
-
-		mcpTools, err := client.ListTools(ctx)
-		if err != nil {
-			return fmt.Errorf("failed to list tools: %w", err)
-		}
-		mapper := toolname.NewMapper(mcpTools)
-
-		caller = &cliToolCaller{
-			callFn:        client.CallTool,
-			listFn:        client.ListTools,
-			defaultServer: "default",
-			mapper:        mapper,
-		}
+		client, err = createRemoteClient(ctx, cmd)
+	}
+	if err != nil {
+		return nil, nil, err
 	}
-	defer cleanup()
 
-	// Execute using shared implementation
-	logger := getLogger(cmd)
-	execResult, err := tools.ExecuteCode(ctx, logger, caller, code)
+	mcpTools, err := client.ListTools(ctx)
 	if err != nil {
-		return err
+		client.Close()
+		return nil, nil, fmt.Errorf("failed to list tools: %w", err)
 	}
+	mapper := toolname.NewMapper(mcpTools)
+
+	return &cliToolCaller{
+		callFn:        client.CallTool,
+		listFn:        client.ListTools,
+		defaultServer: "default",
+		mapper:        mapper,
+	}, client.Close, nil
+}
 
-	// Output
+// printExecResult prints the logs and result of a script, or all of it as JSON
+func printExecResult(execResult *tools.ExecResult, jsonOutput bool) error {
 	if jsonOutput {
-		data, marshalErr := json.MarshalIndent(execResult, "", "  ")
+		output := struct {
+			*tools.ExecResult
+			Content []mcp.Content `json:"content,omitempty"`
+		}{execResult, execResult.Content}
+		data, marshalErr := json.MarshalIndent(output, "", "  ")
 		if marshalErr != nil {
 			return fmt.Errorf("failed to marshal JSON: %w", marshalErr)
 		}
 		fmt.Println(string(data))
-	} else {
-		// Print logs first
-		for _, log := range execResult.Logs {
-			fmt.Printf("[%s] %s\n", log.Level, log.Message)
-		}
-
-		if execResult.Error != nil {
-			return fmt.Errorf("execution failed: %s: %s", execResult.Error.Type, execResult.Error.Message)
-		}
-
-		// Print result
-		if execResult.Result != nil {
-			switch v := execResult.Result.(type) {
-			case string:
-				fmt.Println(v)
-			default:
-				data, marshalErr := json.MarshalIndent(execResult.Result, "", "  ")
-				if marshalErr != nil {
-					fmt.Printf("%v\n", execResult.Result)
-				} else {
-					fmt.Println(string(data))
-				}
+		return nil
+	}
+
+	// Print logs first
+	for _, log := range execResult.Logs {
+		fmt.Printf("[%s] %s\n", log.Level, log.Message)
+	}
+	if execResult.Trace != nil {
+		printTrace(os.Stderr, execResult.Trace)
+	}
+
+	if execResult.Error != nil {
+		return fmt.Errorf("execution failed: %s: %s", execResult.Error.Type, execResult.Error.Message)
+	}
+
+	// Print content blocks returned by the script
+	printContent(execResult.Content)
+
+	// Print result
+	if execResult.Result != nil {
+		switch v := execResult.Result.(type) {
+		case string:
+			fmt.Println(v)
+		default:
+			data, marshalErr := json.MarshalIndent(execResult.Result, "", "  ")
+			if marshalErr != nil {
+				fmt.Printf("%v\n", execResult.Result)
+			} else {
+				fmt.Println(string(data))
 			}
 		}
 	}
-
 	return nil
 }
+
+// printTrace renders the trace of an execution as a timeline of its tool
+// calls, followed by the failing source line and the JS stack
+func printTrace(w io.Writer, trace *js.Trace) {
+	fmt.Fprintf(w, "Trace: %d tool calls\n", len(trace.Calls))
+	for _, call := range trace.Calls {
+		where := ""
+		if call.Location != nil {
+			where = formatLocation(call.Location)
+		}
+		params := ""
+		if call.Params != nil {
+			if data, err := json.Marshal(call.Params); err == nil {
+				params = textutil.Abbreviate(string(data), 80)
+			}
+		}
+		fmt.Fprintf(w, "  %6dms %+6dms  %-14s %s\n", call.StartMs, call.DurationMs, where, strings.TrimSpace(call.Tool+" "+params))
+		if call.Error != "" {
+			fmt.Fprintf(w, "  %17s  ✗ %s\n", "", call.Error)
+		} else {
+			fmt.Fprintf(w, "  %17s  → %s\n", "", call.Result)
+		}
+	}
+	if len(trace.Stack) == 0 {
+		return
+	}
+
+	failed := trace.Stack[0]
+	fmt.Fprintf(w, "Failed at %s\n", formatLocation(&failed))
+	if failed.Source != "" {
+		gutter := fmt.Sprintf("  %d | ", failed.Line)
+		fmt.Fprintf(w, "%s%s\n", gutter, failed.Source)
+		// The caret keeps the tabs of the source line so it lines up
+		var pad strings.Builder
+		for i, r := range failed.Source {
+			if i >= failed.Column-1 {
+				break
+			}
+			if r == '\t' {
+				pad.WriteRune('\t')
+			} else {
+				pad.WriteRune(' ')
+			}
+		}
+		fmt.Fprintf(w, "%s| %s^\n", strings.Repeat(" ", len(gutter)-2), pad.String())
+	}
+	for _, frame := range trace.Stack {
+		function := frame.Function
+		if function == "" {
+			function = "<anonymous>"
+		}
+		fmt.Fprintf(w, "  at %s (%s)\n", function, formatLocation(&frame))
+	}
+}
+
+// formatLocation renders a source location as file:line:column, naming the
+// script "line" when the position is in the script itself
+func formatLocation(loc *js.SourceLocation) string {
+	if loc.File == "" {
+		return fmt.Sprintf("line %d:%d", loc.Line, loc.Column)
+	}
+	return fmt.Sprintf("%s:%d:%d", loc.File, loc.Line, loc.Column)
+}
+
+// languageFromPath returns the script language of a file from its extension
+func languageFromPath(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".ts", ".mts", ".cts":
+		return js.LanguageTypeScript
+	}
+	return js.LanguageJavaScript
+}
@@ -0,0 +1,37 @@
+package cli
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/vaayne/mcphub/internal/js"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrintTrace(t *testing.T) {
+	var out bytes.Buffer
+	printTrace(&out, &js.Trace{
+		Calls: []js.TraceCall{
+			{Tool: "github__search", Params: map[string]any{"q": "mcp"}, StartMs: 0, DurationMs: 12, Result: "3 repos",
+				Location: &js.SourceLocation{Line: 1, Column: 14}},
+			{Tool: "github__get_file", StartMs: 12, DurationMs: 4, Error: "tool 'github.get_file' failed: not found",
+				Location: &js.SourceLocation{File: "lib/gh.js", Line: 3, Column: 10}},
+		},
+		Stack: []js.SourceLocation{
+			{Function: "load", Line: 4, Column: 10, Source: "\treturn x.y;"},
+			{Line: 6, Column: 1, Source: "load();"},
+		},
+	})
+	assert.Equal(t, `Trace: 2 tool calls
+       0ms    +12ms  line 1:14      github__search {"q":"mcp"}
+                     → 3 repos
+      12ms     +4ms  lib/gh.js:3:10 github__get_file
+                     ✗ tool 'github.get_file' failed: not found
+Failed at line 4:10
+  4 | 	return x.y;
+    | 	        ^
+  at load (line 4:10)
+  at <anonymous> (line 6:1)
+`, out.String())
+}
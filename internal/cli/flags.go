@@ -31,13 +31,17 @@ func MCPClientFlags() []ucli.Flag {
 		},
 		&ucli.IntFlag{
 			Name:  "timeout",
-			Usage: "connection timeout in seconds",
+			Usage: "connection timeout in seconds (with --config, startup.connectTimeout unless set)",
 			Value: 30,
 		},
 		&ucli.StringSliceFlag{
 			Name:  "header",
 			Usage: "HTTP headers (repeatable, format: \"Key: Value\")",
 		},
+		&ucli.BoolFlag{
+			Name:  "refresh",
+			Usage: "with --config, connect to every server instead of listing tools from the catalog",
+		},
 		&ucli.BoolFlag{
 			Name:  "json",
 			Usage: "output as JSON",
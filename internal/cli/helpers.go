@@ -9,7 +9,6 @@ import (
 	"time"
 
 	"github.com/vaayne/mcphub/internal/logging"
-	"github.com/vaayne/mcphub/internal/toolname"
 
 	ucli "github.com/urfave/cli/v3"
 )
@@ -55,10 +54,18 @@ func createRemoteClient(ctx context.Context, cmd *ucli.Command) (*RemoteClient,
 
 func createConfigClient(ctx context.Context, cmd *ucli.Command) (*ConfigClient, error) {
 	configPath := cmd.String("config")
-	timeout := cmd.Int("timeout")
 	logger := getLogger(cmd)
 
-	return NewConfigClient(ctx, configPath, logger, time.Duration(timeout)*time.Second)
+	return NewConfigClient(ctx, configPath, logger, connectTimeout(cmd), cmd.Bool("refresh"))
+}
+
+// connectTimeout returns the --timeout given on the command line, or 0 when
+// it wasn't set so the config's startup.connectTimeout applies
+func connectTimeout(cmd *ucli.Command) time.Duration {
+	if !cmd.IsSet("timeout") {
+		return 0
+	}
+	return time.Duration(cmd.Int("timeout")) * time.Second
 }
 
 // parseHeaders parses headers from []string in format "Key: Value" into map[string]string.
@@ -97,14 +104,6 @@ func getLogger(cmd *ucli.Command) *slog.Logger {
 	return logging.Logger
 }
 
-// ensureNamespacedToolName validates that a tool name is in namespaced format for config mode
-func ensureNamespacedToolName(name string) error {
-	if !toolname.IsNamespaced(name) {
-		return fmt.Errorf("tool name must include server prefix (server__tool) when using --config")
-	}
-	return nil
-}
-
 // getStdioCommand extracts the stdio command from os.Args after the "--" separator.
 // Returns the command slice and an error if --stdio is used without a command.
 func getStdioCommand() ([]string, error) {
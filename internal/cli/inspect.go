@@ -106,20 +106,13 @@ func runInspect(ctx context.Context, cmd *ucli.Command) error {
 	// Resolve tool name (accepts both JS name and original name)
 	originalName, found := mapper.Resolve(toolName)
 	if !found {
-		// If not found in mapper but looks like a namespaced name, use it directly
-		if !toolname.IsNamespaced(toolName) {
+		// Otherwise it must be a name the provider resolves to a server and tool
+		if _, _, ok := toolname.Resolve(provider, toolName); !ok {
 			return fmt.Errorf("tool '%s' not found", toolName)
 		}
 		originalName = toolName
 	}
 
-	// For config mode, ensure the resolved name is namespaced
-	if configPath != "" {
-		if err := ensureNamespacedToolName(originalName); err != nil {
-			return err
-		}
-	}
-
 	// Call shared core function
 	result, err := tools.InspectTool(ctx, provider, originalName, mapper)
 	if err != nil {
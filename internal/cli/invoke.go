@@ -149,20 +149,13 @@ func runInvoke(ctx context.Context, cmd *ucli.Command) error {
 	// Resolve tool name (accepts both JS name and original name)
 	originalName, found := mapper.Resolve(toolName)
 	if !found {
-		// If not found in mapper but looks like a namespaced name, use it directly
-		if !toolname.IsNamespaced(toolName) {
+		// Otherwise it must be a name the provider resolves to a server and tool
+		if _, _, ok := toolname.Resolve(provider, toolName); !ok {
 			return fmt.Errorf("tool '%s' not found", toolName)
 		}
 		originalName = toolName
 	}
 
-	// For config mode, ensure the resolved name is namespaced
-	if configPath != "" {
-		if err := ensureNamespacedToolName(originalName); err != nil {
-			return err
-		}
-	}
-
 	// Call shared core function
 	result, err := tools.InvokeTool(ctx, provider, originalName, params, mapper)
 	if err != nil {
@@ -191,12 +184,19 @@ func printCallToolResult(result *mcp.CallToolResult) {
 		fmt.Println("Error:")
 	}
 
-	for _, content := range result.Content {
+	printContent(result.Content)
+}
+
+// printContent pretty-prints MCP content blocks
+func printContent(contents []mcp.Content) {
+	for _, content := range contents {
 		switch c := content.(type) {
 		case *mcp.TextContent:
 			fmt.Println(c.Text)
 		case *mcp.ImageContent:
 			fmt.Printf("[Image: %s, %d bytes]\n", c.MIMEType, len(c.Data))
+		case *mcp.AudioContent:
+			fmt.Printf("[Audio: %s, %d bytes]\n", c.MIMEType, len(c.Data))
 		case *mcp.EmbeddedResource:
 			printEmbeddedResource(c)
 		default:
@@ -38,8 +38,8 @@ Examples:
   # List tools filtered by server
   mh list -c config.json --server github
 
-  # List tools filtered by keywords
-  mh list -c config.json --query "search,file"
+  # Search tools, best matches first
+  mh list -c config.json --query "create a github issue" --limit 5
 
   # List tools from a stdio MCP server
   mh list --stdio -- npx @modelcontextprotocol/server-everything`,
@@ -50,7 +50,15 @@ Examples:
 		},
 		&ucli.StringFlag{
 			Name:  "query",
-			Usage: "comma-separated keywords for search (matches name or description)",
+			Usage: "search query; matching tools are ranked best first",
+		},
+		&ucli.IntFlag{
+			Name:  "limit",
+			Usage: "maximum number of tools to list (max 100)",
+		},
+		&ucli.IntFlag{
+			Name:  "offset",
+			Usage: "number of matching tools to skip",
 		},
 	),
 	Before: ValidateMCPClientFlags,
@@ -64,6 +72,11 @@ func runList(ctx context.Context, cmd *ucli.Command) error {
 	jsonOutput := cmd.Bool("json")
 	serverFilter := cmd.String("server")
 	queryFilter := cmd.String("query")
+	limit := cmd.Int("limit")
+	offset := cmd.Int("offset")
+	if limit < 0 || offset < 0 {
+		return fmt.Errorf("--limit and --offset must not be negative")
+	}
 
 	// Create provider
 	var provider tools.ToolProvider
@@ -100,6 +113,8 @@ func runList(ctx context.Context, cmd *ucli.Command) error {
 	result, err := tools.ListTools(ctx, provider, tools.ListOptions{
 		Server:            serverFilter,
 		Query:             queryFilter,
+		Limit:             limit,
+		Offset:            offset,
 		IncludeUnprefixed: includeUnprefixed,
 	})
 	if err != nil {
@@ -120,10 +135,12 @@ func runList(ctx context.Context, cmd *ucli.Command) error {
 	// Format result with JS names (same as MCP tool output)
 	formatted := tools.FormatListResult(result, mapper)
 
-	// Sort by JS name for consistent output
-	sort.Slice(formatted, func(i, j int) bool {
-		return formatted[i].Name < formatted[j].Name
-	})
+	// Sort by JS name for consistent output; search results keep their rank
+	if queryFilter == "" {
+		sort.Slice(formatted, func(i, j int) bool {
+			return formatted[i].Name < formatted[j].Name
+		})
+	}
 
 	// Output
 	if jsonOutput {
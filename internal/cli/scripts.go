@@ -0,0 +1,302 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/vaayne/mcphub/internal/config"
+	"github.com/vaayne/mcphub/internal/js"
+	"github.com/vaayne/mcphub/internal/scripts"
+	"github.com/vaayne/mcphub/internal/tools"
+
+	ucli "github.com/urfave/cli/v3"
+)
+
+// ScriptsCmd is the scripts subcommand for managing the script library
+var ScriptsCmd = &ucli.Command{
+	Name:  "scripts",
+	Usage: "Manage and run saved exec scripts",
+	Description: `Manage the script library: saved exec scripts, run by name, and shared
+modules that any script loads with require("lib/name").
+
+Saved scripts are the .js and .ts files at the top of the library directory;
+a script's leading comment is its description, and its parameters are in the
+params global. Modules are the .js, .ts and .json files under lib/.
+
+The library directory is taken from --dir, the "scripts.dir" setting of
+--config, or the default user config directory. A running hub offers saved
+scripts through its run tool.
+
+Examples:
+  mh scripts list
+  mh scripts save repo-stars repo-stars.js
+  mh scripts run -c config.json repo-stars --param repo=vaayne/mcphub`,
+	Commands: []*ucli.Command{
+		scriptsListCmd,
+		scriptsRunCmd,
+		scriptsSaveCmd,
+	},
+}
+
+var scriptsListCmd = &ucli.Command{
+	Name:  "list",
+	Usage: "List saved scripts and library modules",
+	Flags: append(scriptsFlags(),
+		&ucli.BoolFlag{
+			Name:  "json",
+			Usage: "output as JSON",
+		},
+	),
+	Action: runScriptsList,
+}
+
+var scriptsRunCmd = &ucli.Command{
+	Name:      "run",
+	Usage:     "Run a saved script",
+	ArgsUsage: "<name>",
+	Description: `Run a saved script against MCP tools, like exec.
+
+Parameters are passed as --param key=value (values that parse as JSON are
+used as JSON, others as strings) or as a JSON object with --params.
+
+Examples:
+  mh scripts run -c config.json repo-stars --param repo=vaayne/mcphub
+  mh scripts run -c config.json report --params '{"days": 7, "labels": ["bug"]}'
+  mh scripts run --stdio echo-all --param message=hi -- npx @modelcontextprotocol/server-everything`,
+	Flags: append(MCPClientFlags(),
+		&ucli.StringFlag{
+			Name:  "dir",
+			Usage: "script library directory (overrides config)",
+		},
+		&ucli.StringSliceFlag{
+			Name:  "param",
+			Usage: "script parameter (repeatable, format: key=value)",
+		},
+		&ucli.StringFlag{
+			Name:  "params",
+			Usage: "script parameters as a JSON object",
+		},
+		&ucli.IntFlag{
+			Name:  "max-concurrency",
+			Usage: fmt.Sprintf("mcp.callToolAsync calls in flight at once (default %d, max %d)", js.DefaultMaxConcurrency, js.MaxConcurrencyLimit),
+		},
+	),
+	Before: ValidateMCPClientFlags,
+	Action: runScriptsRun,
+}
+
+var scriptsSaveCmd = &ucli.Command{
+	Name:      "save",
+	Usage:     "Save a script to the library",
+	ArgsUsage: "<name> <file | ->",
+	Description: `Save a script to the library under name, replacing any script of that name.
+
+The language is taken from the file extension (.ts, .mts and .cts are
+TypeScript) unless --language is given; code read from stdin is JavaScript by
+default.
+
+Examples:
+  mh scripts save repo-stars repo-stars.js
+  cat report.ts | mh scripts save report - --language typescript`,
+	Flags: append(scriptsFlags(),
+		&ucli.StringFlag{
+			Name:  "language",
+			Usage: "language of the script: javascript or typescript",
+		},
+	),
+	Action: runScriptsSave,
+}
+
+// scriptsFlags are flags shared by the scripts subcommands that don't run scripts
+func scriptsFlags() []ucli.Flag {
+	return []ucli.Flag{
+		&ucli.StringFlag{
+			Name:    "config",
+			Aliases: []string{"c"},
+			Usage:   "path to configuration file (for scripts.dir)",
+		},
+		&ucli.StringFlag{
+			Name:  "dir",
+			Usage: "script library directory (overrides config)",
+		},
+	}
+}
+
+// scriptsDir returns the script library directory selected by the flag named
+// dirFlag or --config
+func scriptsDir(cmd *ucli.Command, dirFlag string) (string, error) {
+	if dir := cmd.String(dirFlag); dir != "" {
+		return dir, nil
+	}
+	if configPath := cmd.String("config"); configPath != "" {
+		cfg, err := config.LoadConfig(configPath)
+		if err != nil {
+			return "", err
+		}
+		return cfg.GetScripts().GetDir(), nil
+	}
+	return config.DefaultScriptsDir(), nil
+}
+
+func runScriptsList(ctx context.Context, cmd *ucli.Command) error {
+	dir, err := scriptsDir(cmd, "dir")
+	if err != nil {
+		return err
+	}
+	library := scripts.NewLibrary(dir)
+
+	saved, err := library.List()
+	if err != nil {
+		return err
+	}
+	modules, err := library.Modules()
+	if err != nil {
+		return err
+	}
+
+	if cmd.Bool("json") {
+		output, err := json.MarshalIndent(struct {
+			Dir     string           `json:"dir"`
+			Scripts []scripts.Script `json:"scripts"`
+			Modules []string         `json:"modules"`
+		}{library.Dir(), append([]scripts.Script{}, saved...), append([]string{}, modules...)}, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		fmt.Println(string(output))
+		return nil
+	}
+
+	fmt.Printf("Library: %s\n", library.Dir())
+	if len(saved) == 0 {
+		fmt.Println("No saved scripts")
+	} else {
+		fmt.Println("Scripts:")
+		for _, s := range saved {
+			desc, _, _ := strings.Cut(s.Description, "\n")
+			if desc == "" {
+				desc = "No description"
+			}
+			fmt.Printf("- %s (%s): %s\n", s.Name, s.Language, tools.TruncateDescription(desc, 50))
+		}
+	}
+	if len(modules) > 0 {
+		fmt.Println("Modules:")
+		for _, m := range modules {
+			fmt.Printf("- %s\n", m)
+		}
+	}
+	return nil
+}
+
+func runScriptsRun(ctx context.Context, cmd *ucli.Command) error {
+	args := filterArgsBeforeDash(cmd.Args().Slice())
+	if len(args) != 1 {
+		return fmt.Errorf("accepts 1 arg (script name), received %d", len(args))
+	}
+	params, err := scriptParams(cmd.String("params"), cmd.StringSlice("param"))
+	if err != nil {
+		return err
+	}
+	dir, err := scriptsDir(cmd, "dir")
+	if err != nil {
+		return err
+	}
+	library := scripts.NewLibrary(dir)
+	// Fail on a missing script before connecting to any server
+	if _, _, err := library.Load(args[0]); err != nil {
+		return err
+	}
+	settings, err := execSettings(cmd)
+	if err != nil {
+		return err
+	}
+	if !settings.GetExec().IsEnabled() {
+		return fmt.Errorf("exec is disabled by the config (exec.enable)")
+	}
+	runCfg, err := execRuntimeConfig(settings, dir)
+	if err != nil {
+		return err
+	}
+	runCfg.MaxConcurrency = cmd.Int("max-concurrency")
+
+	caller, cleanup, err := newExecCaller(ctx, cmd)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	execResult, err := tools.RunScript(ctx, getLogger(cmd), caller, library, args[0], params, runCfg)
+	if err != nil {
+		return err
+	}
+	return printExecResult(execResult, cmd.Bool("json"))
+}
+
+// scriptParams builds script parameters from a JSON object and key=value
+// pairs, which take precedence. Values that parse as JSON are used as JSON,
+// others as strings.
+func scriptParams(paramsJSON string, pairs []string) (map[string]any, error) {
+	params := make(map[string]any)
+	if paramsJSON != "" {
+		if err := json.Unmarshal([]byte(paramsJSON), &params); err != nil {
+			return nil, fmt.Errorf("--params must be a JSON object: %w", err)
+		}
+		if params == nil {
+			params = make(map[string]any) // --params null
+		}
+	}
+	for _, pair := range pairs {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid --param %q: expected key=value", pair)
+		}
+		var v any
+		if err := json.Unmarshal([]byte(value), &v); err != nil {
+			v = value
+		}
+		params[key] = v
+	}
+	return params, nil
+}
+
+func runScriptsSave(ctx context.Context, cmd *ucli.Command) error {
+	args := cmd.Args().Slice()
+	if len(args) != 2 {
+		return fmt.Errorf("accepts 2 args (name and file or -), received %d", len(args))
+	}
+	name, file := args[0], args[1]
+
+	var data []byte
+	var err error
+	language := cmd.String("language")
+	if file == "-" {
+		data, err = io.ReadAll(os.Stdin)
+		if err != nil {
+			return fmt.Errorf("failed to read from stdin: %w", err)
+		}
+	} else {
+		data, err = os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		if language == "" {
+			language = languageFromPath(file)
+		}
+	}
+
+	dir, err := scriptsDir(cmd, "dir")
+	if err != nil {
+		return err
+	}
+	script, err := scripts.NewLibrary(dir).Save(name, string(data), language)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Saved %s (%s) to %s\n", script.Name, script.Language, script.Path)
+	return nil
+}
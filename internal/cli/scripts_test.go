@@ -0,0 +1,35 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScriptParams(t *testing.T) {
+	params, err := scriptParams(`{"days": 7, "repo": "a/b"}`, []string{"repo=vaayne/mcphub", "limit=10", "labels=[\"bug\"]", "query=a=b"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"days":   float64(7),
+		"repo":   "vaayne/mcphub",
+		"limit":  float64(10),
+		"labels": []any{"bug"},
+		"query":  "a=b",
+	}, params)
+
+	params, err = scriptParams("", nil)
+	require.NoError(t, err)
+	assert.Empty(t, params)
+
+	params, err = scriptParams("null", []string{"a=1"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"a": float64(1)}, params)
+
+	_, err = scriptParams("[1]", nil)
+	assert.Error(t, err)
+	_, err = scriptParams("", []string{"novalue"})
+	assert.Error(t, err)
+	_, err = scriptParams("", []string{"=1"})
+	assert.Error(t, err)
+}
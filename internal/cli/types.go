@@ -0,0 +1,98 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/vaayne/mcphub/internal/tools"
+
+	ucli "github.com/urfave/cli/v3"
+)
+
+// TypesCmd is the types subcommand that generates TypeScript declarations for exec scripts
+var TypesCmd = &ucli.Command{
+	Name:  "types",
+	Usage: "Generate TypeScript declarations for exec scripts",
+	Description: `Generate a TypeScript declaration file (.d.ts) covering every tool of an MCP
+service, for type-checking exec scripts in an editor.
+
+The file declares an interface for each tool's parameters (and result, when
+the tool has an output schema), mcp.callTool overloads keyed by tool name, and
+typed mcp.tools and mcp.servers namespaces.
+
+Examples:
+  # Write declarations for every server in the config
+  mh types -c config.json -o mcp.d.ts
+
+  # Only one server's tools
+  mh types -c config.json --server github
+
+  # From a remote server
+  mh types -u http://localhost:3000 > mcp.d.ts`,
+	Flags: append(MCPClientFlags(),
+		&ucli.StringFlag{
+			Name:  "server",
+			Usage: "only declare the tools of this server",
+		},
+		&ucli.StringFlag{
+			Name:    "output",
+			Aliases: []string{"o"},
+			Usage:   "write the declarations to this file instead of stdout",
+		},
+	),
+	Before: ValidateMCPClientFlags,
+	Action: runTypes,
+}
+
+func runTypes(ctx context.Context, cmd *ucli.Command) error {
+	url := cmd.String("url")
+	configPath := cmd.String("config")
+	stdio := cmd.Bool("stdio")
+
+	// Create provider
+	var provider tools.ToolProvider
+	var cleanup func() error
+
+	if configPath != "" {
+		client, err := createConfigClient(ctx, cmd)
+		if err != nil {
+			return err
+		}
+		cleanup = client.Close
+		provider = client
+	} else if stdio {
+		client, err := createStdioClientFromCmd(ctx, cmd)
+		if err != nil {
+			return err
+		}
+		cleanup = client.Close
+		provider = client
+	} else {
+		client, err := createRemoteClient(ctx, cmd)
+		if err != nil {
+			return err
+		}
+		cleanup = client.Close
+		provider = client
+	}
+	defer cleanup()
+
+	// For stdio and remote modes, tools are not namespaced (direct from server)
+	output, err := tools.GenerateTypes(ctx, provider, tools.TypesOptions{
+		Server:            cmd.String("server"),
+		IncludeUnprefixed: stdio || url != "",
+	})
+	if err != nil {
+		return err
+	}
+
+	if path := cmd.String("output"); path != "" {
+		if err := os.WriteFile(path, []byte(output), 0o644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		return nil
+	}
+	fmt.Print(output)
+	return nil
+}
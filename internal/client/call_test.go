@@ -0,0 +1,438 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vaayne/mcphub/internal/breaker"
+	"github.com/vaayne/mcphub/internal/cache"
+	"github.com/vaayne/mcphub/internal/catalog"
+	"github.com/vaayne/mcphub/internal/config"
+	"github.com/vaayne/mcphub/internal/logging"
+	"github.com/vaayne/mcphub/internal/ratelimit"
+)
+
+// inMemoryFactory connects the manager to an in-process MCP server
+type inMemoryFactory struct {
+	server   *mcp.Server
+	connects atomic.Int32
+}
+
+func (f *inMemoryFactory) CreateTransport(cfg config.MCPServer) (mcp.Transport, error) {
+	f.connects.Add(1)
+	clientTransport, serverTransport := mcp.NewInMemoryTransports()
+	if _, err := f.server.Connect(context.Background(), serverTransport, nil); err != nil {
+		return nil, err
+	}
+	return clientTransport, nil
+}
+
+// testBackend is an in-process server whose tools count their calls. The
+// "flaky" tool fails while failures is positive.
+type testBackend struct {
+	server   *mcp.Server
+	calls    atomic.Int32
+	failures atomic.Int32
+}
+
+func newTestBackend() *testBackend {
+	b := &testBackend{
+		server: mcp.NewServer(&mcp.Implementation{Name: "backend", Version: "v1.0.0"}, nil),
+	}
+	handler := func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		b.calls.Add(1)
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "ok"}}}, nil
+	}
+	inputSchema := map[string]any{"type": "object"}
+	b.server.AddTool(&mcp.Tool{
+		Name:        "search",
+		InputSchema: inputSchema,
+		Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true},
+	}, handler)
+	b.server.AddTool(&mcp.Tool{Name: "write", InputSchema: inputSchema}, handler)
+	b.server.AddTool(&mcp.Tool{
+		Name:        "flaky",
+		InputSchema: inputSchema,
+		Annotations: &mcp.ToolAnnotations{IdempotentHint: true},
+	}, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if b.failures.Add(-1) >= 0 {
+			b.calls.Add(1)
+			return nil, errors.New("backend unavailable")
+		}
+		return handler(ctx, req)
+	})
+	return b
+}
+
+// connect returns a manager connected to the backend as serverID
+func (b *testBackend) connect(t *testing.T, serverID string, serverCfg config.MCPServer) *Manager {
+	t.Helper()
+	manager := NewManagerWithFactory(logging.NopLogger(), &inMemoryFactory{server: b.server})
+	t.Cleanup(func() { manager.DisconnectAll() })
+	require.NoError(t, manager.ConnectToServer(serverID, serverCfg))
+	return manager
+}
+
+func TestCallTool_Cache(t *testing.T) {
+	backend := newTestBackend()
+	enabled := true
+	manager := backend.connect(t, "srv", config.MCPServer{
+		Command: "test",
+		Tools:   map[string]config.ToolConfig{"wr*": {Cache: &enabled}},
+	})
+	manager.SetCache(cache.NewMemory(10), config.DefaultCacheTTL)
+	ctx := context.Background()
+
+	// readOnlyHint tools are cached per canonical arguments
+	for range 3 {
+		result, err := manager.CallTool(ctx, "srv", "search", map[string]any{"q": "mcp", "n": 1})
+		require.NoError(t, err)
+		assert.Equal(t, "ok", result.Content[0].(*mcp.TextContent).Text)
+	}
+	assert.Equal(t, int32(1), backend.calls.Load())
+
+	_, err := manager.CallTool(ctx, "srv", "search", map[string]any{"q": "other"})
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), backend.calls.Load())
+
+	// Other tools are cached when a matching config entry opts in
+	for range 2 {
+		_, err := manager.CallTool(ctx, "srv", "write", nil)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int32(3), backend.calls.Load())
+
+	stats := manager.Cache().Stats()
+	assert.Equal(t, uint64(3), stats.Hits)
+}
+
+func TestCallTool_RenamedAndHiddenTools(t *testing.T) {
+	backend := newTestBackend()
+	manager := backend.connect(t, "srv", config.MCPServer{
+		Command:      "test",
+		ExcludeTools: []string{"fl*"},
+		Tools: map[string]config.ToolConfig{
+			"search": {Rename: "find", Notes: "Prefer short queries."},
+			"write":  {RateLimit: &config.RateLimitConfig{RequestsPerSecond: 0.01}},
+		},
+	})
+	ctx := context.Background()
+
+	tools := manager.GetAllTools()
+	assert.Len(t, tools, 2)
+	require.Contains(t, tools, "srv__find")
+	assert.Equal(t, "find", tools["srv__find"].Name)
+	assert.Equal(t, "Prefer short queries.", tools["srv__find"].Description)
+	assert.Contains(t, tools, "srv__write")
+
+	// Renamed tools are called by their new name only; hidden tools not at all
+	_, err := manager.CallTool(ctx, "srv", "find", nil)
+	require.NoError(t, err)
+	_, err = manager.CallTool(ctx, "srv", "search", nil)
+	assert.ErrorContains(t, err, "tool not found")
+	_, err = manager.CallTool(ctx, "srv", "flaky", nil)
+	assert.ErrorContains(t, err, "tool not found")
+	assert.Equal(t, int32(1), backend.calls.Load())
+}
+
+func TestConnectToServer_RenameClash(t *testing.T) {
+	backend := newTestBackend()
+	manager := NewManagerWithFactory(logging.NopLogger(), &inMemoryFactory{server: backend.server})
+	t.Cleanup(func() { manager.DisconnectAll() })
+
+	// Renaming write to search would hide the backend's own search tool
+	err := manager.ConnectToServer("srv", config.MCPServer{
+		Command: "test",
+		Tools:   map[string]config.ToolConfig{"write": {Rename: "search"}},
+	})
+	assert.ErrorContains(t, err, `tools "search" and "write" are both exposed as "search"`)
+}
+
+func TestGetAllTools_QualifiedNames(t *testing.T) {
+	backend := newTestBackend()
+	empty := ""
+	manager := backend.connect(t, "main", config.MCPServer{Command: "test", Prefix: &empty})
+	require.NoError(t, manager.ConnectToServer("github", config.MCPServer{Command: "test", Separator: "-"}))
+	ctx := context.Background()
+
+	tools := manager.GetAllTools()
+	assert.Contains(t, tools, "search")
+	assert.Contains(t, tools, "github-search")
+	assert.Len(t, tools, 6)
+
+	serverID, toolName, ok := manager.ResolveTool("github-search")
+	require.True(t, ok)
+	assert.Equal(t, "github", serverID)
+	assert.Equal(t, "search", toolName)
+	serverID, _, ok = manager.ResolveTool("search")
+	require.True(t, ok)
+	assert.Equal(t, "main", serverID)
+	assert.Empty(t, manager.ToolNameCollisions())
+
+	// A second unprefixed server collides with the first; the lower ID keeps the names
+	require.NoError(t, manager.ConnectToServer("other", config.MCPServer{Command: "test", Prefix: &empty}))
+	assert.Len(t, manager.ToolNameCollisions(), 3)
+	serverID, _, _ = manager.ResolveTool("search")
+	assert.Equal(t, "main", serverID)
+
+	_, err := manager.CallTool(ctx, "github", "search", nil)
+	require.NoError(t, err)
+}
+
+func TestCallTool_ServerNotFound(t *testing.T) {
+	manager := NewManager(logging.NopLogger())
+	defer manager.DisconnectAll()
+
+	_, err := manager.CallTool(context.Background(), "missing", "tool", nil)
+	assert.ErrorContains(t, err, "server not found")
+}
+
+func TestCallTool_RateLimit(t *testing.T) {
+	backend := newTestBackend()
+	manager := backend.connect(t, "srv", config.MCPServer{
+		Command: "test",
+		Tools: map[string]config.ToolConfig{
+			"write": {RateLimit: &config.RateLimitConfig{RequestsPerSecond: 0.01}},
+		},
+	})
+	ctx := context.Background()
+
+	_, err := manager.CallTool(ctx, "srv", "write", nil)
+	require.NoError(t, err)
+
+	// The second call exceeds the bucket and is rejected without reaching the backend
+	_, err = manager.CallTool(ctx, "srv", "write", nil)
+	var limitErr *ratelimit.LimitError
+	require.ErrorAs(t, err, &limitErr)
+	assert.Equal(t, ratelimit.ReasonRate, limitErr.Reason)
+	assert.Positive(t, limitErr.RetryAfter)
+	assert.Equal(t, int32(1), backend.calls.Load())
+
+	// Other tools are not limited
+	_, err = manager.CallTool(ctx, "srv", "search", nil)
+	require.NoError(t, err)
+
+	status := manager.Status()
+	require.Len(t, status, 1)
+	assert.True(t, status[0].Connected)
+	assert.Equal(t, 3, status[0].Tools)
+	require.Len(t, status[0].Limits, 1)
+	assert.Equal(t, uint64(1), status[0].Limits[0].Allowed)
+	assert.Equal(t, uint64(1), status[0].Limits[0].Rejected)
+}
+
+func TestCallTool_Retry(t *testing.T) {
+	backend := newTestBackend()
+	manager := backend.connect(t, "srv", config.MCPServer{
+		Command: "test",
+		Retry:   &config.RetryConfig{MaxAttempts: 3, InitialBackoff: 0.001},
+	})
+	ctx := context.Background()
+
+	// Idempotent tools are retried until they succeed
+	backend.failures.Store(2)
+	result, err := manager.CallTool(ctx, "srv", "flaky", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "ok", result.Content[0].(*mcp.TextContent).Text)
+	assert.Equal(t, int32(3), backend.calls.Load())
+
+	// and give up after maxAttempts
+	backend.calls.Store(0)
+	backend.failures.Store(5)
+	_, err = manager.CallTool(ctx, "srv", "flaky", nil)
+	assert.ErrorContains(t, err, "backend unavailable")
+	assert.Equal(t, int32(3), backend.calls.Load())
+}
+
+func TestCallTool_CircuitBreaker(t *testing.T) {
+	backend := newTestBackend()
+	manager := backend.connect(t, "srv", config.MCPServer{
+		Command:        "test",
+		CircuitBreaker: &config.CircuitBreakerConfig{FailureThreshold: 2, OpenTimeout: 60},
+	})
+	ctx := context.Background()
+
+	backend.failures.Store(10)
+	for range 2 {
+		_, err := manager.CallTool(ctx, "srv", "flaky", nil)
+		assert.ErrorContains(t, err, "backend unavailable")
+	}
+
+	// The open circuit fails calls to every tool without reaching the server
+	_, err := manager.CallTool(ctx, "srv", "search", nil)
+	var openErr *breaker.OpenError
+	require.ErrorAs(t, err, &openErr)
+	assert.Equal(t, int32(2), backend.calls.Load())
+
+	status := manager.Status()
+	require.Len(t, status, 1)
+	require.NotNil(t, status[0].Circuit)
+	assert.Equal(t, breaker.StateOpen, status[0].Circuit.State)
+	assert.Equal(t, uint64(1), status[0].Circuit.Rejected)
+}
+
+// replicaFactory connects each replica URL to its own in-process server
+type replicaFactory map[string]*mcp.Server
+
+func (f replicaFactory) CreateTransport(cfg config.MCPServer) (mcp.Transport, error) {
+	server, ok := f[cfg.URL]
+	if !ok {
+		return nil, fmt.Errorf("unknown replica: %s", cfg.URL)
+	}
+	return (&inMemoryFactory{server: server}).CreateTransport(cfg)
+}
+
+// connectReplicas returns a manager connected to the backends as replicas of "srv"
+func connectReplicas(t *testing.T, serverCfg config.MCPServer, backends ...*testBackend) *Manager {
+	t.Helper()
+	factory := replicaFactory{}
+	for i, backend := range backends {
+		url := fmt.Sprintf("http://replica%d.test/mcp", i)
+		factory[url] = backend.server
+		serverCfg.Replicas = append(serverCfg.Replicas, config.Replica{URL: url})
+	}
+
+	manager := NewManagerWithFactory(logging.NopLogger(), factory)
+	t.Cleanup(func() { manager.DisconnectAll() })
+	require.NoError(t, manager.ConnectToServer("srv", serverCfg))
+	return manager
+}
+
+func TestCallTool_ReplicasRoundRobin(t *testing.T) {
+	a, b := newTestBackend(), newTestBackend()
+	manager := connectReplicas(t, config.MCPServer{}, a, b)
+	ctx := context.Background()
+
+	// Replicas share one namespace
+	allTools := manager.GetAllTools()
+	assert.Len(t, allTools, 3)
+	assert.Contains(t, allTools, "srv__search")
+	assert.Equal(t, []string{"srv"}, manager.ListClients())
+
+	for range 4 {
+		_, err := manager.CallTool(ctx, "srv", "search", nil)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int32(2), a.calls.Load())
+	assert.Equal(t, int32(2), b.calls.Load())
+
+	status := manager.Status()
+	require.Len(t, status, 1)
+	assert.True(t, status[0].Connected)
+	assert.Equal(t, config.LoadBalanceRoundRobin, status[0].LoadBalance)
+	require.Len(t, status[0].Replicas, 2)
+	assert.Equal(t, "srv[0]", status[0].Replicas[0].ID)
+	assert.Empty(t, status[0].Drift)
+}
+
+func TestCallTool_ReplicasFailover(t *testing.T) {
+	primary, standby := newTestBackend(), newTestBackend()
+	manager := connectReplicas(t, config.MCPServer{
+		LoadBalance:    config.LoadBalanceFailover,
+		Retry:          &config.RetryConfig{InitialBackoff: 0.001},
+		CircuitBreaker: &config.CircuitBreakerConfig{FailureThreshold: 1},
+	}, primary, standby)
+	ctx := context.Background()
+
+	_, err := manager.CallTool(ctx, "srv", "search", nil)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), primary.calls.Load())
+
+	// A failing primary is retried on the standby and ejected from rotation
+	primary.failures.Store(100)
+	_, err = manager.CallTool(ctx, "srv", "flaky", nil)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), primary.calls.Load())
+	assert.Equal(t, int32(1), standby.calls.Load())
+
+	_, err = manager.CallTool(ctx, "srv", "search", nil)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), primary.calls.Load())
+	assert.Equal(t, int32(2), standby.calls.Load())
+
+	replicas := manager.Status()[0].Replicas
+	require.NotNil(t, replicas[0].Circuit)
+	assert.Equal(t, breaker.StateOpen, replicas[0].Circuit.State)
+}
+
+func TestCallTool_ReplicasShareRateLimit(t *testing.T) {
+	a, b := newTestBackend(), newTestBackend()
+	manager := connectReplicas(t, config.MCPServer{
+		RateLimit: &config.RateLimitConfig{RequestsPerSecond: 0.01},
+	}, a, b)
+	ctx := context.Background()
+
+	// The server's rate covers the group, not each replica
+	_, err := manager.CallTool(ctx, "srv", "search", nil)
+	require.NoError(t, err)
+	_, err = manager.CallTool(ctx, "srv", "search", nil)
+	var limitErr *ratelimit.LimitError
+	require.ErrorAs(t, err, &limitErr)
+	assert.Equal(t, int32(1), a.calls.Load()+b.calls.Load())
+
+	status := manager.Status()[0]
+	require.Len(t, status.Limits, 1)
+	assert.Equal(t, "server 'srv'", status.Limits[0].Scope)
+	assert.Empty(t, status.Replicas[0].Limits)
+}
+
+func TestStatus_ReplicaDrift(t *testing.T) {
+	a, b := newTestBackend(), newTestBackend()
+	b.server.AddTool(&mcp.Tool{Name: "extra", InputSchema: map[string]any{"type": "object"}},
+		func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return &mcp.CallToolResult{}, nil
+		})
+	manager := connectReplicas(t, config.MCPServer{}, a, b)
+
+	status := manager.Status()
+	require.Len(t, status, 1)
+	assert.Equal(t, []string{"srv[1] has extra tool 'extra' not served by srv[0]"}, status[0].Drift)
+}
+
+func TestConnectToServer_LazyAndIdle(t *testing.T) {
+	backend := newTestBackend()
+	store, err := catalog.NewStore(t.TempDir(), 0)
+	require.NoError(t, err)
+	serverCfg := config.MCPServer{Command: "test", Lazy: true, IdleTimeout: 1}
+	ctx := context.Background()
+
+	// Without a catalog entry a lazy server is started to discover its tools
+	first := NewManagerWithFactory(logging.NopLogger(), &inMemoryFactory{server: backend.server})
+	first.SetCatalog(store)
+	require.NoError(t, first.ConnectToServer("srv", serverCfg))
+	first.DisconnectAll()
+
+	factory := &inMemoryFactory{server: backend.server}
+	manager := NewManagerWithFactory(logging.NopLogger(), factory)
+	t.Cleanup(func() { manager.DisconnectAll() })
+	manager.SetCatalog(store)
+	require.NoError(t, manager.ConnectToServer("srv", serverCfg))
+
+	// Tools come from the catalog until the first call starts the server
+	assert.Equal(t, int32(0), factory.connects.Load())
+	assert.Len(t, manager.GetAllTools(), 3)
+	assert.True(t, manager.Status()[0].Stopped)
+
+	_, err = manager.CallTool(ctx, "srv", "search", nil)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), factory.connects.Load())
+	assert.True(t, manager.Status()[0].Connected)
+
+	// The idle server is shut down and started again on the next call
+	require.Eventually(t, func() bool {
+		return manager.Status()[0].Stopped
+	}, 3*time.Second, 50*time.Millisecond)
+	assert.False(t, manager.Status()[0].Connected)
+
+	_, err = manager.CallTool(ctx, "srv", "search", nil)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), factory.connects.Load())
+	assert.Equal(t, int32(2), backend.calls.Load())
+}
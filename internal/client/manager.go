@@ -1,21 +1,34 @@
 package client
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"maps"
+	"slices"
+	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/vaayne/mcphub/internal/breaker"
+	"github.com/vaayne/mcphub/internal/cache"
+	"github.com/vaayne/mcphub/internal/catalog"
 	"github.com/vaayne/mcphub/internal/config"
+	"github.com/vaayne/mcphub/internal/ratelimit"
+	"github.com/vaayne/mcphub/internal/retry"
+	"github.com/vaayne/mcphub/internal/toolname"
 	"github.com/vaayne/mcphub/internal/transport"
 )
 
 // clientInfo holds information about a connected client
 type clientInfo struct {
 	serverID      string
+	config        config.MCPServer
 	session       *mcp.ClientSession
 	tools         map[string]*mcp.Tool // tool name -> tool schema
 	mu            sync.RWMutex
@@ -23,6 +36,199 @@ type clientInfo struct {
 	lastConnected time.Time
 	backoff       time.Duration
 	cancelFunc    context.CancelFunc
+	ctx           context.Context               // lifetime of the client, used to start it on demand
+	stopped       bool                          // lazy or idle server that is not running; started by the next call
+	startMu       sync.Mutex                    // serializes starting and stopping on demand
+	lastUsed      atomic.Int64                  // unix nanoseconds of the last call
+	limiters      map[string]*ratelimit.Limiter // "" = whole server, else Tools key
+	breaker       *breaker.Breaker              // nil = no circuit breaker
+
+	group    *clientInfo   // replicated server this endpoint belongs to
+	replicas []*clientInfo // endpoints of a replicated server, in config order
+	next     atomic.Uint64 // round-robin position among replicas
+	inFlight atomic.Int64  // calls in progress on this endpoint
+}
+
+// startable reports whether the server is stopped and will be started on demand
+func (c *clientInfo) startable() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.stopped
+}
+
+// touch records a call for the idle timeout
+func (c *clientInfo) touch() {
+	c.lastUsed.Store(time.Now().UnixNano())
+}
+
+// connected reports whether the server, or any of its replicas, has a session
+func (c *clientInfo) connected() bool {
+	for _, replica := range c.replicas {
+		if replica.connected() {
+			return true
+		}
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.session != nil
+}
+
+// healthy reports whether an endpoint is connected and its circuit admits calls
+func (c *clientInfo) healthy() bool {
+	c.mu.RLock()
+	connected := c.session != nil
+	c.mu.RUnlock()
+	return connected && (c.breaker == nil || c.breaker.Ready())
+}
+
+// pick chooses the endpoint for a call: the server itself, or one of its
+// replicas per the server's load balancing strategy. Unhealthy replicas are
+// ejected from rotation unless none is healthy; avoid (the endpoint that just
+// failed) is skipped when another healthy replica is available.
+func (c *clientInfo) pick(avoid *clientInfo) *clientInfo {
+	if len(c.replicas) == 0 {
+		return c
+	}
+
+	var candidates []*clientInfo
+	for _, replica := range c.replicas {
+		if replica.healthy() && replica != avoid {
+			candidates = append(candidates, replica)
+		}
+	}
+	if len(candidates) == 0 && avoid != nil && avoid.healthy() {
+		candidates = []*clientInfo{avoid}
+	}
+	if len(candidates) == 0 {
+		candidates = c.replicas
+	}
+
+	switch c.config.GetLoadBalance() {
+	case config.LoadBalanceFailover:
+		return candidates[0]
+	case config.LoadBalanceLeastInflight:
+		best := candidates[0]
+		for _, replica := range candidates[1:] {
+			if replica.inFlight.Load() < best.inFlight.Load() {
+				best = replica
+			}
+		}
+		return best
+	default:
+		return candidates[(c.next.Add(1)-1)%uint64(len(candidates))]
+	}
+}
+
+// drift describes how the tools of each connected replica differ from those of
+// the first connected replica
+func (c *clientInfo) drift() []string {
+	var refID string
+	var ref map[string]*mcp.Tool
+	var drift []string
+
+	for _, replica := range c.replicas {
+		replica.mu.RLock()
+		connected, tools := replica.session != nil, replica.tools
+		replica.mu.RUnlock()
+
+		if !connected {
+			continue
+		}
+		if ref == nil {
+			refID, ref = replica.serverID, tools
+			continue
+		}
+
+		for name, tool := range ref {
+			other, ok := tools[name]
+			if !ok {
+				drift = append(drift, fmt.Sprintf("%s is missing tool '%s' served by %s", replica.serverID, name, refID))
+			} else if !sameSchema(tool.InputSchema, other.InputSchema) {
+				drift = append(drift, fmt.Sprintf("%s has a different input schema for tool '%s' than %s", replica.serverID, name, refID))
+			}
+		}
+		for name := range tools {
+			if _, ok := ref[name]; !ok {
+				drift = append(drift, fmt.Sprintf("%s has extra tool '%s' not served by %s", replica.serverID, name, refID))
+			}
+		}
+	}
+
+	sort.Strings(drift)
+	return drift
+}
+
+// sameSchema reports whether two input schemas are equivalent
+func sameSchema(a, b any) bool {
+	dataA, errA := json.Marshal(a)
+	dataB, errB := json.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(dataA, dataB)
+}
+
+// newLimiters creates the rate limiters configured for a server
+func newLimiters(serverID string, serverCfg config.MCPServer) map[string]*ratelimit.Limiter {
+	limiters := make(map[string]*ratelimit.Limiter)
+	if serverCfg.RateLimit != nil {
+		limiters[""] = ratelimit.New(fmt.Sprintf("server '%s'", serverID), *serverCfg.RateLimit)
+	}
+	for key, tool := range serverCfg.Tools {
+		if tool.RateLimit != nil {
+			limiters[key] = ratelimit.New(fmt.Sprintf("tools '%s'", serverCfg.QualifiedToolName(serverID, key)), *tool.RateLimit)
+		}
+	}
+	return limiters
+}
+
+// newBreaker creates the circuit breaker configured for a server, or nil
+func (m *Manager) newBreaker(serverID string, serverCfg config.MCPServer) *breaker.Breaker {
+	if serverCfg.CircuitBreaker == nil {
+		return nil
+	}
+	b := breaker.New(fmt.Sprintf("server '%s'", serverID), *serverCfg.CircuitBreaker)
+	b.IsFailure = func(err error) bool {
+		return retry.Classify(err) != ""
+	}
+	b.OnStateChange = func(from, to string) {
+		m.logger.Warn("Circuit breaker state changed",
+			slog.String("serverID", serverID),
+			slog.String("from", from),
+			slog.String("to", to))
+	}
+	return b
+}
+
+// acquire admits a call to toolName through the server and tool limiters,
+// returning a func that releases both. Replicas share the limiters of their
+// group, so the configured rate holds for the server as a whole.
+func (c *clientInfo) acquire(ctx context.Context, toolName string) (func(), error) {
+	if c.group != nil {
+		return c.group.acquire(ctx, toolName)
+	}
+
+	var releases []func()
+	releaseAll := func() {
+		for _, release := range releases {
+			release()
+		}
+	}
+
+	limiters := []*ratelimit.Limiter{c.limiters[""]}
+	if key, _ := c.config.ToolRateLimit(toolName); key != "" {
+		limiters = append(limiters, c.limiters[key])
+	}
+
+	for _, limiter := range limiters {
+		if limiter == nil {
+			continue
+		}
+		release, err := limiter.Acquire(ctx)
+		if err != nil {
+			releaseAll()
+			return nil, err
+		}
+		releases = append(releases, release)
+	}
+	return releaseAll, nil
 }
 
 // Manager manages connections to remote MCP servers
@@ -34,13 +240,26 @@ type Manager struct {
 	cancel           context.CancelFunc
 	timeout          time.Duration
 	transportFactory transport.Factory
+	cache            cache.Cache    // nil = tool results are not cached
+	cacheTTL         time.Duration  // default TTL of cached results
+	catalog          *catalog.Store // nil = tool lists are not persisted
+
+	namesMu sync.Mutex
+	names   *toolNames // qualified names of every server's tools, nil = rebuilt on next use
+}
+
+// toolNames maps the qualified names the hub lists tools under to the tools
+type toolNames struct {
+	registry *toolname.Registry
+	tools    map[toolname.Ref]*mcp.Tool
+	errs     []error // collisions between servers' tool names
 }
 
 const (
 	initialBackoff = 1 * time.Second
 	maxBackoff     = 30 * time.Second
 	backoffFactor  = 2.0
-	defaultTimeout = 60 * time.Second
+	defaultTimeout = config.DefaultConnectTimeout
 )
 
 // NewManager creates a new client manager
@@ -69,6 +288,38 @@ func NewManagerWithFactory(logger *slog.Logger, factory transport.Factory) *Mana
 	}
 }
 
+// SetCache enables caching of idempotent tool call results in c, kept for
+// defaultTTL unless the tool's config sets its own TTL
+func (m *Manager) SetCache(c cache.Cache, defaultTTL time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.cache = c
+	m.cacheTTL = defaultTTL
+}
+
+// Cache returns the response cache, or nil if caching is disabled
+func (m *Manager) Cache() cache.Cache {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.cache
+}
+
+// SetCatalog persists the tool list of each server in store, so lazy servers
+// can list their tools without being started
+func (m *Manager) SetCatalog(store *catalog.Store) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.catalog = store
+}
+
+// SetConnectTimeout sets the time allowed to connect to a server and list its
+// tools, for servers that don't set their own connectTimeout
+func (m *Manager) SetConnectTimeout(timeout time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.timeout = timeout
+}
+
 // ConnectToServer connects to a remote MCP server
 func (m *Manager) ConnectToServer(serverID string, serverCfg config.MCPServer) error {
 	m.logger.Info("Connecting to remote MCP server",
@@ -79,10 +330,7 @@ func (m *Manager) ConnectToServer(serverID string, serverCfg config.MCPServer) e
 	m.mu.RLock()
 	if existing, ok := m.clients[serverID]; ok {
 		m.mu.RUnlock()
-		existing.mu.RLock()
-		isConnected := existing.session != nil
-		existing.mu.RUnlock()
-		if isConnected {
+		if existing.connected() || existing.startable() {
 			m.logger.Info("Already connected to server", slog.String("serverID", serverID))
 			return nil
 		}
@@ -90,14 +338,55 @@ func (m *Manager) ConnectToServer(serverID string, serverCfg config.MCPServer) e
 		m.mu.RUnlock()
 	}
 
+	if len(serverCfg.Replicas) > 0 {
+		return m.connectReplicas(serverID, serverCfg)
+	}
+
 	// Create client info
 	clientCtx, clientCancel := context.WithCancel(m.ctx)
 	info := &clientInfo{
 		serverID:      serverID,
+		config:        serverCfg,
 		tools:         make(map[string]*mcp.Tool),
 		backoff:       initialBackoff,
 		lastConnected: time.Now(),
 		cancelFunc:    clientCancel,
+		ctx:           clientCtx,
+		limiters:      newLimiters(serverID, serverCfg),
+		breaker:       m.newBreaker(serverID, serverCfg),
+	}
+
+	if serverCfg.GetIdleTimeout() > 0 {
+		go m.watchIdle(clientCtx, info)
+	}
+
+	// Lazy servers with a catalog entry list its tools until the first call;
+	// without one they are started now to discover their tools
+	m.mu.RLock()
+	store := m.catalog
+	m.mu.RUnlock()
+	if serverCfg.Lazy && store != nil {
+		if entry, ok := store.Load(serverID, serverCfg); ok {
+			tools, err := toolname.Expose(serverCfg, entry.Tools)
+			if err != nil {
+				clientCancel()
+				return fmt.Errorf("failed to connect to server %s: %w", serverID, err)
+			}
+			for _, tool := range tools {
+				info.tools[tool.Name] = tool
+			}
+			info.stopped = true
+
+			m.mu.Lock()
+			m.clients[serverID] = info
+			m.invalidateNames()
+			m.mu.Unlock()
+
+			m.logger.Info("Deferred start of lazy server",
+				slog.String("serverID", serverID),
+				slog.Int("toolCount", len(entry.Tools)))
+			return nil
+		}
 	}
 
 	// Attempt connection
@@ -109,6 +398,7 @@ func (m *Manager) ConnectToServer(serverID string, serverCfg config.MCPServer) e
 	// Store client info
 	m.mu.Lock()
 	m.clients[serverID] = info
+	m.invalidateNames()
 	m.mu.Unlock()
 
 	// Start reconnection goroutine
@@ -117,6 +407,162 @@ func (m *Manager) ConnectToServer(serverID string, serverCfg config.MCPServer) e
 	return nil
 }
 
+// start connects to a stopped server on demand
+func (m *Manager) start(info *clientInfo) error {
+	info.startMu.Lock()
+	defer info.startMu.Unlock()
+
+	info.mu.RLock()
+	running := info.session != nil
+	info.mu.RUnlock()
+	if running {
+		return nil
+	}
+
+	m.logger.Info("Starting server on demand", slog.String("serverID", info.serverID))
+	if err := m.connectClient(info.ctx, info, info.config); err != nil {
+		return fmt.Errorf("failed to start server %s: %w", info.serverID, err)
+	}
+
+	go m.maintainConnection(info.ctx, info.serverID, info.config, info)
+	return nil
+}
+
+// watchIdle shuts the server down whenever it goes its idle timeout without calls
+func (m *Manager) watchIdle(ctx context.Context, info *clientInfo) {
+	timeout := info.config.GetIdleTimeout()
+	ticker := time.NewTicker(max(timeout/4, 10*time.Millisecond))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.stopIfIdle(info, timeout)
+		}
+	}
+}
+
+// stopIfIdle closes the server's session if it has had no calls for timeout.
+// The server is marked stopped and started again by the next call.
+func (m *Manager) stopIfIdle(info *clientInfo, timeout time.Duration) {
+	info.startMu.Lock()
+	defer info.startMu.Unlock()
+
+	info.mu.Lock()
+	session := info.session
+	idle := time.Since(time.Unix(0, info.lastUsed.Load())) >= timeout
+	if session == nil || !idle || info.inFlight.Load() > 0 {
+		info.mu.Unlock()
+		return
+	}
+	info.session = nil
+	info.stopped = true
+	info.mu.Unlock()
+
+	m.logger.Info("Stopping idle server",
+		slog.String("serverID", info.serverID),
+		slog.Duration("idleTimeout", timeout))
+	if err := session.Close(); err != nil {
+		m.logger.Debug("Error closing idle server",
+			slog.String("serverID", info.serverID),
+			slog.String("error", err.Error()))
+	}
+}
+
+// connectReplicas connects to every endpoint of a replicated server. It fails
+// only if no replica can be reached; the others keep reconnecting in the
+// background and join the rotation once connected.
+func (m *Manager) connectReplicas(serverID string, serverCfg config.MCPServer) error {
+	groupCtx, groupCancel := context.WithCancel(m.ctx)
+	group := &clientInfo{
+		serverID:      serverID,
+		config:        serverCfg,
+		tools:         make(map[string]*mcp.Tool),
+		lastConnected: time.Now(),
+		cancelFunc:    groupCancel,
+		limiters:      newLimiters(serverID, serverCfg),
+	}
+
+	var contexts []context.Context
+	var errs []error
+	for i, endpoint := range serverCfg.Endpoints() {
+		replicaID := fmt.Sprintf("%s[%d]", serverID, i)
+		// Failing replicas are ejected from rotation by their circuit breaker
+		if endpoint.CircuitBreaker == nil {
+			endpoint.CircuitBreaker = &config.CircuitBreakerConfig{}
+		}
+
+		replicaCtx, replicaCancel := context.WithCancel(groupCtx)
+		replica := &clientInfo{
+			serverID:      replicaID,
+			config:        endpoint,
+			tools:         make(map[string]*mcp.Tool),
+			backoff:       initialBackoff,
+			lastConnected: time.Now(),
+			cancelFunc:    replicaCancel,
+			breaker:       m.newBreaker(replicaID, endpoint),
+			group:         group,
+		}
+		group.replicas = append(group.replicas, replica)
+		contexts = append(contexts, replicaCtx)
+
+		if err := m.connectClient(replicaCtx, replica, endpoint); err != nil {
+			m.logger.Warn("Failed to connect to replica",
+				slog.String("serverID", replicaID),
+				slog.String("error", err.Error()))
+			errs = append(errs, fmt.Errorf("%s: %w", replicaID, err))
+			replica.reconnecting = true
+		}
+	}
+
+	if len(errs) == len(group.replicas) {
+		groupCancel()
+		return fmt.Errorf("failed to connect to server %s: %w", serverID, errors.Join(errs...))
+	}
+
+	m.mu.Lock()
+	m.clients[serverID] = group
+	m.invalidateNames()
+	m.mu.Unlock()
+
+	for i, replica := range group.replicas {
+		go m.maintainConnection(contexts[i], replica.serverID, replica.config, replica)
+	}
+
+	return nil
+}
+
+// syncReplicaTools sets a replicated server's tools to those of its first
+// connected replica and reports replicas whose tools differ
+func (m *Manager) syncReplicaTools(group *clientInfo) {
+	var tools map[string]*mcp.Tool
+	for _, replica := range group.replicas {
+		replica.mu.RLock()
+		if tools == nil && replica.session != nil {
+			tools = replica.tools
+		}
+		replica.mu.RUnlock()
+	}
+	if tools == nil {
+		// Keep the last known tools while every replica is down
+		return
+	}
+
+	group.mu.Lock()
+	group.tools = tools
+	group.lastConnected = time.Now()
+	group.mu.Unlock()
+	m.invalidateNames()
+
+	for _, drift := range group.drift() {
+		m.logger.Warn("Replica tools differ",
+			slog.String("serverID", group.serverID),
+			slog.String("drift", drift))
+	}
+}
+
 // connectClient establishes a connection to a remote MCP server
 func (m *Manager) connectClient(ctx context.Context, info *clientInfo, serverCfg config.MCPServer) error {
 	// Create transport using factory
@@ -131,8 +577,13 @@ func (m *Manager) connectClient(ctx context.Context, info *clientInfo, serverCfg
 		Version: "v1.0.0",
 	}, nil)
 
+	m.mu.RLock()
+	timeout := serverCfg.GetConnectTimeout(m.timeout)
+	store := m.catalog
+	m.mu.RUnlock()
+
 	// Connect with timeout
-	connectCtx, cancel := context.WithTimeout(ctx, m.timeout)
+	connectCtx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
 	session, err := client.Connect(connectCtx, transport, nil)
@@ -141,10 +592,23 @@ func (m *Manager) connectClient(ctx context.Context, info *clientInfo, serverCfg
 	}
 
 	// Discover tools
-	toolsCtx, toolsCancel := context.WithTimeout(ctx, m.timeout)
+	toolsCtx, toolsCancel := context.WithTimeout(ctx, timeout)
 	defer toolsCancel()
 
-	toolsResult, err := session.ListTools(toolsCtx, nil)
+	// With a catalog, prompts and resources are listed too so they can be saved
+	var listing *catalog.Listing
+	if store != nil && info.group == nil {
+		listing, err = catalog.Discover(toolsCtx, session)
+	} else {
+		var toolsResult *mcp.ListToolsResult
+		if toolsResult, err = session.ListTools(toolsCtx, nil); err == nil {
+			listing = &catalog.Listing{Tools: toolsResult.Tools}
+		}
+	}
+	var tools []*mcp.Tool
+	if err == nil {
+		tools, err = toolname.Expose(info.config, listing.Tools)
+	}
 	if err != nil {
 		// Clean up session with timeout on error
 		closeCtx, closeCancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -174,18 +638,33 @@ func (m *Manager) connectClient(ctx context.Context, info *clientInfo, serverCfg
 	info.mu.Lock()
 	info.session = session
 	info.tools = make(map[string]*mcp.Tool)
-	for _, tool := range toolsResult.Tools {
+	for _, tool := range tools {
 		info.tools[tool.Name] = tool
 	}
 	info.lastConnected = time.Now()
 	info.reconnecting = false
+	info.stopped = false
 	info.mu.Unlock()
+	info.touch()
+	m.invalidateNames()
+
+	if store != nil && info.group == nil {
+		if err := store.Save(info.serverID, info.config, listing); err != nil {
+			m.logger.Warn("Failed to save tool catalog",
+				slog.String("serverID", info.serverID),
+				slog.String("error", err.Error()))
+		}
+	}
 
 	m.logger.Info("Connected to server",
 		slog.String("serverID", info.serverID),
-		slog.Int("toolCount", len(toolsResult.Tools)),
+		slog.Int("toolCount", len(listing.Tools)),
 	)
 
+	if info.group != nil {
+		m.syncReplicaTools(info.group)
+	}
+
 	return nil
 }
 
@@ -207,15 +686,20 @@ func (m *Manager) maintainConnection(ctx context.Context, serverID string, serve
 			// Block until connection fails
 			err := session.Wait()
 
+			// A session closed for idleness (or already replaced by a new
+			// one) is not reconnected
 			info.mu.Lock()
-			if err != nil && ctx.Err() == nil {
+			current := info.session == session
+			if err != nil && ctx.Err() == nil && current && !info.stopped {
 				m.logger.Warn("Server connection lost",
 					slog.String("serverID", serverID),
 					slog.String("error", err.Error()),
 				)
 				info.reconnecting = true
 			}
-			info.session = nil
+			if current {
+				info.session = nil
+			}
 			info.mu.Unlock()
 		}
 
@@ -278,9 +762,11 @@ func (m *Manager) DisconnectAll() error {
 	clients := make([]*clientInfo, 0, len(m.clients))
 	for _, info := range m.clients {
 		clients = append(clients, info)
+		clients = append(clients, info.replicas...)
 	}
 	// Clear the registry
 	m.clients = make(map[string]*clientInfo)
+	m.invalidateNames()
 	m.mu.Unlock()
 
 	// Disconnect each client with timeout
@@ -333,9 +819,16 @@ func (m *Manager) GetClient(serverID string) (*mcp.ClientSession, error) {
 		return nil, fmt.Errorf("server not found: %s", serverID)
 	}
 
-	info.mu.RLock()
-	session := info.session
-	info.mu.RUnlock()
+	target := info.pick(nil)
+	if target.startable() {
+		if err := m.start(target); err != nil {
+			return nil, err
+		}
+	}
+
+	target.mu.RLock()
+	session := target.session
+	target.mu.RUnlock()
 
 	if session == nil {
 		return nil, fmt.Errorf("server not connected: %s", serverID)
@@ -344,6 +837,15 @@ func (m *Manager) GetClient(serverID string) (*mcp.ClientSession, error) {
 	return session, nil
 }
 
+// HasServer reports whether a server is configured, whatever its connection
+// state. Unlike GetClient it doesn't pick a replica or start the server.
+func (m *Manager) HasServer(serverID string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	_, ok := m.clients[serverID]
+	return ok
+}
+
 // ListClients returns the IDs of all connected clients
 func (m *Manager) ListClients() []string {
 	m.mu.RLock()
@@ -376,23 +878,324 @@ func (m *Manager) GetTools(serverID string) (map[string]*mcp.Tool, error) {
 	return tools, nil
 }
 
-// GetAllTools returns all tools from all servers with namespace prefix
+// GetAllTools returns all tools from all servers, keyed by the qualified name
+// each server's prefix and separator give them
 func (m *Manager) GetAllTools() map[string]*mcp.Tool {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
+	names := m.registry()
 	allTools := make(map[string]*mcp.Tool)
+	for _, name := range names.registry.Names() {
+		ref, _ := names.registry.Resolve(name)
+		allTools[name] = names.tools[ref]
+	}
 
-	for serverID, info := range m.clients {
+	return allTools
+}
+
+// ResolveTool returns the server and tool listed under a qualified name
+func (m *Manager) ResolveTool(name string) (serverID, toolName string, ok bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	ref, ok := m.registry().registry.Resolve(name)
+	return ref.ServerID, ref.ToolName, ok
+}
+
+// QualifiedToolName returns the name a server's tool is listed under
+func (m *Manager) QualifiedToolName(serverID, toolName string) string {
+	m.mu.RLock()
+	info, ok := m.clients[serverID]
+	m.mu.RUnlock()
+
+	if !ok {
+		return (&config.MCPServer{}).QualifiedToolName(serverID, toolName)
+	}
+	return info.config.QualifiedToolName(serverID, toolName)
+}
+
+// ToolNameCollisions describes tools of different servers listed under the
+// same qualified name; the tool of the server with the lowest ID keeps it
+func (m *Manager) ToolNameCollisions() []error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.registry().errs
+}
+
+// registry returns the qualified names of every server's tools, building them
+// when a server's tools have changed since the last call. Servers and tools are
+// added in sorted order so collisions are resolved the same way every time.
+// Callers must hold m.mu.
+func (m *Manager) registry() *toolNames {
+	m.namesMu.Lock()
+	defer m.namesMu.Unlock()
+
+	if m.names != nil {
+		return m.names
+	}
+
+	names := &toolNames{
+		registry: toolname.NewRegistry(),
+		tools:    make(map[toolname.Ref]*mcp.Tool),
+	}
+	for _, serverID := range slices.Sorted(maps.Keys(m.clients)) {
+		info := m.clients[serverID]
 		info.mu.RLock()
-		for toolName, tool := range info.tools {
-			namespacedName := fmt.Sprintf("%s__%s", serverID, toolName)
-			allTools[namespacedName] = tool
+		for _, toolName := range slices.Sorted(maps.Keys(info.tools)) {
+			ref := toolname.Ref{ServerID: serverID, ToolName: toolName}
+			names.tools[ref] = info.tools[toolName]
+			if err := names.registry.Add(info.config.QualifiedToolName(serverID, toolName), ref); err != nil {
+				names.errs = append(names.errs, err)
+			}
 		}
 		info.mu.RUnlock()
 	}
 
-	return allTools
+	m.names = names
+	return names
+}
+
+// invalidateNames drops the registry after a server's tools change, so the
+// next lookup rebuilds it. Callers must not hold the mutex of any clientInfo.
+func (m *Manager) invalidateNames() {
+	m.namesMu.Lock()
+	m.names = nil
+	m.namesMu.Unlock()
+}
+
+// CallTool calls a tool on a connected server by the name it is exposed as.
+// All backend tool calls go through here; results of cacheable tools are served
+// from the cache, and failed calls to idempotent tools are retried per the
+// server's retry policy.
+func (m *Manager) CallTool(ctx context.Context, serverID, name string, args map[string]any) (*mcp.CallToolResult, error) {
+	m.mu.RLock()
+	info, ok := m.clients[serverID]
+	c, defaultTTL := m.cache, m.cacheTTL
+	m.mu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("server not found: %s", serverID)
+	}
+
+	toolName, ok := info.config.BackendToolName(name)
+	if !ok {
+		return nil, fmt.Errorf("tool not found: %s", info.config.QualifiedToolName(serverID, name))
+	}
+
+	info.mu.RLock()
+	tool := info.tools[name]
+	info.mu.RUnlock()
+
+	if !info.connected() && !info.startable() {
+		return nil, fmt.Errorf("server not connected: %s", serverID)
+	}
+
+	readOnly := tool != nil && tool.Annotations != nil && tool.Annotations.ReadOnlyHint
+	idempotent := readOnly || (tool != nil && tool.Annotations != nil && tool.Annotations.IdempotentHint)
+	policy := info.config.RetryPolicy(toolName, idempotent)
+
+	// Each attempt picks an endpoint, so retries of a replicated server move
+	// on from the replica that just failed
+	var target *clientInfo
+	call := func() (*mcp.CallToolResult, error) {
+		return retry.Do(ctx, policy, func() (*mcp.CallToolResult, error) {
+			target = info.pick(target)
+			return m.callOnce(ctx, target, toolName, args)
+		}, func(attempt int, err error, delay time.Duration) {
+			m.logger.Warn("Retrying tool call",
+				slog.String("serverID", serverID),
+				slog.String("tool", toolName),
+				slog.Int("attempt", attempt),
+				slog.Duration("delay", delay),
+				slog.String("error", err.Error()))
+		})
+	}
+
+	if c == nil {
+		return call()
+	}
+
+	ttl, cacheable := info.config.CachePolicy(toolName, readOnly, defaultTTL)
+	if !cacheable {
+		return call()
+	}
+
+	key, err := cache.Key(serverID, toolName, args)
+	if err != nil {
+		return call()
+	}
+	return cache.Call(c, key, ttl, call)
+}
+
+// callOnce makes a single attempt at a tool call through the server's rate
+// limiters and circuit breaker
+func (m *Manager) callOnce(ctx context.Context, info *clientInfo, toolName string, args map[string]any) (*mcp.CallToolResult, error) {
+	release, err := info.acquire(ctx, toolName)
+	if err != nil {
+		var limitErr *ratelimit.LimitError
+		if errors.As(err, &limitErr) {
+			m.logger.Warn("Tool call rejected by rate limit",
+				slog.String("serverID", info.serverID),
+				slog.String("tool", toolName),
+				slog.String("error", err.Error()))
+		}
+		return nil, err
+	}
+	defer release()
+
+	done := func(error) {}
+	if info.breaker != nil {
+		if done, err = info.breaker.Allow(); err != nil {
+			return nil, err
+		}
+	}
+
+	// Counted in flight before the session is read so an idle shutdown can't
+	// close it underneath the call
+	info.inFlight.Add(1)
+	defer info.inFlight.Add(-1)
+	info.touch()
+	defer info.touch()
+
+	// Retries pick up the new session after a reconnect; stopped servers are
+	// started on demand
+	if info.startable() {
+		if err := m.start(info); err != nil {
+			done(err)
+			return nil, err
+		}
+	}
+	info.mu.RLock()
+	session := info.session
+	info.mu.RUnlock()
+	if session == nil {
+		err := fmt.Errorf("%w: server not connected: %s", mcp.ErrConnectionClosed, info.serverID)
+		done(err)
+		return nil, err
+	}
+
+	result, err := session.CallTool(ctx, &mcp.CallToolParams{
+		Name:      toolName,
+		Arguments: args,
+	})
+	done(err)
+	return result, err
+}
+
+// ServerStatus describes a server's connection, call limits and circuit breaker
+type ServerStatus struct {
+	ID        string            `json:"id"`
+	Connected bool              `json:"connected"`
+	Tools     int               `json:"tools"`
+	Limits    []ratelimit.Stats `json:"limits,omitempty"`
+	Circuit   *breaker.Stats    `json:"circuit,omitempty"`
+
+	// Replicated servers only
+	LoadBalance string         `json:"loadBalance,omitempty"`
+	Replicas    []ServerStatus `json:"replicas,omitempty"`
+	Drift       []string       `json:"drift,omitempty"` // differences between the replicas' tools
+
+	// Replicas only
+	InFlight int64 `json:"inFlight,omitempty"`
+
+	Stopped bool `json:"stopped,omitempty"` // lazy or idle server, started by the next call
+}
+
+// Status returns the status of every configured server, sorted by ID
+func (m *Manager) Status() []ServerStatus {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	statuses := make([]ServerStatus, 0, len(m.clients))
+	for _, info := range m.clients {
+		statuses = append(statuses, info.status())
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].ID < statuses[j].ID
+	})
+	return statuses
+}
+
+// status describes the server, including each of its replicas
+func (c *clientInfo) status() ServerStatus {
+	c.mu.RLock()
+	status := ServerStatus{
+		ID:        c.serverID,
+		Connected: c.session != nil,
+		Tools:     len(c.tools),
+		Stopped:   c.stopped,
+	}
+	c.mu.RUnlock()
+
+	for _, limiter := range c.limiters {
+		status.Limits = append(status.Limits, limiter.Stats())
+	}
+	sort.Slice(status.Limits, func(i, j int) bool {
+		return status.Limits[i].Scope < status.Limits[j].Scope
+	})
+
+	if c.breaker != nil {
+		stats := c.breaker.Stats()
+		status.Circuit = &stats
+	}
+
+	if c.group != nil {
+		status.InFlight = c.inFlight.Load()
+	}
+
+	if len(c.replicas) > 0 {
+		status.Connected = c.connected()
+		status.LoadBalance = c.config.GetLoadBalance()
+		for _, replica := range c.replicas {
+			status.Replicas = append(status.Replicas, replica.status())
+		}
+		status.Drift = c.drift()
+	}
+
+	return status
+}
+
+// ValidatesArgs reports whether tool arguments for a server should be validated
+// against the tool's inputSchema (true unless the server config opts out)
+func (m *Manager) ValidatesArgs(serverID string) bool {
+	m.mu.RLock()
+	info, ok := m.clients[serverID]
+	m.mu.RUnlock()
+
+	if !ok {
+		return true
+	}
+	return info.config.ShouldValidateArgs()
+}
+
+// CoercesArgs reports whether tool arguments for a server should be coerced
+// to match the tool's inputSchema (true unless the config opts out)
+func (m *Manager) CoercesArgs(serverID string) bool {
+	m.mu.RLock()
+	info, ok := m.clients[serverID]
+	m.mu.RUnlock()
+
+	if !ok {
+		return true
+	}
+	return info.config.ShouldCoerceArgs()
+}
+
+// ResultLimit returns the maximum bytes of text returned for a tool's result
+// before it is truncated (0 = unlimited)
+func (m *Manager) ResultLimit(serverID, name string) int {
+	m.mu.RLock()
+	info, ok := m.clients[serverID]
+	m.mu.RUnlock()
+
+	if !ok {
+		return config.DefaultMaxResultBytes
+	}
+	toolName, _ := info.config.BackendToolName(name)
+	return info.config.ResultLimit(toolName)
 }
 
 // DetectNameCollisions returns tools with duplicate names across servers
@@ -3,23 +3,390 @@ package config
 import (
 	"encoding/json"
 	"fmt"
+	"maps"
+	"math"
 	"net"
 	"net/url"
 	"os"
+	"path"
 	"path/filepath"
 	"regexp"
 	"slices"
+	"sort"
 	"strings"
+	"time"
+	"unicode"
 )
 
 // validServerNameRegex matches: starts with letter, followed by alphanumeric or underscore
 var validServerNameRegex = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_]*$`)
 
+// validSeparatorRegex matches separators that keep qualified tool names valid
+// JS method names once converted (empty = the default separator)
+var validSeparatorRegex = regexp.MustCompile(`^[a-zA-Z0-9_$-]*$`)
+
+// DefaultMaxResultBytes is the default size limit for text returned by a tool call
+const DefaultMaxResultBytes = 100_000
+
 // Config represents the MCP hub configuration
 type Config struct {
-	Version      string                 `json:"version,omitempty"`
-	MCPServers   map[string]MCPServer   `json:"mcpServers"`
-	BuiltinTools map[string]BuiltinTool `json:"builtinTools,omitempty"`
+	Version        string                 `json:"version,omitempty"`
+	MCPServers     map[string]MCPServer   `json:"mcpServers"`
+	BuiltinTools   map[string]BuiltinTool `json:"builtinTools,omitempty"`
+	CoerceArgs     *bool                  `json:"coerceArgs,omitempty"`     // Hub-wide default for argument coercion (default true)
+	MaxResultBytes *int                   `json:"maxResultBytes,omitempty"` // Hub-wide result size limit in bytes, 0 = unlimited
+	Cache          *CacheConfig           `json:"cache,omitempty"`          // Response cache for idempotent tool calls (nil = disabled)
+	Catalog        *CatalogConfig         `json:"catalog,omitempty"`        // Persisted tool catalog (default: enabled)
+	Startup        *StartupConfig         `json:"startup,omitempty"`        // How servers are connected at startup
+	Separator      string                 `json:"separator,omitempty"`      // Hub-wide separator between server prefix and tool name (default "__")
+	Search         *SearchConfig          `json:"search,omitempty"`         // How the list tool ranks tools for a query
+	Scripts        *ScriptsConfig         `json:"scripts,omitempty"`        // Library of saved scripts and modules for exec
+	Exec           *ExecConfig            `json:"exec,omitempty"`           // Runtime settings of exec scripts
+	State          *StateConfig           `json:"state,omitempty"`          // Key-value state exec scripts keep between calls
+}
+
+// DefaultSeparator separates a server's prefix from its tool names
+const DefaultSeparator = "__"
+
+// Startup defaults
+const (
+	DefaultStartupParallelism = 8
+	DefaultConnectTimeout     = 60 * time.Second
+)
+
+// StartupConfig configures how servers are connected at startup
+type StartupConfig struct {
+	Parallelism    int `json:"parallelism,omitempty"`    // Servers connected at the same time (default 8)
+	ConnectTimeout int `json:"connectTimeout,omitempty"` // Default seconds to connect and list tools (default 60)
+}
+
+// GetStartup returns the startup settings, with defaults if none are configured
+func (c *Config) GetStartup() *StartupConfig {
+	if c.Startup == nil {
+		return &StartupConfig{}
+	}
+	return c.Startup
+}
+
+// GetParallelism returns how many servers are connected at the same time
+func (c *StartupConfig) GetParallelism() int {
+	if c.Parallelism <= 0 {
+		return DefaultStartupParallelism
+	}
+	return c.Parallelism
+}
+
+// GetConnectTimeout returns the default time allowed to connect to a server
+func (c *StartupConfig) GetConnectTimeout() time.Duration {
+	if c.ConnectTimeout <= 0 {
+		return DefaultConnectTimeout
+	}
+	return time.Duration(c.ConnectTimeout) * time.Second
+}
+
+// DefaultSemanticWeight is the share of a tool's search score taken from
+// embedding similarity when an embedder is configured
+const DefaultSemanticWeight = 0.5
+
+// SearchConfig configures how tools are ranked against a list query
+type SearchConfig struct {
+	Embedder       string   `json:"embedder,omitempty"`       // Embedder blended with BM25 ("" = BM25 only, "hash" = local hashed n-grams)
+	SemanticWeight *float64 `json:"semanticWeight,omitempty"` // Share of the score from embedding similarity, 0-1 (default 0.5)
+}
+
+// GetSemanticWeight returns the share of the score taken from embedding similarity
+func (c *SearchConfig) GetSemanticWeight() float64 {
+	if c.SemanticWeight == nil {
+		return DefaultSemanticWeight
+	}
+	return *c.SemanticWeight
+}
+
+// DefaultCatalogTTL is how long catalog entries are used before servers are
+// contacted again
+const DefaultCatalogTTL = 24 * time.Hour
+
+// CatalogConfig configures the persisted catalog of server tools, prompts and resources
+type CatalogConfig struct {
+	Enable *bool  `json:"enable,omitempty"` // default true
+	Dir    string `json:"dir,omitempty"`    // default: user cache directory
+	TTL    int    `json:"ttl,omitempty"`    // Seconds an entry is used before it is refreshed (default 86400)
+}
+
+// GetCatalog returns the catalog settings, with defaults if none are configured
+func (c *Config) GetCatalog() *CatalogConfig {
+	if c.Catalog == nil {
+		return &CatalogConfig{}
+	}
+	return c.Catalog
+}
+
+// IsEnabled returns true unless the catalog is disabled
+func (c *CatalogConfig) IsEnabled() bool {
+	return c.Enable == nil || *c.Enable
+}
+
+// GetDir returns the catalog directory
+func (c *CatalogConfig) GetDir() string {
+	if c.Dir != "" {
+		return c.Dir
+	}
+	return DefaultCatalogDir()
+}
+
+// GetTTL returns how long catalog entries are used
+func (c *CatalogConfig) GetTTL() time.Duration {
+	if c.TTL <= 0 {
+		return DefaultCatalogTTL
+	}
+	return time.Duration(c.TTL) * time.Second
+}
+
+// Cache store types
+const (
+	CacheStoreMemory = "memory"
+	CacheStoreDisk   = "disk"
+)
+
+// Cache defaults
+const (
+	DefaultCacheMaxEntries = 1000
+	DefaultCacheMaxBytes   = 100 << 20 // 100 MB
+	DefaultCacheTTL        = 5 * time.Minute
+)
+
+// CacheConfig configures the response cache for idempotent tool calls
+type CacheConfig struct {
+	Store      string `json:"store,omitempty"`      // "memory" (default) or "disk"
+	Dir        string `json:"dir,omitempty"`        // Directory for the disk store (default: user cache dir)
+	MaxEntries int    `json:"maxEntries,omitempty"` // Maximum entries kept by either store (default 1000)
+	MaxBytes   int64  `json:"maxBytes,omitempty"`   // Maximum total size of the disk store's entries (default 100 MB)
+	TTL        int    `json:"ttl,omitempty"`        // Default time-to-live in seconds (default 300)
+}
+
+// GetStore returns the cache store type, defaulting to memory
+func (c *CacheConfig) GetStore() string {
+	if c.Store == "" {
+		return CacheStoreMemory
+	}
+	return strings.ToLower(c.Store)
+}
+
+// GetDir returns the disk store directory, defaulting to the user cache directory
+func (c *CacheConfig) GetDir() string {
+	if c.Dir != "" {
+		return c.Dir
+	}
+	return DefaultCacheDir()
+}
+
+// DefaultCatalogDir returns the default directory of the persisted tool catalog
+func DefaultCatalogDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "mcphub", "catalog")
+}
+
+// DefaultCacheDir returns the default directory of the disk cache store
+func DefaultCacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "mcphub", "cache")
+}
+
+// ScriptsConfig configures the script library: saved scripts run by name, and
+// modules in its lib folder that exec scripts load with require("lib/name")
+type ScriptsConfig struct {
+	Dir string `json:"dir,omitempty"` // default: user config directory
+}
+
+// GetScripts returns the script library settings, with defaults if none are configured
+func (c *Config) GetScripts() *ScriptsConfig {
+	if c.Scripts == nil {
+		return &ScriptsConfig{}
+	}
+	return c.Scripts
+}
+
+// GetDir returns the script library directory
+func (c *ScriptsConfig) GetDir() string {
+	if c.Dir != "" {
+		return c.Dir
+	}
+	return DefaultScriptsDir()
+}
+
+// DefaultScriptsDir returns the default directory of the script library
+func DefaultScriptsDir() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "mcphub", "scripts")
+}
+
+// ExecModules are the node:* built-in modules exec scripts can require; the
+// runtime limits require to the allowedModules among them
+var ExecModules = []string{"buffer", "console", "process", "url", "util"}
+
+// ExecConfig configures the runtime of exec scripts, for the exec and run
+// builtins and the mh exec and mh scripts run commands. Unset limits use the
+// runtime defaults.
+type ExecConfig struct {
+	Enable             *bool               `json:"enable,omitempty"`             // Offer the exec and run builtins (default true)
+	Timeout            int                 `json:"timeout,omitempty"`            // Seconds a script may run (default 15)
+	MaxScriptSize      int                 `json:"maxScriptSize,omitempty"`      // Bytes of a script or library module (default 102400)
+	MaxLogEntries      int                 `json:"maxLogEntries,omitempty"`      // Log entries kept per script (default 1000)
+	MaxMemory          int64               `json:"maxMemory,omitempty"`          // Bytes of process-wide heap growth while a script runs alone (default 256MB)
+	MaxCallDepth       int                 `json:"maxCallDepth,omitempty"`       // JS call stack depth (default 10000)
+	MaxToolCalls       int                 `json:"maxToolCalls,omitempty"`       // Tool calls per script (default 1000)
+	MaxToolResultBytes int64               `json:"maxToolResultBytes,omitempty"` // Total bytes of tool results per script (default 50MB)
+	AllowedTools       map[string][]string `json:"allowedTools,omitempty"`       // Tool name globs scripts may call, by server (default: all)
+	AllowedModules     []string            `json:"allowedModules,omitempty"`     // node:* built-ins scripts may require (default: all)
+	Fetch              *ExecFetchConfig    `json:"fetch,omitempty"`              // Hosts mcp.fetch may reach (default: no network access)
+}
+
+// ExecFetchConfig opens mcp.fetch in scripts to a list of hosts
+type ExecFetchConfig struct {
+	AllowedHosts     []string                     `json:"allowedHosts,omitempty"`     // Host globs, optionally with a port
+	AllowedMethods   []string                     `json:"allowedMethods,omitempty"`   // HTTP methods (default GET and HEAD)
+	Timeout          int                          `json:"timeout,omitempty"`          // Seconds per request (default 10)
+	MaxResponseBytes int64                        `json:"maxResponseBytes,omitempty"` // Bytes of a response body (default 1MB)
+	Headers          map[string]map[string]string `json:"headers,omitempty"`          // Headers added to requests by host glob; values may reference environment variables as ${NAME}
+}
+
+// GetExec returns the exec settings, with defaults if none are configured
+func (c *Config) GetExec() *ExecConfig {
+	if c.Exec == nil {
+		return &ExecConfig{}
+	}
+	return c.Exec
+}
+
+// IsEnabled returns true unless the exec builtin is disabled
+func (c *ExecConfig) IsEnabled() bool {
+	return c.Enable == nil || *c.Enable
+}
+
+// GetTimeout returns how long a script may run (0 = runtime default)
+func (c *ExecConfig) GetTimeout() time.Duration {
+	return time.Duration(c.Timeout) * time.Second
+}
+
+// FetchEnabled returns true if scripts may reach any host with mcp.fetch
+func (c *ExecConfig) FetchEnabled() bool {
+	return c.Fetch != nil && len(c.Fetch.AllowedHosts) > 0
+}
+
+// GetTimeout returns how long a request may take (0 = runtime default)
+func (c *ExecFetchConfig) GetTimeout() time.Duration {
+	return time.Duration(c.Timeout) * time.Second
+}
+
+// FetchMethods are the HTTP methods exec.fetch.allowedMethods may name
+var FetchMethods = []string{"GET", "HEAD", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
+
+// State defaults
+const (
+	DefaultStateMaxKeys    = 1000
+	DefaultStateMaxBytes   = 1 << 20 // 1MB
+	DefaultStateSessionTTL = time.Hour
+)
+
+// StateConfig configures mcp.state, the key-value state exec scripts keep
+// between calls. Each client session has its own namespace in memory; the
+// persistent namespace is kept on disk and shared by every session.
+type StateConfig struct {
+	MaxKeys    int    `json:"maxKeys,omitempty"`    // Keys per namespace (default 1000)
+	MaxBytes   int    `json:"maxBytes,omitempty"`   // Bytes of keys and values per namespace (default 1MB)
+	SessionTTL int    `json:"sessionTTL,omitempty"` // Seconds a session's state is kept after its last use (default 3600)
+	Persistent bool   `json:"persistent,omitempty"` // Offer mcp.state.persistent
+	Dir        string `json:"dir,omitempty"`        // Directory of the persistent namespace (default: user config directory)
+}
+
+// GetState returns the state settings, with defaults if none are configured
+func (c *Config) GetState() *StateConfig {
+	if c.State == nil {
+		return &StateConfig{}
+	}
+	return c.State
+}
+
+// GetMaxKeys returns the number of keys a namespace may hold
+func (c *StateConfig) GetMaxKeys() int {
+	if c.MaxKeys <= 0 {
+		return DefaultStateMaxKeys
+	}
+	return c.MaxKeys
+}
+
+// GetMaxBytes returns the bytes of keys and values a namespace may hold
+func (c *StateConfig) GetMaxBytes() int {
+	if c.MaxBytes <= 0 {
+		return DefaultStateMaxBytes
+	}
+	return c.MaxBytes
+}
+
+// GetSessionTTL returns how long a session's state is kept after its last use
+func (c *StateConfig) GetSessionTTL() time.Duration {
+	if c.SessionTTL <= 0 {
+		return DefaultStateSessionTTL
+	}
+	return time.Duration(c.SessionTTL) * time.Second
+}
+
+// GetDir returns the directory of the persistent namespace
+func (c *StateConfig) GetDir() string {
+	if c.Dir != "" {
+		return c.Dir
+	}
+	return DefaultStateDir()
+}
+
+// DefaultStateDir returns the default directory of the persistent namespace
+func DefaultStateDir() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "mcphub", "state")
+}
+
+// GetMaxEntries returns the capacity of the store in entries
+func (c *CacheConfig) GetMaxEntries() int {
+	if c.MaxEntries <= 0 {
+		return DefaultCacheMaxEntries
+	}
+	return c.MaxEntries
+}
+
+// GetMaxBytes returns the capacity of the disk store in bytes
+func (c *CacheConfig) GetMaxBytes() int64 {
+	if c.MaxBytes <= 0 {
+		return DefaultCacheMaxBytes
+	}
+	return c.MaxBytes
+}
+
+// GetTTL returns the default time-to-live of cached results
+func (c *CacheConfig) GetTTL() time.Duration {
+	if c.TTL <= 0 {
+		return DefaultCacheTTL
+	}
+	return time.Duration(c.TTL) * time.Second
+}
+
+// ResultLimit returns the hub-wide result size limit (0 = unlimited)
+func (c *Config) ResultLimit() int {
+	if c.MaxResultBytes == nil {
+		return DefaultMaxResultBytes
+	}
+	return *c.MaxResultBytes
 }
 
 // MCPServer represents a remote MCP server configuration
@@ -34,6 +401,278 @@ type MCPServer struct {
 	Headers       map[string]string `json:"headers,omitempty"`       // Custom HTTP headers for http/sse transports
 	Timeout       *int              `json:"timeout,omitempty"`       // Request timeout in seconds
 	TLSSkipVerify *bool             `json:"tlsSkipVerify,omitempty"` // Skip TLS verification (dev only)
+	ValidateArgs  *bool             `json:"validateArgs,omitempty"`  // Validate tool arguments against inputSchema (default true)
+	CoerceArgs    *bool             `json:"coerceArgs,omitempty"`    // Coerce tool arguments to inputSchema types (overrides hub default)
+
+	MaxResultBytes *int                  `json:"maxResultBytes,omitempty"` // Result size limit in bytes (overrides hub default)
+	Tools          map[string]ToolConfig `json:"tools,omitempty"`          // Per-tool settings keyed by tool name or glob (e.g. "search_*")
+	IncludeTools   []string              `json:"includeTools,omitempty"`   // Globs of the tools to expose (default: all)
+	ExcludeTools   []string              `json:"excludeTools,omitempty"`   // Globs of tools to hide, applied after includeTools
+	RateLimit      *RateLimitConfig      `json:"rateLimit,omitempty"`      // Limits shared by all calls to this server
+	Retry          *RetryConfig          `json:"retry,omitempty"`          // Retries of failed calls to idempotent tools (nil = no retries)
+	CircuitBreaker *CircuitBreakerConfig `json:"circuitBreaker,omitempty"` // Fast-fail calls while the server keeps failing (nil = disabled)
+
+	Replicas    []Replica `json:"replicas,omitempty"`    // Endpoints serving the same tools, balanced as one server
+	LoadBalance string    `json:"loadBalance,omitempty"` // Replica selection: round-robin (default), least-inflight or failover
+
+	Lazy        bool `json:"lazy,omitempty"`        // Start on the first call instead of at hub startup
+	IdleTimeout int  `json:"idleTimeout,omitempty"` // Seconds without calls before the server is shut down, 0 = never
+
+	ConnectTimeout int `json:"connectTimeout,omitempty"` // Seconds to connect and list tools, any transport (overrides the startup default)
+
+	Prefix    *string `json:"prefix,omitempty"`    // Prefix of the server's tool names (default: server ID, "" = unprefixed)
+	Separator string  `json:"separator,omitempty"` // Separator between prefix and tool name (overrides hub default)
+}
+
+// GetPrefix returns the prefix of the server's tool names
+func (s *MCPServer) GetPrefix(serverID string) string {
+	if s.Prefix == nil {
+		return serverID
+	}
+	return *s.Prefix
+}
+
+// GetSeparator returns the separator between the server's prefix and tool names
+func (s *MCPServer) GetSeparator() string {
+	if s.Separator == "" {
+		return DefaultSeparator
+	}
+	return s.Separator
+}
+
+// QualifiedToolName returns the name the hub lists a server's tool under:
+// prefix, separator and tool name, or just the tool name without a prefix
+func (s *MCPServer) QualifiedToolName(serverID, toolName string) string {
+	prefix := s.GetPrefix(serverID)
+	if prefix == "" {
+		return toolName
+	}
+	return prefix + s.GetSeparator() + toolName
+}
+
+// GetConnectTimeout returns the time allowed to connect to the server and list
+// its tools, or fallback if the server doesn't set one
+func (s *MCPServer) GetConnectTimeout(fallback time.Duration) time.Duration {
+	if s.ConnectTimeout <= 0 {
+		return fallback
+	}
+	return time.Duration(s.ConnectTimeout) * time.Second
+}
+
+// GetIdleTimeout returns how long the server may go without calls before it is
+// shut down (0 = never)
+func (s *MCPServer) GetIdleTimeout() time.Duration {
+	return time.Duration(s.IdleTimeout) * time.Second
+}
+
+// Load balancing strategies for replicated servers
+const (
+	LoadBalanceRoundRobin    = "round-robin"
+	LoadBalanceLeastInflight = "least-inflight"
+	LoadBalanceFailover      = "failover"
+)
+
+// LoadBalanceStrategies lists the valid loadBalance values
+var LoadBalanceStrategies = []string{LoadBalanceRoundRobin, LoadBalanceLeastInflight, LoadBalanceFailover}
+
+// Replica is one endpoint of a replicated server. Fields left empty are taken
+// from the server; env and headers are merged with the server's.
+type Replica struct {
+	Command string            `json:"command,omitempty"`
+	Args    []string          `json:"args,omitempty"`
+	Env     map[string]string `json:"env,omitempty"`
+	URL     string            `json:"url,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// GetLoadBalance returns the replica selection strategy
+func (s *MCPServer) GetLoadBalance() string {
+	if s.LoadBalance == "" {
+		return LoadBalanceRoundRobin
+	}
+	return strings.ToLower(s.LoadBalance)
+}
+
+// Endpoints returns the configuration of each replica of the server, or the
+// server itself if it has no replicas. Endpoint configs have no replicas.
+func (s *MCPServer) Endpoints() []MCPServer {
+	if len(s.Replicas) == 0 {
+		return []MCPServer{*s}
+	}
+
+	endpoints := make([]MCPServer, 0, len(s.Replicas))
+	for _, replica := range s.Replicas {
+		endpoint := *s
+		endpoint.Replicas = nil
+		if replica.Command != "" {
+			endpoint.Command = replica.Command
+		}
+		if replica.Args != nil {
+			endpoint.Args = replica.Args
+		}
+		if replica.URL != "" {
+			endpoint.URL = replica.URL
+		}
+		endpoint.Env = mergeMaps(s.Env, replica.Env)
+		endpoint.Headers = mergeMaps(s.Headers, replica.Headers)
+		endpoints = append(endpoints, endpoint)
+	}
+	return endpoints
+}
+
+// mergeMaps returns base overlaid with override, or base if override is empty
+func mergeMaps(base, override map[string]string) map[string]string {
+	if len(override) == 0 {
+		return base
+	}
+	merged := make(map[string]string, len(base)+len(override))
+	maps.Copy(merged, base)
+	maps.Copy(merged, override)
+	return merged
+}
+
+// Retryable error classes
+const (
+	ErrorClassTimeout    = "timeout"    // the call timed out
+	ErrorClassConnection = "connection" // the connection was closed, refused or reset
+	ErrorClassServer     = "server"     // the server reported an internal error
+)
+
+// ErrorClasses lists the retryable error classes
+var ErrorClasses = []string{ErrorClassTimeout, ErrorClassConnection, ErrorClassServer}
+
+// Retry and circuit breaker defaults
+const (
+	DefaultRetryMaxAttempts    = 3
+	DefaultRetryInitialBackoff = 500 * time.Millisecond
+	DefaultRetryMaxBackoff     = 10 * time.Second
+	DefaultBreakerThreshold    = 5
+	DefaultBreakerOpenTimeout  = 30 * time.Second
+)
+
+// RetryConfig controls retries of failed tool calls. Only tools that are safe to
+// repeat (annotated readOnlyHint or idempotentHint, or marked idempotent in the
+// server's tools settings) are retried.
+type RetryConfig struct {
+	MaxAttempts    int      `json:"maxAttempts,omitempty"`    // Total attempts including the first (default 3)
+	InitialBackoff float64  `json:"initialBackoff,omitempty"` // Seconds before the first retry, doubled on each retry (default 0.5)
+	MaxBackoff     float64  `json:"maxBackoff,omitempty"`     // Upper bound of the delay between attempts in seconds (default 10)
+	RetryOn        []string `json:"retryOn,omitempty"`        // Error classes to retry: timeout, connection, server (default all)
+}
+
+// GetMaxAttempts returns the total number of attempts per call
+func (r *RetryConfig) GetMaxAttempts() int {
+	if r.MaxAttempts <= 0 {
+		return DefaultRetryMaxAttempts
+	}
+	return r.MaxAttempts
+}
+
+// GetInitialBackoff returns the delay before the first retry
+func (r *RetryConfig) GetInitialBackoff() time.Duration {
+	if r.InitialBackoff <= 0 {
+		return DefaultRetryInitialBackoff
+	}
+	return time.Duration(r.InitialBackoff * float64(time.Second))
+}
+
+// GetMaxBackoff returns the upper bound of the delay between attempts
+func (r *RetryConfig) GetMaxBackoff() time.Duration {
+	if r.MaxBackoff <= 0 {
+		return DefaultRetryMaxBackoff
+	}
+	return time.Duration(r.MaxBackoff * float64(time.Second))
+}
+
+// RetriesOn reports whether errors of class are retried
+func (r *RetryConfig) RetriesOn(class string) bool {
+	if len(r.RetryOn) == 0 {
+		return slices.Contains(ErrorClasses, class)
+	}
+	return slices.Contains(r.RetryOn, class)
+}
+
+// CircuitBreakerConfig controls when calls to a failing server are fast-failed
+type CircuitBreakerConfig struct {
+	FailureThreshold int     `json:"failureThreshold,omitempty"` // Consecutive failures that open the circuit (default 5)
+	OpenTimeout      float64 `json:"openTimeout,omitempty"`      // Seconds the circuit stays open before a trial call (default 30)
+}
+
+// GetFailureThreshold returns the consecutive failures that open the circuit
+func (c *CircuitBreakerConfig) GetFailureThreshold() int {
+	if c.FailureThreshold <= 0 {
+		return DefaultBreakerThreshold
+	}
+	return c.FailureThreshold
+}
+
+// GetOpenTimeout returns how long the circuit stays open before a trial call
+func (c *CircuitBreakerConfig) GetOpenTimeout() time.Duration {
+	if c.OpenTimeout <= 0 {
+		return DefaultBreakerOpenTimeout
+	}
+	return time.Duration(c.OpenTimeout * float64(time.Second))
+}
+
+// RateLimitConfig limits the rate and concurrency of tool calls
+type RateLimitConfig struct {
+	RequestsPerSecond float64 `json:"requestsPerSecond,omitempty"` // Token bucket refill rate, 0 = unlimited
+	Burst             int     `json:"burst,omitempty"`             // Token bucket size (default: requestsPerSecond rounded up)
+	MaxConcurrent     int     `json:"maxConcurrent,omitempty"`     // Maximum in-flight calls, 0 = unlimited
+	MaxWait           float64 `json:"maxWait,omitempty"`           // Seconds a call may queue for a slot, 0 = reject immediately
+}
+
+// GetBurst returns the token bucket size
+func (r *RateLimitConfig) GetBurst() int {
+	if r.Burst > 0 {
+		return r.Burst
+	}
+	return max(1, int(math.Ceil(r.RequestsPerSecond)))
+}
+
+// GetMaxWait returns how long a call may queue before it is rejected
+func (r *RateLimitConfig) GetMaxWait() time.Duration {
+	return time.Duration(r.MaxWait * float64(time.Second))
+}
+
+// ToolConfig holds settings for the tools of a server matching a name or glob
+type ToolConfig struct {
+	MaxResultBytes *int  `json:"maxResultBytes,omitempty"` // Result size limit in bytes (overrides server setting)
+	Cache          *bool `json:"cache,omitempty"`          // Cache results (default: only tools annotated readOnlyHint)
+	CacheTTL       *int  `json:"cacheTTL,omitempty"`       // Cache time-to-live in seconds (overrides hub setting)
+
+	RateLimit  *RateLimitConfig `json:"rateLimit,omitempty"`  // Limits shared by all calls to tools matching this entry
+	Idempotent *bool            `json:"idempotent,omitempty"` // Safe to retry (default: tools annotated readOnlyHint or idempotentHint)
+
+	Rename      string `json:"rename,omitempty"`      // Name the tool is exposed as (exact entries only)
+	Description string `json:"description,omitempty"` // Replaces the tool's description
+	Notes       string `json:"notes,omitempty"`       // Appended to the tool's description
+}
+
+// merge fills unset fields of t from other
+func (t ToolConfig) merge(other ToolConfig) ToolConfig {
+	if t.MaxResultBytes == nil {
+		t.MaxResultBytes = other.MaxResultBytes
+	}
+	if t.Cache == nil {
+		t.Cache = other.Cache
+	}
+	if t.CacheTTL == nil {
+		t.CacheTTL = other.CacheTTL
+	}
+	if t.RateLimit == nil {
+		t.RateLimit = other.RateLimit
+	}
+	if t.Idempotent == nil {
+		t.Idempotent = other.Idempotent
+	}
+	if t.Description == "" {
+		t.Description = other.Description
+	}
+	if t.Notes == "" {
+		t.Notes = other.Notes
+	}
+	return t
 }
 
 // IsEnabled returns true if the server should be enabled (default true if not specified)
@@ -44,6 +683,168 @@ func (s *MCPServer) IsEnabled() bool {
 	return *s.Enable
 }
 
+// ShouldValidateArgs returns true if tool arguments should be validated against
+// the tool's inputSchema before calling the server (default true if not specified)
+func (s *MCPServer) ShouldValidateArgs() bool {
+	if s.ValidateArgs == nil {
+		return true
+	}
+	return *s.ValidateArgs
+}
+
+// ShouldCoerceArgs returns true if tool arguments should be coerced to match the
+// tool's inputSchema and filled with defaults (default true if not specified)
+func (s *MCPServer) ShouldCoerceArgs() bool {
+	if s.CoerceArgs == nil {
+		return true
+	}
+	return *s.CoerceArgs
+}
+
+// ToolSettings returns the settings for toolName. An exact entry in Tools wins,
+// then glob entries from the most to the least specific (longest pattern first);
+// each field takes the first value set.
+func (s *MCPServer) ToolSettings(toolName string) ToolConfig {
+	var settings ToolConfig
+	for _, key := range s.matchingToolKeys(toolName) {
+		settings = settings.merge(s.Tools[key])
+	}
+	return settings
+}
+
+// ToolRateLimit returns the rate limit that applies to toolName and the Tools
+// key it was configured under, or nil if no matching entry sets one. Calls to
+// every tool matching that key share the limit.
+func (s *MCPServer) ToolRateLimit(toolName string) (string, *RateLimitConfig) {
+	for _, key := range s.matchingToolKeys(toolName) {
+		if limit := s.Tools[key].RateLimit; limit != nil {
+			return key, limit
+		}
+	}
+	return "", nil
+}
+
+// RetryPolicy returns the retry settings for toolName, or nil if its calls are
+// not retried. idempotent reports whether the tool is annotated readOnlyHint or
+// idempotentHint; the tool's settings may override it.
+func (s *MCPServer) RetryPolicy(toolName string, idempotent bool) *RetryConfig {
+	if s.Retry == nil {
+		return nil
+	}
+	if settings := s.ToolSettings(toolName); settings.Idempotent != nil {
+		idempotent = *settings.Idempotent
+	}
+	if !idempotent {
+		return nil
+	}
+	return s.Retry
+}
+
+// ExposesTool reports whether the backend tool toolName is exposed by the hub:
+// it matches includeTools (if set) and doesn't match excludeTools
+func (s *MCPServer) ExposesTool(toolName string) bool {
+	if len(s.IncludeTools) > 0 && !matchesAny(s.IncludeTools, toolName) {
+		return false
+	}
+	return !matchesAny(s.ExcludeTools, toolName)
+}
+
+// ExposedToolName returns the name the backend tool toolName is exposed as
+func (s *MCPServer) ExposedToolName(toolName string) string {
+	if rename := s.Tools[toolName].Rename; rename != "" {
+		return rename
+	}
+	return toolName
+}
+
+// BackendToolName returns the backend name of the tool exposed as name. It
+// reports false if no exposed tool has that name: the tool is hidden, or name
+// is the original name of a renamed tool.
+func (s *MCPServer) BackendToolName(name string) (string, bool) {
+	backend := name
+	for key, tool := range s.Tools {
+		if tool.Rename == name {
+			backend = key
+			break
+		}
+	}
+	if backend == name && s.Tools[name].Rename != "" {
+		return "", false
+	}
+	if !s.ExposesTool(backend) {
+		return "", false
+	}
+	return backend, true
+}
+
+// matchesAny reports whether name matches any of the globs
+func matchesAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// matchingToolKeys returns the Tools keys matching toolName, most specific first
+func (s *MCPServer) matchingToolKeys(toolName string) []string {
+	var keys []string
+	if _, ok := s.Tools[toolName]; ok {
+		keys = append(keys, toolName)
+	}
+
+	patterns := make([]string, 0, len(s.Tools))
+	for pattern := range s.Tools {
+		if pattern == toolName {
+			continue
+		}
+		if matched, _ := path.Match(pattern, toolName); matched {
+			patterns = append(patterns, pattern)
+		}
+	}
+	sort.Slice(patterns, func(i, j int) bool {
+		if len(patterns[i]) != len(patterns[j]) {
+			return len(patterns[i]) > len(patterns[j])
+		}
+		return patterns[i] < patterns[j]
+	})
+
+	return append(keys, patterns...)
+}
+
+// CachePolicy reports whether results of toolName may be cached and for how long.
+// Tools annotated readOnlyHint are cacheable unless their settings say otherwise;
+// defaultTTL applies when the tool sets no TTL of its own.
+func (s *MCPServer) CachePolicy(toolName string, readOnly bool, defaultTTL time.Duration) (time.Duration, bool) {
+	settings := s.ToolSettings(toolName)
+
+	cacheable := readOnly
+	if settings.Cache != nil {
+		cacheable = *settings.Cache
+	}
+	if !cacheable {
+		return 0, false
+	}
+
+	if settings.CacheTTL != nil {
+		return time.Duration(*settings.CacheTTL) * time.Second, true
+	}
+	return defaultTTL, true
+}
+
+// ResultLimit returns the maximum bytes of text returned for a tool's result
+// before it is truncated, preferring the tool's own setting (0 = unlimited)
+func (s *MCPServer) ResultLimit(toolName string) int {
+	if limit := s.ToolSettings(toolName).MaxResultBytes; limit != nil {
+		return *limit
+	}
+	if s.MaxResultBytes != nil {
+		return *s.MaxResultBytes
+	}
+	return DefaultMaxResultBytes
+}
+
 // GetTransport returns the transport type, defaulting based on URL/Command presence
 func (s *MCPServer) GetTransport() string {
 	// If transport is explicitly set, use it
@@ -99,6 +900,16 @@ func LoadConfig(configPath string) (*Config, error) {
 		if server.Env == nil {
 			server.Env = make(map[string]string)
 		}
+		// Servers inherit the hub-wide coercion setting unless they set their own
+		if server.CoerceArgs == nil {
+			server.CoerceArgs = cfg.CoerceArgs
+		}
+		if server.MaxResultBytes == nil {
+			server.MaxResultBytes = cfg.MaxResultBytes
+		}
+		if server.Separator == "" {
+			server.Separator = cfg.Separator
+		}
 		cfg.MCPServers[name] = server
 	}
 
@@ -116,12 +927,82 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("mcpServers is required and must contain at least one server")
 	}
 
+	if c.MaxResultBytes != nil && *c.MaxResultBytes < 0 {
+		return fmt.Errorf("maxResultBytes must not be negative")
+	}
+
+	if c.Cache != nil {
+		if store := c.Cache.GetStore(); store != CacheStoreMemory && store != CacheStoreDisk {
+			return fmt.Errorf("cache: invalid store: %s (must be memory or disk)", c.Cache.Store)
+		}
+		if c.Cache.TTL < 0 || c.Cache.MaxEntries < 0 || c.Cache.MaxBytes < 0 {
+			return fmt.Errorf("cache: ttl, maxEntries and maxBytes must not be negative")
+		}
+	}
+
+	if c.Catalog != nil && c.Catalog.TTL < 0 {
+		return fmt.Errorf("catalog: ttl must not be negative")
+	}
+
+	if c.Startup != nil && (c.Startup.Parallelism < 0 || c.Startup.ConnectTimeout < 0) {
+		return fmt.Errorf("startup: parallelism and connectTimeout must not be negative")
+	}
+
+	if c.Search != nil {
+		if w := c.Search.GetSemanticWeight(); w < 0 || w > 1 {
+			return fmt.Errorf("search: semanticWeight must be between 0 and 1")
+		}
+	}
+
+	if c.State != nil && (c.State.MaxKeys < 0 || c.State.MaxBytes < 0 || c.State.SessionTTL < 0) {
+		return fmt.Errorf("state: maxKeys, maxBytes and sessionTTL must not be negative")
+	}
+
+	if err := validateExec(c.Exec, c.MCPServers); err != nil {
+		return fmt.Errorf("exec: %w", err)
+	}
+
+	if !validSeparatorRegex.MatchString(c.Separator) {
+		return fmt.Errorf("separator may only contain letters, digits, '_', '$' and '-'")
+	}
+
 	for name, server := range c.MCPServers {
 		if err := validateServer(name, server); err != nil {
 			return err
 		}
 	}
 
+	return validateNamespaces(c.MCPServers)
+}
+
+// validateNamespaces checks that no two enabled servers list their tools under
+// the same prefix, or under prefixes where one extends the other (gh__ and
+// gh__x__), since their tool names could then collide. Servers without a
+// prefix share the unprefixed namespace; clashes between their tool names are
+// reported in the status once the tools are known.
+func validateNamespaces(servers map[string]MCPServer) error {
+	names := slices.Sorted(maps.Keys(servers))
+	owners := make(map[string]string)
+	for _, name := range names {
+		server := servers[name]
+		if !server.IsEnabled() || server.GetPrefix(name) == "" {
+			continue
+		}
+		namespace := server.GetPrefix(name) + server.GetSeparator()
+		if other, ok := owners[namespace]; ok {
+			return fmt.Errorf("servers %q and %q both list their tools as %s<tool>", other, name, namespace)
+		}
+		owners[namespace] = name
+	}
+
+	namespaces := slices.Sorted(maps.Keys(owners))
+	for _, namespace := range namespaces {
+		for _, other := range namespaces {
+			if other != namespace && strings.HasPrefix(other, namespace) {
+				return fmt.Errorf("servers %q and %q list their tools as %s<tool> and %s<tool>, which can collide", owners[namespace], owners[other], namespace, other)
+			}
+		}
+	}
 	return nil
 }
 
@@ -139,6 +1020,35 @@ func validateServer(name string, server MCPServer) error {
 		return fmt.Errorf("server %q: name must start with a letter and contain only alphanumeric characters and underscores", name)
 	}
 
+	if server.IdleTimeout < 0 {
+		return fmt.Errorf("server %q: idleTimeout must not be negative", name)
+	}
+	if server.ConnectTimeout < 0 {
+		return fmt.Errorf("server %q: connectTimeout must not be negative", name)
+	}
+	if server.Prefix != nil && strings.ContainsFunc(*server.Prefix, unicode.IsSpace) {
+		return fmt.Errorf("server %q: prefix must not contain whitespace", name)
+	}
+	if !validSeparatorRegex.MatchString(server.Separator) {
+		return fmt.Errorf("server %q: separator may only contain letters, digits, '_', '$' and '-'", name)
+	}
+
+	// Validate each replica as a server of its own
+	if len(server.Replicas) > 0 {
+		if server.Lazy || server.IdleTimeout > 0 {
+			return fmt.Errorf("server %q: lazy and idleTimeout are not supported with replicas", name)
+		}
+		if !slices.Contains(LoadBalanceStrategies, server.GetLoadBalance()) {
+			return fmt.Errorf("server %q: invalid loadBalance: %s (must be one of %s)", name, server.LoadBalance, strings.Join(LoadBalanceStrategies, ", "))
+		}
+		for i, endpoint := range server.Endpoints() {
+			if err := validateServer(name, endpoint); err != nil {
+				return fmt.Errorf("replica %d: %w", i, err)
+			}
+		}
+		return nil
+	}
+
 	// Get the transport type (with auto-detection)
 	transport := strings.ToLower(server.GetTransport())
 
@@ -209,6 +1119,152 @@ func validateServer(name string, server MCPServer) error {
 		return err
 	}
 
+	// Validate rate limits
+	if err := validateRateLimit(server.RateLimit); err != nil {
+		return fmt.Errorf("server %q: %w", name, err)
+	}
+
+	// Validate retry and circuit breaker settings
+	if err := validateRetry(server.Retry); err != nil {
+		return fmt.Errorf("server %q: %w", name, err)
+	}
+	if cb := server.CircuitBreaker; cb != nil && (cb.FailureThreshold < 0 || cb.OpenTimeout < 0) {
+		return fmt.Errorf("server %q: circuitBreaker values must not be negative", name)
+	}
+
+	// Validate result size limits
+	if server.MaxResultBytes != nil && *server.MaxResultBytes < 0 {
+		return fmt.Errorf("server %q: maxResultBytes must not be negative", name)
+	}
+	for toolName, tool := range server.Tools {
+		if _, err := path.Match(toolName, ""); err != nil {
+			return fmt.Errorf("server %q: tool %q: invalid glob pattern", name, toolName)
+		}
+		if tool.MaxResultBytes != nil && *tool.MaxResultBytes < 0 {
+			return fmt.Errorf("server %q: tool %q: maxResultBytes must not be negative", name, toolName)
+		}
+		if tool.CacheTTL != nil && *tool.CacheTTL < 0 {
+			return fmt.Errorf("server %q: tool %q: cacheTTL must not be negative", name, toolName)
+		}
+		if err := validateRateLimit(tool.RateLimit); err != nil {
+			return fmt.Errorf("server %q: tool %q: %w", name, toolName, err)
+		}
+		if err := validateRename(server, toolName, tool.Rename); err != nil {
+			return fmt.Errorf("server %q: tool %q: %w", name, toolName, err)
+		}
+	}
+	for _, pattern := range slices.Concat(server.IncludeTools, server.ExcludeTools) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("server %q: invalid tool glob %q", name, pattern)
+		}
+	}
+
+	return nil
+}
+
+// validateRename checks that a tool is renamed to a name no other tool of the
+// server is exposed as
+func validateRename(server MCPServer, toolName, rename string) error {
+	if rename == "" {
+		return nil
+	}
+	if strings.ContainsAny(toolName, "*?[") {
+		return fmt.Errorf("rename requires an exact tool name, not a glob")
+	}
+	if strings.TrimSpace(rename) != rename {
+		return fmt.Errorf("invalid rename %q", rename)
+	}
+	for other, tool := range server.Tools {
+		if other == toolName {
+			continue
+		}
+		if tool.Rename == rename || (other == rename && tool.Rename == "") {
+			return fmt.Errorf("rename %q collides with tool %q", rename, other)
+		}
+	}
+	return nil
+}
+
+// validateRateLimit checks that rate limit settings are not negative
+func validateRateLimit(limit *RateLimitConfig) error {
+	if limit == nil {
+		return nil
+	}
+	if limit.RequestsPerSecond < 0 || limit.Burst < 0 || limit.MaxConcurrent < 0 || limit.MaxWait < 0 {
+		return fmt.Errorf("rateLimit values must not be negative")
+	}
+	return nil
+}
+
+// validateExec checks exec settings; allowed tools must name configured servers
+func validateExec(exec *ExecConfig, servers map[string]MCPServer) error {
+	if exec == nil {
+		return nil
+	}
+	if exec.Timeout < 0 || exec.MaxScriptSize < 0 || exec.MaxLogEntries < 0 || exec.MaxMemory < 0 ||
+		exec.MaxCallDepth < 0 || exec.MaxToolCalls < 0 || exec.MaxToolResultBytes < 0 {
+		return fmt.Errorf("limits must not be negative")
+	}
+	for _, serverID := range slices.Sorted(maps.Keys(exec.AllowedTools)) {
+		if _, ok := servers[serverID]; !ok {
+			return fmt.Errorf("allowedTools: unknown server %q", serverID)
+		}
+		for _, pattern := range exec.AllowedTools[serverID] {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("allowedTools: server %q: invalid tool glob %q", serverID, pattern)
+			}
+		}
+	}
+	for _, module := range exec.AllowedModules {
+		if !slices.Contains(ExecModules, module) {
+			return fmt.Errorf("allowedModules: unknown module %q (must be one of %s)", module, strings.Join(ExecModules, ", "))
+		}
+	}
+	if err := validateExecFetch(exec.Fetch); err != nil {
+		return fmt.Errorf("fetch: %w", err)
+	}
+	return nil
+}
+
+// validateExecFetch checks the mcp.fetch settings of exec
+func validateExecFetch(fetch *ExecFetchConfig) error {
+	if fetch == nil {
+		return nil
+	}
+	if fetch.Timeout < 0 || fetch.MaxResponseBytes < 0 {
+		return fmt.Errorf("limits must not be negative")
+	}
+	for _, pattern := range fetch.AllowedHosts {
+		if _, err := path.Match(pattern, ""); err != nil || pattern == "" || strings.Contains(pattern, "/") {
+			return fmt.Errorf("allowedHosts: invalid host glob %q", pattern)
+		}
+	}
+	for _, method := range fetch.AllowedMethods {
+		if !slices.Contains(FetchMethods, strings.ToUpper(method)) {
+			return fmt.Errorf("allowedMethods: unknown method %q (must be one of %s)", method, strings.Join(FetchMethods, ", "))
+		}
+	}
+	for _, pattern := range slices.Sorted(maps.Keys(fetch.Headers)) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("headers: invalid host glob %q", pattern)
+		}
+	}
+	return nil
+}
+
+// validateRetry checks retry settings
+func validateRetry(retry *RetryConfig) error {
+	if retry == nil {
+		return nil
+	}
+	if retry.MaxAttempts < 0 || retry.InitialBackoff < 0 || retry.MaxBackoff < 0 {
+		return fmt.Errorf("retry values must not be negative")
+	}
+	for _, class := range retry.RetryOn {
+		if !slices.Contains(ErrorClasses, class) {
+			return fmt.Errorf("invalid retryOn class: %s (must be one of %s)", class, strings.Join(ErrorClasses, ", "))
+		}
+	}
 	return nil
 }
 
@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -718,6 +719,519 @@ func TestMCPServer_IsEnabled(t *testing.T) {
 	}
 }
 
+func TestMCPServer_ShouldValidateArgs(t *testing.T) {
+	tests := []struct {
+		name         string
+		validateArgs *bool
+		want         bool
+	}{
+		{
+			name:         "nil validateArgs - default true",
+			validateArgs: nil,
+			want:         true,
+		},
+		{
+			name:         "explicitly false",
+			validateArgs: boolPtr(false),
+			want:         false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := &MCPServer{
+				ValidateArgs: tt.validateArgs,
+			}
+			if got := server.ShouldValidateArgs(); got != tt.want {
+				t.Errorf("ShouldValidateArgs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadConfig_CoerceArgsInherited(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	configJSON := `{
+		"coerceArgs": false,
+		"mcpServers": {
+			"inherits": {"command": "test"},
+			"overrides": {"command": "test", "coerceArgs": true}
+		}
+	}`
+	if err := os.WriteFile(configPath, []byte(configJSON), 0644); err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+
+	cfg, err := LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v, want nil", err)
+	}
+
+	inherits := cfg.MCPServers["inherits"]
+	if inherits.ShouldCoerceArgs() {
+		t.Error("inherits: ShouldCoerceArgs() = true, want hub default false")
+	}
+	overrides := cfg.MCPServers["overrides"]
+	if !overrides.ShouldCoerceArgs() {
+		t.Error("overrides: ShouldCoerceArgs() = false, want server setting true")
+	}
+}
+
+func TestMCPServer_ResultLimit(t *testing.T) {
+	server := MCPServer{
+		MaxResultBytes: intPtr(5000),
+		Tools: map[string]ToolConfig{
+			"search_code": {MaxResultBytes: intPtr(100)},
+			"get_file":    {},
+		},
+	}
+
+	if got := server.ResultLimit("search_code"); got != 100 {
+		t.Errorf("ResultLimit(search_code) = %d, want 100", got)
+	}
+	if got := server.ResultLimit("get_file"); got != 5000 {
+		t.Errorf("ResultLimit(get_file) = %d, want 5000", got)
+	}
+
+	empty := MCPServer{}
+	if got := empty.ResultLimit("any"); got != DefaultMaxResultBytes {
+		t.Errorf("ResultLimit() = %d, want default %d", got, DefaultMaxResultBytes)
+	}
+}
+
+func TestMCPServer_CachePolicy(t *testing.T) {
+	server := MCPServer{
+		Tools: map[string]ToolConfig{
+			"search_*":      {Cache: boolPtr(true), CacheTTL: intPtr(60)},
+			"search_issues": {CacheTTL: intPtr(10)},
+			"get_*":         {Cache: boolPtr(false)},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		tool      string
+		readOnly  bool
+		wantTTL   time.Duration
+		wantCache bool
+	}{
+		{"glob opts in", "search_code", false, time.Minute, true},
+		{"exact entry overrides glob TTL", "search_issues", false, 10 * time.Second, true},
+		{"glob opts out of readOnly tool", "get_file", true, 0, false},
+		{"readOnly default", "list_repos", true, DefaultCacheTTL, true},
+		{"not cacheable by default", "create_issue", false, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ttl, cacheable := server.CachePolicy(tt.tool, tt.readOnly, DefaultCacheTTL)
+			if cacheable != tt.wantCache || ttl != tt.wantTTL {
+				t.Errorf("CachePolicy(%s) = (%v, %v), want (%v, %v)", tt.tool, ttl, cacheable, tt.wantTTL, tt.wantCache)
+			}
+		})
+	}
+}
+
+func TestValidateServer_NegativeResultLimitRejected(t *testing.T) {
+	server := MCPServer{
+		Command: "test",
+		Tools:   map[string]ToolConfig{"search": {MaxResultBytes: intPtr(-1)}},
+	}
+	if err := validateServer("test", server); err == nil {
+		t.Error("validateServer() error = nil, want error for negative maxResultBytes")
+	}
+}
+
+func TestMCPServer_ToolRateLimit(t *testing.T) {
+	server := MCPServer{
+		Tools: map[string]ToolConfig{
+			"search_*":      {RateLimit: &RateLimitConfig{RequestsPerSecond: 2}},
+			"search_issues": {MaxResultBytes: intPtr(1000)},
+		},
+	}
+
+	key, limit := server.ToolRateLimit("search_issues")
+	if key != "search_*" || limit == nil || limit.RequestsPerSecond != 2 {
+		t.Errorf("ToolRateLimit(search_issues) = (%q, %v), want glob limit", key, limit)
+	}
+	if key, limit := server.ToolRateLimit("create_issue"); key != "" || limit != nil {
+		t.Errorf("ToolRateLimit(create_issue) = (%q, %v), want none", key, limit)
+	}
+	if burst := limit.GetBurst(); burst != 2 {
+		t.Errorf("GetBurst() = %d, want 2", burst)
+	}
+}
+
+func TestValidateServer_NegativeRateLimitRejected(t *testing.T) {
+	server := MCPServer{
+		Command:   "test",
+		RateLimit: &RateLimitConfig{RequestsPerSecond: -1},
+	}
+	if err := validateServer("test", server); err == nil {
+		t.Error("validateServer() error = nil, want error for negative rateLimit")
+	}
+}
+
+func TestMCPServer_RetryPolicy(t *testing.T) {
+	server := MCPServer{
+		Retry: &RetryConfig{MaxAttempts: 4},
+		Tools: map[string]ToolConfig{
+			"create_*":    {Idempotent: boolPtr(true)},
+			"get_session": {Idempotent: boolPtr(false)},
+		},
+	}
+
+	if policy := server.RetryPolicy("search", true); policy == nil || policy.GetMaxAttempts() != 4 {
+		t.Errorf("RetryPolicy(search) = %v, want server policy", policy)
+	}
+	if policy := server.RetryPolicy("delete_repo", false); policy != nil {
+		t.Errorf("RetryPolicy(delete_repo) = %v, want nil for non-idempotent tool", policy)
+	}
+	if policy := server.RetryPolicy("create_label", false); policy == nil {
+		t.Error("RetryPolicy(create_label) = nil, want policy for tool marked idempotent")
+	}
+	if policy := server.RetryPolicy("get_session", true); policy != nil {
+		t.Errorf("RetryPolicy(get_session) = %v, want nil for tool opted out", policy)
+	}
+
+	noRetry := MCPServer{}
+	if policy := noRetry.RetryPolicy("search", true); policy != nil {
+		t.Errorf("RetryPolicy() = %v, want nil without retry config", policy)
+	}
+
+	if !server.Retry.RetriesOn(ErrorClassServer) {
+		t.Error("RetriesOn(server) = false, want true by default")
+	}
+}
+
+func TestValidateServer_InvalidRetryClassRejected(t *testing.T) {
+	server := MCPServer{
+		Command: "test",
+		Retry:   &RetryConfig{RetryOn: []string{"everything"}},
+	}
+	if err := validateServer("test", server); err == nil {
+		t.Error("validateServer() error = nil, want error for unknown retryOn class")
+	}
+}
+
+func TestMCPServer_Endpoints(t *testing.T) {
+	server := MCPServer{
+		Transport: "http",
+		Headers:   map[string]string{"Authorization": "Bearer token", "X-Team": "core"},
+		Replicas: []Replica{
+			{URL: "https://a.example.com/mcp"},
+			{URL: "https://b.example.com/mcp", Headers: map[string]string{"X-Team": "edge"}},
+		},
+	}
+
+	endpoints := server.Endpoints()
+	if len(endpoints) != 2 {
+		t.Fatalf("Endpoints() returned %d endpoints, want 2", len(endpoints))
+	}
+	if endpoints[0].URL != "https://a.example.com/mcp" || endpoints[0].Headers["X-Team"] != "core" {
+		t.Errorf("endpoint 0 = %+v, want server headers", endpoints[0])
+	}
+	if endpoints[1].Headers["X-Team"] != "edge" || endpoints[1].Headers["Authorization"] != "Bearer token" {
+		t.Errorf("endpoint 1 headers = %v, want merged headers", endpoints[1].Headers)
+	}
+	if endpoints[1].Replicas != nil || endpoints[1].Transport != "http" {
+		t.Errorf("endpoint 1 = %+v, want server settings without replicas", endpoints[1])
+	}
+	if err := validateServer("internal", server); err != nil {
+		t.Errorf("validateServer() error = %v", err)
+	}
+
+	server.Replicas[1].URL = "ftp://b.example.com"
+	if err := validateServer("internal", server); err == nil {
+		t.Error("validateServer() error = nil, want error for invalid replica url")
+	}
+
+	server.Replicas[1].URL = "https://b.example.com/mcp"
+	server.LoadBalance = "random"
+	if err := validateServer("internal", server); err == nil {
+		t.Error("validateServer() error = nil, want error for unknown loadBalance")
+	}
+
+	plain := MCPServer{Command: "node"}
+	if endpoints := plain.Endpoints(); len(endpoints) != 1 || endpoints[0].Command != "node" {
+		t.Errorf("Endpoints() = %+v, want the server itself", endpoints)
+	}
+}
+
+func TestValidateServer_LazyOptions(t *testing.T) {
+	lazy := MCPServer{Command: "test", Lazy: true, IdleTimeout: 300}
+	if err := validateServer("test", lazy); err != nil {
+		t.Errorf("validateServer() error = %v", err)
+	}
+	if got := lazy.GetIdleTimeout(); got != 5*time.Minute {
+		t.Errorf("GetIdleTimeout() = %v, want 5m", got)
+	}
+
+	negative := MCPServer{Command: "test", IdleTimeout: -1}
+	if err := validateServer("test", negative); err == nil {
+		t.Error("validateServer() error = nil, want error for negative idleTimeout")
+	}
+
+	replicated := MCPServer{Lazy: true, Replicas: []Replica{{Command: "a"}, {Command: "b"}}}
+	if err := validateServer("test", replicated); err == nil {
+		t.Error("validateServer() error = nil, want error for lazy replicated server")
+	}
+}
+
+func TestMCPServer_ToolExposure(t *testing.T) {
+	server := MCPServer{
+		Command:      "test",
+		IncludeTools: []string{"search_*", "get_*"},
+		ExcludeTools: []string{"search_code"},
+		Tools: map[string]ToolConfig{
+			"search_repositories": {Rename: "findRepos"},
+		},
+	}
+	if err := validateServer("test", server); err != nil {
+		t.Fatalf("validateServer() error = %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		wantBackend string
+		wantOK      bool
+	}{
+		{"findRepos", "search_repositories", true},
+		{"search_repositories", "", false}, // renamed away
+		{"search_issues", "search_issues", true},
+		{"search_code", "", false}, // excluded
+		{"delete_repo", "", false}, // not included
+	}
+	for _, tt := range tests {
+		backend, ok := server.BackendToolName(tt.name)
+		if backend != tt.wantBackend || ok != tt.wantOK {
+			t.Errorf("BackendToolName(%q) = %q, %v, want %q, %v", tt.name, backend, ok, tt.wantBackend, tt.wantOK)
+		}
+	}
+	if got := server.ExposedToolName("search_repositories"); got != "findRepos" {
+		t.Errorf("ExposedToolName() = %q, want findRepos", got)
+	}
+
+	invalid := []map[string]ToolConfig{
+		{"search_*": {Rename: "find"}},
+		{"a": {Rename: "x"}, "b": {Rename: "x"}},
+		{"a": {Rename: "b"}, "b": {Cache: new(bool)}},
+		{"a": {Rename: " a"}},
+	}
+	for _, tools := range invalid {
+		if err := validateServer("test", MCPServer{Command: "test", Tools: tools}); err == nil {
+			t.Errorf("validateServer(%v) error = nil, want error", tools)
+		}
+	}
+}
+
+func TestMCPServer_QualifiedToolName(t *testing.T) {
+	empty := ""
+	gh := "gh"
+	tests := []struct {
+		name   string
+		server MCPServer
+		want   string
+	}{
+		{"default", MCPServer{}, "github__search"},
+		{"prefix", MCPServer{Prefix: &gh}, "gh__search"},
+		{"separator", MCPServer{Prefix: &gh, Separator: "-"}, "gh-search"},
+		{"unprefixed", MCPServer{Prefix: &empty, Separator: "-"}, "search"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.server.QualifiedToolName("github", "search"); got != tt.want {
+				t.Errorf("QualifiedToolName() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateNamespaces(t *testing.T) {
+	empty := ""
+	gh := "gh"
+	valid := map[string]MCPServer{
+		"github": {Command: "a", Prefix: &gh},
+		"gh":     {Command: "b", Separator: "-"},
+		"main":   {Command: "c", Prefix: &empty},
+		"local":  {Command: "d", Prefix: &empty},
+	}
+	if err := validateNamespaces(valid); err != nil {
+		t.Errorf("validateNamespaces() error = %v", err)
+	}
+
+	clashing := map[string]MCPServer{
+		"github": {Command: "a", Prefix: &gh},
+		"gh":     {Command: "b"},
+	}
+	if err := validateNamespaces(clashing); err == nil {
+		t.Error("validateNamespaces() error = nil, want error for servers sharing a prefix")
+	}
+
+	// gh__x__search could be tool x__search of gh or tool search of gh__x
+	ghx := "gh__x"
+	overlapping := map[string]MCPServer{
+		"github": {Command: "a", Prefix: &gh},
+		"ghx":    {Command: "b", Prefix: &ghx},
+	}
+	if err := validateNamespaces(overlapping); err == nil {
+		t.Error("validateNamespaces() error = nil, want error for overlapping prefixes")
+	}
+}
+
+func TestValidate_Separator(t *testing.T) {
+	for _, separator := range []string{".", " ", "::", "/"} {
+		cfg := &Config{MCPServers: map[string]MCPServer{"github": {Command: "a", Separator: separator}}}
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("Validate() error = nil, want error for separator %q", separator)
+		}
+		cfg = &Config{Separator: separator, MCPServers: map[string]MCPServer{"github": {Command: "a"}}}
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("Validate() error = nil, want error for hub separator %q", separator)
+		}
+	}
+
+	cfg := &Config{Separator: "_", MCPServers: map[string]MCPServer{"github": {Command: "a", Separator: "-"}}}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+}
+
+func TestStartupSettings(t *testing.T) {
+	cfg := &Config{}
+	if got := cfg.GetStartup().GetParallelism(); got != DefaultStartupParallelism {
+		t.Errorf("GetParallelism() = %d, want %d", got, DefaultStartupParallelism)
+	}
+	if got := cfg.GetStartup().GetConnectTimeout(); got != DefaultConnectTimeout {
+		t.Errorf("GetConnectTimeout() = %v, want %v", got, DefaultConnectTimeout)
+	}
+
+	server := MCPServer{Command: "test", ConnectTimeout: 5}
+	if got := server.GetConnectTimeout(time.Minute); got != 5*time.Second {
+		t.Errorf("MCPServer.GetConnectTimeout() = %v, want 5s", got)
+	}
+	if got := (&MCPServer{}).GetConnectTimeout(time.Minute); got != time.Minute {
+		t.Errorf("MCPServer.GetConnectTimeout() = %v, want fallback", got)
+	}
+
+	if err := validateServer("test", MCPServer{Command: "test", ConnectTimeout: -1}); err == nil {
+		t.Error("validateServer() error = nil, want error for negative connectTimeout")
+	}
+	cfg.Startup = &StartupConfig{Parallelism: -1}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Validate() error = nil, want error for negative parallelism")
+	}
+}
+
+func TestSearchSettings(t *testing.T) {
+	cfg := &SearchConfig{}
+	if got := cfg.GetSemanticWeight(); got != DefaultSemanticWeight {
+		t.Errorf("GetSemanticWeight() = %v, want %v", got, DefaultSemanticWeight)
+	}
+
+	for _, weight := range []float64{-0.1, 1.5} {
+		c := &Config{Search: &SearchConfig{Embedder: "hash", SemanticWeight: &weight}}
+		if err := c.Validate(); err == nil {
+			t.Errorf("Validate() error = nil, want error for semanticWeight %v", weight)
+		}
+	}
+}
+
+func TestScriptsSettings(t *testing.T) {
+	cfg := &Config{}
+	if got := cfg.GetScripts().GetDir(); got != DefaultScriptsDir() {
+		t.Errorf("GetDir() = %q, want %q", got, DefaultScriptsDir())
+	}
+
+	cfg.Scripts = &ScriptsConfig{Dir: "/srv/scripts"}
+	if got := cfg.GetScripts().GetDir(); got != "/srv/scripts" {
+		t.Errorf("GetDir() = %q, want /srv/scripts", got)
+	}
+}
+
+func TestExecSettings(t *testing.T) {
+	cfg := &Config{MCPServers: map[string]MCPServer{"github": {Command: "test"}}}
+	if !cfg.GetExec().IsEnabled() {
+		t.Error("IsEnabled() = false, want true by default")
+	}
+	if got := cfg.GetExec().GetTimeout(); got != 0 {
+		t.Errorf("GetTimeout() = %v, want 0 (runtime default)", got)
+	}
+
+	disabled := false
+	cfg.Exec = &ExecConfig{
+		Enable:         &disabled,
+		Timeout:        30,
+		AllowedTools:   map[string][]string{"github": {"search_*"}},
+		AllowedModules: []string{"url", "buffer"},
+		Fetch: &ExecFetchConfig{
+			AllowedHosts:   []string{"api.internal.example.com", "*.corp.example.com:8443"},
+			AllowedMethods: []string{"get", "POST"},
+			Headers:        map[string]map[string]string{"api.internal.example.com": {"Authorization": "Bearer ${TOKEN}"}},
+		},
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v, want nil", err)
+	}
+	if cfg.GetExec().IsEnabled() {
+		t.Error("IsEnabled() = true, want false")
+	}
+	if got := cfg.GetExec().GetTimeout(); got != 30*time.Second {
+		t.Errorf("GetTimeout() = %v, want 30s", got)
+	}
+	if !cfg.GetExec().FetchEnabled() {
+		t.Error("FetchEnabled() = false, want true with allowed hosts")
+	}
+	if (&ExecConfig{Fetch: &ExecFetchConfig{}}).FetchEnabled() {
+		t.Error("FetchEnabled() = true, want false without allowed hosts")
+	}
+
+	invalid := map[string]*ExecConfig{
+		"negative timeout": {Timeout: -1},
+		"negative memory":  {MaxMemory: -1},
+		"unknown server":   {AllowedTools: map[string][]string{"gitlab": {"*"}}},
+		"invalid glob":     {AllowedTools: map[string][]string{"github": {"["}}},
+		"unknown module":   {AllowedModules: []string{"fs"}},
+		"fetch host glob":  {Fetch: &ExecFetchConfig{AllowedHosts: []string{"["}}},
+		"fetch host path":  {Fetch: &ExecFetchConfig{AllowedHosts: []string{"example.com/api"}}},
+		"fetch method":     {Fetch: &ExecFetchConfig{AllowedHosts: []string{"example.com"}, AllowedMethods: []string{"TRACE"}}},
+		"fetch timeout":    {Fetch: &ExecFetchConfig{Timeout: -1}},
+	}
+	for name, exec := range invalid {
+		cfg.Exec = exec
+		if err := cfg.Validate(); err == nil || !strings.HasPrefix(err.Error(), "exec: ") {
+			t.Errorf("%s: Validate() error = %v, want exec error", name, err)
+		}
+	}
+}
+
+func TestStateSettings(t *testing.T) {
+	cfg := &Config{MCPServers: map[string]MCPServer{"test": {Command: "test"}}}
+	state := cfg.GetState()
+	if state.GetMaxKeys() != DefaultStateMaxKeys || state.GetMaxBytes() != DefaultStateMaxBytes {
+		t.Errorf("quotas = %d keys, %d bytes, want defaults", state.GetMaxKeys(), state.GetMaxBytes())
+	}
+	if got := state.GetSessionTTL(); got != DefaultStateSessionTTL {
+		t.Errorf("GetSessionTTL() = %v, want %v", got, DefaultStateSessionTTL)
+	}
+	if got := state.GetDir(); got != DefaultStateDir() {
+		t.Errorf("GetDir() = %q, want %q", got, DefaultStateDir())
+	}
+
+	cfg.State = &StateConfig{MaxKeys: 10, SessionTTL: 60, Dir: "/srv/state"}
+	if got := cfg.GetState().GetSessionTTL(); got != time.Minute {
+		t.Errorf("GetSessionTTL() = %v, want 1m", got)
+	}
+	if got := cfg.GetState().GetDir(); got != "/srv/state" {
+		t.Errorf("GetDir() = %q, want /srv/state", got)
+	}
+
+	cfg.State = &StateConfig{MaxBytes: -1}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Validate() error = nil, want error for negative maxBytes")
+	}
+}
+
 func TestMCPServer_GetTransport(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -821,3 +1335,8 @@ func TestConfig_MapsInitialized(t *testing.T) {
 func boolPtr(b bool) *bool {
 	return &b
 }
+
+// Helper function to create int pointer
+func intPtr(i int) *int {
+	return &i
+}
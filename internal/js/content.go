@@ -0,0 +1,337 @@
+package js
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/dop251/goja"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Content block types as they appear in the MCP wire format
+const (
+	contentTypeText         = "text"
+	contentTypeImage        = "image"
+	contentTypeAudio        = "audio"
+	contentTypeResource     = "resource"
+	contentTypeResourceLink = "resource_link"
+)
+
+// contentBlock mirrors the MCP wire format of a single content block.
+// Scripts see content blocks as plain objects with these fields.
+type contentBlock struct {
+	Type        string                `json:"type"`
+	Text        string                `json:"text,omitempty"`
+	MIMEType    string                `json:"mimeType,omitempty"`
+	Data        []byte                `json:"data,omitempty"`
+	Resource    *mcp.ResourceContents `json:"resource,omitempty"`
+	URI         string                `json:"uri,omitempty"`
+	Name        string                `json:"name,omitempty"`
+	Title       string                `json:"title,omitempty"`
+	Description string                `json:"description,omitempty"`
+}
+
+// contentToJS converts MCP content to plain values in wire format
+// (binary data is base64-encoded)
+func contentToJS(content []mcp.Content) ([]any, error) {
+	blocks := make([]any, 0, len(content))
+	for _, c := range content {
+		data, err := json.Marshal(c)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode content: %w", err)
+		}
+		var block map[string]any
+		if err := json.Unmarshal(data, &block); err != nil {
+			return nil, fmt.Errorf("failed to decode content: %w", err)
+		}
+		blocks = append(blocks, block)
+	}
+	return blocks, nil
+}
+
+// contentFromJS converts a single exported JS value into MCP content.
+// Returns false if the value is not a well-formed content block.
+func contentFromJS(v any) (mcp.Content, bool) {
+	m, ok := v.(map[string]any)
+	if !ok {
+		return nil, false
+	}
+	if _, ok := m["type"].(string); !ok {
+		return nil, false
+	}
+
+	data, err := json.Marshal(m)
+	if err != nil {
+		return nil, false
+	}
+	var block contentBlock
+	if err := json.Unmarshal(data, &block); err != nil {
+		return nil, false
+	}
+
+	switch block.Type {
+	case contentTypeText:
+		if _, ok := m["text"].(string); !ok {
+			return nil, false
+		}
+		return &mcp.TextContent{Text: block.Text}, true
+	case contentTypeImage:
+		if len(block.Data) == 0 || block.MIMEType == "" {
+			return nil, false
+		}
+		return &mcp.ImageContent{Data: block.Data, MIMEType: block.MIMEType}, true
+	case contentTypeAudio:
+		if len(block.Data) == 0 || block.MIMEType == "" {
+			return nil, false
+		}
+		return &mcp.AudioContent{Data: block.Data, MIMEType: block.MIMEType}, true
+	case contentTypeResource:
+		if block.Resource == nil || block.Resource.URI == "" {
+			return nil, false
+		}
+		return &mcp.EmbeddedResource{Resource: block.Resource}, true
+	case contentTypeResourceLink:
+		if block.URI == "" {
+			return nil, false
+		}
+		return &mcp.ResourceLink{
+			URI:         block.URI,
+			Name:        block.Name,
+			Title:       block.Title,
+			Description: block.Description,
+			MIMEType:    block.MIMEType,
+		}, true
+	default:
+		return nil, false
+	}
+}
+
+// contentListFromJS converts a list of exported JS values into MCP content.
+// Every element must be a well-formed content block.
+func contentListFromJS(items []any) ([]mcp.Content, bool) {
+	if len(items) == 0 {
+		return nil, false
+	}
+	content := make([]mcp.Content, 0, len(items))
+	for _, item := range items {
+		c, ok := contentFromJS(item)
+		if !ok {
+			return nil, false
+		}
+		content = append(content, c)
+	}
+	return content, true
+}
+
+// hasBinaryContent reports whether any block is something other than text
+func hasBinaryContent(content []mcp.Content) bool {
+	for _, c := range content {
+		if _, ok := c.(*mcp.TextContent); !ok {
+			return true
+		}
+	}
+	return false
+}
+
+// resultMarker tags the result objects built by newResultObject, so only
+// those, and not plain data with a content key, are returned as content
+var resultMarker = goja.NewSymbol("mcp.result")
+
+// toolResult is a script result exported from a result object built by
+// newResultObject; it serializes like any other object
+type toolResult map[string]any
+
+// exportResult exports the value a script returned, keeping track of result
+// objects built by newResultObject
+func exportResult(v goja.Value) any {
+	if obj, ok := v.(*goja.Object); ok {
+		if marker := obj.GetSymbol(resultMarker); marker != nil && marker.ToBoolean() {
+			if m, ok := obj.Export().(map[string]any); ok {
+				return toolResult(m)
+			}
+		}
+	}
+	if v == nil {
+		return nil
+	}
+	return v.Export()
+}
+
+// ContentFromResult extracts MCP content from a script result.
+// A result is treated as content when it is:
+//   - a tool result object returned by mcp.callToolRaw, or by mcp.callTool for
+//     multi-block results (plain objects with a content key are not)
+//   - a single image, audio or resource block (e.g. from mcp.image())
+//   - an array of content blocks containing at least one non-text block
+//
+// Anything else is left for JSON serialization and false is returned.
+func ContentFromResult(result any) ([]mcp.Content, bool) {
+	switch v := result.(type) {
+	case toolResult:
+		items, _ := v["content"].([]any)
+		return contentListFromJS(items)
+	case map[string]any:
+		c, ok := contentFromJS(v)
+		if !ok {
+			return nil, false
+		}
+		if _, isText := c.(*mcp.TextContent); isText {
+			return nil, false
+		}
+		return []mcp.Content{c}, true
+	case []any:
+		content, ok := contentListFromJS(v)
+		if !ok || !hasBinaryContent(content) {
+			return nil, false
+		}
+		return content, true
+	default:
+		return nil, false
+	}
+}
+
+// newResultObject wraps a CallToolResult in a JS object exposing the full
+// content array along with text(), images(), audio() and resources() helpers
+func newResultObject(vm *goja.Runtime, result *mcp.CallToolResult) (goja.Value, error) {
+	blocks, err := contentToJS(result.Content)
+	if err != nil {
+		return nil, err
+	}
+
+	obj := vm.NewObject()
+	if err := obj.DefineDataPropertySymbol(resultMarker, vm.ToValue(true), goja.FLAG_FALSE, goja.FLAG_FALSE, goja.FLAG_FALSE); err != nil {
+		return nil, err
+	}
+	if err := obj.Set("content", blocks); err != nil {
+		return nil, err
+	}
+	if err := obj.Set("isError", result.IsError); err != nil {
+		return nil, err
+	}
+	if result.StructuredContent != nil {
+		if err := obj.Set("structuredContent", result.StructuredContent); err != nil {
+			return nil, err
+		}
+	}
+
+	// filter returns the blocks whose type is one of the given types
+	filter := func(types ...string) []any {
+		matched := make([]any, 0)
+		for _, b := range blocks {
+			block, _ := b.(map[string]any)
+			for _, t := range types {
+				if block["type"] == t {
+					matched = append(matched, block)
+					break
+				}
+			}
+		}
+		return matched
+	}
+
+	helpers := map[string]func(goja.FunctionCall) goja.Value{
+		// text() joins all text blocks with newlines
+		"text": func(goja.FunctionCall) goja.Value {
+			var parts []string
+			for _, b := range filter(contentTypeText) {
+				if text, ok := b.(map[string]any)["text"].(string); ok {
+					parts = append(parts, text)
+				}
+			}
+			return vm.ToValue(strings.Join(parts, "\n"))
+		},
+		"images": func(goja.FunctionCall) goja.Value {
+			return vm.ToValue(filter(contentTypeImage))
+		},
+		"audio": func(goja.FunctionCall) goja.Value {
+			return vm.ToValue(filter(contentTypeAudio))
+		},
+		"resources": func(goja.FunctionCall) goja.Value {
+			return vm.ToValue(filter(contentTypeResource, contentTypeResourceLink))
+		},
+	}
+	// Helpers are non-enumerable so results stay JSON-serializable when returned
+	for name, fn := range helpers {
+		if err := obj.DefineDataProperty(name, vm.ToValue(fn), goja.FLAG_FALSE, goja.FLAG_FALSE, goja.FLAG_FALSE); err != nil {
+			return nil, err
+		}
+	}
+
+	return obj, nil
+}
+
+// binaryBlock builds an image or audio content block from a JS value.
+// data may be a base64 string, a Buffer/Uint8Array or an ArrayBuffer.
+func binaryBlock(vm *goja.Runtime, blockType string, call goja.FunctionCall) goja.Value {
+	if len(call.Arguments) != 2 {
+		panic(vm.NewTypeError(fmt.Sprintf("mcp.%s requires 2 arguments: data, mimeType", blockType)))
+	}
+
+	data, err := exportBytes(call.Argument(0))
+	if err != nil {
+		panic(vm.NewTypeError(fmt.Sprintf("mcp.%s: %v", blockType, err)))
+	}
+	mimeType := call.Argument(1).String()
+	if mimeType == "" {
+		panic(vm.NewTypeError(fmt.Sprintf("mcp.%s: mimeType is required", blockType)))
+	}
+
+	return vm.ToValue(map[string]any{
+		"type":     blockType,
+		"data":     data,
+		"mimeType": mimeType,
+	})
+}
+
+// resourceBlock builds an embedded resource content block from a JS object
+// of the form {uri, mimeType?, text?, blob?}
+func resourceBlock(vm *goja.Runtime, call goja.FunctionCall) goja.Value {
+	if len(call.Arguments) != 1 || goja.IsUndefined(call.Argument(0)) || goja.IsNull(call.Argument(0)) {
+		panic(vm.NewTypeError("mcp.resource requires 1 argument: {uri, mimeType, text | blob}"))
+	}
+
+	obj := call.Argument(0).ToObject(vm)
+	uri := obj.Get("uri")
+	if uri == nil || goja.IsUndefined(uri) || uri.String() == "" {
+		panic(vm.NewTypeError("mcp.resource: uri is required"))
+	}
+
+	resource := map[string]any{"uri": uri.String()}
+	if mimeType := obj.Get("mimeType"); mimeType != nil && !goja.IsUndefined(mimeType) {
+		resource["mimeType"] = mimeType.String()
+	}
+	if text := obj.Get("text"); text != nil && !goja.IsUndefined(text) {
+		resource["text"] = text.String()
+	}
+	if blob := obj.Get("blob"); blob != nil && !goja.IsUndefined(blob) {
+		data, err := exportBytes(blob)
+		if err != nil {
+			panic(vm.NewTypeError(fmt.Sprintf("mcp.resource: blob %v", err)))
+		}
+		resource["blob"] = data
+	}
+
+	return vm.ToValue(map[string]any{
+		"type":     contentTypeResource,
+		"resource": resource,
+	})
+}
+
+// exportBytes converts a JS value holding binary data to a base64 string
+func exportBytes(v goja.Value) (string, error) {
+	switch data := v.Export().(type) {
+	case string:
+		if _, err := base64.StdEncoding.DecodeString(data); err != nil {
+			return "", fmt.Errorf("data string must be base64-encoded")
+		}
+		return data, nil
+	case []byte:
+		return base64.StdEncoding.EncodeToString(data), nil
+	case goja.ArrayBuffer:
+		return base64.StdEncoding.EncodeToString(data.Bytes()), nil
+	default:
+		return "", fmt.Errorf("data must be a base64 string, Buffer or ArrayBuffer, got %T", data)
+	}
+}
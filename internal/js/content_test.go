@@ -0,0 +1,193 @@
+package js
+
+import (
+	"context"
+	"testing"
+
+	"github.com/vaayne/mcphub/internal/logging"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeCaller is a ToolCaller returning canned results per tool name
+type fakeCaller struct {
+	results map[string]*mcp.CallToolResult
+	schemas map[string]any
+}
+
+func (f *fakeCaller) CallTool(ctx context.Context, serverID, toolName string, params map[string]any) (*mcp.CallToolResult, error) {
+	result, ok := f.results[serverID+"__"+toolName]
+	if !ok {
+		return nil, assert.AnError
+	}
+	return result, nil
+}
+
+func (f *fakeCaller) ListTools(ctx context.Context) ([]*mcp.Tool, error) {
+	tools := make([]*mcp.Tool, 0, len(f.results))
+	for name := range f.results {
+		tools = append(tools, &mcp.Tool{Name: name, InputSchema: f.schemas[name]})
+	}
+	return tools, nil
+}
+
+func newRichCaller() *fakeCaller {
+	return &fakeCaller{results: map[string]*mcp.CallToolResult{
+		"srv__json":  {Content: []mcp.Content{&mcp.TextContent{Text: `{"a": 1}`}}},
+		"srv__empty": {},
+		"srv__screenshot": {Content: []mcp.Content{
+			&mcp.TextContent{Text: "captured"},
+			&mcp.ImageContent{Data: []byte("png-bytes"), MIMEType: "image/png"},
+			&mcp.TextContent{Text: "done"},
+		}},
+		"srv__file": {Content: []mcp.Content{
+			&mcp.EmbeddedResource{Resource: &mcp.ResourceContents{URI: "file:///a.txt", MIMEType: "text/plain", Text: "hello"}},
+			&mcp.AudioContent{Data: []byte("wav"), MIMEType: "audio/wav"},
+		}},
+	}}
+}
+
+// TestCallTool_SingleTextUnchanged verifies single text results keep returning parsed JSON
+func TestCallTool_SingleTextUnchanged(t *testing.T) {
+	runtime := NewRuntime(logging.NopLogger(), newRichCaller(), nil)
+
+	result, _, err := runtime.Execute(context.Background(), `mcp.callTool("srvJson", {}).a`)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), result)
+
+	result, _, err = runtime.Execute(context.Background(), `mcp.callTool("srvEmpty", {})`)
+	require.NoError(t, err)
+	assert.Nil(t, result)
+}
+
+// TestCallTool_RichContent verifies multi-block and binary results are exposed in full
+func TestCallTool_RichContent(t *testing.T) {
+	runtime := NewRuntime(logging.NopLogger(), newRichCaller(), nil)
+
+	script := `
+		const r = mcp.callTool("srvScreenshot", {});
+		({
+			count: r.content.length,
+			text: r.text(),
+			images: r.images().map(i => i.mimeType),
+			isError: r.isError,
+		})
+	`
+	result, _, err := runtime.Execute(context.Background(), script)
+	require.NoError(t, err)
+
+	m, ok := result.(map[string]any)
+	require.True(t, ok)
+	assert.Equal(t, int64(3), m["count"])
+	assert.Equal(t, "captured\ndone", m["text"])
+	assert.Equal(t, []any{"image/png"}, m["images"])
+	assert.Equal(t, false, m["isError"])
+}
+
+// TestCallToolRaw verifies callToolRaw always returns the result object
+func TestCallToolRaw(t *testing.T) {
+	runtime := NewRuntime(logging.NopLogger(), newRichCaller(), nil)
+
+	script := `
+		const r = mcp.callToolRaw("srvJson", {});
+		const f = mcp.callToolRaw("srvFile", {});
+		[r.text(), r.content[0].type, f.resources()[0].resource.uri, f.audio().length]
+	`
+	result, _, err := runtime.Execute(context.Background(), script)
+	require.NoError(t, err)
+	assert.Equal(t, []any{`{"a": 1}`, "text", "file:///a.txt", int64(1)}, result)
+}
+
+// TestContentHelpers verifies mcp.image/audio/resource build content blocks
+func TestContentHelpers(t *testing.T) {
+	runtime := NewRuntime(logging.NopLogger(), newRichCaller(), nil)
+
+	tests := []struct {
+		name     string
+		script   string
+		expected mcp.Content
+	}{
+		{
+			name:     "image from base64",
+			script:   `mcp.image("aGk=", "image/png")`,
+			expected: &mcp.ImageContent{Data: []byte("hi"), MIMEType: "image/png"},
+		},
+		{
+			name:     "audio from buffer",
+			script:   `const { Buffer } = require("node:buffer"); mcp.audio(Buffer.from("hi"), "audio/wav")`,
+			expected: &mcp.AudioContent{Data: []byte("hi"), MIMEType: "audio/wav"},
+		},
+		{
+			name:     "text resource",
+			script:   `mcp.resource({uri: "mem://note", mimeType: "text/plain", text: "note"})`,
+			expected: &mcp.EmbeddedResource{Resource: &mcp.ResourceContents{URI: "mem://note", MIMEType: "text/plain", Text: "note"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, _, err := runtime.Execute(context.Background(), tt.script)
+			require.NoError(t, err)
+
+			content, ok := ContentFromResult(result)
+			require.True(t, ok)
+			require.Len(t, content, 1)
+			assert.Equal(t, tt.expected, content[0])
+		})
+	}
+
+	_, _, err := runtime.Execute(context.Background(), `mcp.image("not base64!", "image/png")`)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "base64")
+}
+
+// TestCallToolRaw_ErrorResult verifies error results are returned by
+// callToolRaw with isError set instead of being thrown
+func TestCallToolRaw_ErrorResult(t *testing.T) {
+	caller := newRichCaller()
+	caller.results["srv__fail"] = &mcp.CallToolResult{IsError: true, Content: []mcp.Content{&mcp.TextContent{Text: "quota exceeded"}}}
+	runtime := NewRuntime(logging.NopLogger(), caller, nil)
+
+	result, _, err := runtime.Execute(context.Background(), `
+		const r = mcp.callToolRaw("srvFail", {});
+		[r.isError, r.text()]
+	`)
+	require.NoError(t, err)
+	assert.Equal(t, []any{true, "quota exceeded"}, result)
+}
+
+// TestContentFromResult verifies which script results are treated as content
+func TestContentFromResult(t *testing.T) {
+	runtime := NewRuntime(logging.NopLogger(), newRichCaller(), nil)
+
+	tests := []struct {
+		name      string
+		script    string
+		isContent bool
+		count     int
+	}{
+		{"plain value", `({a: 1})`, false, 0},
+		{"text-only block", `({type: "text", text: "hi"})`, false, 0},
+		{"text-only array", `[{type: "text", text: "hi"}]`, false, 0},
+		{"mixed array", `[{type: "text", text: "hi"}, mcp.image("aGk=", "image/png")]`, true, 2},
+		{"raw result", `mcp.callToolRaw("srvScreenshot", {})`, true, 3},
+		{"forwarded images", `mcp.callTool("srvScreenshot", {}).images()`, true, 1},
+		{"malformed block", `[{type: "image", mimeType: "image/png"}]`, false, 0},
+		{"data with a content key", `({content: [{type: "text", text: "hi"}]})`, false, 0},
+		{"copied raw result", `({...mcp.callToolRaw("srvScreenshot", {})})`, false, 0},
+		{"async raw result", `mcp.callToolRawAsync("srvScreenshot", {})`, true, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, _, err := runtime.Execute(context.Background(), tt.script)
+			require.NoError(t, err)
+
+			content, ok := ContentFromResult(result)
+			assert.Equal(t, tt.isContent, ok)
+			assert.Len(t, content, tt.count)
+		})
+	}
+}
@@ -0,0 +1,382 @@
+package js
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/dop251/goja"
+)
+
+// Fetch defaults
+const (
+	// DefaultFetchTimeout is the default timeout of a single mcp.fetch request
+	DefaultFetchTimeout = 10 * time.Second
+	// DefaultFetchMaxResponseBytes is the default size limit of a response body
+	DefaultFetchMaxResponseBytes = 1 << 20 // 1MB
+)
+
+// DefaultFetchMethods are the methods allowed when a policy names none
+var DefaultFetchMethods = []string{http.MethodGet, http.MethodHead}
+
+// maxFetchRedirects is the number of redirects followed per request
+const maxFetchRedirects = 5
+
+// FetchPolicy opens mcp.fetch to a list of hosts. Without a policy scripts
+// have no network access and mcp.fetch throws.
+type FetchPolicy struct {
+	AllowedHosts     []string                     // host globs, e.g. "api.example.com" or "*.corp.example.com"; "host:port" allows that port only
+	AllowedMethods   []string                     // HTTP methods (default GET and HEAD)
+	Timeout          time.Duration                // per request, within the script timeout (default 10s)
+	MaxResponseBytes int64                        // per response body (default 1MB)
+	Headers          map[string]map[string]string // headers added to requests by host glob; ${NAME} in values reads the environment
+}
+
+// fetcher performs the requests of one execution under a policy
+type fetcher struct {
+	policy    *FetchPolicy
+	methods   []string
+	timeout   time.Duration
+	maxBytes  int64
+	client    *http.Client
+	appendLog func(LogEntry)
+}
+
+// fetchRequest is a request built from the arguments of mcp.fetch
+type fetchRequest struct {
+	method  string
+	url     string
+	headers map[string]string
+	body    string
+}
+
+// fetchResponse is a response read in full
+type fetchResponse struct {
+	url        string
+	status     int
+	statusText string
+	headers    map[string]any
+	body       string
+}
+
+// newFetcher returns a fetcher for policy, or nil for a nil policy
+func newFetcher(policy *FetchPolicy, appendLog func(LogEntry)) *fetcher {
+	if policy == nil {
+		return nil
+	}
+	f := &fetcher{
+		policy:    policy,
+		methods:   DefaultFetchMethods,
+		timeout:   DefaultFetchTimeout,
+		maxBytes:  DefaultFetchMaxResponseBytes,
+		appendLog: appendLog,
+	}
+	if len(policy.AllowedMethods) > 0 {
+		f.methods = make([]string, len(policy.AllowedMethods))
+		for i, method := range policy.AllowedMethods {
+			f.methods[i] = strings.ToUpper(method)
+		}
+	}
+	if policy.Timeout > 0 {
+		f.timeout = policy.Timeout
+	}
+	if policy.MaxResponseBytes > 0 {
+		f.maxBytes = policy.MaxResponseBytes
+	}
+	f.client = &http.Client{CheckRedirect: f.checkRedirect}
+	return f
+}
+
+// hostAllowed reports whether the policy allows requests to u
+func (f *fetcher) hostAllowed(u *url.URL) bool {
+	return matchesAny(f.policy.AllowedHosts, u.Hostname()) || matchesAny(f.policy.AllowedHosts, u.Host)
+}
+
+// injectedHeaders returns the configured headers for requests to u, with
+// environment references expanded
+func (f *fetcher) injectedHeaders(u *url.URL) map[string]string {
+	headers := make(map[string]string)
+	for _, pattern := range slices.Sorted(maps.Keys(f.policy.Headers)) {
+		if !matchesAny([]string{pattern}, u.Hostname()) && !matchesAny([]string{pattern}, u.Host) {
+			continue
+		}
+		for name, value := range f.policy.Headers[pattern] {
+			headers[name] = os.ExpandEnv(value)
+		}
+	}
+	return headers
+}
+
+// checkRedirect follows redirects to allowed hosts only, swapping the
+// injected headers for those of the new host
+func (f *fetcher) checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= maxFetchRedirects {
+		return fmt.Errorf("stopped after %d redirects", maxFetchRedirects)
+	}
+	if !f.hostAllowed(req.URL) {
+		return fmt.Errorf("redirect to host %q is not allowed", req.URL.Host)
+	}
+	for name := range f.injectedHeaders(via[len(via)-1].URL) {
+		req.Header.Del(name)
+	}
+	for name, value := range f.injectedHeaders(req.URL) {
+		req.Header.Set(name, value)
+	}
+	return nil
+}
+
+// do performs a request and records it in the execution logs
+func (f *fetcher) do(ctx context.Context, fr *fetchRequest) (*fetchResponse, error) {
+	start := time.Now()
+	resp, err := f.send(ctx, fr)
+
+	fields := map[string]any{
+		"method":     fr.method,
+		"url":        fr.url,
+		"durationMs": time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		fields["error"] = err.Error()
+		f.appendLog(LogEntry{Level: "warn", Message: fmt.Sprintf("fetch %s %s failed", fr.method, fr.url), Fields: fields})
+		return nil, err
+	}
+	fields["status"] = resp.status
+	fields["bytes"] = len(resp.body)
+	f.appendLog(LogEntry{Level: "info", Message: fmt.Sprintf("fetch %s %s: %d", fr.method, fr.url, resp.status), Fields: fields})
+	return resp, nil
+}
+
+// send checks a request against the policy and performs it
+func (f *fetcher) send(ctx context.Context, fr *fetchRequest) (*fetchResponse, error) {
+	u, err := url.Parse(fr.url)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported URL scheme %q (must be http or https)", u.Scheme)
+	}
+	if !f.hostAllowed(u) {
+		return nil, fmt.Errorf("host %q is not allowed (exec.fetch.allowedHosts)", u.Host)
+	}
+	if !slices.Contains(f.methods, fr.method) {
+		return nil, fmt.Errorf("method %s is not allowed (exec.fetch.allowedMethods)", fr.method)
+	}
+
+	reqCtx, cancel := context.WithTimeout(ctx, f.timeout)
+	defer cancel()
+
+	var body io.Reader
+	if fr.body != "" {
+		body = strings.NewReader(fr.body)
+	}
+	req, err := http.NewRequestWithContext(reqCtx, fr.method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	for name, value := range fr.headers {
+		req.Header.Set(name, value)
+	}
+	// Configured headers win over the script's
+	for name, value := range f.injectedHeaders(u) {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+			return nil, fmt.Errorf("request timed out after %v", f.timeout)
+		}
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, f.maxBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if int64(len(data)) > f.maxBytes {
+		return nil, fmt.Errorf("response exceeds the limit of %d bytes (exec.fetch.maxResponseBytes)", f.maxBytes)
+	}
+
+	headers := make(map[string]any, len(resp.Header))
+	for name, values := range resp.Header {
+		headers[strings.ToLower(name)] = strings.Join(values, ", ")
+	}
+	return &fetchResponse{
+		url:        resp.Request.URL.String(),
+		status:     resp.StatusCode,
+		statusText: strings.TrimSpace(strings.TrimPrefix(resp.Status, fmt.Sprint(resp.StatusCode))),
+		headers:    headers,
+		body:       string(data),
+	}, nil
+}
+
+// fetchFunctions builds mcp.fetch and mcp.fetchAsync. Without a fetch policy
+// both throw, keeping the sandbox closed.
+func (r *Runtime) fetchFunctions(ctx context.Context, vm *goja.Runtime, appendLog func(LogEntry), async *asyncCalls) (func(goja.FunctionCall) goja.Value, func(goja.FunctionCall) goja.Value, error) {
+	// Bodies and json() go through the built-ins as they are now, before the
+	// script can replace them
+	jsonObj := vm.Get("JSON").ToObject(vm)
+	stringify, ok := goja.AssertFunction(jsonObj.Get("stringify"))
+	if !ok {
+		return nil, nil, fmt.Errorf("JSON.stringify is not a function")
+	}
+	parse, ok := goja.AssertFunction(jsonObj.Get("parse"))
+	if !ok {
+		return nil, nil, fmt.Errorf("JSON.parse is not a function")
+	}
+	f := newFetcher(r.fetch, appendLog)
+
+	// request builds the request of a call, throwing on bad arguments
+	request := func(fnName string, call goja.FunctionCall) *fetchRequest {
+		if f == nil {
+			panic(vm.NewGoError(fmt.Errorf("%s: network access is not enabled (exec.fetch in the hub config)", fnName)))
+		}
+		if goja.IsUndefined(call.Argument(0)) {
+			panic(vm.NewTypeError("%s requires a URL", fnName))
+		}
+		fr := &fetchRequest{method: http.MethodGet, url: call.Argument(0).String(), headers: make(map[string]string)}
+		options := call.Argument(1)
+		if goja.IsUndefined(options) || goja.IsNull(options) {
+			return fr
+		}
+		opts := options.ToObject(vm)
+		if method := opts.Get("method"); method != nil && !goja.IsUndefined(method) {
+			fr.method = strings.ToUpper(method.String())
+		}
+		if headers := opts.Get("headers"); headers != nil && !goja.IsUndefined(headers) && !goja.IsNull(headers) {
+			headersObj := headers.ToObject(vm)
+			for _, name := range headersObj.Keys() {
+				fr.headers[name] = headersObj.Get(name).String()
+			}
+		}
+		// A string body is sent as is; anything else is sent as JSON
+		if body := opts.Get("body"); body != nil && !goja.IsUndefined(body) && !goja.IsNull(body) {
+			if _, isString := body.Export().(string); isString {
+				fr.body = body.String()
+			} else {
+				encoded, err := stringify(goja.Undefined(), body)
+				if err != nil {
+					panic(err)
+				}
+				fr.body = encoded.String()
+				if !hasHeader(fr.headers, "Content-Type") {
+					fr.headers["Content-Type"] = "application/json"
+				}
+			}
+		}
+		return fr
+	}
+
+	// response builds the script's view of a response: status, headers and
+	// the text() and json() readers of the body
+	response := func(resp *fetchResponse) (goja.Value, error) {
+		obj := vm.NewObject()
+		fields := map[string]any{
+			"url":        resp.url,
+			"status":     resp.status,
+			"statusText": resp.statusText,
+			"ok":         resp.status >= 200 && resp.status < 300,
+			"headers":    resp.headers,
+		}
+		for _, name := range slices.Sorted(maps.Keys(fields)) {
+			if err := obj.Set(name, fields[name]); err != nil {
+				return nil, err
+			}
+		}
+		readers := map[string]func(goja.FunctionCall) goja.Value{
+			"text": func(goja.FunctionCall) goja.Value {
+				return vm.ToValue(resp.body)
+			},
+			"json": func(goja.FunctionCall) goja.Value {
+				parsed, err := parse(goja.Undefined(), vm.ToValue(resp.body))
+				if err != nil {
+					panic(err)
+				}
+				return parsed
+			},
+		}
+		// Readers are non-enumerable so responses stay JSON-serializable when returned
+		for name, fn := range readers {
+			if err := obj.DefineDataProperty(name, vm.ToValue(fn), goja.FLAG_FALSE, goja.FLAG_FALSE, goja.FLAG_FALSE); err != nil {
+				return nil, err
+			}
+		}
+		return obj, nil
+	}
+
+	// mcp.fetch(url, {method, headers, body}) performs the request and
+	// returns the response
+	fetchFn := func(call goja.FunctionCall) goja.Value {
+		fr := request("mcp.fetch", call)
+		resp, err := f.do(ctx, fr)
+		if err != nil {
+			panic(vm.NewGoError(fmt.Errorf("mcp.fetch: %w", err)))
+		}
+		value, err := response(resp)
+		if err != nil {
+			panic(vm.NewGoError(err))
+		}
+		return value
+	}
+
+	// mcp.fetchAsync(url, options) returns a Promise for the response; like
+	// mcp.callToolAsync, requests awaited together run in parallel
+	fetchAsyncFn := func(call goja.FunctionCall) goja.Value {
+		fr := request("mcp.fetchAsync", call)
+		promise, resolve, reject := vm.NewPromise()
+
+		go func() {
+			var (
+				resp *fetchResponse
+				err  error
+			)
+			select {
+			case async.slots <- struct{}{}:
+				resp, err = f.do(ctx, fr)
+				<-async.slots
+			case <-ctx.Done():
+				err = fmt.Errorf("execution cancelled")
+			}
+
+			async.loop.RunOnLoop(func(vm *goja.Runtime) {
+				settle := func() error {
+					if err != nil {
+						return reject(vm.NewGoError(fmt.Errorf("mcp.fetchAsync: %w", err)))
+					}
+					value, convErr := response(resp)
+					if convErr != nil {
+						return reject(vm.NewGoError(convErr))
+					}
+					return resolve(value)
+				}
+				// As in callAsyncFromJS, a failure to settle ends the execution
+				if settleErr := settle(); settleErr != nil {
+					async.abort(settleErr)
+				}
+			})
+		}()
+
+		return vm.ToValue(promise)
+	}
+
+	return fetchFn, fetchAsyncFn, nil
+}
+
+// hasHeader reports whether headers has name, in any case
+func hasHeader(headers map[string]string, name string) bool {
+	for key := range headers {
+		if strings.EqualFold(key, name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,135 @@
+package js
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/vaayne/mcphub/internal/logging"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fetchServer echoes requests back as JSON
+func fetchServer(t *testing.T) (*httptest.Server, string) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/big":
+			_, _ = w.Write([]byte(strings.Repeat("x", 100)))
+			return
+		case "/redirect":
+			http.Redirect(w, r, r.URL.Query().Get("to"), http.StatusFound)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Trace", "t1")
+		_, _ = w.Write([]byte(`{"method":"` + r.Method + `","token":"` + r.Header.Get("Authorization") +
+			`","type":"` + r.Header.Get("Content-Type") + `","body":` + stringOrNull(body) + `}`))
+	}))
+	t.Cleanup(server.Close)
+	u, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	return server, u.Host
+}
+
+func stringOrNull(body []byte) string {
+	if len(body) == 0 {
+		return "null"
+	}
+	return string(body)
+}
+
+// TestExecute_Fetch verifies mcp.fetch reaches allowed hosts with configured
+// headers, and records each request in the logs
+func TestExecute_Fetch(t *testing.T) {
+	server, host := fetchServer(t)
+	t.Setenv("INTERNAL_TOKEN", "s3cret")
+	cfg := &Config{Fetch: &FetchPolicy{
+		AllowedHosts:   []string{"127.0.0.1"},
+		AllowedMethods: []string{"get", "post"},
+		Headers:        map[string]map[string]string{host: {"Authorization": "Bearer ${INTERNAL_TOKEN}"}},
+	}}
+
+	result, logs, err := NewRuntime(logging.NopLogger(), nil, cfg).Execute(context.Background(), `
+		const res = mcp.fetch("`+server.URL+`/items", { headers: { Authorization: "mine" } });
+		const posted = mcp.fetch("`+server.URL+`/items", { method: "POST", body: { name: "a" } }).json();
+		[res.status, res.ok, res.headers["x-trace"], res.json().token, posted.method, posted.type, posted.body.name];
+	`)
+	require.NoError(t, err)
+	assert.Equal(t, []any{int64(200), true, "t1", "Bearer s3cret", "POST", "application/json", "a"}, result)
+	require.Len(t, logs, 2)
+	assert.Equal(t, "info", logs[0].Level)
+	assert.Equal(t, "fetch GET "+server.URL+"/items: 200", logs[0].Message)
+	assert.Equal(t, 200, logs[0].Fields["status"])
+	assert.NotContains(t, logs[0].Message+logs[1].Message, "s3cret")
+}
+
+// TestExecute_FetchHidesEnv verifies scripts can't read the secrets fetch
+// headers reference from the environment
+func TestExecute_FetchHidesEnv(t *testing.T) {
+	t.Setenv("INTERNAL_TOKEN", "s3cret")
+	code := `[require("node:process").env.INTERNAL_TOKEN, require("process").env.INTERNAL_TOKEN]`
+
+	result, _, err := NewRuntime(logging.NopLogger(), nil, nil).Execute(context.Background(), code)
+	require.NoError(t, err)
+	assert.Equal(t, []any{"s3cret", "s3cret"}, result, "without fetch the environment is visible")
+
+	cfg := &Config{Fetch: &FetchPolicy{
+		AllowedHosts: []string{"127.0.0.1"},
+		Headers:      map[string]map[string]string{"127.0.0.1:*": {"Authorization": "Bearer ${INTERNAL_TOKEN}"}},
+	}}
+	result, _, err = NewRuntime(logging.NopLogger(), nil, cfg).Execute(context.Background(), code)
+	require.NoError(t, err)
+	assert.Equal(t, []any{nil, nil}, result)
+}
+
+// TestExecute_FetchAsync verifies mcp.fetchAsync returns a Promise of the response
+func TestExecute_FetchAsync(t *testing.T) {
+	server, _ := fetchServer(t)
+	cfg := &Config{Fetch: &FetchPolicy{AllowedHosts: []string{"127.0.0.1"}}}
+
+	result, logs, err := NewRuntime(logging.NopLogger(), nil, cfg).Execute(context.Background(), `
+		(async () => {
+			const responses = await Promise.all([1, 2, 3].map((i) => mcp.fetchAsync("`+server.URL+`/items/" + i)));
+			return responses.map((res) => res.status);
+		})()
+	`)
+	require.NoError(t, err)
+	assert.Equal(t, []any{int64(200), int64(200), int64(200)}, result)
+	assert.Len(t, logs, 3)
+}
+
+// TestExecute_FetchDenied verifies requests outside the policy throw and are
+// still logged
+func TestExecute_FetchDenied(t *testing.T) {
+	server, host := fetchServer(t)
+	policy := &FetchPolicy{AllowedHosts: []string{host}, MaxResponseBytes: 10}
+
+	scripts := map[string]string{
+		`mcp.fetch("` + server.URL + `/items", { method: "DELETE" })`:     "method DELETE is not allowed",
+		`mcp.fetch("http://example.com/")`:                                `host "example.com" is not allowed`,
+		`mcp.fetch("file:///etc/passwd")`:                                 `unsupported URL scheme "file"`,
+		`mcp.fetch("` + server.URL + `/big")`:                             "exceeds the limit of 10 bytes",
+		`mcp.fetch("` + server.URL + `/redirect?to=http://example.com/")`: `redirect to host "example.com" is not allowed`,
+		`mcp.fetch()`:                           "requires a URL",
+		`mcp.fetchAsync("http://example.com/")`: `host "example.com" is not allowed`,
+	}
+	for script, want := range scripts {
+		_, logs, err := NewRuntime(logging.NopLogger(), nil, &Config{Fetch: policy}).Execute(context.Background(), script)
+		require.Error(t, err, script)
+		assert.Contains(t, err.Error(), want, script)
+		if want != "requires a URL" {
+			require.Len(t, logs, 1, script)
+			assert.Equal(t, "warn", logs[0].Level, script)
+		}
+	}
+
+	_, _, err := NewRuntime(logging.NopLogger(), nil, nil).Execute(context.Background(), `mcp.fetch("`+server.URL+`")`)
+	assert.ErrorContains(t, err, "network access is not enabled")
+}
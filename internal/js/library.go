@@ -0,0 +1,145 @@
+package js
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/dop251/goja"
+	"github.com/dop251/goja_nodejs/process"
+	"github.com/dop251/goja_nodejs/require"
+	"github.com/vaayne/mcphub/internal/config"
+)
+
+// ModulesDir is the folder of the script library holding the modules scripts
+// load with require("lib/name")
+const ModulesDir = "lib"
+
+// modulePrefix is where library modules live in the module namespace seen by
+// require: require("lib/foo") resolves to /lib/foo
+const modulePrefix = "/" + ModulesDir + "/"
+
+// newRegistry creates the require registry of an execution. Module paths are
+// resolved without touching the host filesystem, and only library modules
+// are loaded; node:* built-ins are unaffected, except that node:process has
+// an empty env when fetch is configured.
+func (r *Runtime) newRegistry() *require.Registry {
+	registry := require.NewRegistry(
+		require.WithGlobalFolders("/"),
+		require.WithPathResolver(func(base, target string) string {
+			return path.Join(base, target)
+		}),
+		require.WithLoader(r.loadModule),
+	)
+	// Fetch headers read secrets from the environment so scripts never see
+	// them; process.env must not hand them over either
+	if r.fetch != nil {
+		registry.RegisterNativeModule(process.ModuleName, requireProcessWithoutEnv)
+		registry.RegisterNativeModule(require.NodePrefix+process.ModuleName, requireProcessWithoutEnv)
+	}
+	return registry
+}
+
+// requireProcessWithoutEnv loads node:process with an empty env
+func requireProcessWithoutEnv(vm *goja.Runtime, module *goja.Object) {
+	exports := module.Get("exports").(*goja.Object)
+	_ = exports.Set("env", vm.NewObject())
+}
+
+// loadModule is the source loader of require. Only files in the lib folder of
+// the script library are served; every other path, including node_modules
+// lookups, is reported missing, so scripts cannot read the host filesystem.
+// A TypeScript module answers for the .js file of the same name.
+func (r *Runtime) loadModule(name string) ([]byte, error) {
+	rel, ok := strings.CutPrefix(name, modulePrefix)
+	if !ok || rel == "" || r.library == "" {
+		return nil, require.ModuleFileDoesNotExistError
+	}
+
+	// os.Root refuses paths leaving the folder, including through symlinks
+	root, err := os.OpenRoot(filepath.Join(r.library, ModulesDir))
+	if err != nil {
+		return nil, require.ModuleFileDoesNotExistError
+	}
+	defer root.Close()
+
+	source, err := readModule(root, rel, r.maxScriptSize)
+	if err == require.ModuleFileDoesNotExistError && path.Ext(rel) == ".js" {
+		rel = strings.TrimSuffix(rel, ".js") + ".ts"
+		source, err = readModule(root, rel, r.maxScriptSize)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if path.Ext(rel) == ".ts" {
+		stripped, err := StripTypes(string(source))
+		if err != nil {
+			return nil, fmt.Errorf("%s/%s: %w", ModulesDir, rel, err)
+		}
+		source = []byte(stripped)
+	}
+	return source, nil
+}
+
+// readModule reads a module file of at most maxSize bytes from root
+func readModule(root *os.Root, name string, maxSize int) ([]byte, error) {
+	info, err := root.Stat(name)
+	if err != nil || !info.Mode().IsRegular() {
+		return nil, require.ModuleFileDoesNotExistError
+	}
+	if info.Size() > int64(maxSize) {
+		return nil, fmt.Errorf("%s/%s exceeds maximum size of %d bytes", ModulesDir, name, maxSize)
+	}
+	data, err := root.ReadFile(name)
+	if err != nil {
+		return nil, require.ModuleFileDoesNotExistError
+	}
+	return data, nil
+}
+
+// restrictModules limits the node:* built-ins require loads to the allowed
+// modules. Library modules share the global require, so the limit applies to
+// them too.
+func (r *Runtime) restrictModules(vm *goja.Runtime) error {
+	if r.allowedModules == nil {
+		return nil
+	}
+	load, ok := goja.AssertFunction(vm.Get("require"))
+	if !ok {
+		return &RuntimeError{Type: ErrorTypeRuntime, Message: "failed to setup require"}
+	}
+	return vm.Set("require", func(call goja.FunctionCall) goja.Value {
+		name := call.Argument(0).String()
+		builtin := strings.TrimPrefix(name, require.NodePrefix)
+		if slices.Contains(config.ExecModules, builtin) && !slices.Contains(r.allowedModules, builtin) {
+			panic(vm.NewGoError(fmt.Errorf("module %q is not allowed", name)))
+		}
+
+		// require resolves relative paths against the module of its caller,
+		// which is now this wrapper, so they are resolved here instead
+		if isRelative(name) {
+			var frames [2]goja.StackFrame
+			if stack := vm.CaptureCallStack(2, frames[:0]); len(stack) == 2 {
+				if dir := path.Dir(stack[1].SrcName()); path.IsAbs(dir) {
+					name = path.Join(dir, name)
+				}
+			}
+		}
+
+		module, err := load(goja.Undefined(), vm.ToValue(name))
+		if err != nil {
+			panic(err)
+		}
+		return module
+	})
+}
+
+// isRelative reports whether a module name is a path relative to the module
+// requiring it
+func isRelative(name string) bool {
+	return name == "." || name == ".." || strings.HasPrefix(name, "./") || strings.HasPrefix(name, "../")
+}
@@ -0,0 +1,144 @@
+package js
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/vaayne/mcphub/internal/logging"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeLibrary creates a script library with the given lib modules
+func writeLibrary(t *testing.T, modules map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, source := range modules {
+		path := filepath.Join(dir, ModulesDir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(source), 0o644))
+	}
+	return dir
+}
+
+// TestExecute_LibraryModules verifies require("lib/...") loads library modules
+func TestExecute_LibraryModules(t *testing.T) {
+	dir := writeLibrary(t, map[string]string{
+		"strings.js":       `exports.shout = (s) => s.toUpperCase() + "!";`,
+		"math.ts":          "const square = (n: number): number => n * n;\nmodule.exports = { square };",
+		"text/index.js":    `const { shout } = require("../strings"); module.exports = (s) => shout(s.trim());`,
+		"config.json":      `{"greeting": "hello"}`,
+		"nested/deep.js":   `module.exports = require("./helper").value;`,
+		"nested/helper.js": `exports.value = 42;`,
+	})
+	runtime := NewRuntime(logging.NopLogger(), nil, &Config{Library: dir})
+
+	result, _, err := runtime.Execute(context.Background(), `
+		const { shout } = require("lib/strings");
+		const { square } = require("lib/math");
+		const text = require("lib/text");
+		const { greeting } = require("lib/config.json");
+		[shout(greeting), square(7), text("  hi "), require("lib/nested/deep")];
+	`)
+	require.NoError(t, err)
+	assert.Equal(t, []any{"HELLO!", int64(49), "HI!", int64(42)}, result)
+}
+
+// TestExecute_LibraryModuleTypeError verifies TypeScript errors in a module
+// name the module
+func TestExecute_LibraryModuleTypeError(t *testing.T) {
+	dir := writeLibrary(t, map[string]string{
+		"broken.ts": "enum Color { Red }\n",
+	})
+	runtime := NewRuntime(logging.NopLogger(), nil, &Config{Library: dir})
+
+	_, _, err := runtime.Execute(context.Background(), `require("lib/broken")`)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "lib/broken.ts")
+}
+
+// TestExecute_RequireSandboxed verifies require cannot read files outside the
+// library's lib folder
+func TestExecute_RequireSandboxed(t *testing.T) {
+	dir := writeLibrary(t, map[string]string{
+		"ok.js": `module.exports = 1;`,
+	})
+	secret := filepath.Join(dir, "secret.js")
+	require.NoError(t, os.WriteFile(secret, []byte(`module.exports = "secret";`), 0o644))
+	require.NoError(t, os.Symlink(secret, filepath.Join(dir, ModulesDir, "link.js")))
+
+	scripts := map[string]string{
+		"absolute host path":  `require(` + jsString(secret) + `)`,
+		"relative host path":  `require("./secret.js")`,
+		"escape from lib":     `require("lib/../secret")`,
+		"symlink out of lib":  `require("lib/link")`,
+		"node_modules lookup": `require("secret")`,
+	}
+	for name, script := range scripts {
+		t.Run(name, func(t *testing.T) {
+			runtime := NewRuntime(logging.NopLogger(), nil, &Config{Library: dir})
+			_, _, err := runtime.Execute(context.Background(), script)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "Invalid module")
+		})
+	}
+
+	t.Run("no library", func(t *testing.T) {
+		runtime := NewRuntime(logging.NopLogger(), nil, nil)
+		_, _, err := runtime.Execute(context.Background(), `require("lib/ok")`)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Invalid module")
+	})
+}
+
+// TestExecute_Params verifies Config.Params is exposed as the params global
+func TestExecute_Params(t *testing.T) {
+	runtime := NewRuntime(logging.NopLogger(), nil, &Config{Params: map[string]any{"name": "world"}})
+	result, _, err := runtime.Execute(context.Background(), "`hello ${params.name}`")
+	require.NoError(t, err)
+	assert.Equal(t, "hello world", result)
+
+	runtime = NewRuntime(logging.NopLogger(), nil, nil)
+	result, _, err = runtime.Execute(context.Background(), `typeof params`)
+	require.NoError(t, err)
+	assert.Equal(t, "undefined", result)
+}
+
+// jsString quotes s as a JavaScript string literal
+func jsString(s string) string {
+	return `"` + filepath.ToSlash(s) + `"`
+}
+
+// TestExecute_AllowedModules verifies require only loads the allowed node:*
+// built-ins, while library modules, relative paths included, keep working
+func TestExecute_AllowedModules(t *testing.T) {
+	dir := writeLibrary(t, map[string]string{
+		"strings.js":       `exports.shout = (s) => s.toUpperCase() + "!";`,
+		"text/index.js":    `const { shout } = require("../strings"); module.exports = (s) => shout(s.trim());`,
+		"nested/deep.js":   `module.exports = require("./helper").value;`,
+		"nested/helper.js": `exports.value = 42;`,
+		"encode.js":        `module.exports = require("node:buffer").Buffer.from("hi").toString("hex");`,
+	})
+	runtime := NewRuntime(logging.NopLogger(), nil, &Config{Library: dir, AllowedModules: []string{"url"}})
+
+	result, _, err := runtime.Execute(context.Background(), `
+		const { URL } = require("node:url");
+		[new URL("https://example.com/a").pathname, require("url") !== undefined, require("lib/text")(" hi "), require("lib/nested/deep")];
+	`)
+	require.NoError(t, err)
+	assert.Equal(t, []any{"/a", true, "HI!", int64(42)}, result)
+
+	for _, script := range []string{`require("buffer")`, `require("node:buffer")`, `require("lib/encode")`} {
+		_, _, err := runtime.Execute(context.Background(), script)
+		require.Error(t, err, script)
+		assert.Contains(t, err.Error(), "is not allowed", script)
+	}
+
+	// An empty list allows no built-ins
+	runtime = NewRuntime(logging.NopLogger(), nil, &Config{AllowedModules: []string{}})
+	_, _, err = runtime.Execute(context.Background(), `require("node:url")`)
+	assert.ErrorContains(t, err, "is not allowed")
+}
@@ -0,0 +1,191 @@
+package js
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"runtime"
+	"runtime/metrics"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/dop251/goja"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Resource budget defaults
+const (
+	// DefaultMaxMemory is the default heap growth allowed during an execution
+	DefaultMaxMemory = 256 << 20 // 256MB
+	// DefaultMaxCallDepth is the default maximum JS call stack depth
+	DefaultMaxCallDepth = 10_000
+	// DefaultMaxToolCalls is the default number of tool calls per execution
+	DefaultMaxToolCalls = 1000
+	// DefaultMaxToolResultBytes is the default total size of tool results per execution
+	DefaultMaxToolResultBytes = 50 << 20 // 50MB
+)
+
+// memorySampleInterval is how often heap growth is checked during an execution
+const memorySampleInterval = 10 * time.Millisecond
+
+// memoryGCInterval is how often a garbage collection may be forced to confirm
+// heap growth, across every execution in the process
+const memoryGCInterval = time.Second
+
+// heapMetric is the runtime metric sampled for heap growth: bytes held by
+// heap objects, live or not yet swept
+const heapMetric = "/memory/classes/heap/objects:bytes"
+
+// limitError is the error of a tripped budget
+func limitError(errType ErrorType, format string, args ...any) *RuntimeError {
+	return &RuntimeError{Type: errType, Message: fmt.Sprintf(format, args...)}
+}
+
+// stopScript ends the execution with err. The interrupt cannot be caught by
+// the script, so it fires even inside try/catch; abort covers a script that is
+// idle, waiting on a promise.
+func stopScript(vm *goja.Runtime, async *asyncCalls, err *RuntimeError) {
+	vm.Interrupt(err)
+	if async != nil {
+		async.abort(err)
+	}
+}
+
+// failCall throws the error of a failed tool call into the script; a tripped
+// budget ends the script instead
+func failCall(vm *goja.Runtime, err error) {
+	var limitErr *RuntimeError
+	if errors.As(err, &limitErr) {
+		stopScript(vm, nil, limitErr)
+	}
+	panic(vm.NewGoError(err))
+}
+
+// toolBudget counts the tool calls of an execution and the bytes they return
+type toolBudget struct {
+	maxCalls int
+	maxBytes int64
+
+	mu    sync.Mutex
+	calls int
+	bytes int64
+}
+
+// startCall takes a tool call from the budget
+func (b *toolBudget) startCall() *RuntimeError {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.calls >= b.maxCalls {
+		return limitError(ErrorTypeToolCallLimit, "script exceeded the limit of %d tool calls", b.maxCalls)
+	}
+	b.calls++
+	return nil
+}
+
+// addResult takes the size of a tool result from the budget
+func (b *toolBudget) addResult(result *mcp.CallToolResult) *RuntimeError {
+	size := resultSize(result)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.bytes += size
+	if b.bytes > b.maxBytes {
+		return limitError(ErrorTypeToolResultLimit, "tool results exceeded the limit of %d bytes", b.maxBytes)
+	}
+	return nil
+}
+
+// resultSize approximates the bytes a tool result hands the script: text,
+// binary data and structured content
+func resultSize(result *mcp.CallToolResult) int64 {
+	if result == nil {
+		return 0
+	}
+	var size int64
+	for _, content := range result.Content {
+		switch c := content.(type) {
+		case *mcp.TextContent:
+			size += int64(len(c.Text))
+		case *mcp.ImageContent:
+			size += int64(len(c.Data))
+		case *mcp.AudioContent:
+			size += int64(len(c.Data))
+		case *mcp.EmbeddedResource:
+			if c.Resource != nil {
+				size += int64(len(c.Resource.Text) + len(c.Resource.Blob))
+			}
+		}
+	}
+	if result.StructuredContent != nil {
+		if data, err := json.Marshal(result.StructuredContent); err == nil {
+			size += int64(len(data))
+		}
+	}
+	return size
+}
+
+// heapBytes samples the bytes held by heap objects
+func heapBytes() int64 {
+	sample := []metrics.Sample{{Name: heapMetric}}
+	metrics.Read(sample)
+	if sample[0].Value.Kind() != metrics.KindUint64 {
+		return 0
+	}
+	return int64(sample[0].Value.Uint64())
+}
+
+// lastGC is the last garbage collection forced to confirm heap growth, shared
+// by every execution so concurrent scripts don't each stop the world
+var lastGC struct {
+	sync.Mutex
+	at   time.Time
+	heap int64 // bytes held by heap objects right after the collection
+}
+
+// collectedHeapBytes returns the bytes held by heap objects after a garbage
+// collection. A collection is forced at most once per memoryGCInterval; in
+// between, the heap measured after the last one is used unless the heap has
+// shrunk since.
+func collectedHeapBytes() int64 {
+	lastGC.Lock()
+	defer lastGC.Unlock()
+	if time.Since(lastGC.at) >= memoryGCInterval {
+		runtime.GC()
+		lastGC.at = time.Now()
+		lastGC.heap = heapBytes()
+		return lastGC.heap
+	}
+	return min(lastGC.heap, heapBytes())
+}
+
+// runningScripts counts executions in progress, so memory checks can tell
+// whether heap growth belongs to a single script
+var runningScripts atomic.Int32
+
+// watchMemory samples heap growth until done is closed, calling exceeded
+// once growth beyond the baseline passes max. The heap is process-wide, so
+// growth is confirmed after a garbage collection before the script is blamed,
+// and the check is skipped while other executions run; growth is then
+// measured from the heap at the time the script was last running alone.
+func watchMemory(baseline, max int64, done <-chan struct{}, exceeded func()) {
+	ticker := time.NewTicker(memorySampleInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+		if runningScripts.Load() > 1 {
+			baseline = heapBytes()
+			continue
+		}
+		if heapBytes()-baseline <= max {
+			continue
+		}
+		if collectedHeapBytes()-baseline > max {
+			exceeded()
+			return
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package js
+
+import (
+	"context"
+	"runtime/metrics"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/vaayne/mcphub/internal/logging"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// requireLimit asserts that err is a RuntimeError of the given type
+func requireLimit(t *testing.T, err error, errType ErrorType) {
+	t.Helper()
+	require.Error(t, err)
+	runtimeErr, ok := err.(*RuntimeError)
+	require.True(t, ok, "error %v is not a RuntimeError", err)
+	assert.Equal(t, errType, runtimeErr.Type, runtimeErr.Message)
+}
+
+// TestNewRuntime_DefaultLimits verifies unset budgets fall back to defaults
+func TestNewRuntime_DefaultLimits(t *testing.T) {
+	runtime := NewRuntime(logging.NopLogger(), nil, &Config{MaxToolCalls: 5})
+	assert.Equal(t, int64(DefaultMaxMemory), runtime.maxMemory)
+	assert.Equal(t, DefaultMaxCallDepth, runtime.maxCallDepth)
+	assert.Equal(t, 5, runtime.maxToolCalls)
+	assert.Equal(t, int64(DefaultMaxToolResultBytes), runtime.maxToolResultBytes)
+}
+
+// TestExecute_MemoryLimit verifies runaway allocation ends the script, even
+// inside try/catch
+func TestExecute_MemoryLimit(t *testing.T) {
+	runtime := NewRuntime(logging.NopLogger(), nil, &Config{
+		MaxMemory: 32 << 20,
+		Timeout:   10 * time.Second,
+	})
+
+	start := time.Now()
+	_, _, err := runtime.Execute(context.Background(), `
+		const hoard = [];
+		try {
+			while (true) hoard.push(new Array(1000).fill("leak"));
+		} catch (e) {
+			"swallowed";
+		}
+	`)
+	requireLimit(t, err, ErrorTypeMemoryLimit)
+	assert.Less(t, time.Since(start), 5*time.Second, "memory limit should trip before the timeout")
+}
+
+// TestCollectedHeapBytes_RateLimitsGC verifies concurrent memory checks force
+// at most one garbage collection per interval between them
+func TestCollectedHeapBytes_RateLimitsGC(t *testing.T) {
+	forcedGCs := func() uint64 {
+		sample := []metrics.Sample{{Name: "/gc/cycles/forced:gc-cycles"}}
+		metrics.Read(sample)
+		return sample[0].Value.Uint64()
+	}
+	lastGC.Lock()
+	lastGC.at = time.Time{}
+	lastGC.Unlock()
+
+	before := forcedGCs()
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Go(func() {
+			assert.Positive(t, collectedHeapBytes())
+		})
+	}
+	wg.Wait()
+	assert.Equal(t, uint64(1), forcedGCs()-before)
+}
+
+// TestWatchMemory_SkipsConcurrentScripts verifies heap growth isn't blamed on
+// a script while other executions run
+func TestWatchMemory_SkipsConcurrentScripts(t *testing.T) {
+	watch := func() bool {
+		var exceeded atomic.Bool
+		done := make(chan struct{})
+		go watchMemory(heapBytes()-(1<<30), 1<<20, done, func() { exceeded.Store(true) })
+		time.Sleep(10 * memorySampleInterval)
+		close(done)
+		return exceeded.Load()
+	}
+
+	runningScripts.Add(1)
+	assert.True(t, watch(), "growth of a script running alone should trip the limit")
+
+	runningScripts.Add(1)
+	assert.False(t, watch(), "growth while another script runs should be ignored")
+	runningScripts.Add(-2)
+}
+
+// TestExecute_CallDepthLimit verifies unbounded recursion reports the call
+// depth limit
+func TestExecute_CallDepthLimit(t *testing.T) {
+	runtime := NewRuntime(logging.NopLogger(), nil, &Config{MaxCallDepth: 100})
+
+	_, _, err := runtime.Execute(context.Background(), `
+		function down(n) { return down(n + 1); }
+		try { down(0); } catch (e) { "swallowed"; }
+	`)
+	requireLimit(t, err, ErrorTypeCallDepthLimit)
+
+	// Recursion within the limit is fine
+	result, _, err := runtime.Execute(context.Background(), `
+		function depth(n) { return n === 0 ? 0 : 1 + depth(n - 1); }
+		depth(50);
+	`)
+	require.NoError(t, err)
+	assert.Equal(t, int64(50), result)
+}
+
+// TestExecute_ToolCallLimit verifies the number of tool calls is capped for
+// sync and async calls, and the limit can't be caught
+func TestExecute_ToolCallLimit(t *testing.T) {
+	runtime := NewRuntime(logging.NopLogger(), newRichCaller(), &Config{MaxToolCalls: 3})
+
+	result, _, err := runtime.Execute(context.Background(), `
+		[1, 2, 3].map(() => mcp.callTool("srvJson", {}).a);
+	`)
+	require.NoError(t, err)
+	assert.Equal(t, []any{int64(1), int64(1), int64(1)}, result)
+
+	scripts := map[string]string{
+		"sync": `
+			for (let i = 0; i < 10; i++) {
+				try { mcp.callTool("srvJson", {}); } catch (e) {}
+			}`,
+		"proxy": `for (let i = 0; i < 10; i++) mcp.tools.srvJson({});`,
+		"async": `
+			(async () => {
+				const calls = [];
+				for (let i = 0; i < 10; i++) calls.push(mcp.callToolAsync("srvJson", {}).catch(() => null));
+				return await Promise.all(calls);
+			})()`,
+	}
+	for name, script := range scripts {
+		t.Run(name, func(t *testing.T) {
+			_, _, err := runtime.Execute(context.Background(), script)
+			requireLimit(t, err, ErrorTypeToolCallLimit)
+		})
+	}
+}
+
+// TestExecute_ToolResultLimit verifies the total size of tool results is capped
+func TestExecute_ToolResultLimit(t *testing.T) {
+	caller := &fakeCaller{results: map[string]*mcp.CallToolResult{
+		"srv__big": {Content: []mcp.Content{&mcp.TextContent{Text: string(make([]byte, 400))}}},
+	}}
+	runtime := NewRuntime(logging.NopLogger(), caller, &Config{MaxToolResultBytes: 1000})
+
+	_, _, err := runtime.Execute(context.Background(), `
+		mcp.callTool("srvBig", {});
+		mcp.callTool("srvBig", {});
+		"two calls fit";
+	`)
+	require.NoError(t, err)
+
+	_, _, err = runtime.Execute(context.Background(), `
+		for (let i = 0; i < 3; i++) mcp.callTool("srvBig", {});
+	`)
+	requireLimit(t, err, ErrorTypeToolResultLimit)
+}
+
+// TestResultSize verifies the size of each kind of content is counted
+func TestResultSize(t *testing.T) {
+	assert.Equal(t, int64(0), resultSize(nil))
+	assert.Equal(t, int64(24), resultSize(&mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: "text"},
+			&mcp.ImageContent{Data: []byte("image"), MIMEType: "image/png"},
+			&mcp.AudioContent{Data: []byte("audio"), MIMEType: "audio/wav"},
+			&mcp.EmbeddedResource{Resource: &mcp.ResourceContents{URI: "file:///a", Text: "res"}},
+		},
+		StructuredContent: map[string]any{"a": 1},
+	}))
+}
+
+// TestExecute_ConfiguredLimits verifies script size, log and tool settings
+// replace the defaults
+func TestExecute_ConfiguredLimits(t *testing.T) {
+	runtime := NewRuntime(logging.NopLogger(), newRichCaller(), &Config{
+		MaxScriptSize: 100,
+		MaxLogEntries: 2,
+		AllowedTools:  map[string][]string{"srv": {"j*"}},
+	})
+
+	_, _, err := runtime.Execute(context.Background(), "// "+string(make([]byte, 100)))
+	requireLimit(t, err, ErrorTypeValidation)
+
+	result, logs, err := runtime.Execute(context.Background(), `
+		for (let i = 0; i < 5; i++) console.log(i);
+		mcp.callTool("srvJson", {}).a;
+	`)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), result)
+	assert.Len(t, logs, 2)
+
+	_, _, err = runtime.Execute(context.Background(), `mcp.callTool("srvEmpty", {})`)
+	assert.ErrorContains(t, err, "not authorized")
+}
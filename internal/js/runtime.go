@@ -3,8 +3,10 @@ package js
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
+	"path"
 	"regexp"
 	"slices"
 	"strings"
@@ -18,18 +20,43 @@ import (
 	_ "github.com/dop251/goja_nodejs/url"
 	_ "github.com/dop251/goja_nodejs/util"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/vaayne/mcphub/internal/config"
+	"github.com/vaayne/mcphub/internal/schema"
 	"github.com/vaayne/mcphub/internal/toolname"
 )
 
 const (
 	// DefaultTimeout is the default timeout for JS execution
 	DefaultTimeout = 15 * time.Second
-	// MaxScriptSize is the maximum script size in bytes
+	// MaxScriptSize is the default maximum script size in bytes
 	MaxScriptSize = 100 * 1024 // 100KB
-	// MaxLogEntries is the maximum number of log entries allowed
+	// MaxLogEntries is the default maximum number of log entries kept
 	MaxLogEntries = 1000
+	// DefaultMaxConcurrency is the default number of mcp.callToolAsync calls
+	// a script may have in flight at once
+	DefaultMaxConcurrency = 4
+	// MaxConcurrencyLimit is the highest allowed MaxConcurrency
+	MaxConcurrencyLimit = 16
 )
 
+// Script languages
+const (
+	LanguageJavaScript = "javascript"
+	LanguageTypeScript = "typescript"
+)
+
+// ParseLanguage returns the script language named by name: "javascript" or
+// "js", "typescript" or "ts". An empty name is JavaScript.
+func ParseLanguage(name string) (string, error) {
+	switch strings.ToLower(name) {
+	case "", "js", LanguageJavaScript:
+		return LanguageJavaScript, nil
+	case "ts", LanguageTypeScript:
+		return LanguageTypeScript, nil
+	}
+	return "", fmt.Errorf("unknown language %q (expected %s or %s)", name, LanguageJavaScript, LanguageTypeScript)
+}
+
 // ErrorType represents the type of runtime error
 type ErrorType string
 
@@ -39,6 +66,12 @@ const (
 	ErrorTypeRuntime    ErrorType = "runtime_error"
 	ErrorTypeValidation ErrorType = "validation_error"
 	ErrorTypeAsync      ErrorType = "async_not_allowed"
+
+	// Resource budgets; each tripped budget ends the script with its own type
+	ErrorTypeMemoryLimit     ErrorType = "memory_limit"
+	ErrorTypeCallDepthLimit  ErrorType = "call_depth_limit"
+	ErrorTypeToolCallLimit   ErrorType = "tool_call_limit"
+	ErrorTypeToolResultLimit ErrorType = "tool_result_limit"
 )
 
 // RuntimeError represents a structured runtime error
@@ -84,8 +117,28 @@ func NewManagerCaller(getter SessionGetter) *ManagerCaller {
 	return &ManagerCaller{getter: getter}
 }
 
+// backendCaller is implemented by SessionGetters with their own tool call path
+// (client.Manager applies its response cache there)
+type backendCaller interface {
+	CallTool(ctx context.Context, serverID, toolName string, args map[string]any) (*mcp.CallToolResult, error)
+}
+
+// serverChecker is implemented by SessionGetters that can look up a server
+// without picking one of its replicas
+type serverChecker interface {
+	HasServer(serverID string) bool
+}
+
 // CallTool implements ToolCaller for ManagerCaller
 func (m *ManagerCaller) CallTool(ctx context.Context, serverID, toolName string, params map[string]any) (*mcp.CallToolResult, error) {
+	// The backend picks the replica itself, so only check the server exists
+	if backend, ok := m.getter.(backendCaller); ok {
+		if checker, ok := m.getter.(serverChecker); ok && !checker.HasServer(serverID) {
+			return nil, fmt.Errorf("server '%s' not found", serverID)
+		}
+		return backend.CallTool(ctx, serverID, toolName, params)
+	}
+
 	session, err := m.getter.GetClient(serverID)
 	if err != nil {
 		return nil, fmt.Errorf("server '%s' not found", serverID)
@@ -99,79 +152,296 @@ func (m *ManagerCaller) CallTool(ctx context.Context, serverID, toolName string,
 	return session.CallTool(ctx, toolParams)
 }
 
+// ResolveTool implements toolname.Resolver, deferring to the SessionGetter
+// when it implements it
+func (m *ManagerCaller) ResolveTool(name string) (serverID, toolName string, ok bool) {
+	if resolver, isResolver := m.getter.(toolname.Resolver); isResolver {
+		return resolver.ResolveTool(name)
+	}
+	return "", "", false
+}
+
 // ListTools implements ToolCaller for ManagerCaller
 func (m *ManagerCaller) ListTools(ctx context.Context) ([]*mcp.Tool, error) {
 	allTools := m.getter.GetAllTools()
 	tools := make([]*mcp.Tool, 0, len(allTools))
 	for namespacedName, tool := range allTools {
-		tools = append(tools, &mcp.Tool{
-			Name:        namespacedName,
-			Description: tool.Description,
-			InputSchema: tool.InputSchema,
-		})
+		t := *tool
+		t.Name = namespacedName
+		tools = append(tools, &t)
 	}
 	return tools, nil
 }
 
+// ValidatesArgs reports whether tool arguments for a server are validated,
+// deferring to the SessionGetter when it implements schema.Policy
+func (m *ManagerCaller) ValidatesArgs(serverID string) bool {
+	return schema.ValidationEnabled(m.getter, serverID)
+}
+
+// CoercesArgs reports whether tool arguments for a server are coerced,
+// deferring to the SessionGetter when it implements schema.CoercionPolicy
+func (m *ManagerCaller) CoercesArgs(serverID string) bool {
+	return schema.CoercionEnabled(m.getter, serverID)
+}
+
+// toolCatalog holds the tools known when an execution starts, and the budget
+// the execution has for calling them
+type toolCatalog struct {
+	tools   []*mcp.Tool // sorted by name
+	mapper  *toolname.Mapper
+	schemas map[toolname.Ref]any    // resolved tool -> inputSchema
+	names   map[toolname.Ref]string // resolved tool -> name it is listed under
+	budget  *toolBudget
+	trace   *tracer // nil unless tracing
+}
+
+// qualifiedName returns the name the given tool is listed under. Tools missing
+// from the listing get the default serverID__toolName; tools of single-server
+// callers keep their own name.
+func (c *toolCatalog) qualifiedName(serverID, toolName string) string {
+	if name, ok := c.names[toolname.Ref{ServerID: serverID, ToolName: toolName}]; ok {
+		return name
+	}
+	if serverID == "" {
+		return toolName
+	}
+	return (&config.MCPServer{}).QualifiedToolName(serverID, toolName)
+}
+
+// inputSchema returns the inputSchema of the given tool, if known
+func (c *toolCatalog) inputSchema(serverID, toolName string) (any, bool) {
+	inputSchema, ok := c.schemas[toolname.Ref{ServerID: serverID, ToolName: toolName}]
+	return inputSchema, ok
+}
+
+// coerce adjusts params to match the inputSchema of the given tool, if known
+func (c *toolCatalog) coerce(caller ToolCaller, serverID, toolName string, params map[string]any) (map[string]any, []schema.Coercion) {
+	inputSchema, ok := c.inputSchema(serverID, toolName)
+	if !ok || !schema.CoercionEnabled(caller, serverID) {
+		return params, nil
+	}
+	return schema.Coerce(inputSchema, params)
+}
+
+// validate checks params against the inputSchema of the given tool, if known
+func (c *toolCatalog) validate(caller ToolCaller, serverID, toolName string, params map[string]any) error {
+	inputSchema, ok := c.inputSchema(serverID, toolName)
+	if !ok || !schema.ValidationEnabled(caller, serverID) {
+		return nil
+	}
+	return schema.Validate(inputSchema, params)
+}
+
 // Runtime represents a JavaScript runtime for executing tool scripts
 type Runtime struct {
-	logger       *slog.Logger
-	caller       ToolCaller
-	timeout      time.Duration
-	allowedTools map[string][]string // nil = allow all
+	logger          *slog.Logger
+	caller          ToolCaller
+	timeout         time.Duration
+	allowedTools    map[string][]string // nil = allow all
+	allowedModules  []string            // node:* built-ins require may load, nil = all
+	maxConcurrency  int                 // async tool calls in flight per execution
+	maxScriptSize   int                 // bytes, of scripts and library modules
+	maxLogEntries   int
+	language        string
+	library         string         // script library directory ("" = no library modules)
+	params          map[string]any // the params global, nil = not defined
+	state           StateStore     // mcp.state, nil = not available
+	persistentState StateStore     // mcp.state.persistent, nil = not enabled
+	fetch           *FetchPolicy   // mcp.fetch, nil = no network access
+	trace           bool
+
+	// Resource budgets per execution
+	maxMemory          int64 // heap growth in bytes
+	maxCallDepth       int
+	maxToolCalls       int
+	maxToolResultBytes int64
 }
 
 // Config holds runtime configuration
 type Config struct {
-	Timeout      time.Duration
-	AllowedTools map[string][]string // map[serverID][]toolNames, nil = allow all
+	Timeout         time.Duration
+	AllowedTools    map[string][]string // map[serverID][]tool name globs, nil = allow all
+	AllowedModules  []string            // node:* built-ins require may load, without the prefix; nil = all
+	MaxConcurrency  int                 // async tool calls in flight per execution (default 4, max 16)
+	MaxScriptSize   int                 // bytes, of scripts and library modules (default 100KB)
+	MaxLogEntries   int                 // log entries kept per execution (default 1000)
+	Language        string              // script language: LanguageJavaScript (default) or LanguageTypeScript
+	Library         string              // script library directory; require("lib/name") loads its modules
+	Params          map[string]any      // exposed to the script as the params global (nil = not defined)
+	State           StateStore          // mcp.state: the namespace of the calling session (nil = not available)
+	PersistentState StateStore          // mcp.state.persistent: shared and kept on disk (nil = not enabled)
+	Fetch           *FetchPolicy        // hosts mcp.fetch may reach (nil = no network access)
+	Trace           bool                // record tool calls and the failure stack (see ExecuteWithTrace)
+
+	// Resource budgets per execution (0 = default)
+	MaxMemory          int64 // heap growth in bytes, sampled process-wide and only checked while one execution runs (default 256MB)
+	MaxCallDepth       int   // JS call stack depth (default 10000)
+	MaxToolCalls       int   // tool calls, sync and async (default 1000)
+	MaxToolResultBytes int64 // total size of tool results (default 50MB)
 }
 
 // NewRuntime creates a new JavaScript runtime
 func NewRuntime(logger *slog.Logger, caller ToolCaller, cfg *Config) *Runtime {
 	timeout := DefaultTimeout
+	maxConcurrency := DefaultMaxConcurrency
+	language := LanguageJavaScript
+	maxScriptSize := MaxScriptSize
+	maxLogEntries := MaxLogEntries
 	var allowedTools map[string][]string
+	var allowedModules []string
+	var library string
+	var params map[string]any
+	var state, persistentState StateStore
+	var fetch *FetchPolicy
+	var trace bool
+	maxMemory := int64(DefaultMaxMemory)
+	maxCallDepth := DefaultMaxCallDepth
+	maxToolCalls := DefaultMaxToolCalls
+	maxToolResultBytes := int64(DefaultMaxToolResultBytes)
 
 	if cfg != nil {
 		if cfg.Timeout > 0 {
 			timeout = cfg.Timeout
 		}
+		if cfg.MaxConcurrency > 0 {
+			maxConcurrency = min(cfg.MaxConcurrency, MaxConcurrencyLimit)
+		}
+		if cfg.Language != "" {
+			language = cfg.Language
+		}
+		if cfg.MaxScriptSize > 0 {
+			maxScriptSize = cfg.MaxScriptSize
+		}
+		if cfg.MaxLogEntries > 0 {
+			maxLogEntries = cfg.MaxLogEntries
+		}
 		allowedTools = cfg.AllowedTools
+		allowedModules = cfg.AllowedModules
+		library = cfg.Library
+		params = cfg.Params
+		state = cfg.State
+		persistentState = cfg.PersistentState
+		fetch = cfg.Fetch
+		trace = cfg.Trace
+		if cfg.MaxMemory > 0 {
+			maxMemory = cfg.MaxMemory
+		}
+		if cfg.MaxCallDepth > 0 {
+			maxCallDepth = cfg.MaxCallDepth
+		}
+		if cfg.MaxToolCalls > 0 {
+			maxToolCalls = cfg.MaxToolCalls
+		}
+		if cfg.MaxToolResultBytes > 0 {
+			maxToolResultBytes = cfg.MaxToolResultBytes
+		}
 	}
 
 	return &Runtime{
-		logger:       logger,
-		caller:       caller,
-		timeout:      timeout,
-		allowedTools: allowedTools,
+		logger:          logger,
+		caller:          caller,
+		timeout:         timeout,
+		allowedTools:    allowedTools,
+		allowedModules:  allowedModules,
+		maxConcurrency:  maxConcurrency,
+		maxScriptSize:   maxScriptSize,
+		maxLogEntries:   maxLogEntries,
+		language:        language,
+		library:         library,
+		params:          params,
+		state:           state,
+		persistentState: persistentState,
+		fetch:           fetch,
+		trace:           trace,
+
+		maxMemory:          maxMemory,
+		maxCallDepth:       maxCallDepth,
+		maxToolCalls:       maxToolCalls,
+		maxToolResultBytes: maxToolResultBytes,
 	}
 }
 
+// asyncCalls runs the tool calls of mcp.callToolAsync off the event loop,
+// handing their results back to it
+type asyncCalls struct {
+	loop  *eventloop.EventLoop
+	slots chan struct{}   // bounds the calls in flight
+	abort func(err error) // ends the execution when the VM can no longer take a result
+}
+
 // Execute executes a JavaScript script with sync-only enforcement
 func (r *Runtime) Execute(ctx context.Context, script string) (any, []LogEntry, error) {
+	result, logs, _, err := r.ExecuteWithTrace(ctx, script)
+	return result, logs, err
+}
+
+// ExecuteWithTrace executes a script like Execute. When the runtime traces,
+// it also returns the tool calls the script made and, if it failed, the JS
+// stack at the failure, with positions in the script's own source.
+func (r *Runtime) ExecuteWithTrace(ctx context.Context, script string) (any, []LogEntry, *Trace, error) {
 	// Validate script size
-	if len(script) > MaxScriptSize {
-		return nil, nil, &RuntimeError{
+	if len(script) > r.maxScriptSize {
+		return nil, nil, nil, &RuntimeError{
 			Type:    ErrorTypeValidation,
-			Message: fmt.Sprintf("script exceeds maximum size of %d bytes", MaxScriptSize),
+			Message: fmt.Sprintf("script exceeds maximum size of %d bytes", r.maxScriptSize),
 		}
 	}
 
+	// The trace quotes the source as written, before any types are removed
+	var trace *tracer
+	if r.trace {
+		trace = newTracer(script)
+	}
+
+	// TypeScript runs with its types removed; positions are kept, so errors
+	// still point at the right line
+	if r.language == LanguageTypeScript {
+		stripped, err := StripTypes(script)
+		if err != nil {
+			return nil, nil, trace.trace(), &RuntimeError{
+				Type:    ErrorTypeSyntax,
+				Message: sanitizeError("SyntaxError: " + err.Error()),
+			}
+		}
+		script = stripped
+	}
+
 	// Apply timeout
 	execCtx, cancel := context.WithTimeout(ctx, r.timeout)
 	defer cancel()
 
-	// Build tool name mapper for name resolution
-	var mapper *toolname.Mapper
+	// Build tool catalog for name resolution and argument validation
+	catalog := &toolCatalog{
+		schemas: make(map[toolname.Ref]any),
+		names:   make(map[toolname.Ref]string),
+		budget:  &toolBudget{maxCalls: r.maxToolCalls, maxBytes: r.maxToolResultBytes},
+		trace:   trace,
+	}
 	if r.caller != nil {
 		tools, err := r.caller.ListTools(execCtx)
 		if err == nil && len(tools) > 0 {
-			mapper = toolname.NewMapper(tools)
+			catalog.tools = slices.SortedFunc(slices.Values(tools), func(a, b *mcp.Tool) int {
+				return strings.Compare(a.Name, b.Name)
+			})
+			catalog.mapper = toolname.NewMapper(tools)
+			for _, tool := range tools {
+				ref := r.resolve(tool.Name)
+				catalog.names[ref] = tool.Name
+				if tool.InputSchema != nil {
+					catalog.schemas[ref] = tool.InputSchema
+				}
+			}
 		}
 	}
 
-	// Execute script with a Node-like event loop
-	loop := eventloop.NewEventLoop()
+	runningScripts.Add(1)
+	defer runningScripts.Add(-1)
+	heapBaseline := heapBytes()
+
+	// Execute script with a Node-like event loop; require only reaches
+	// node:* built-ins and library modules
+	loop := eventloop.NewEventLoop(eventloop.WithRegistry(r.newRegistry()))
 	loop.Start()
 
 	var (
@@ -188,39 +458,60 @@ func (r *Runtime) Execute(ctx context.Context, script string) (any, []LogEntry,
 	stopLoop := sync.Once{}
 	defer stopLoop.Do(func() { loop.Stop() })
 
-	signalReady := func() {
+	// finish records the outcome of the execution; the first outcome wins
+	finish := func(value any, err error) {
 		readyOnce.Do(func() {
+			result, runErr = value, err
 			close(resultCh)
 		})
 	}
 
+	async := &asyncCalls{
+		loop:  loop,
+		slots: make(chan struct{}, r.maxConcurrency),
+		abort: func(err error) { finish(nil, err) },
+	}
+
 	loop.RunOnLoop(func(vm *goja.Runtime) {
 		vmPtr = vm
+		vm.SetMaxCallStackSize(r.maxCallDepth)
 		close(vmReady)
 
-		if err := r.injectMCPHelpers(execCtx, vm, &logs, &logsMu, mapper); err != nil {
-			runErr = err
-			signalReady()
+		if err := r.injectMCPHelpers(execCtx, vm, &logs, &logsMu, catalog, async); err != nil {
+			finish(nil, err)
+			return
+		}
+		if err := r.restrictModules(vm); err != nil {
+			finish(nil, err)
 			return
 		}
+		if r.params != nil {
+			if err := vm.Set("params", r.params); err != nil {
+				finish(nil, &RuntimeError{
+					Type:    ErrorTypeRuntime,
+					Message: fmt.Sprintf("failed to setup params: %v", err),
+				})
+				return
+			}
+		}
 
 		defer func() {
 			if caught := recover(); caught != nil {
 				if interrupted, ok := caught.(*goja.InterruptedError); ok {
-					runErr = fmt.Errorf("execution interrupted: %v", interrupted)
+					finish(nil, fmt.Errorf("execution interrupted: %w", interrupted))
+				} else if overflow, ok := caught.(*goja.StackOverflowError); ok {
+					finish(nil, overflow)
 				} else if val, ok := caught.(goja.Value); ok {
-					runErr = fmt.Errorf("%v", val)
+					finish(nil, fmt.Errorf("%v", val))
 				} else {
-					runErr = fmt.Errorf("runtime error: %v", caught)
+					finish(nil, fmt.Errorf("runtime error: %v", caught))
 				}
-				signalReady()
 			}
 		}()
 
 		res, err := vm.RunString(script)
 		if err != nil {
-			runErr = err
-			signalReady()
+			finish(nil, err)
 			return
 		}
 
@@ -228,23 +519,14 @@ func (r *Runtime) Execute(ctx context.Context, script string) (any, []LogEntry,
 		if promise, ok := res.Export().(*goja.Promise); ok && promise.State() == goja.PromiseStatePending {
 			thenVal := res.ToObject(vm).Get("then")
 			if thenFunc, ok := goja.AssertFunction(thenVal); ok {
-				resolved := false
 				resolve := func(call goja.FunctionCall) goja.Value {
-					if resolved {
-						return goja.Undefined()
-					}
-					resolved = true
-					result = call.Argument(0).Export()
-					signalReady()
+					finish(exportResult(call.Argument(0)), nil)
 					return goja.Undefined()
 				}
 				reject := func(call goja.FunctionCall) goja.Value {
-					if resolved {
-						return goja.Undefined()
-					}
-					resolved = true
-					runErr = fmt.Errorf("%v", call.Argument(0))
-					signalReady()
+					err := fmt.Errorf("%v", call.Argument(0))
+					trace.fail(err, call.Argument(0))
+					finish(nil, err)
 					return goja.Undefined()
 				}
 				thenFunc(res, vm.ToValue(resolve), vm.ToValue(reject))
@@ -256,19 +538,28 @@ func (r *Runtime) Execute(ctx context.Context, script string) (any, []LogEntry,
 		if promise, ok := res.Export().(*goja.Promise); ok {
 			switch promise.State() {
 			case goja.PromiseStateFulfilled:
-				result = promise.Result().Export()
+				finish(exportResult(promise.Result()), nil)
 			case goja.PromiseStateRejected:
-				runErr = fmt.Errorf("%v", promise.Result())
+				err := fmt.Errorf("%v", promise.Result())
+				trace.fail(err, promise.Result())
+				finish(nil, err)
 			default:
-				result = res.Export()
+				finish(exportResult(res), nil)
 			}
 		} else {
-			result = res.Export()
+			finish(exportResult(res), nil)
 		}
-
-		signalReady()
 	})
 
+	// Sample heap growth while the script runs
+	go func() {
+		<-vmReady
+		watchMemory(heapBaseline, r.maxMemory, resultCh, func() {
+			stopScript(vmPtr, async, limitError(ErrorTypeMemoryLimit,
+				"script exceeded the memory limit of %d bytes", r.maxMemory))
+		})
+	}()
+
 	// Monitor for timeout/cancellation and interrupt the VM if needed
 	go func() {
 		select {
@@ -288,29 +579,38 @@ func (r *Runtime) Execute(ctx context.Context, script string) (any, []LogEntry,
 	}
 
 	if runErr != nil {
-		return nil, logs, r.mapError(runErr)
+		trace.fail(runErr, nil)
+		return nil, logs, trace.trace(), r.mapError(runErr)
 	}
 
 	if execCtx.Err() == context.DeadlineExceeded {
-		return nil, logs, &RuntimeError{
+		return nil, logs, trace.trace(), &RuntimeError{
 			Type:    ErrorTypeTimeout,
 			Message: fmt.Sprintf("script execution exceeded timeout of %v", r.timeout),
 		}
 	}
 
 	if execCtx.Err() != nil {
-		return nil, logs, &RuntimeError{
+		return nil, logs, trace.trace(), &RuntimeError{
 			Type:    ErrorTypeRuntime,
 			Message: "script execution cancelled",
 		}
 	}
 
-	return result, logs, nil
+	return result, logs, trace.trace(), nil
 }
 
 // injectMCPHelpers wires mcp helpers and console log capture into the VM
-func (r *Runtime) injectMCPHelpers(ctx context.Context, vm *goja.Runtime, logs *[]LogEntry, logsMu *sync.Mutex, mapper *toolname.Mapper) error {
-	// Store logs
+func (r *Runtime) injectMCPHelpers(ctx context.Context, vm *goja.Runtime, logs *[]LogEntry, logsMu *sync.Mutex, catalog *toolCatalog, async *asyncCalls) error {
+	// appendLog records a log entry produced by the runtime itself
+	appendLog := func(entry LogEntry) {
+		logsMu.Lock()
+		defer logsMu.Unlock()
+		if len(*logs) < r.maxLogEntries {
+			*logs = append(*logs, entry)
+		}
+	}
+
 	// Setup mcp helpers
 	mcpObj := vm.NewObject()
 
@@ -318,55 +618,122 @@ func (r *Runtime) injectMCPHelpers(ctx context.Context, vm *goja.Runtime, logs *
 	// - JS name: "serverIdToolName" (camelCase)
 	// - Original name: "serverID__toolName"
 	// - Single-server mode: "toolName"
+	// A single text result is returned as parsed JSON or string; anything
+	// richer is returned as a result object (see mcp.callToolRaw)
 	if err := mcpObj.Set("callTool", func(call goja.FunctionCall) goja.Value {
-		// Check context cancellation
-		select {
-		case <-ctx.Done():
-			panic(vm.NewGoError(fmt.Errorf("execution cancelled")))
-		default:
+		result := r.callFromJS(ctx, vm, catalog, appendLog, "mcp.callTool", call)
+		value, err := r.toScriptValue(vm, result)
+		if err != nil {
+			panic(vm.NewGoError(err))
 		}
-
-		if len(call.Arguments) != 2 {
-			panic(vm.NewTypeError("mcp.callTool requires 2 arguments: toolName (e.g., 'serverTool' or 'server__tool'), params"))
+		return value
+	}); err != nil {
+		return &RuntimeError{
+			Type:    ErrorTypeRuntime,
+			Message: fmt.Sprintf("failed to setup callTool: %v", err),
 		}
+	}
 
-		inputName := call.Argument(0).String()
-		params := call.Argument(1).Export()
-
-		// Resolve tool name using mapper
-		var serverID, toolName string
-		resolvedName := inputName
+	// mcp.callToolRaw(toolName, params) - always returns the full result object:
+	// {content, isError, structuredContent, text(), images(), audio(), resources()}
+	if err := mcpObj.Set("callToolRaw", func(call goja.FunctionCall) goja.Value {
+		result := r.callFromJS(ctx, vm, catalog, appendLog, "mcp.callToolRaw", call)
+		value, err := newResultObject(vm, result)
+		if err != nil {
+			panic(vm.NewGoError(err))
+		}
+		return value
+	}); err != nil {
+		return &RuntimeError{
+			Type:    ErrorTypeRuntime,
+			Message: fmt.Sprintf("failed to setup callToolRaw: %v", err),
+		}
+	}
 
-		// Try to resolve using mapper first
-		if mapper != nil {
-			if original, found := mapper.Resolve(inputName); found {
-				resolvedName = original
-			}
+	// mcp.callToolAsync(toolName, params) and mcp.callToolRawAsync(toolName, params)
+	// return a Promise for the value of mcp.callTool or mcp.callToolRaw. The call
+	// runs in the background, so Promise.all over several calls runs them in
+	// parallel, up to the runtime's max concurrency.
+	if err := mcpObj.Set("callToolAsync", func(call goja.FunctionCall) goja.Value {
+		return r.callAsyncFromJS(ctx, vm, catalog, appendLog, async, "mcp.callToolAsync", call,
+			func(result *mcp.CallToolResult) (goja.Value, error) { return r.toScriptValue(vm, result) })
+	}); err != nil {
+		return &RuntimeError{
+			Type:    ErrorTypeRuntime,
+			Message: fmt.Sprintf("failed to setup callToolAsync: %v", err),
+		}
+	}
+	if err := mcpObj.Set("callToolRawAsync", func(call goja.FunctionCall) goja.Value {
+		return r.callAsyncFromJS(ctx, vm, catalog, appendLog, async, "mcp.callToolRawAsync", call,
+			func(result *mcp.CallToolResult) (goja.Value, error) { return newResultObject(vm, result) })
+	}); err != nil {
+		return &RuntimeError{
+			Type:    ErrorTypeRuntime,
+			Message: fmt.Sprintf("failed to setup callToolRawAsync: %v", err),
 		}
+	}
 
-		// Parse the resolved name
-		if s, t, ok := toolname.ParseNamespacedName(resolvedName); ok {
-			serverID = s
-			toolName = t
-		} else {
-			// Single-server mode: use tool name directly with empty serverID
-			serverID = ""
-			toolName = resolvedName
+	// mcp.tools.<jsName>(params) and mcp.servers.<serverID>.<jsToolName>(params)
+	// call listed tools without spelling out their names
+	toolsObj, serversObj, err := r.toolProxies(ctx, vm, catalog, appendLog)
+	if err != nil {
+		return &RuntimeError{
+			Type:    ErrorTypeRuntime,
+			Message: fmt.Sprintf("failed to setup tool proxies: %v", err),
 		}
+	}
+	if err := mcpObj.Set("tools", toolsObj); err != nil {
+		return &RuntimeError{Type: ErrorTypeRuntime, Message: "failed to setup mcp.tools"}
+	}
+	if err := mcpObj.Set("servers", serversObj); err != nil {
+		return &RuntimeError{Type: ErrorTypeRuntime, Message: "failed to setup mcp.servers"}
+	}
 
-		// Call the tool
-		result, err := r.callTool(ctx, serverID, toolName, params)
-		if err != nil {
-			panic(vm.NewGoError(err))
+	// mcp.state keeps values between executions
+	stateObj, err := r.stateObject(vm)
+	if err != nil {
+		return &RuntimeError{
+			Type:    ErrorTypeRuntime,
+			Message: fmt.Sprintf("failed to setup mcp.state: %v", err),
 		}
+	}
+	if err := mcpObj.Set("state", stateObj); err != nil {
+		return &RuntimeError{Type: ErrorTypeRuntime, Message: "failed to setup mcp.state"}
+	}
 
-		return vm.ToValue(result)
-	}); err != nil {
+	// mcp.fetch(url, options) and mcp.fetchAsync(url, options) reach the
+	// hosts of the fetch policy
+	fetchFn, fetchAsyncFn, err := r.fetchFunctions(ctx, vm, appendLog, async)
+	if err != nil {
 		return &RuntimeError{
 			Type:    ErrorTypeRuntime,
-			Message: fmt.Sprintf("failed to setup callTool: %v", err),
+			Message: fmt.Sprintf("failed to setup mcp.fetch: %v", err),
 		}
 	}
+	if err := mcpObj.Set("fetch", fetchFn); err != nil {
+		return &RuntimeError{Type: ErrorTypeRuntime, Message: "failed to setup mcp.fetch"}
+	}
+	if err := mcpObj.Set("fetchAsync", fetchAsyncFn); err != nil {
+		return &RuntimeError{Type: ErrorTypeRuntime, Message: "failed to setup mcp.fetchAsync"}
+	}
+
+	// mcp.image(data, mimeType), mcp.audio(data, mimeType) and mcp.resource({uri, ...})
+	// build content blocks that the exec tool returns as native MCP content
+	if err := mcpObj.Set("image", func(call goja.FunctionCall) goja.Value {
+		return binaryBlock(vm, contentTypeImage, call)
+	}); err != nil {
+		return &RuntimeError{Type: ErrorTypeRuntime, Message: "failed to setup mcp.image"}
+	}
+	if err := mcpObj.Set("audio", func(call goja.FunctionCall) goja.Value {
+		return binaryBlock(vm, contentTypeAudio, call)
+	}); err != nil {
+		return &RuntimeError{Type: ErrorTypeRuntime, Message: "failed to setup mcp.audio"}
+	}
+	if err := mcpObj.Set("resource", func(call goja.FunctionCall) goja.Value {
+		return resourceBlock(vm, call)
+	}); err != nil {
+		return &RuntimeError{Type: ErrorTypeRuntime, Message: "failed to setup mcp.resource"}
+	}
 
 	// mcp.log(level, message, fields?)
 	if err := mcpObj.Set("log", func(call goja.FunctionCall) goja.Value {
@@ -374,7 +741,7 @@ func (r *Runtime) injectMCPHelpers(ctx context.Context, vm *goja.Runtime, logs *
 		defer logsMu.Unlock()
 
 		// Enforce max log entries
-		if len(*logs) >= MaxLogEntries {
+		if len(*logs) >= r.maxLogEntries {
 			return goja.Undefined()
 		}
 
@@ -433,7 +800,7 @@ func (r *Runtime) injectMCPHelpers(ctx context.Context, vm *goja.Runtime, logs *
 			logsMu.Lock()
 			defer logsMu.Unlock()
 
-			if len(*logs) >= MaxLogEntries {
+			if len(*logs) >= r.maxLogEntries {
 				return goja.Undefined()
 			}
 
@@ -521,8 +888,249 @@ func sanitizeLogFields(fields map[string]any) map[string]any {
 	return sanitized
 }
 
-// callTool calls a proxied MCP tool
-func (r *Runtime) callTool(ctx context.Context, serverID, toolName string, params any) (any, error) {
+// callFromJS resolves the tool name from a JS call and invokes the tool,
+// panicking with a JS error on failure
+func (r *Runtime) callFromJS(ctx context.Context, vm *goja.Runtime, catalog *toolCatalog, appendLog func(LogEntry), fnName string, call goja.FunctionCall) *mcp.CallToolResult {
+	serverID, toolName, params := r.parseCall(ctx, vm, catalog, fnName, call)
+
+	// Call the tool
+	traced := catalog.trace.call(vm, fnName, catalog.qualifiedName(serverID, toolName), params)
+	result, coercions, err := r.callTool(ctx, catalog, serverID, toolName, params)
+	traced.end(catalog.trace, result, err)
+	if err != nil {
+		failCall(vm, err)
+	}
+
+	// Surface argument adjustments so the script author can fix the call
+	if len(coercions) > 0 {
+		appendLog(coercionLog(toolName, serverID, coercions))
+	}
+
+	return result
+}
+
+// callAsyncFromJS starts the tool call of a JS call in the background and
+// returns a Promise settled on the event loop with the value built by toValue
+func (r *Runtime) callAsyncFromJS(ctx context.Context, vm *goja.Runtime, catalog *toolCatalog, appendLog func(LogEntry), async *asyncCalls, fnName string, call goja.FunctionCall, toValue func(*mcp.CallToolResult) (goja.Value, error)) goja.Value {
+	serverID, toolName, params := r.parseCall(ctx, vm, catalog, fnName, call)
+	promise, resolve, reject := vm.NewPromise()
+	traced := catalog.trace.call(vm, fnName, catalog.qualifiedName(serverID, toolName), params)
+
+	go func() {
+		var (
+			result    *mcp.CallToolResult
+			coercions []schema.Coercion
+			err       error
+		)
+		select {
+		case async.slots <- struct{}{}:
+			traced.begin(catalog.trace)
+			result, coercions, err = r.callTool(ctx, catalog, serverID, toolName, params)
+			<-async.slots
+		case <-ctx.Done():
+			err = fmt.Errorf("execution cancelled")
+		}
+		traced.end(catalog.trace, result, err)
+
+		if len(coercions) > 0 {
+			appendLog(coercionLog(toolName, serverID, coercions))
+		}
+
+		var limitErr *RuntimeError
+		if errors.As(err, &limitErr) {
+			stopScript(vm, async, limitErr)
+			return
+		}
+
+		async.loop.RunOnLoop(func(vm *goja.Runtime) {
+			settle := func() error {
+				if err != nil {
+					return reject(vm.NewGoError(err))
+				}
+				value, convErr := toValue(result)
+				if convErr != nil {
+					return reject(vm.NewGoError(convErr))
+				}
+				return resolve(value)
+			}
+			// Settling runs the script's continuation; an error here is
+			// uncatchable (the VM was interrupted) and ends the execution
+			if settleErr := settle(); settleErr != nil {
+				async.abort(settleErr)
+			}
+		})
+	}()
+
+	return vm.ToValue(promise)
+}
+
+// toolProxies builds the mcp.tools and mcp.servers objects. Every listed tool
+// gets a method under its JS name in mcp.tools and, for tools of a named
+// server, under its JS tool name in mcp.servers[serverID]. Methods take the
+// params object, return what mcp.callTool would, and carry the tool's JSDoc
+// stub (as shown by inspect) in their doc property.
+func (r *Runtime) toolProxies(ctx context.Context, vm *goja.Runtime, catalog *toolCatalog, appendLog func(LogEntry)) (*goja.Object, *goja.Object, error) {
+	toolsObj := vm.NewObject()
+	serversObj := vm.NewObject()
+	servers := make(map[string]*goja.Object)
+
+	for _, tool := range catalog.tools {
+		jsName := catalog.mapper.ToJSName(tool.Name)
+		if catalog.mapper.ToOriginal(jsName) != tool.Name {
+			continue // another tool has the same JS name and mcp.callTool resolves to it
+		}
+		ref := r.resolve(tool.Name)
+		method, err := r.toolMethod(ctx, vm, catalog, appendLog, ref, jsName, tool)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := toolsObj.Set(jsName, method); err != nil {
+			return nil, nil, err
+		}
+
+		if ref.ServerID == "" {
+			continue
+		}
+		serverObj, ok := servers[ref.ServerID]
+		if !ok {
+			serverObj = vm.NewObject()
+			servers[ref.ServerID] = serverObj
+			if err := serversObj.Set(ref.ServerID, serverObj); err != nil {
+				return nil, nil, err
+			}
+		}
+		if err := serverObj.Set(toolname.ToJSName(ref.ToolName), method); err != nil {
+			return nil, nil, err
+		}
+	}
+	return toolsObj, serversObj, nil
+}
+
+// toolMethod builds the mcp.tools method calling one tool
+func (r *Runtime) toolMethod(ctx context.Context, vm *goja.Runtime, catalog *toolCatalog, appendLog func(LogEntry), ref toolname.Ref, jsName string, tool *mcp.Tool) (*goja.Object, error) {
+	method := vm.ToValue(func(call goja.FunctionCall) goja.Value {
+		select {
+		case <-ctx.Done():
+			panic(vm.NewGoError(fmt.Errorf("execution cancelled")))
+		default:
+		}
+		if len(call.Arguments) > 1 {
+			panic(vm.NewTypeError("mcp.tools." + jsName + " takes 1 argument: params"))
+		}
+
+		var params any
+		if arg := call.Argument(0); !goja.IsUndefined(arg) && !goja.IsNull(arg) {
+			params = arg.Export()
+		}
+
+		traced := catalog.trace.call(vm, "mcp.tools."+jsName, catalog.qualifiedName(ref.ServerID, ref.ToolName), params)
+		result, coercions, err := r.callTool(ctx, catalog, ref.ServerID, ref.ToolName, params)
+		traced.end(catalog.trace, result, err)
+		if err != nil {
+			failCall(vm, err)
+		}
+		if len(coercions) > 0 {
+			appendLog(coercionLog(ref.ToolName, ref.ServerID, coercions))
+		}
+
+		value, err := r.toScriptValue(vm, result)
+		if err != nil {
+			panic(vm.NewGoError(err))
+		}
+		return value
+	}).ToObject(vm)
+
+	inputSchema, _ := tool.InputSchema.(map[string]any)
+	if err := method.Set("doc", schema.JSDoc(jsName, tool.Description, inputSchema)); err != nil {
+		return nil, err
+	}
+	return method, nil
+}
+
+// parseCall checks the arguments of a JS tool call and resolves the tool,
+// panicking with a JS error on failure
+func (r *Runtime) parseCall(ctx context.Context, vm *goja.Runtime, catalog *toolCatalog, fnName string, call goja.FunctionCall) (serverID, toolName string, params any) {
+	// Check context cancellation
+	select {
+	case <-ctx.Done():
+		panic(vm.NewGoError(fmt.Errorf("execution cancelled")))
+	default:
+	}
+
+	if len(call.Arguments) != 2 {
+		panic(vm.NewTypeError(fnName + " requires 2 arguments: toolName (e.g., 'serverTool' or 'server__tool'), params"))
+	}
+
+	inputName := call.Argument(0).String()
+	params = call.Argument(1).Export()
+
+	// Resolve tool name using mapper
+	resolvedName := inputName
+
+	// Try to resolve using mapper first
+	if catalog.mapper != nil {
+		if original, found := catalog.mapper.Resolve(inputName); found {
+			resolvedName = original
+		}
+	}
+
+	// Split the resolved name into server and tool; in single-server mode the
+	// server ID is empty
+	ref := r.resolve(resolvedName)
+	return ref.ServerID, ref.ToolName, params
+}
+
+// toScriptValue converts a tool result into the value returned by mcp.callTool.
+// A single text block is parsed as JSON (falling back to the raw string), an
+// empty result is null, and anything else becomes a full result object.
+func (r *Runtime) toScriptValue(vm *goja.Runtime, result *mcp.CallToolResult) (goja.Value, error) {
+	if len(result.Content) == 0 {
+		return goja.Null(), nil
+	}
+
+	if len(result.Content) == 1 {
+		if content, ok := result.Content[0].(*mcp.TextContent); ok {
+			// Try to parse as JSON, otherwise return as string
+			var jsonResult any
+			if err := json.Unmarshal([]byte(content.Text), &jsonResult); err == nil {
+				return vm.ToValue(jsonResult), nil
+			}
+			return vm.ToValue(content.Text), nil
+		}
+	}
+
+	return newResultObject(vm, result)
+}
+
+// coercionLog builds the warning logged when a tool call's arguments were coerced
+func coercionLog(toolName, serverID string, coercions []schema.Coercion) LogEntry {
+	fullToolName := toolName
+	if serverID != "" {
+		fullToolName = serverID + "." + toolName
+	}
+	changes := make([]any, 0, len(coercions))
+	for _, c := range coercions {
+		changes = append(changes, c.String())
+	}
+	return LogEntry{
+		Level:   "warn",
+		Message: fmt.Sprintf("arguments for '%s' were adjusted to match the tool schema", fullToolName),
+		Fields:  map[string]any{"coercions": changes},
+	}
+}
+
+// resolve returns the server and tool of a listed tool name, asking the caller
+// when it implements toolname.Resolver. Names that aren't namespaced belong
+// to the single server of single-server callers.
+func (r *Runtime) resolve(name string) toolname.Ref {
+	if serverID, toolName, ok := toolname.Resolve(r.caller, name); ok {
+		return toolname.Ref{ServerID: serverID, ToolName: toolName}
+	}
+	return toolname.Ref{ToolName: name}
+}
+
+// callTool calls a proxied MCP tool, returning any coercions applied to params
+func (r *Runtime) callTool(ctx context.Context, catalog *toolCatalog, serverID, toolName string, params any) (*mcp.CallToolResult, []schema.Coercion, error) {
 	// Build display name for error messages
 	var fullToolName string
 	if serverID != "" {
@@ -533,7 +1141,7 @@ func (r *Runtime) callTool(ctx context.Context, serverID, toolName string, param
 
 	// Validate inputs
 	if toolName == "" {
-		return nil, fmt.Errorf("toolName is required")
+		return nil, nil, fmt.Errorf("toolName is required")
 	}
 
 	// Convert params to map for CallToolParams - do this BEFORE authorization/client checks
@@ -544,16 +1152,29 @@ func (r *Runtime) callTool(ctx context.Context, serverID, toolName string, param
 		paramsMap, ok = params.(map[string]any)
 		if !ok {
 			// Proper error for type mismatch instead of silent failure
-			return nil, fmt.Errorf("params must be an object, got %T", params)
+			return nil, nil, fmt.Errorf("params must be an object, got %T", params)
 		}
 	}
 
 	// Check tool authorization
 	if r.allowedTools != nil {
 		allowed, ok := r.allowedTools[serverID]
-		if !ok || !contains(allowed, toolName) {
-			return nil, fmt.Errorf("tool '%s' is not authorized", fullToolName)
+		if !ok || !matchesAny(allowed, toolName) {
+			return nil, nil, fmt.Errorf("tool '%s' is not authorized", fullToolName)
+		}
+	}
+
+	// Coerce and validate arguments against the tool's inputSchema
+	paramsMap, coercions := catalog.coerce(r.caller, serverID, toolName, paramsMap)
+	if err := catalog.validate(r.caller, serverID, toolName, paramsMap); err != nil {
+		if verr, ok := err.(*schema.ValidationError); ok {
+			verr.Tool = fullToolName
 		}
+		return nil, nil, err
+	}
+
+	if limitErr := catalog.budget.startCall(); limitErr != nil {
+		return nil, nil, limitErr
 	}
 
 	// Call tool via the ToolCaller interface
@@ -561,26 +1182,14 @@ func (r *Runtime) callTool(ctx context.Context, serverID, toolName string, param
 	if err != nil {
 		// Provide helpful error message with sanitized details
 		errMsg := sanitizeToolError(err)
-		return nil, fmt.Errorf("tool '%s' failed: %s", fullToolName, errMsg)
+		return nil, nil, fmt.Errorf("tool '%s' failed: %s", fullToolName, errMsg)
 	}
 
-	// Extract result from content
-	if len(result.Content) == 0 {
-		return nil, nil
+	if limitErr := catalog.budget.addResult(result); limitErr != nil {
+		return nil, nil, limitErr
 	}
 
-	// Return first content item
-	switch content := result.Content[0].(type) {
-	case *mcp.TextContent:
-		// Try to parse as JSON, otherwise return as string
-		var jsonResult any
-		if err := json.Unmarshal([]byte(content.Text), &jsonResult); err == nil {
-			return jsonResult, nil
-		}
-		return content.Text, nil
-	default:
-		return nil, fmt.Errorf("unsupported content type from '%s'", fullToolName)
-	}
+	return result, coercions, nil
 }
 
 // sanitizeToolError extracts useful error info while removing sensitive details
@@ -635,6 +1244,19 @@ func (r *Runtime) mapError(err error) error {
 		return nil
 	}
 
+	// Tripped budgets carry their own error, also through an interrupt
+	var runtimeErr *RuntimeError
+	if errors.As(err, &runtimeErr) {
+		return runtimeErr
+	}
+	var overflow *goja.StackOverflowError
+	if errors.As(err, &overflow) {
+		return &RuntimeError{
+			Type:    ErrorTypeCallDepthLimit,
+			Message: fmt.Sprintf("script exceeded the maximum call depth of %d", r.maxCallDepth),
+		}
+	}
+
 	errMsg := err.Error()
 
 	// Check for interruption (timeout/cancellation)
@@ -692,7 +1314,12 @@ func sanitizeError(msg string) string {
 	return msg
 }
 
-// contains checks if a slice contains a string
-func contains(slice []string, str string) bool {
-	return slices.Contains(slice, str)
+// matchesAny reports whether name matches one of the glob patterns
+func matchesAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if matched, err := path.Match(pattern, name); err == nil && matched {
+			return true
+		}
+	}
+	return false
 }
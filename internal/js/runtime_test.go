@@ -3,12 +3,14 @@ package js
 import (
 	"context"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/vaayne/mcphub/internal/client"
 	"github.com/vaayne/mcphub/internal/logging"
 
+	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -941,3 +943,275 @@ func TestExecute_ConcurrentTimeouts(t *testing.T) {
 		assert.Equal(t, ErrorTypeTimeout, runtimeErr.Type)
 	}
 }
+
+// TestExecute_ArgumentValidation verifies callTool validates params against inputSchema
+func TestExecute_ArgumentValidation(t *testing.T) {
+	caller := newRichCaller()
+	caller.schemas = map[string]any{
+		"srv__json": map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"limit": map[string]any{"type": "integer"},
+			},
+			"required": []any{"limit"},
+		},
+	}
+	runtime := NewRuntime(logging.NopLogger(), caller, nil)
+
+	_, _, err := runtime.Execute(context.Background(), `mcp.callTool("srvJson", {limit: "ten"})`)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `invalid arguments for 'srv.json': params.limit: expected integer, got string "ten"`)
+
+	result, _, err := runtime.Execute(context.Background(), `mcp.callTool("srvJson", {limit: 10}).a`)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), result)
+}
+
+// TestExecute_ArgumentCoercion verifies callTool coerces params and logs the adjustments
+func TestExecute_ArgumentCoercion(t *testing.T) {
+	caller := newRichCaller()
+	caller.schemas = map[string]any{
+		"srv__json": map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"limit": map[string]any{"type": "integer"},
+			},
+			"required": []any{"limit"},
+		},
+	}
+	runtime := NewRuntime(logging.NopLogger(), caller, nil)
+
+	result, logs, err := runtime.Execute(context.Background(), `mcp.callTool("srvJson", {limit: "10"}).a`)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), result)
+
+	require.Len(t, logs, 1)
+	assert.Equal(t, "warn", logs[0].Level)
+	assert.Contains(t, logs[0].Message, "'srv.json'")
+	assert.Equal(t, []any{`params.limit: converted string "10" to integer 10`}, logs[0].Fields["coercions"])
+}
+
+// resolvingCaller lists tools under custom qualified names and resolves them
+type resolvingCaller struct {
+	fakeCaller
+	refs map[string][2]string // listed name -> server ID, tool name
+}
+
+func (r *resolvingCaller) ListTools(ctx context.Context) ([]*mcp.Tool, error) {
+	tools := make([]*mcp.Tool, 0, len(r.refs))
+	for name, ref := range r.refs {
+		tools = append(tools, &mcp.Tool{Name: name, InputSchema: r.schemas[ref[0]+"__"+ref[1]]})
+	}
+	return tools, nil
+}
+
+func (r *resolvingCaller) ResolveTool(name string) (string, string, bool) {
+	ref, ok := r.refs[name]
+	return ref[0], ref[1], ok
+}
+
+// TestExecute_ResolvesQualifiedNames verifies custom prefixes and separators
+// are resolved through the caller instead of split on "__"
+func TestExecute_ResolvesQualifiedNames(t *testing.T) {
+	ok := &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: `{"a": 1}`}}}
+	caller := &resolvingCaller{
+		fakeCaller: fakeCaller{
+			results: map[string]*mcp.CallToolResult{
+				"github__search_code": ok,
+				"main__get__item":     ok,
+			},
+			schemas: map[string]any{
+				"github__search_code": map[string]any{
+					"type":       "object",
+					"properties": map[string]any{"q": map[string]any{"type": "string"}},
+					"required":   []any{"q"},
+				},
+			},
+		},
+		refs: map[string][2]string{
+			"gh.search_code": {"github", "search_code"},
+			"get__item":      {"main", "get__item"},
+		},
+	}
+	runtime := NewRuntime(logging.NopLogger(), caller, nil)
+
+	result, _, err := runtime.Execute(context.Background(), `mcp.callTool("gh.search_code", {q: "x"}).a`)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), result)
+
+	result, _, err = runtime.Execute(context.Background(), `mcp.callTool("get__item", {}).a`)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), result)
+
+	// Schemas are found for resolved names too
+	_, _, err = runtime.Execute(context.Background(), `mcp.callTool("gh.search_code", {})`)
+	assert.ErrorContains(t, err, "invalid arguments for 'github.search_code'")
+}
+
+// slowCaller answers every call after a delay, recording peak concurrency
+type slowCaller struct {
+	fakeCaller
+	delay         time.Duration
+	running, peak atomic.Int32
+}
+
+func (s *slowCaller) CallTool(ctx context.Context, serverID, toolName string, params map[string]any) (*mcp.CallToolResult, error) {
+	n := s.running.Add(1)
+	defer s.running.Add(-1)
+	for {
+		p := s.peak.Load()
+		if n <= p || s.peak.CompareAndSwap(p, n) {
+			break
+		}
+	}
+	select {
+	case <-time.After(s.delay):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	return s.fakeCaller.CallTool(ctx, serverID, toolName, params)
+}
+
+// TestExecute_CallToolAsync verifies Promise.all runs tool calls in parallel,
+// bounded by MaxConcurrency
+func TestExecute_CallToolAsync(t *testing.T) {
+	caller := &slowCaller{fakeCaller: *newRichCaller(), delay: 100 * time.Millisecond}
+	runtime := NewRuntime(logging.NopLogger(), caller, &Config{MaxConcurrency: 3})
+
+	start := time.Now()
+	result, _, err := runtime.Execute(context.Background(), `
+		(async () => {
+			const calls = [];
+			for (let i = 0; i < 6; i++) calls.push(mcp.callToolAsync("srv__json", {}));
+			const results = await Promise.all(calls);
+			const raw = await mcp.callToolRawAsync("srv__json", {});
+			return results.map(r => r.a).concat(raw.text());
+		})()
+	`)
+	elapsed := time.Since(start)
+
+	require.NoError(t, err)
+	assert.Equal(t, []any{int64(1), int64(1), int64(1), int64(1), int64(1), int64(1), `{"a": 1}`}, result)
+	assert.Equal(t, int32(3), caller.peak.Load())
+	assert.Less(t, elapsed, 600*time.Millisecond, "six calls should take two rounds plus the raw call")
+}
+
+// TestExecute_CallToolAsyncErrors verifies failed calls reject their Promise
+// and that pending calls don't outlive the timeout
+func TestExecute_CallToolAsyncErrors(t *testing.T) {
+	runtime := NewRuntime(logging.NopLogger(), newRichCaller(), nil)
+	result, _, err := runtime.Execute(context.Background(), `
+		(async () => {
+			try {
+				await mcp.callToolAsync("srv__missing", {});
+				return "resolved";
+			} catch (e) {
+				return "rejected";
+			}
+		})()
+	`)
+	require.NoError(t, err)
+	assert.Equal(t, "rejected", result)
+
+	caller := &slowCaller{fakeCaller: *newRichCaller(), delay: time.Minute}
+	runtime = NewRuntime(logging.NopLogger(), caller, &Config{Timeout: 100 * time.Millisecond})
+	start := time.Now()
+	_, _, err = runtime.Execute(context.Background(), `mcp.callToolAsync("srv__json", {})`)
+	require.Error(t, err)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+// TestExecute_ToolProxies verifies mcp.tools and mcp.servers call listed tools,
+// validate their arguments and carry the tool's JSDoc
+func TestExecute_ToolProxies(t *testing.T) {
+	caller := &fakeCaller{
+		results: map[string]*mcp.CallToolResult{
+			"github__search_repos": {Content: []mcp.Content{&mcp.TextContent{Text: `{"total": 2}`}}},
+			"srv__json":            {Content: []mcp.Content{&mcp.TextContent{Text: `{"a": 1}`}}},
+		},
+		schemas: map[string]any{
+			"github__search_repos": map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"query": map[string]any{"type": "string", "description": "Search query"},
+				},
+				"required": []any{"query"},
+			},
+		},
+	}
+	runtime := NewRuntime(logging.NopLogger(), caller, nil)
+
+	result, _, err := runtime.Execute(context.Background(), `[
+		mcp.tools.githubSearchRepos({query: "mcp"}).total,
+		mcp.servers.github.searchRepos({query: "mcp"}).total,
+		mcp.tools.srvJson().a,
+		Object.keys(mcp.servers).sort().join(","),
+	]`)
+	require.NoError(t, err)
+	assert.Equal(t, []any{int64(2), int64(2), int64(1), "github,srv"}, result)
+
+	result, _, err = runtime.Execute(context.Background(), `mcp.tools.githubSearchRepos.doc`)
+	require.NoError(t, err)
+	assert.Contains(t, result, "@param {string} params.query - Search query (required)")
+	assert.Contains(t, result, "function githubSearchRepos(params) {}")
+
+	_, _, err = runtime.Execute(context.Background(), `mcp.tools.githubSearchRepos({})`)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "query")
+}
+
+// TestExecute_TypeScript verifies TypeScript runs with its types removed and
+// errors keep the source's line numbers
+func TestExecute_TypeScript(t *testing.T) {
+	caller := &fakeCaller{
+		results: map[string]*mcp.CallToolResult{
+			"srv__json": {Content: []mcp.Content{&mcp.TextContent{Text: `{"a": 1}`}}},
+		},
+	}
+	runtime := NewRuntime(logging.NopLogger(), caller, &Config{Language: LanguageTypeScript})
+
+	result, _, err := runtime.Execute(context.Background(), `
+		interface Data { a: number }
+		const double = <T extends number>(x: T): number => x * 2;
+		const data = mcp.callTool("srv__json", {}) as Data;
+		double(data.a!)
+	`)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), result)
+
+	// Errors from the engine point at the TypeScript line
+	_, _, err = runtime.Execute(context.Background(), "type A = string;\nconst a: A = 'x';\nconst b = ;")
+	var runtimeErr *RuntimeError
+	require.ErrorAs(t, err, &runtimeErr)
+	assert.Equal(t, ErrorTypeSyntax, runtimeErr.Type)
+	assert.Contains(t, runtimeErr.Message, "Line 3:11")
+
+	// Constructs that need code generation are syntax errors
+	_, _, err = runtime.Execute(context.Background(), "const a = 1;\nenum Color { Red }")
+	require.ErrorAs(t, err, &runtimeErr)
+	assert.Equal(t, ErrorTypeSyntax, runtimeErr.Type)
+	assert.Contains(t, runtimeErr.Message, "Line 2:1 enums are not supported")
+
+	// JavaScript is run as is
+	runtime = NewRuntime(logging.NopLogger(), caller, nil)
+	_, _, err = runtime.Execute(context.Background(), "const a: number = 1")
+	require.ErrorAs(t, err, &runtimeErr)
+	assert.Equal(t, ErrorTypeSyntax, runtimeErr.Type)
+}
+
+func TestParseLanguage(t *testing.T) {
+	for name, expected := range map[string]string{
+		"":           LanguageJavaScript,
+		"js":         LanguageJavaScript,
+		"JavaScript": LanguageJavaScript,
+		"ts":         LanguageTypeScript,
+		"typescript": LanguageTypeScript,
+	} {
+		language, err := ParseLanguage(name)
+		require.NoError(t, err)
+		assert.Equal(t, expected, language, name)
+	}
+
+	_, err := ParseLanguage("python")
+	assert.Error(t, err)
+}
@@ -0,0 +1,152 @@
+package js
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/dop251/goja"
+)
+
+// StateStore is a namespace of JSON values kept between executions; it backs
+// mcp.state (see the state package)
+type StateStore interface {
+	Get(key string) (json.RawMessage, bool, error)
+	Set(key string, value json.RawMessage, ttl time.Duration) error
+	Delete(key string) (bool, error)
+	List(prefix string) ([]string, error)
+}
+
+// stateObject builds mcp.state over the session namespace, with the
+// persistent namespace as mcp.state.persistent. A missing namespace throws on
+// use, explaining why it is missing.
+func (r *Runtime) stateObject(vm *goja.Runtime) (*goja.Object, error) {
+	// Values cross the store as JSON; the built-ins are taken now, before the
+	// script can replace them
+	jsonObj := vm.Get("JSON").ToObject(vm)
+	stringify, ok := goja.AssertFunction(jsonObj.Get("stringify"))
+	if !ok {
+		return nil, fmt.Errorf("JSON.stringify is not a function")
+	}
+	parse, ok := goja.AssertFunction(jsonObj.Get("parse"))
+	if !ok {
+		return nil, fmt.Errorf("JSON.parse is not a function")
+	}
+	codec := &stateCodec{vm: vm, stringify: stringify, parse: parse}
+
+	stateObj, err := codec.namespace("mcp.state", r.state, "state is not available to this script")
+	if err != nil {
+		return nil, err
+	}
+	persistentObj, err := codec.namespace("mcp.state.persistent", r.persistentState, "persistent state is not enabled (state.persistent in the hub config)")
+	if err != nil {
+		return nil, err
+	}
+	if err := stateObj.Set("persistent", persistentObj); err != nil {
+		return nil, err
+	}
+	return stateObj, nil
+}
+
+// stateCodec converts values between the script and a StateStore
+type stateCodec struct {
+	vm        *goja.Runtime
+	stringify goja.Callable
+	parse     goja.Callable
+}
+
+// namespace builds the get, set, delete and list methods over store; with a
+// nil store every method throws unavailable
+func (c *stateCodec) namespace(name string, store StateStore, unavailable string) (*goja.Object, error) {
+	vm := c.vm
+	obj := vm.NewObject()
+	fail := func(err error) {
+		panic(vm.NewGoError(fmt.Errorf("%s: %w", name, err)))
+	}
+	open := func() StateStore {
+		if store == nil {
+			panic(vm.NewGoError(fmt.Errorf("%s: %s", name, unavailable)))
+		}
+		return store
+	}
+
+	// get(key) returns the value, or undefined if the key is not set
+	if err := obj.Set("get", func(call goja.FunctionCall) goja.Value {
+		value, ok, err := open().Get(call.Argument(0).String())
+		if err != nil {
+			fail(err)
+		}
+		if !ok {
+			return goja.Undefined()
+		}
+		parsed, err := c.parse(goja.Undefined(), vm.ToValue(string(value)))
+		if err != nil {
+			fail(err)
+		}
+		return parsed
+	}); err != nil {
+		return nil, err
+	}
+
+	// set(key, value, {ttl}) stores a JSON value; ttl is in seconds
+	if err := obj.Set("set", func(call goja.FunctionCall) goja.Value {
+		ns := open()
+		key := call.Argument(0).String()
+		encoded, err := c.stringify(goja.Undefined(), call.Argument(1))
+		if err != nil {
+			fail(err)
+		}
+		if goja.IsUndefined(encoded) {
+			panic(vm.NewTypeError("%s.set: value of %q is not JSON-serializable", name, key))
+		}
+		var ttl time.Duration
+		if options := call.Argument(2); !goja.IsUndefined(options) && !goja.IsNull(options) {
+			if seconds := options.ToObject(vm).Get("ttl"); seconds != nil && !goja.IsUndefined(seconds) {
+				secs := seconds.ToFloat()
+				if math.IsNaN(secs) || math.IsInf(secs, 0) || secs <= 0 {
+					panic(vm.NewTypeError("%s.set: ttl must be a positive number of seconds", name))
+				}
+				ttl = time.Duration(secs * float64(time.Second))
+			}
+		}
+		if err := ns.Set(key, json.RawMessage(encoded.String()), ttl); err != nil {
+			fail(err)
+		}
+		return goja.Undefined()
+	}); err != nil {
+		return nil, err
+	}
+
+	// delete(key) removes the key, returning whether it was set
+	if err := obj.Set("delete", func(call goja.FunctionCall) goja.Value {
+		deleted, err := open().Delete(call.Argument(0).String())
+		if err != nil {
+			fail(err)
+		}
+		return vm.ToValue(deleted)
+	}); err != nil {
+		return nil, err
+	}
+
+	// list(prefix?) returns the keys starting with prefix, sorted
+	if err := obj.Set("list", func(call goja.FunctionCall) goja.Value {
+		var prefix string
+		if arg := call.Argument(0); !goja.IsUndefined(arg) && !goja.IsNull(arg) {
+			prefix = arg.String()
+		}
+		keys, err := open().List(prefix)
+		if err != nil {
+			fail(err)
+		}
+		items := make([]any, len(keys))
+		for i, key := range keys {
+			items[i] = key
+		}
+		return vm.NewArray(items...)
+	}); err != nil {
+		return nil, err
+	}
+
+	return obj, nil
+}
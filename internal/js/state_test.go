@@ -0,0 +1,85 @@
+package js
+
+import (
+	"context"
+	"testing"
+
+	"github.com/vaayne/mcphub/internal/logging"
+	"github.com/vaayne/mcphub/internal/state"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestExecute_State verifies mcp.state keeps values between executions
+func TestExecute_State(t *testing.T) {
+	cfg := &Config{State: state.NewMemory(0, 0)}
+
+	_, _, err := NewRuntime(logging.NopLogger(), nil, cfg).Execute(context.Background(), `
+		mcp.state.set("cursor", "page-2");
+		mcp.state.set("seen", { repos: ["a", "b"], count: 2 }, { ttl: 60 });
+		mcp.state.set("tmp", 1);
+		mcp.state.delete("tmp");
+	`)
+	require.NoError(t, err)
+
+	result, _, err := NewRuntime(logging.NopLogger(), nil, cfg).Execute(context.Background(), `
+		const seen = mcp.state.get("seen");
+		[mcp.state.get("cursor"), seen.repos.length + seen.count, mcp.state.get("tmp") === undefined,
+		 mcp.state.delete("missing"), mcp.state.list(), mcp.state.list("c")];
+	`)
+	require.NoError(t, err)
+	assert.Equal(t, []any{"page-2", int64(4), true, false, []any{"cursor", "seen"}, []any{"cursor"}}, result)
+}
+
+// TestExecute_StateErrors verifies bad values, quotas and missing namespaces
+// throw catchable errors
+func TestExecute_StateErrors(t *testing.T) {
+	runtime := NewRuntime(logging.NopLogger(), nil, &Config{State: state.NewMemory(1, 0)})
+
+	scripts := map[string]string{
+		`mcp.state.set("f", () => 1)`:                     "not JSON-serializable",
+		`mcp.state.set("k", 1, { ttl: 0 })`:               "ttl must be a positive number",
+		`mcp.state.set("k", 1, { ttl: NaN })`:             "ttl must be a positive number",
+		`mcp.state.set("k", 1, { ttl: "soon" })`:          "ttl must be a positive number",
+		`mcp.state.set("k", 1, { ttl: Infinity })`:        "ttl must be a positive number",
+		`mcp.state.set("a", 1); mcp.state.set("b", 1)`:    "at most 1 keys",
+		`mcp.state.set("", 1)`:                            "key is required",
+		`mcp.state.persistent.get("k")`:                   "persistent state is not enabled",
+		`const o = {}; o.self = o; mcp.state.set("o", o)`: "circular",
+	}
+	for script, want := range scripts {
+		_, _, err := runtime.Execute(context.Background(), script)
+		require.Error(t, err, script)
+		assert.Contains(t, err.Error(), want, script)
+	}
+
+	result, _, err := runtime.Execute(context.Background(), `
+		try { mcp.state.persistent.list(); } catch (e) { "caught"; }
+	`)
+	require.NoError(t, err)
+	assert.Equal(t, "caught", result)
+
+	_, _, err = NewRuntime(logging.NopLogger(), nil, nil).Execute(context.Background(), `mcp.state.get("k")`)
+	assert.ErrorContains(t, err, "state is not available")
+}
+
+// TestExecute_PersistentState verifies mcp.state.persistent is separate from
+// the session namespace
+func TestExecute_PersistentState(t *testing.T) {
+	disk, err := state.NewDisk(t.TempDir(), 0, 0)
+	require.NoError(t, err)
+	cfg := &Config{State: state.NewMemory(0, 0), PersistentState: disk}
+
+	result, _, err := NewRuntime(logging.NopLogger(), nil, cfg).Execute(context.Background(), `
+		mcp.state.persistent.set("token", "abc");
+		[mcp.state.persistent.get("token"), mcp.state.get("token") === undefined];
+	`)
+	require.NoError(t, err)
+	assert.Equal(t, []any{"abc", true}, result)
+
+	value, ok, err := disk.Get("token")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.JSONEq(t, `"abc"`, string(value))
+}
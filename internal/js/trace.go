@@ -0,0 +1,284 @@
+package js
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dop251/goja"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/vaayne/mcphub/internal/textutil"
+)
+
+// maxTraceSummary is the length of the result summary of a traced call
+const maxTraceSummary = 200
+
+// Trace records a traced execution: its tool calls in the order the script
+// made them and, when the execution failed, the JS stack at the failure
+type Trace struct {
+	Calls []TraceCall      `json:"calls"`
+	Stack []SourceLocation `json:"stack,omitempty"` // innermost frame first
+}
+
+// TraceCall is one tool call of a traced execution
+type TraceCall struct {
+	Function   string          `json:"function"` // mcp.callTool, mcp.callToolAsync, mcp.tools.<jsName>, ...
+	Tool       string          `json:"tool"`     // name the tool is listed under, or its name on a single-server caller
+	Params     any             `json:"params,omitempty"`
+	StartMs    int64           `json:"startMs"` // since the execution started
+	DurationMs int64           `json:"durationMs"`
+	Result     string          `json:"result,omitempty"` // summary of the result
+	Error      string          `json:"error,omitempty"`
+	Location   *SourceLocation `json:"location,omitempty"` // where the script made the call
+}
+
+// SourceLocation is a position in the script, or in a library module
+type SourceLocation struct {
+	File     string `json:"file,omitempty"` // library module; empty for the script itself
+	Function string `json:"function,omitempty"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+	Source   string `json:"source,omitempty"` // the line of the script at Line
+}
+
+// tracer collects the trace of one execution
+type tracer struct {
+	start time.Time
+	lines []string // script source, for SourceLocation.Source
+
+	mu     sync.Mutex // async calls finish on other goroutines
+	calls  []*traceCall
+	stack  []SourceLocation
+	failed bool
+}
+
+// traceCall is a call in progress
+type traceCall struct {
+	TraceCall
+	started  time.Time
+	finished bool
+}
+
+// newTracer starts the trace of an execution of script
+func newTracer(script string) *tracer {
+	return &tracer{start: time.Now(), lines: strings.Split(script, "\n")}
+}
+
+// call records a tool call made by the script; the position is taken from
+// the JS stack, so it must be called on the event loop. A nil trace records
+// nothing.
+func (t *tracer) call(vm *goja.Runtime, fnName, tool string, params any) *traceCall {
+	if t == nil {
+		return nil
+	}
+	c := &traceCall{TraceCall: TraceCall{Function: fnName, Tool: tool, Params: params}}
+	var frames [8]goja.StackFrame
+	for _, frame := range vm.CaptureCallStack(len(frames), frames[:0]) {
+		if loc, ok := t.location(&frame); ok {
+			c.Location = loc
+			break
+		}
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	c.started = time.Now()
+	c.StartMs = c.started.Sub(t.start).Milliseconds()
+	t.calls = append(t.calls, c)
+	return c
+}
+
+// begin restarts the clock of a call that waited for a slot
+func (c *traceCall) begin(t *tracer) {
+	if c == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	c.started = time.Now()
+	c.StartMs = c.started.Sub(t.start).Milliseconds()
+}
+
+// end records the outcome of a call
+func (c *traceCall) end(t *tracer, result *mcp.CallToolResult, err error) {
+	if c == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	c.DurationMs = time.Since(c.started).Milliseconds()
+	c.finished = true
+	if err != nil {
+		c.Error = sanitizeError(err.Error())
+		return
+	}
+	c.Result = summarizeResult(result)
+}
+
+// fail records the JS stack of the error that ended the execution; the first
+// failure recorded wins. A rejected promise carries its stack only as text, in
+// the stack property of the rejection value.
+func (t *tracer) fail(err error, rejection goja.Value) {
+	if t == nil || err == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.failed {
+		return
+	}
+	t.failed = true
+
+	var (
+		exception   *goja.Exception
+		interrupted *goja.InterruptedError
+		stack       []SourceLocation
+	)
+	switch {
+	case errors.As(err, &interrupted):
+		stack = t.frames(interrupted.Stack())
+	case errors.As(err, &exception):
+		stack = t.frames(exception.Stack())
+	case rejection != nil:
+		if obj, ok := rejection.(*goja.Object); ok {
+			if text, ok := obj.Get("stack").Export().(string); ok {
+				stack = t.parseStack(text)
+			}
+		}
+	}
+	// A syntax error has no stack; its position is only in the message
+	if m := syntaxPosition.FindStringSubmatch(err.Error()); len(stack) == 0 && m != nil {
+		line, _ := strconv.Atoi(m[1])
+		column, _ := strconv.Atoi(m[2])
+		stack = []SourceLocation{t.at("", "", line, column)}
+	}
+	t.stack = stack
+}
+
+// trace returns what was recorded; calls still running when the execution
+// ended are marked unfinished
+func (t *tracer) trace() *Trace {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	trace := &Trace{Calls: make([]TraceCall, len(t.calls)), Stack: t.stack}
+	for i, c := range t.calls {
+		trace.Calls[i] = c.TraceCall
+		if !c.finished {
+			trace.Calls[i].Error = "did not finish"
+		}
+	}
+	return trace
+}
+
+// frames converts a goja stack, skipping native frames
+func (t *tracer) frames(stack []goja.StackFrame) []SourceLocation {
+	var locations []SourceLocation
+	for i := range stack {
+		if loc, ok := t.location(&stack[i]); ok {
+			locations = append(locations, *loc)
+		}
+	}
+	return locations
+}
+
+// location converts a stack frame, reporting false for native frames
+func (t *tracer) location(frame *goja.StackFrame) (*SourceLocation, bool) {
+	pos := frame.Position()
+	if pos.Line == 0 {
+		return nil, false
+	}
+	function := frame.FuncName()
+	if function == "<anonymous>" {
+		function = ""
+	}
+	loc := t.at(pos.Filename, function, pos.Line, pos.Column)
+	return &loc, true
+}
+
+// at builds a location, with the source line for positions in the script
+func (t *tracer) at(file, function string, line, column int) SourceLocation {
+	loc := SourceLocation{File: strings.TrimPrefix(file, "/"), Function: function, Line: line, Column: column}
+	if loc.File == "" && line >= 1 && line <= len(t.lines) {
+		loc.Source = strings.TrimRight(t.lines[line-1], "\r")
+	}
+	return loc
+}
+
+// stackLine matches a frame of a JS stack property: "at fn (file:1:2(3))" or
+// "at file:1:2(3)"
+var stackLine = regexp.MustCompile(`^\s*at (?:(.*) \()?(.*):(\d+):(\d+)\(\d+\)\)?$`)
+
+// syntaxPosition matches the position in the message of a syntax error in
+// the script
+var syntaxPosition = regexp.MustCompile(`\(anonymous\): Line (\d+):(\d+)`)
+
+// parseStack converts the stack property of an error, skipping native frames
+func (t *tracer) parseStack(text string) []SourceLocation {
+	var locations []SourceLocation
+	for _, line := range strings.Split(text, "\n") {
+		m := stackLine.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		file := m[2]
+		if file == "<eval>" {
+			file = ""
+		}
+		lineNo, _ := strconv.Atoi(m[3])
+		column, _ := strconv.Atoi(m[4])
+		locations = append(locations, t.at(file, m[1], lineNo, column))
+	}
+	return locations
+}
+
+// summarizeResult describes a tool result in one short line
+func summarizeResult(result *mcp.CallToolResult) string {
+	if result == nil {
+		return ""
+	}
+	var summary string
+	switch {
+	case len(result.Content) == 0:
+		summary = "empty"
+	case len(result.Content) == 1:
+		if text, ok := result.Content[0].(*mcp.TextContent); ok {
+			summary = strings.Join(strings.Fields(text.Text), " ")
+			break
+		}
+		fallthrough
+	default:
+		types := make([]string, len(result.Content))
+		for i, block := range result.Content {
+			types[i] = contentTypeOf(block)
+		}
+		summary = fmt.Sprintf("%d content blocks: %s", len(result.Content), strings.Join(types, ", "))
+	}
+	if result.IsError {
+		summary = "error: " + summary
+	}
+	return textutil.Abbreviate(summary, maxTraceSummary)
+}
+
+// contentTypeOf returns the wire type of a content block
+func contentTypeOf(block mcp.Content) string {
+	switch block.(type) {
+	case *mcp.TextContent:
+		return contentTypeText
+	case *mcp.ImageContent:
+		return contentTypeImage
+	case *mcp.AudioContent:
+		return contentTypeAudio
+	case *mcp.EmbeddedResource:
+		return contentTypeResource
+	case *mcp.ResourceLink:
+		return contentTypeResourceLink
+	}
+	return fmt.Sprintf("%T", block)
+}
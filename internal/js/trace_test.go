@@ -0,0 +1,123 @@
+package js
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/vaayne/mcphub/internal/logging"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestExecuteWithTrace verifies traced executions record each tool call with
+// its arguments, result summary and call site, and the stack at a failure
+func TestExecuteWithTrace(t *testing.T) {
+	runtime := NewRuntime(logging.NopLogger(), newRichCaller(), &Config{Trace: true})
+
+	_, _, trace, err := runtime.ExecuteWithTrace(context.Background(), `const data = mcp.callTool("srvJson", { q: "x" });
+mcp.tools.srvScreenshot({});
+function load(name) {
+  return mcp.callTool(name, {});
+}
+load("srvMissing");`)
+	require.Error(t, err)
+	require.NotNil(t, trace)
+	require.Len(t, trace.Calls, 3)
+
+	first := trace.Calls[0]
+	assert.Equal(t, "mcp.callTool", first.Function)
+	assert.Equal(t, "srv__json", first.Tool)
+	assert.Equal(t, map[string]any{"q": "x"}, first.Params)
+	assert.Equal(t, `{"a": 1}`, first.Result)
+	require.NotNil(t, first.Location)
+	assert.Equal(t, 1, first.Location.Line)
+	assert.Equal(t, `const data = mcp.callTool("srvJson", { q: "x" });`, first.Location.Source)
+
+	assert.Equal(t, "mcp.tools.srvScreenshot", trace.Calls[1].Function)
+	assert.Equal(t, "3 content blocks: text, image, text", trace.Calls[1].Result)
+
+	failed := trace.Calls[2]
+	assert.Equal(t, "srvMissing", failed.Tool)
+	assert.Contains(t, failed.Error, "tool 'srvMissing' failed")
+	assert.Equal(t, 4, failed.Location.Line)
+
+	require.Len(t, trace.Stack, 2)
+	assert.Equal(t, SourceLocation{Function: "load", Line: 4, Column: 22, Source: "  return mcp.callTool(name, {});"}, trace.Stack[0])
+	assert.Equal(t, 6, trace.Stack[1].Line)
+}
+
+// TestExecuteWithTrace_Failures verifies the failure position of rejected
+// promises, syntax errors and TypeScript, which keeps its source lines
+func TestExecuteWithTrace_Failures(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      *Config
+		script   string
+		line     int
+		column   int // 0 = any
+		function string
+	}{
+		{"rejection", &Config{}, "(async () => {\n  await null;\n  throw new Error(\"late\");\n})()", 3, 9, ""},
+		{"syntax", &Config{}, "const a = 1;\nconst b = ;", 2, 11, ""},
+		{"typescript", &Config{Language: LanguageTypeScript}, "const n: number = 1;\nconst s: string = (n as any).a.b;", 2, 32, ""},
+		// The interrupt lands anywhere in the loop, so only the line is known
+		{"timeout", &Config{Timeout: 50 * time.Millisecond}, "function spin() {\n  let i = 0;\n  while (true) { i = i + 1; }\n}\nspin();", 3, 0, "spin"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.cfg.Trace = true
+			_, _, trace, err := NewRuntime(logging.NopLogger(), nil, tt.cfg).ExecuteWithTrace(context.Background(), tt.script)
+			require.Error(t, err)
+			require.NotNil(t, trace)
+			require.NotEmpty(t, trace.Stack)
+			assert.Equal(t, tt.line, trace.Stack[0].Line)
+			if tt.column > 0 {
+				assert.Equal(t, tt.column, trace.Stack[0].Column)
+			}
+			assert.Equal(t, tt.function, trace.Stack[0].Function)
+			assert.NotEmpty(t, trace.Stack[0].Source)
+		})
+	}
+}
+
+// TestExecuteWithTrace_Async verifies async calls are traced in call order,
+// and that executions are not traced by default
+func TestExecuteWithTrace_Async(t *testing.T) {
+	caller := &slowCaller{fakeCaller: *newRichCaller(), delay: 20 * time.Millisecond}
+	caller.results["srv__empty"] = &mcp.CallToolResult{}
+
+	_, _, trace, err := NewRuntime(logging.NopLogger(), caller, &Config{Trace: true}).ExecuteWithTrace(context.Background(),
+		`Promise.all([mcp.callToolAsync("srvJson", {}), mcp.callToolRawAsync("srvEmpty", {})])`)
+	require.NoError(t, err)
+	require.Len(t, trace.Calls, 2)
+	assert.Equal(t, "mcp.callToolAsync", trace.Calls[0].Function)
+	assert.Equal(t, "mcp.callToolRawAsync", trace.Calls[1].Function)
+	assert.Equal(t, "empty", trace.Calls[1].Result)
+	assert.GreaterOrEqual(t, trace.Calls[0].DurationMs, int64(20))
+	assert.Empty(t, trace.Stack)
+
+	_, _, trace, err = NewRuntime(logging.NopLogger(), caller, nil).ExecuteWithTrace(context.Background(), `mcp.callTool("srvJson", {})`)
+	require.NoError(t, err)
+	assert.Nil(t, trace)
+}
+
+// TestExecuteWithTrace_QualifiedNames verifies calls are traced under the name
+// the caller lists the tool as, not the default serverID__toolName
+func TestExecuteWithTrace_QualifiedNames(t *testing.T) {
+	caller := &resolvingCaller{
+		fakeCaller: fakeCaller{results: map[string]*mcp.CallToolResult{
+			"github__search": {Content: []mcp.Content{&mcp.TextContent{Text: "ok"}}},
+		}},
+		refs: map[string][2]string{"gh-search": {"github", "search"}},
+	}
+
+	_, _, trace, err := NewRuntime(logging.NopLogger(), caller, &Config{Trace: true}).ExecuteWithTrace(context.Background(),
+		`mcp.callTool("gh-search", {}); mcp.tools.ghSearch({})`)
+	require.NoError(t, err)
+	require.Len(t, trace.Calls, 2)
+	assert.Equal(t, "gh-search", trace.Calls[0].Tool)
+	assert.Equal(t, "gh-search", trace.Calls[1].Tool)
+}
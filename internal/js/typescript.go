@@ -0,0 +1,1511 @@
+package js
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// TypeScriptError reports TypeScript that StripTypes cannot turn into
+// JavaScript. Line and Column are 1-based.
+type TypeScriptError struct {
+	Line    int
+	Column  int
+	Message string
+}
+
+// Error implements the error interface
+func (e *TypeScriptError) Error() string {
+	return fmt.Sprintf("Line %d:%d %s", e.Line, e.Column, e.Message)
+}
+
+// StripTypes converts TypeScript to JavaScript by removing its type syntax:
+// annotations, type parameters and arguments, interfaces, type aliases,
+// declare statements, access modifiers, `as` and `satisfies` expressions and
+// non-null assertions. Removed text is replaced with spaces and line breaks
+// are kept, so positions in the result match the source and errors from the
+// JavaScript engine point at the right line. Constructs that generate code
+// (enums, namespaces, parameter properties) are rejected; the script is
+// otherwise not type-checked.
+func StripTypes(source string) (string, error) {
+	lexer := &tsLexer{src: source}
+	if err := lexer.run(); err != nil {
+		return "", err
+	}
+
+	s := newTSStripper(source, lexer.tokens)
+	s.scan(len(s.toks) - 1)
+	if s.err != nil {
+		return "", s.err
+	}
+	return s.output(), nil
+}
+
+type tsTokenKind int
+
+const (
+	tsEOF tsTokenKind = iota
+	tsIdent
+	tsNumber
+	tsString
+	tsRegex
+	tsPunct
+	tsTemplate       // template literal without substitutions
+	tsTemplateHead   // `...${
+	tsTemplateMiddle // }...${
+	tsTemplateTail   // }...`
+)
+
+type tsToken struct {
+	kind    tsTokenKind
+	text    string
+	start   int  // byte offset in the source
+	end     int  // byte offset after the token
+	newline bool // a line break precedes the token
+}
+
+// is reports whether the token is the punctuator or identifier text
+func (t tsToken) is(text string) bool {
+	return (t.kind == tsPunct || t.kind == tsIdent) && t.text == text
+}
+
+// tsPunctuators are the multi-character punctuators, longest first. ">" is
+// always a token of its own so that nested type arguments close one by one.
+var tsPunctuators = []string{
+	"...", "===", "!==", "**=", "<<=", "&&=", "||=", "??=",
+	"=>", "==", "!=", "<=", "&&", "||", "??", "?.", "++", "--", "**", "<<",
+	"+=", "-=", "*=", "/=", "%=", "&=", "|=", "^=",
+}
+
+// tsKeywords are reserved words that cannot end an expression
+var tsKeywords = map[string]bool{
+	"await": true, "break": true, "case": true, "catch": true, "class": true,
+	"const": true, "continue": true, "debugger": true, "default": true,
+	"delete": true, "do": true, "else": true, "export": true, "extends": true,
+	"finally": true, "for": true, "function": true, "if": true, "import": true,
+	"in": true, "instanceof": true, "let": true, "new": true, "return": true,
+	"switch": true, "throw": true, "try": true, "typeof": true, "var": true,
+	"void": true, "while": true, "with": true, "yield": true,
+}
+
+// tsRegexKeywords are keywords after which a slash starts a regular expression
+var tsRegexKeywords = map[string]bool{
+	"await": true, "case": true, "delete": true, "do": true, "else": true,
+	"in": true, "instanceof": true, "new": true, "of": true, "return": true,
+	"throw": true, "typeof": true, "void": true, "yield": true,
+}
+
+type tsLexer struct {
+	src    string
+	pos    int
+	tokens []tsToken
+	braces []bool // open braces, true for template substitutions
+}
+
+func (l *tsLexer) run() error {
+	newline := false
+	if strings.HasPrefix(l.src, "#!") {
+		l.skipLine()
+	}
+	for {
+	space:
+		for l.pos < len(l.src) {
+			c := l.src[l.pos]
+			switch {
+			case c == '\n' || c == '\r':
+				newline = true
+				l.pos++
+			case c == ' ' || c == '\t' || c == '\v' || c == '\f':
+				l.pos++
+			case c == '/' && l.peek(1) == '/':
+				l.skipLine()
+			case c == '/' && l.peek(1) == '*':
+				end := strings.Index(l.src[l.pos+2:], "*/")
+				if end < 0 {
+					return l.errorAt(l.pos, "unterminated comment")
+				}
+				if strings.ContainsAny(l.src[l.pos:l.pos+2+end], "\n\r\u2028\u2029") {
+					newline = true
+				}
+				l.pos += end + 4
+			case c >= utf8.RuneSelf:
+				r, size := utf8.DecodeRuneInString(l.src[l.pos:])
+				if r == '\u2028' || r == '\u2029' {
+					newline = true
+				} else if !unicode.IsSpace(r) && r != '\uFEFF' {
+					break space
+				}
+				l.pos += size
+			default:
+				break space
+			}
+		}
+
+		start := l.pos
+		if start >= len(l.src) {
+			l.tokens = append(l.tokens, tsToken{kind: tsEOF, start: start, end: start, newline: newline})
+			return nil
+		}
+		kind, err := l.next()
+		if err != nil {
+			return err
+		}
+		l.tokens = append(l.tokens, tsToken{
+			kind:    kind,
+			text:    l.src[start:l.pos],
+			start:   start,
+			end:     l.pos,
+			newline: newline,
+		})
+		newline = false
+	}
+}
+
+func (l *tsLexer) next() (tsTokenKind, error) {
+	start := l.pos
+	c := l.src[l.pos]
+	r, size := utf8.DecodeRuneInString(l.src[l.pos:])
+
+	switch {
+	case isTSIdentStart(r) || c == '\\' || c == '#':
+		l.pos += size
+		l.identifier()
+		return tsIdent, nil
+	case isDigit(c) || (c == '.' && isDigit(l.peek(1))):
+		l.number()
+		return tsNumber, nil
+	case c == '"' || c == '\'':
+		l.pos++
+		for l.pos < len(l.src) && l.src[l.pos] != c {
+			if l.src[l.pos] == '\\' {
+				l.pos++
+			} else if l.src[l.pos] == '\n' {
+				break
+			}
+			l.pos++
+		}
+		if l.pos >= len(l.src) || l.src[l.pos] != c {
+			return 0, l.errorAt(start, "unterminated string literal")
+		}
+		l.pos++
+		return tsString, nil
+	case c == '`':
+		return l.template(tsTemplate, tsTemplateHead)
+	case c == '}' && len(l.braces) > 0 && l.braces[len(l.braces)-1]:
+		l.braces = l.braces[:len(l.braces)-1]
+		return l.template(tsTemplateTail, tsTemplateMiddle)
+	case c == '/' && l.regexAllowed():
+		l.pos++
+		inClass := false
+		for {
+			if l.pos >= len(l.src) || l.src[l.pos] == '\n' {
+				return 0, l.errorAt(start, "unterminated regular expression")
+			}
+			ch := l.src[l.pos]
+			l.pos++
+			if ch == '\\' {
+				l.pos++
+			} else if ch == '[' {
+				inClass = true
+			} else if ch == ']' {
+				inClass = false
+			} else if ch == '/' && !inClass {
+				break
+			}
+		}
+		l.identifier() // flags
+		return tsRegex, nil
+	}
+
+	for _, p := range tsPunctuators {
+		if strings.HasPrefix(l.src[l.pos:], p) {
+			if p == "?." && isDigit(l.peek(2)) {
+				continue // a?.5:b is a conditional
+			}
+			l.pos += len(p)
+			return tsPunct, nil
+		}
+	}
+	switch c {
+	case '{':
+		l.braces = append(l.braces, false)
+	case '}':
+		if len(l.braces) > 0 {
+			l.braces = l.braces[:len(l.braces)-1]
+		}
+	}
+	l.pos += size
+	return tsPunct, nil
+}
+
+// template lexes the rest of a template literal after its opening ` or }
+func (l *tsLexer) template(complete, open tsTokenKind) (tsTokenKind, error) {
+	start := l.pos
+	l.pos++
+	for l.pos < len(l.src) {
+		switch l.src[l.pos] {
+		case '\\':
+			l.pos += 2
+		case '`':
+			l.pos++
+			return complete, nil
+		case '$':
+			if l.peek(1) == '{' {
+				l.pos += 2
+				l.braces = append(l.braces, true)
+				return open, nil
+			}
+			l.pos++
+		default:
+			l.pos++
+		}
+	}
+	return 0, l.errorAt(start, "unterminated template literal")
+}
+
+func (l *tsLexer) identifier() {
+	for l.pos < len(l.src) {
+		if l.src[l.pos] == '\\' {
+			l.pos += 2
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(l.src[l.pos:])
+		if !isTSIdentPart(r) {
+			return
+		}
+		l.pos += size
+	}
+}
+
+func (l *tsLexer) number() {
+	hex := l.src[l.pos] == '0' && strings.ContainsRune("xXoObB", rune(l.peek(1)))
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		if !hex && (c == 'e' || c == 'E') && (l.peek(1) == '+' || l.peek(1) == '-') {
+			l.pos += 2
+			continue
+		}
+		if !isDigit(c) && c != '.' && c != '_' && !unicode.IsLetter(rune(c)) {
+			return
+		}
+		l.pos++
+	}
+}
+
+// regexAllowed reports whether a slash at the current position starts a
+// regular expression rather than a division
+func (l *tsLexer) regexAllowed() bool {
+	if len(l.tokens) == 0 {
+		return true
+	}
+	prev := l.tokens[len(l.tokens)-1]
+	switch prev.kind {
+	case tsIdent:
+		return tsRegexKeywords[prev.text]
+	case tsPunct:
+		return prev.text != ")" && prev.text != "]" && prev.text != "}"
+	case tsTemplateHead, tsTemplateMiddle:
+		return true
+	}
+	return false
+}
+
+func (l *tsLexer) skipLine() {
+	for l.pos < len(l.src) && l.src[l.pos] != '\n' && l.src[l.pos] != '\r' {
+		l.pos++
+	}
+}
+
+func (l *tsLexer) peek(n int) byte {
+	if l.pos+n < len(l.src) {
+		return l.src[l.pos+n]
+	}
+	return 0
+}
+
+func (l *tsLexer) errorAt(offset int, message string) error {
+	line, column := tsPosition(l.src, offset)
+	return &TypeScriptError{Line: line, Column: column, Message: message}
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isTSIdentStart(r rune) bool {
+	return r == '_' || r == '$' || unicode.IsLetter(r)
+}
+
+func isTSIdentPart(r rune) bool {
+	return isTSIdentStart(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r) ||
+		unicode.Is(unicode.Mc, r) || r == '\u200C' || r == '\u200D'
+}
+
+// tsPosition returns the 1-based line and column of a byte offset
+func tsPosition(src string, offset int) (line, column int) {
+	line = 1
+	lineStart := 0
+	for i := 0; i < offset && i < len(src); i++ {
+		if src[i] == '\n' {
+			line++
+			lineStart = i + 1
+		}
+	}
+	return line, utf8.RuneCountInString(src[lineStart:min(offset, len(src))]) + 1
+}
+
+// tsStripper walks the tokens of a script, marking type syntax for removal
+type tsStripper struct {
+	src   string
+	toks  []tsToken // ends with a tsEOF token
+	match []int     // opener -> closer and closer -> opener; -1 for other tokens
+	blank []bool    // source bytes to replace with spaces
+	pos   int       // next token to scan
+	err   error
+}
+
+func newTSStripper(src string, toks []tsToken) *tsStripper {
+	s := &tsStripper{
+		src:   src,
+		toks:  toks,
+		match: make([]int, len(toks)),
+		blank: make([]bool, len(src)),
+	}
+
+	eof := len(toks) - 1
+	var stack []int
+	for i, t := range toks {
+		s.match[i] = -1
+		closes := t.kind == tsTemplateMiddle || t.kind == tsTemplateTail ||
+			(t.kind == tsPunct && (t.text == ")" || t.text == "]" || t.text == "}"))
+		if closes && len(stack) > 0 {
+			open := stack[len(stack)-1]
+			if tsCloser(toks[open]) == tsCloser(t) {
+				stack = stack[:len(stack)-1]
+				s.match[open] = i
+				if t.kind == tsPunct {
+					s.match[i] = open
+				}
+			}
+		}
+		if t.kind == tsTemplateHead || t.kind == tsTemplateMiddle ||
+			(t.kind == tsPunct && (t.text == "(" || t.text == "[" || t.text == "{")) {
+			stack = append(stack, i)
+		}
+	}
+	for _, open := range stack {
+		s.match[open] = eof
+	}
+	return s
+}
+
+// tsCloser returns the kind of bracket a token opens or closes
+func tsCloser(t tsToken) string {
+	switch t.kind {
+	case tsTemplateHead, tsTemplateMiddle, tsTemplateTail:
+		return "`"
+	}
+	switch t.text {
+	case "(", ")":
+		return ")"
+	case "[", "]":
+		return "]"
+	}
+	return "}"
+}
+
+func (s *tsStripper) output() string {
+	var sb strings.Builder
+	sb.Grow(len(s.src))
+	for i, r := range s.src {
+		if s.blank[i] && r != '\n' && r != '\r' && r != '\u2028' && r != '\u2029' {
+			sb.WriteByte(' ')
+			continue
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+func (s *tsStripper) tok(i int) tsToken {
+	if i < 0 || i >= len(s.toks) {
+		return s.toks[len(s.toks)-1]
+	}
+	return s.toks[i]
+}
+
+// remove marks the tokens from..to (exclusive) and the space between them
+func (s *tsStripper) remove(from, to int) {
+	if to <= from {
+		return
+	}
+	for i := s.tok(from).start; i < s.tok(to-1).end; i++ {
+		s.blank[i] = true
+	}
+}
+
+func (s *tsStripper) fail(i int, format string, args ...any) {
+	if s.err != nil {
+		return
+	}
+	line, column := tsPosition(s.src, s.tok(i).start)
+	s.err = &TypeScriptError{Line: line, Column: column, Message: fmt.Sprintf(format, args...)}
+}
+
+// skip returns the index after token i, or after the bracketed group it opens
+func (s *tsStripper) skip(i int) int {
+	t := s.tok(i)
+	switch {
+	case t.kind == tsTemplateHead:
+		j := s.match[i]
+		for s.tok(j).kind == tsTemplateMiddle {
+			j = s.match[j]
+		}
+		return j + 1
+	case t.is("(") || t.is("[") || t.is("{"):
+		return s.match[i] + 1
+	}
+	return i + 1
+}
+
+// exprEnd reports whether token i can end an expression, so that a token
+// after it on the same line continues the expression
+func (s *tsStripper) exprEnd(i int) bool {
+	if i < 0 {
+		return false
+	}
+	t := s.tok(i)
+	switch t.kind {
+	case tsNumber, tsString, tsRegex, tsTemplate, tsTemplateTail:
+		return true
+	case tsIdent:
+		return !tsKeywords[t.text]
+	case tsPunct:
+		if t.text == ")" {
+			// if (x) !y is a statement after a condition
+			if open := s.match[i]; open > 0 {
+				switch s.toks[open-1].text {
+				case "if", "while", "for", "with":
+					return false
+				}
+			}
+			return true
+		}
+		return t.text == "]" || t.text == "}"
+	}
+	return false
+}
+
+// lineBreakEnds reports whether the line break before token i ends the
+// statement or class member before it
+func (s *tsStripper) lineBreakEnds(i int) bool {
+	t := s.tok(i)
+	if !t.newline || i == 0 {
+		return false
+	}
+	if !s.exprEnd(i-1) && !s.tok(i-1).is("++") && !s.tok(i-1).is("--") {
+		return false
+	}
+	switch t.kind {
+	case tsIdent:
+		switch t.text {
+		case "in", "instanceof", "as", "satisfies":
+			return false
+		}
+		return true
+	case tsNumber, tsString, tsEOF:
+		return true
+	case tsPunct:
+		switch t.text {
+		case "{", "}", ";", "++", "--", "!", "~", "@":
+			return true
+		}
+	}
+	return false
+}
+
+// statementStart reports whether token i can start a statement
+func (s *tsStripper) statementStart(i int) bool {
+	if i == 0 {
+		return true
+	}
+	prev := s.tok(i - 1)
+	if prev.is(";") || prev.is("{") || prev.is("}") {
+		return true
+	}
+	return s.lineBreakEnds(i)
+}
+
+// scan processes tokens up to end, skipping end itself
+func (s *tsStripper) scan(end int) {
+	s.scanUntil(end, nil)
+}
+
+// scanUntil processes tokens up to end, or until stop reports true for a
+// token outside any brackets opened during the scan
+func (s *tsStripper) scanUntil(end int, stop func(i int) bool) {
+	inDecl := false // a comma continues a variable declaration
+	for s.pos < end && s.err == nil {
+		i := s.pos
+		if stop != nil && stop(i) {
+			return
+		}
+		t := s.toks[i]
+		if inDecl && s.lineBreakEnds(i) {
+			inDecl = false
+		}
+
+		switch t.kind {
+		case tsTemplateHead, tsTemplateMiddle:
+			close := s.match[i]
+			s.pos = i + 1
+			s.scan(close)
+			s.pos = close
+			continue
+		case tsPunct:
+			switch t.text {
+			case "(":
+				s.paren(i)
+				continue
+			case "{", "[":
+				close := s.match[i]
+				s.pos = i + 1
+				s.scan(close)
+				s.pos = close + 1
+				continue
+			case "<":
+				if s.angle(i) {
+					continue
+				}
+			case "!":
+				// Non-null assertion
+				if s.exprEnd(i-1) && !t.newline {
+					s.remove(i, i+1)
+				}
+			case ",":
+				if inDecl {
+					s.pos = i + 1
+					s.declarator()
+					continue
+				}
+			case ";":
+				inDecl = false
+			}
+		case tsIdent:
+			if prev := s.tok(i - 1); i > 0 && (prev.is(".") || prev.is("?.")) {
+				break // property name
+			}
+			switch t.text {
+			case "let", "const", "var":
+				next := s.tok(i + 1)
+				if t.text == "const" && next.is("enum") {
+					s.fail(i, "enums are not supported; only type annotations are removed, use a plain object instead")
+					return
+				}
+				if next.kind == tsIdent || next.is("{") || next.is("[") {
+					s.pos = i + 1
+					s.declarator()
+					inDecl = true
+					continue
+				}
+			case "function":
+				s.function(i)
+				continue
+			case "class":
+				s.class(i)
+				continue
+			case "as", "satisfies":
+				if s.exprEnd(i-1) && !t.newline {
+					end, ok := s.parseType(i + 1)
+					if !ok {
+						s.fail(i+1, "expected a type after %s", t.text)
+						return
+					}
+					s.remove(i, end)
+					s.pos = end
+					continue
+				}
+			case "interface", "type", "declare", "abstract", "enum", "namespace", "module":
+				if s.statementStart(i) && s.declaration(i) {
+					continue
+				}
+			}
+		}
+		s.pos = i + 1
+	}
+}
+
+// declaration handles TypeScript-only statements starting at token i,
+// reporting whether it did
+func (s *tsStripper) declaration(i int) bool {
+	t := s.tok(i)
+	next := s.tok(i + 1)
+	if next.newline {
+		return false
+	}
+
+	switch t.text {
+	case "interface", "type":
+		if next.kind != tsIdent {
+			return false
+		}
+		end, ok := s.typeDeclarationEnd(i)
+		if !ok {
+			return false
+		}
+		s.remove(i, end)
+		s.pos = end
+		return true
+
+	case "declare":
+		switch next.text {
+		case "var", "let", "const", "function", "class", "enum", "namespace", "module",
+			"global", "type", "interface", "abstract", "async":
+		default:
+			return false
+		}
+		end := s.declarationEnd(i)
+		s.remove(i, end)
+		s.pos = end
+		return true
+
+	case "abstract":
+		if !next.is("class") {
+			return false
+		}
+		s.remove(i, i+1)
+		s.pos = i + 1
+		return true
+
+	case "enum":
+		if next.kind == tsIdent && s.tok(i+2).is("{") {
+			s.fail(i, "enums are not supported; only type annotations are removed, use a plain object instead")
+			return true
+		}
+
+	case "namespace", "module":
+		if next.kind == tsIdent && (s.tok(i+2).is("{") || s.tok(i+2).is(".")) {
+			s.fail(i, "namespaces are not supported; only type annotations are removed")
+			return true
+		}
+	}
+	return false
+}
+
+// typeDeclarationEnd returns the index after the interface or type alias
+// declaration at token i
+func (s *tsStripper) typeDeclarationEnd(i int) (int, bool) {
+	j := i + 2
+	if s.tok(j).is("<") {
+		end, ok := s.parseTypeParams(j)
+		if !ok {
+			return i, false
+		}
+		j = end
+	}
+
+	if s.tok(i).is("type") {
+		if !s.tok(j).is("=") {
+			return i, false
+		}
+		end, ok := s.parseType(j + 1)
+		if !ok {
+			s.fail(j+1, "expected a type")
+			return i, false
+		}
+		if s.tok(end).is(";") {
+			end++
+		}
+		return end, true
+	}
+
+	if s.tok(j).is("extends") {
+		for {
+			end, ok := s.parseType(j + 1)
+			if !ok {
+				s.fail(j+1, "expected a type")
+				return i, false
+			}
+			j = end
+			if !s.tok(j).is(",") {
+				break
+			}
+		}
+	}
+	if !s.tok(j).is("{") {
+		return i, false
+	}
+	return s.skip(j), true
+}
+
+// declarationEnd returns the index after the declare statement at token i
+func (s *tsStripper) declarationEnd(i int) int {
+	j := i + 1
+	switch s.tok(j).text {
+	case "var", "let", "const":
+		j++
+		for {
+			j = s.skip(j) // name or pattern
+			if s.tok(j).is(":") {
+				if end, ok := s.parseType(j + 1); ok {
+					j = end
+				}
+			}
+			if !s.tok(j).is(",") {
+				break
+			}
+			j++
+		}
+	case "function", "async":
+		for t := s.tok(j); t.kind != tsEOF && !t.is("("); t = s.tok(j) {
+			j++
+		}
+		j = s.skip(j)
+		if s.tok(j).is(":") {
+			if end, ok := s.parseType(j + 1); ok {
+				j = end
+			}
+		}
+	case "type", "interface":
+		if end, ok := s.typeDeclarationEnd(j); ok {
+			return end
+		}
+		fallthrough
+	default:
+		// Classes, enums, namespaces and modules end with their body
+		for t := s.tok(j); t.kind != tsEOF && !t.is("{"); t = s.tok(j) {
+			j = s.skip(j)
+		}
+		j = s.skip(j)
+	}
+	if s.tok(j).is(";") {
+		j++
+	}
+	return j
+}
+
+// declarator handles a variable name or pattern and its type
+func (s *tsStripper) declarator() {
+	i := s.pos
+	t := s.tok(i)
+	switch {
+	case t.is("{") || t.is("["):
+		close := s.match[i]
+		s.pos = i + 1
+		s.scan(close)
+		i = close + 1
+	case t.kind == tsIdent:
+		i++
+	default:
+		return
+	}
+
+	if s.tok(i).is("!") && s.tok(i+1).is(":") {
+		s.remove(i, i+1)
+		i++
+	}
+	if s.tok(i).is(":") {
+		end, ok := s.parseType(i + 1)
+		if !ok {
+			s.fail(i+1, "expected a type")
+			return
+		}
+		s.remove(i, end)
+		i = end
+	}
+	s.pos = i
+}
+
+// paren handles the parenthesized group opening at token i
+func (s *tsStripper) paren(i int) {
+	close := s.match[i]
+	prev := s.tok(i - 1)
+
+	// Arrow function parameters; arrows in the first branch of a conditional
+	// cannot have a return type, as its colon belongs to the conditional
+	if i == 0 || !s.exprEnd(i-1) || prev.is("async") {
+		if arrow, ok := s.arrow(close, !prev.is("?")); ok {
+			s.params(i, close)
+			s.remove(close+1, arrow)
+			s.pos = arrow
+			return
+		}
+	}
+
+	// catch (e: unknown)
+	if prev.is("catch") {
+		s.params(i, close)
+		s.pos = close + 1
+		return
+	}
+
+	// Method definitions in object literals
+	if s.methodName(i - 1) {
+		j := close + 1
+		if s.tok(j).is(":") {
+			if end, ok := s.parseType(j + 1); ok && s.tok(end).is("{") {
+				s.params(i, close)
+				s.remove(j, end)
+				s.pos = end
+				return
+			}
+		} else if s.tok(j).is("{") && !s.tok(j).newline {
+			s.params(i, close)
+			s.pos = j
+			return
+		}
+	}
+
+	s.pos = i + 1
+	s.scan(close)
+	s.pos = close + 1
+}
+
+// arrow reports whether the parenthesized group closing at token close is
+// followed by an arrow, optionally after a return type, and returns the
+// index of the arrow
+func (s *tsStripper) arrow(close int, returnType bool) (int, bool) {
+	next := s.tok(close + 1)
+	if next.is("=>") && !next.newline {
+		return close + 1, true
+	}
+	if returnType && next.is(":") {
+		if end, ok := s.parseType(close + 2); ok && s.tok(end).is("=>") && !s.tok(end).newline {
+			return end, true
+		}
+	}
+	return 0, false
+}
+
+// methodName reports whether token i names a method in an object literal
+func (s *tsStripper) methodName(i int) bool {
+	t := s.tok(i)
+	if i < 0 || t.kind != tsIdent {
+		return false
+	}
+	prev := s.tok(i - 1)
+	if i > 0 && (prev.is(".") || prev.is("?.")) {
+		return false
+	}
+	return !tsKeywords[t.text] || (i > 0 && prev.is(","))
+}
+
+// angle handles a < at token i that starts type arguments or the type
+// parameters of an arrow function, reporting whether it did
+func (s *tsStripper) angle(i int) bool {
+	prev := s.tok(i - 1)
+
+	// f<T>(x), new Map<K, V>(), tag<T>`...`
+	if i > 0 && (prev.kind == tsIdent || prev.is(")") || prev.is("]")) && s.exprEnd(i-1) {
+		if end, ok := s.parseTypeArgs(i); ok {
+			if next := s.tok(end); next.is("(") || next.kind == tsTemplate || next.kind == tsTemplateHead {
+				s.remove(i, end)
+				s.pos = end
+				return true
+			}
+		}
+	}
+
+	// <T>(x: T) => x
+	if i == 0 || !s.exprEnd(i-1) || prev.is("async") {
+		if end, ok := s.parseTypeParams(i); ok && s.tok(end).is("(") {
+			close := s.match[end]
+			if arrow, ok := s.arrow(close, !prev.is("?")); ok {
+				s.remove(i, end)
+				s.params(end, close)
+				s.remove(close+1, arrow)
+				s.pos = arrow
+				return true
+			}
+		}
+		if end, ok := s.parseTypeArgs(i); ok && !s.exprEnd(i-1) {
+			if next := s.tok(end); next.kind != tsPunct || next.is("(") {
+				s.fail(i, "angle-bracket type assertions are not supported; use `as` instead")
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// params handles the parameter list between open and close
+func (s *tsStripper) params(open, close int) {
+	i := open + 1
+	for i < close && s.err == nil {
+		start := i
+		t := s.tok(i)
+		switch t.text {
+		case "public", "private", "protected", "readonly", "override":
+			if next := s.tok(i + 1); next.kind == tsIdent || next.is("{") || next.is("[") {
+				s.fail(i, "parameter properties are not supported; only type annotations are removed, assign the field in the constructor instead")
+				return
+			}
+		}
+		thisParam := t.is("this") && s.tok(i+1).is(":")
+		if t.is("...") {
+			i++
+		}
+
+		switch t := s.tok(i); {
+		case t.is("{") || t.is("["):
+			end := s.match[i]
+			s.pos = i + 1
+			s.scan(end)
+			i = end + 1
+		case t.kind == tsIdent:
+			i++
+		default:
+			// Not a parameter list after all
+			s.pos = i
+			s.scan(close)
+			return
+		}
+
+		if s.tok(i).is("?") {
+			s.remove(i, i+1)
+			i++
+		}
+		if s.tok(i).is(":") {
+			end, ok := s.parseType(i + 1)
+			if !ok {
+				s.fail(i+1, "expected a type")
+				return
+			}
+			s.remove(i, end)
+			i = end
+		}
+		if s.tok(i).is("=") {
+			s.pos = i + 1
+			s.scanUntil(close, func(j int) bool { return s.tok(j).is(",") })
+			i = s.pos
+		}
+
+		if s.tok(i).is(",") {
+			i++
+		} else if i < close {
+			s.pos = i
+			s.scan(close)
+			return
+		}
+		if thisParam {
+			// The this parameter only types this; it is not a real parameter
+			s.remove(start, i)
+		}
+	}
+}
+
+// function handles the function keyword at token i
+func (s *tsStripper) function(i int) {
+	j := i + 1
+	if s.tok(j).is("*") {
+		j++
+	}
+	if s.tok(j).kind == tsIdent {
+		j++
+	}
+	if s.tok(j).is("<") {
+		if end, ok := s.parseTypeParams(j); ok {
+			s.remove(j, end)
+			j = end
+		}
+	}
+	if !s.tok(j).is("(") {
+		s.pos = i + 1
+		return
+	}
+	close := s.match[j]
+	s.params(j, close)
+	j = close + 1
+	if s.tok(j).is(":") {
+		end, ok := s.parseType(j + 1)
+		if !ok {
+			s.fail(j+1, "expected a type")
+			return
+		}
+		s.remove(j, end)
+		j = end
+	}
+
+	if !s.tok(j).is("{") {
+		// Overload signature
+		start := i
+		if s.tok(i - 1).is("async") {
+			start = i - 1
+		}
+		if s.tok(j).is(";") {
+			j++
+		}
+		s.remove(start, j)
+	}
+	s.pos = j
+}
+
+// class handles the class keyword at token i
+func (s *tsStripper) class(i int) {
+	j := i + 1
+	if t := s.tok(j); t.kind == tsIdent && !t.is("extends") && !t.is("implements") {
+		j++
+	}
+	if s.tok(j).is("<") {
+		if end, ok := s.parseTypeParams(j); ok {
+			s.remove(j, end)
+			j = end
+		}
+	}
+
+	if s.tok(j).is("extends") {
+		j++
+		for t := s.tok(j); t.kind != tsEOF && !t.is("{") && !t.is("implements"); t = s.tok(j) {
+			if t.is("<") {
+				if end, ok := s.parseTypeArgs(j); ok {
+					s.remove(j, end)
+					j = end
+					continue
+				}
+			}
+			if t.is("(") || t.is("[") {
+				close := s.match[j]
+				s.pos = j + 1
+				s.scan(close)
+			}
+			j = s.skip(j)
+		}
+	}
+
+	if s.tok(j).is("implements") {
+		k := j + 1
+		for {
+			end, ok := s.parseType(k)
+			if !ok {
+				s.fail(k, "expected a type")
+				return
+			}
+			k = end
+			if !s.tok(k).is(",") {
+				break
+			}
+			k++
+		}
+		s.remove(j, k)
+		j = k
+	}
+
+	if !s.tok(j).is("{") {
+		s.pos = j
+		return
+	}
+	close := s.match[j]
+	s.pos = j + 1
+	for s.pos < close && s.err == nil {
+		s.member(close)
+	}
+	s.pos = close + 1
+}
+
+// tsMemberModifiers are the keywords that can precede a class member name
+var tsMemberModifiers = map[string]bool{
+	"public": true, "private": true, "protected": true, "readonly": true,
+	"override": true, "abstract": true, "declare": true, "static": true,
+	"async": true, "get": true, "set": true, "accessor": true,
+}
+
+// member handles the class member starting at s.pos in a class body that
+// closes at close
+func (s *tsStripper) member(close int) {
+	start := s.pos
+	i := start
+	if s.tok(i).is(";") {
+		s.pos++
+		return
+	}
+
+	declared := false // declare fields and abstract members have no code
+	for t := s.tok(i); t.kind == tsIdent && tsMemberModifiers[t.text]; t = s.tok(i) {
+		next := s.tok(i + 1)
+		if next.kind != tsIdent && next.kind != tsString && next.kind != tsNumber &&
+			!next.is("[") && !next.is("*") && !next.is("{") {
+			break // the modifier is the member name
+		}
+		switch t.text {
+		case "declare", "abstract":
+			declared = true
+		case "public", "private", "protected", "readonly", "override":
+			s.remove(i, i+1)
+		}
+		i++
+	}
+	if s.tok(i).is("*") {
+		i++
+	}
+
+	// static { ... }
+	if s.tok(i).is("{") {
+		end := s.match[i]
+		s.pos = i + 1
+		s.scan(end)
+		s.pos = end + 1
+		return
+	}
+
+	// Index signatures: [key: string]: T
+	if s.tok(i).is("[") && s.tok(i+1).kind == tsIdent && s.tok(i+2).is(":") {
+		declared = true
+	}
+	if declared {
+		end := s.memberEnd(i, close)
+		s.remove(start, end)
+		s.pos = end
+		return
+	}
+
+	name := s.tok(i)
+	if name.is("[") {
+		end := s.match[i]
+		s.pos = i + 1
+		s.scan(end)
+		i = end + 1
+	} else if i < close {
+		i++
+	}
+	if t := s.tok(i); t.is("?") || t.is("!") {
+		s.remove(i, i+1)
+		i++
+	}
+	if s.tok(i).is("<") {
+		if end, ok := s.parseTypeParams(i); ok {
+			s.remove(i, end)
+			i = end
+		}
+	}
+
+	// Methods
+	if s.tok(i).is("(") {
+		end := s.match[i]
+		s.params(i, end)
+		i = end + 1
+		if s.tok(i).is(":") {
+			typeEnd, ok := s.parseType(i + 1)
+			if !ok {
+				s.fail(i+1, "expected a type")
+				return
+			}
+			s.remove(i, typeEnd)
+			i = typeEnd
+		}
+		if !s.tok(i).is("{") {
+			// Overload signature
+			if s.tok(i).is(";") {
+				i++
+			}
+			s.remove(start, i)
+			s.pos = i
+			return
+		}
+		end = s.match[i]
+		s.pos = i + 1
+		s.scan(end)
+		s.pos = end + 1
+		return
+	}
+
+	// Fields
+	if s.tok(i).is(":") {
+		end, ok := s.parseType(i + 1)
+		if !ok {
+			s.fail(i+1, "expected a type")
+			return
+		}
+		s.remove(i, end)
+		i = end
+	}
+	s.pos = i
+	if s.tok(i).is("=") {
+		s.pos = i + 1
+		s.scanUntil(close, func(j int) bool {
+			return s.tok(j).is(";") || s.lineBreakEnds(j)
+		})
+	}
+	if s.tok(s.pos).is(";") {
+		s.pos++
+	}
+}
+
+// memberEnd returns the index after the class member whose name starts at
+// token i
+func (s *tsStripper) memberEnd(i, close int) int {
+	start := i
+	for i < close {
+		if s.tok(i).is(";") {
+			return i + 1
+		}
+		if i > start && s.tok(i).newline && !tsContinuesType(s.tok(i-1)) && !tsContinuesType(s.tok(i)) {
+			return i
+		}
+		i = s.skip(i)
+	}
+	return close
+}
+
+// tsContinuesType reports whether a type or signature continues over a line
+// break before or after token t
+func tsContinuesType(t tsToken) bool {
+	switch t.text {
+	case "|", "&", ",", ":", "=>", "=", ".", "<", "?", "(", "[", "{", "extends", "keyof", "typeof", "is":
+		return t.kind == tsPunct || t.kind == tsIdent
+	}
+	return false
+}
+
+// parseType returns the index after the type starting at token i
+func (s *tsStripper) parseType(i int) (int, bool) {
+	end, ok := s.parseUnion(i)
+	if !ok {
+		return i, false
+	}
+	// Conditional types: T extends U ? X : Y
+	if s.tok(end).is("extends") && !s.tok(end).newline {
+		if j, ok := s.parseUnion(end + 1); ok && s.tok(j).is("?") {
+			if j, ok = s.parseType(j + 1); ok && s.tok(j).is(":") {
+				return s.parseType(j + 1)
+			}
+		}
+	}
+	return end, true
+}
+
+func (s *tsStripper) parseUnion(i int) (int, bool) {
+	if s.tok(i).is("|") {
+		i++
+	}
+	i, ok := s.parseIntersection(i)
+	for ok && s.tok(i).is("|") {
+		i, ok = s.parseIntersection(i + 1)
+	}
+	return i, ok
+}
+
+func (s *tsStripper) parseIntersection(i int) (int, bool) {
+	if s.tok(i).is("&") {
+		i++
+	}
+	i, ok := s.parseOperator(i)
+	for ok && s.tok(i).is("&") {
+		i, ok = s.parseOperator(i + 1)
+	}
+	return i, ok
+}
+
+func (s *tsStripper) parseOperator(i int) (int, bool) {
+	t := s.tok(i)
+	switch t.text {
+	case "keyof", "unique", "readonly":
+		if t.kind == tsIdent && s.typeStart(i+1) {
+			return s.parseOperator(i + 1)
+		}
+	case "infer":
+		if t.kind == tsIdent && s.tok(i+1).kind == tsIdent {
+			return i + 2, true
+		}
+	}
+
+	i, ok := s.parsePrimary(i)
+	// Arrays and indexed access: T[], T["key"]
+	for ok && s.tok(i).is("[") && !s.tok(i).newline {
+		if s.tok(i + 1).is("]") {
+			i += 2
+			continue
+		}
+		var end int
+		end, ok = s.parseType(i + 1)
+		if !ok || !s.tok(end).is("]") {
+			return i, false
+		}
+		i = end + 1
+	}
+	return i, ok
+}
+
+// typeStart reports whether token i can start a type
+func (s *tsStripper) typeStart(i int) bool {
+	t := s.tok(i)
+	switch t.kind {
+	case tsIdent, tsString, tsNumber, tsTemplate, tsTemplateHead:
+		return true
+	case tsPunct:
+		switch t.text {
+		case "(", "[", "{", "<", "-", "|", "&":
+			return true
+		}
+	}
+	return false
+}
+
+func (s *tsStripper) parsePrimary(i int) (int, bool) {
+	t := s.tok(i)
+	switch t.kind {
+	case tsString, tsNumber, tsTemplate:
+		return i + 1, true
+	case tsTemplateHead:
+		return s.skip(i), true
+	case tsPunct:
+		switch t.text {
+		case "(":
+			close := s.match[i]
+			if s.tok(close + 1).is("=>") {
+				return s.parseType(close + 2) // function type
+			}
+			if end, ok := s.parseType(i + 1); ok && end == close {
+				return close + 1, true
+			}
+		case "{", "[":
+			return s.skip(i), s.match[i] < len(s.toks)-1
+		case "<":
+			// Generic function type: <T>(x: T) => T
+			if end, ok := s.parseTypeParams(i); ok && s.tok(end).is("(") {
+				close := s.match[end]
+				if s.tok(close + 1).is("=>") {
+					return s.parseType(close + 2)
+				}
+			}
+		case "-":
+			if s.tok(i+1).kind == tsNumber {
+				return i + 2, true
+			}
+		}
+		return i, false
+	case tsIdent:
+	default:
+		return i, false
+	}
+
+	switch t.text {
+	case "new":
+		// Constructor types: new (...args: any[]) => T
+		j := i + 1
+		if s.tok(j).is("<") {
+			end, ok := s.parseTypeParams(j)
+			if !ok {
+				return i, false
+			}
+			j = end
+		}
+		if s.tok(j).is("(") && s.tok(s.match[j]+1).is("=>") {
+			return s.parseType(s.match[j] + 2)
+		}
+		return i, false
+	case "abstract":
+		if s.tok(i + 1).is("new") {
+			return s.parsePrimary(i + 1)
+		}
+	case "typeof":
+		if s.tok(i + 1).is("import") {
+			return s.parsePrimary(i + 1)
+		}
+		j := i + 1
+		if s.tok(j).kind != tsIdent {
+			return i, false
+		}
+		return s.typeReference(j)
+	case "import":
+		if !s.tok(i + 1).is("(") {
+			return i, false
+		}
+		j := s.skip(i + 1)
+		for s.tok(j).is(".") && s.tok(j+1).kind == tsIdent {
+			j += 2
+		}
+		if s.tok(j).is("<") {
+			return s.parseTypeArgs(j)
+		}
+		return j, true
+	case "asserts":
+		// asserts x, asserts x is T
+		if next := s.tok(i + 1); next.kind == tsIdent && !next.is("is") && !next.newline {
+			if s.tok(i + 2).is("is") {
+				return s.parseType(i + 3)
+			}
+			return i + 2, true
+		}
+	}
+
+	// Type predicates: x is T
+	if next := s.tok(i + 1); next.is("is") && !next.newline {
+		return s.parseType(i + 2)
+	}
+	return s.typeReference(i)
+}
+
+// typeReference parses a possibly qualified type name and its type arguments
+func (s *tsStripper) typeReference(i int) (int, bool) {
+	j := i + 1
+	for s.tok(j).is(".") && s.tok(j+1).kind == tsIdent {
+		j += 2
+	}
+	if s.tok(j).is("<") && !s.tok(j).newline {
+		return s.parseTypeArgs(j)
+	}
+	return j, true
+}
+
+// parseTypeArgs parses type arguments starting with the < at token i
+func (s *tsStripper) parseTypeArgs(i int) (int, bool) {
+	j := i + 1
+	if s.tok(j).is(">") {
+		return i, false
+	}
+	for {
+		end, ok := s.parseType(j)
+		if !ok {
+			return i, false
+		}
+		j = end
+		if s.tok(j).is(",") {
+			j++
+		}
+		if s.tok(j).is(">") {
+			return j + 1, true
+		}
+		if j == end {
+			return i, false
+		}
+	}
+}
+
+// parseTypeParams parses type parameters starting with the < at token i
+func (s *tsStripper) parseTypeParams(i int) (int, bool) {
+	j := i + 1
+	for {
+		for t := s.tok(j); (t.is("const") || t.is("in") || t.is("out")) && s.tok(j+1).kind == tsIdent; t = s.tok(j) {
+			j++
+		}
+		if s.tok(j).kind != tsIdent {
+			return i, false
+		}
+		j++
+		if s.tok(j).is("extends") {
+			end, ok := s.parseType(j + 1)
+			if !ok {
+				return i, false
+			}
+			j = end
+		}
+		if s.tok(j).is("=") {
+			end, ok := s.parseType(j + 1)
+			if !ok {
+				return i, false
+			}
+			j = end
+		}
+		comma := s.tok(j).is(",")
+		if comma {
+			j++
+		}
+		if s.tok(j).is(">") {
+			return j + 1, true
+		}
+		if !comma {
+			return i, false
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package js
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// squeeze collapses runs of spaces so expectations need not count them
+func squeeze(s string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = strings.Join(strings.Fields(line), " ")
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+func TestStripTypes(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		expected string
+	}{
+		{"plain javascript", `const a = b < c && d > (e); /<T>(x: T)/.test(s) ? a : b`, `const a = b < c && d > (e); /<T>(x: T)/.test(s) ? a : b`},
+		{"variable annotations", `let a: number = 1, b: { x: string } = { x: "" }; let c!: string[]`, `let a = 1, b = { x: "" }; let c`},
+		{"interface", "interface User {\n  id: number\n}\nconst x = 1", "\n\n\nconst x = 1"},
+		{"type alias", "type Id<T = string> = T | number;\nconst y = 2", "\nconst y = 2"},
+		{"declare", "declare const env: Record<string, string>\nfoo()", "\nfoo()"},
+		{"function", `function pick<T, K extends keyof T>(obj: T, key: K): T[K] { return obj[key] }`, `function pick (obj , key ) { return obj[key] }`},
+		{"overloads", "function f(a: string): string;\nfunction f(a: any) { return a }", "\nfunction f(a ) { return a }"},
+		{"optional and this params", `function g(this: Window, a?: number, b = 2) {}`, `function g( a , b = 2) {}`},
+		{"arrow functions", `const f = async <T,>(x: T): Promise<T> => x; const h = ({ a }: Props): void => a`, `const f = async (x ) => x; const h = ({ a } ) => a`},
+		{"conditional is not a return type", `const r = c ? (x) : y => y`, `const r = c ? (x) : y => y`},
+		{"as and satisfies", `const v = (x as unknown as string[]).length + (o satisfies Record<string, unknown>).a`, `const v = (x ).length + (o ).a`},
+		{"as const", `const modes = ["a", "b"] as const`, `const modes = ["a", "b"]`},
+		{"non-null assertions", `a!.b; c![0]; d!(); e != f; g!`, `a .b; c [0]; d (); e != f; g`},
+		{"generic calls", "new Map<string, number[]>(); f<T>(x); tag<T>`s`", "new Map (); f (x); tag `s`"},
+		{"catch binding", `try {} catch (e: unknown) {}`, `try {} catch (e ) {}`},
+		{"object methods", `const o = { m(x: number): number { return x }, n: (y: string) => y }`, `const o = { m(x ) { return x }, n: (y ) => y }`},
+		{"template substitutions", "`${x as string}-${y!}`", "`${x }-${y }`"},
+		{"class", `abstract class A<T> extends B<T> implements C, D<T> {
+  private readonly items: Map<string, T> = new Map()
+  declare extra: string
+  abstract run(): void
+  static count?: number;
+  [key: string]: unknown
+  constructor(x: number) { super(x) }
+  get size(): number { return this.items.size }
+  public async *gen<U>(x: U): AsyncGenerator<U> { yield x }
+  m(): void;
+  m(a?: string): void {}
+}`, `class A extends B {
+items = new Map()
+
+
+static count ;
+
+constructor(x ) { super(x) }
+get size() { return this.items.size }
+async *gen (x ) { yield x }
+
+m(a ) {}
+}`},
+		{"for of destructuring", `for (const [k, v] of Object.entries(o as Record<string, number>)) {}`, `for (const [k, v] of Object.entries(o )) {}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := StripTypes(tt.source)
+			require.NoError(t, err)
+			assert.Equal(t, squeeze(tt.expected), squeeze(output))
+			// Positions are kept
+			assert.Equal(t, len([]rune(tt.source)), len([]rune(output)))
+			assert.Equal(t, strings.Count(tt.source, "\n"), strings.Count(output, "\n"))
+		})
+	}
+}
+
+func TestStripTypes_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		source  string
+		line    int
+		column  int
+		message string
+	}{
+		{"enum", "const a = 1\nenum Color { Red }", 2, 1, "enums are not supported"},
+		{"const enum", "\n\n  const enum E { A }", 3, 3, "enums are not supported"},
+		{"namespace", "namespace NS { export const a = 1 }", 1, 1, "namespaces are not supported"},
+		{"parameter property", "class A {\n  constructor(private x: number) {}\n}", 2, 15, "parameter properties are not supported"},
+		{"type assertion", "const a = <string>b", 1, 11, "type assertions are not supported"},
+		{"missing type", "let a: = 1", 1, 8, "expected a type"},
+		{"unterminated string", "let a = 'x\nb'", 1, 9, "unterminated string literal"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := StripTypes(tt.source)
+			require.Error(t, err)
+			var tsErr *TypeScriptError
+			require.ErrorAs(t, err, &tsErr)
+			assert.Equal(t, tt.line, tsErr.Line)
+			assert.Equal(t, tt.column, tsErr.Column)
+			assert.Contains(t, tsErr.Message, tt.message)
+		})
+	}
+}
@@ -463,6 +463,8 @@ func TestInitLogger_MultipleInits(t *testing.T) {
 
 func TestInitLogger_PathSecurity(t *testing.T) {
 	tmpDir := t.TempDir()
+	// The relative path resolves against the working directory
+	t.Chdir(tmpDir)
 
 	tests := []struct {
 		name              string
@@ -0,0 +1,208 @@
+// Package ratelimit enforces token-bucket rate limits and concurrency caps on
+// tool calls. A call that exceeds a limit either waits for up to the configured
+// maximum wait or is rejected with a *LimitError that says when to retry.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/vaayne/mcphub/internal/config"
+)
+
+// Limit reasons
+const (
+	ReasonRate        = "rate"
+	ReasonConcurrency = "concurrency"
+)
+
+// LimitError is returned when a call is rejected by a limiter
+type LimitError struct {
+	Scope      string        `json:"scope"`
+	Reason     string        `json:"reason"`
+	RetryAfter time.Duration `json:"retryAfter"`
+}
+
+// Error implements the error interface
+func (e *LimitError) Error() string {
+	what := "rate limit exceeded"
+	if e.Reason == ReasonConcurrency {
+		what = "too many concurrent calls"
+	}
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("%s for %s, retry after %s", what, e.Scope, e.RetryAfter.Round(time.Millisecond))
+	}
+	return fmt.Sprintf("%s for %s, retry later", what, e.Scope)
+}
+
+// Stats counts the outcomes of calls that went through a limiter
+type Stats struct {
+	Scope    string `json:"scope"`
+	Allowed  uint64 `json:"allowed"`  // admitted without waiting
+	Queued   uint64 `json:"queued"`   // admitted after waiting
+	Rejected uint64 `json:"rejected"` // rejected or gave up waiting
+	InFlight int64  `json:"inFlight"`
+}
+
+// Limiter applies one RateLimitConfig to the calls sharing it
+type Limiter struct {
+	scope   string
+	rate    float64 // tokens per second, 0 = unlimited
+	burst   float64
+	maxWait time.Duration
+	slots   chan struct{} // nil = unlimited concurrency
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+	now    func() time.Time
+
+	allowed  atomic.Uint64
+	queued   atomic.Uint64
+	rejected atomic.Uint64
+	inFlight atomic.Int64
+}
+
+// New creates a limiter for cfg. scope names the limit in errors and stats
+// (e.g. "server 'github'").
+func New(scope string, cfg config.RateLimitConfig) *Limiter {
+	l := &Limiter{
+		scope:   scope,
+		rate:    cfg.RequestsPerSecond,
+		burst:   float64(cfg.GetBurst()),
+		maxWait: cfg.GetMaxWait(),
+		now:     time.Now,
+	}
+	l.tokens = l.burst
+	l.last = l.now()
+	if cfg.MaxConcurrent > 0 {
+		l.slots = make(chan struct{}, cfg.MaxConcurrent)
+	}
+	return l
+}
+
+// Acquire admits a call, waiting up to the limiter's maximum wait (bounded by
+// ctx) if needed. On success the caller must call release when the call ends.
+func (l *Limiter) Acquire(ctx context.Context) (release func(), err error) {
+	deadline := l.now().Add(l.maxWait)
+
+	waitedForToken, err := l.takeToken(ctx, deadline)
+	if err != nil {
+		l.rejected.Add(1)
+		return nil, err
+	}
+
+	waitedForSlot, err := l.takeSlot(ctx, deadline)
+	if err != nil {
+		// A call refused a slot never runs, so it doesn't spend the rate
+		l.returnToken()
+		l.rejected.Add(1)
+		return nil, err
+	}
+
+	if waitedForToken || waitedForSlot {
+		l.queued.Add(1)
+	} else {
+		l.allowed.Add(1)
+	}
+
+	l.inFlight.Add(1)
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.inFlight.Add(-1)
+			if l.slots != nil {
+				<-l.slots
+			}
+		})
+	}, nil
+}
+
+// takeToken reserves a token, sleeping until it is available if that is
+// possible before deadline
+func (l *Limiter) takeToken(ctx context.Context, deadline time.Time) (bool, error) {
+	if l.rate <= 0 {
+		return false, nil
+	}
+
+	l.mu.Lock()
+	now := l.now()
+	l.tokens = min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+	l.tokens--
+	if l.tokens >= 0 {
+		l.mu.Unlock()
+		return false, nil
+	}
+
+	wait := time.Duration(-l.tokens / l.rate * float64(time.Second))
+	if now.Add(wait).After(deadline) {
+		l.tokens++
+		l.mu.Unlock()
+		return false, &LimitError{Scope: l.scope, Reason: ReasonRate, RetryAfter: wait}
+	}
+	l.mu.Unlock()
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true, nil
+	case <-ctx.Done():
+		l.returnToken()
+		return false, ctx.Err()
+	}
+}
+
+// returnToken gives back a token taken by takeToken
+func (l *Limiter) returnToken() {
+	if l.rate <= 0 {
+		return
+	}
+	l.mu.Lock()
+	l.tokens++
+	l.mu.Unlock()
+}
+
+// takeSlot claims a concurrency slot, waiting until deadline for one to free up
+func (l *Limiter) takeSlot(ctx context.Context, deadline time.Time) (bool, error) {
+	if l.slots == nil {
+		return false, nil
+	}
+
+	select {
+	case l.slots <- struct{}{}:
+		return false, nil
+	default:
+	}
+
+	wait := deadline.Sub(l.now())
+	if wait <= 0 {
+		return false, &LimitError{Scope: l.scope, Reason: ReasonConcurrency}
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case l.slots <- struct{}{}:
+		return true, nil
+	case <-timer.C:
+		return false, &LimitError{Scope: l.scope, Reason: ReasonConcurrency}
+	case <-ctx.Done():
+		return false, ctx.Err()
+	}
+}
+
+// Stats returns the limiter's counters
+func (l *Limiter) Stats() Stats {
+	return Stats{
+		Scope:    l.scope,
+		Allowed:  l.allowed.Load(),
+		Queued:   l.queued.Load(),
+		Rejected: l.rejected.Load(),
+		InFlight: l.inFlight.Load(),
+	}
+}
@@ -0,0 +1,114 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vaayne/mcphub/internal/config"
+)
+
+func TestLimiter_RejectsOverRate(t *testing.T) {
+	l := New("server 'test'", config.RateLimitConfig{RequestsPerSecond: 1, Burst: 2})
+	ctx := context.Background()
+
+	for range 2 {
+		release, err := l.Acquire(ctx)
+		require.NoError(t, err)
+		release()
+	}
+
+	_, err := l.Acquire(ctx)
+	var limitErr *LimitError
+	require.ErrorAs(t, err, &limitErr)
+	assert.Equal(t, ReasonRate, limitErr.Reason)
+	assert.InDelta(t, time.Second, limitErr.RetryAfter, float64(100*time.Millisecond))
+	assert.Contains(t, err.Error(), "rate limit exceeded for server 'test', retry after")
+
+	stats := l.Stats()
+	assert.Equal(t, uint64(2), stats.Allowed)
+	assert.Equal(t, uint64(1), stats.Rejected)
+}
+
+func TestLimiter_QueuesWithinMaxWait(t *testing.T) {
+	l := New("test", config.RateLimitConfig{RequestsPerSecond: 20, Burst: 1, MaxWait: 1})
+	ctx := context.Background()
+
+	release, err := l.Acquire(ctx)
+	require.NoError(t, err)
+	release()
+
+	start := time.Now()
+	release, err = l.Acquire(ctx)
+	require.NoError(t, err)
+	release()
+	assert.GreaterOrEqual(t, time.Since(start), 40*time.Millisecond)
+
+	stats := l.Stats()
+	assert.Equal(t, uint64(1), stats.Allowed)
+	assert.Equal(t, uint64(1), stats.Queued)
+}
+
+func TestLimiter_ConcurrencyCap(t *testing.T) {
+	l := New("test", config.RateLimitConfig{MaxConcurrent: 1, MaxWait: 0.05})
+	ctx := context.Background()
+
+	release, err := l.Acquire(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), l.Stats().InFlight)
+
+	_, err = l.Acquire(ctx)
+	var limitErr *LimitError
+	require.ErrorAs(t, err, &limitErr)
+	assert.Equal(t, ReasonConcurrency, limitErr.Reason)
+
+	// A slot freed while waiting admits the queued call
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		release()
+	}()
+	release2, err := l.Acquire(ctx)
+	require.NoError(t, err)
+	release2()
+	release2() // release is idempotent
+
+	stats := l.Stats()
+	assert.Equal(t, int64(0), stats.InFlight)
+	assert.Equal(t, uint64(1), stats.Queued)
+	assert.Equal(t, uint64(1), stats.Rejected)
+}
+
+// TestLimiter_ConcurrencyRejectionKeepsRate verifies calls refused a slot
+// give back their rate token, so later calls are still admitted
+func TestLimiter_ConcurrencyRejectionKeepsRate(t *testing.T) {
+	l := New("test", config.RateLimitConfig{RequestsPerSecond: 0.001, Burst: 2, MaxConcurrent: 1})
+	ctx := context.Background()
+
+	release, err := l.Acquire(ctx)
+	require.NoError(t, err)
+	for range 3 {
+		_, err = l.Acquire(ctx)
+		var limitErr *LimitError
+		require.ErrorAs(t, err, &limitErr)
+		assert.Equal(t, ReasonConcurrency, limitErr.Reason)
+	}
+	release()
+
+	release, err = l.Acquire(ctx)
+	require.NoError(t, err, "rejected calls must not use up the rate")
+	release()
+}
+
+func TestLimiter_ContextCanceled(t *testing.T) {
+	l := New("test", config.RateLimitConfig{RequestsPerSecond: 1, MaxWait: 10})
+	release, err := l.Acquire(context.Background())
+	require.NoError(t, err)
+	release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = l.Acquire(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
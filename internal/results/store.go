@@ -0,0 +1,160 @@
+// Package results keeps the full output of tool calls that were too large to
+// return to the client. Oversized results are truncated and the complete text is
+// stored under a handle that the read built-in tool pages through.
+package results
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// DefaultMaxEntries is the number of stored results kept before the oldest is evicted
+	DefaultMaxEntries = 100
+	// DefaultTTL is how long a stored result can be read after it was stored
+	DefaultTTL = 30 * time.Minute
+)
+
+// entry is a single stored result
+type entry struct {
+	tool    string
+	text    string
+	created time.Time
+}
+
+// Store is an in-memory, bounded store of full tool outputs
+type Store struct {
+	mu         sync.Mutex
+	entries    map[string]*entry
+	order      []string // handles, oldest first
+	maxEntries int
+	ttl        time.Duration
+	now        func() time.Time
+}
+
+// NewStore creates a store keeping at most maxEntries results for ttl.
+// Non-positive values fall back to the defaults.
+func NewStore(maxEntries int, ttl time.Duration) *Store {
+	if maxEntries <= 0 {
+		maxEntries = DefaultMaxEntries
+	}
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	return &Store{
+		entries:    make(map[string]*entry),
+		maxEntries: maxEntries,
+		ttl:        ttl,
+		now:        time.Now,
+	}
+}
+
+// Put stores the full output of a tool call and returns its handle
+func (s *Store) Put(tool, text string) string {
+	handle := newHandle()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.evictExpired()
+	for len(s.order) >= s.maxEntries {
+		delete(s.entries, s.order[0])
+		s.order = s.order[1:]
+	}
+
+	s.entries[handle] = &entry{tool: tool, text: text, created: s.now()}
+	s.order = append(s.order, handle)
+	return handle
+}
+
+// Page is a slice of a stored result
+type Page struct {
+	Handle     string `json:"handle"`
+	Tool       string `json:"tool"`
+	Text       string `json:"text"`
+	Offset     int    `json:"offset"`
+	NextOffset int    `json:"nextOffset"`
+	Total      int    `json:"total"`
+}
+
+// HasMore reports whether more output follows this page
+func (p *Page) HasMore() bool {
+	return p.NextOffset < p.Total
+}
+
+// Read returns up to limit bytes of a stored result starting at offset.
+// Page boundaries are moved back to the nearest UTF-8 character boundary.
+func (s *Store) Read(handle string, offset, limit int) (*Page, error) {
+	if offset < 0 {
+		return nil, fmt.Errorf("offset must be >= 0")
+	}
+	if limit <= 0 {
+		return nil, fmt.Errorf("limit must be > 0")
+	}
+
+	s.mu.Lock()
+	s.evictExpired()
+	e, ok := s.entries[handle]
+	s.mu.Unlock()
+
+	if !ok {
+		return nil, fmt.Errorf("result '%s' not found (results expire after %s)", handle, s.ttl)
+	}
+
+	total := len(e.text)
+	if offset > total {
+		return nil, fmt.Errorf("offset %d is past the end of the result (%d bytes)", offset, total)
+	}
+
+	start := runeStart(e.text, offset)
+	end := runeStart(e.text, min(start+limit, total))
+	if end == start && start < total {
+		// Always make progress, even if limit is smaller than one character
+		_, size := utf8.DecodeRuneInString(e.text[start:])
+		end = start + size
+	}
+
+	return &Page{
+		Handle:     handle,
+		Tool:       e.tool,
+		Text:       e.text[start:end],
+		Offset:     start,
+		NextOffset: end,
+		Total:      total,
+	}, nil
+}
+
+// evictExpired removes results older than the TTL; callers must hold s.mu
+func (s *Store) evictExpired() {
+	cutoff := s.now().Add(-s.ttl)
+	kept := s.order[:0]
+	for _, handle := range s.order {
+		if s.entries[handle].created.Before(cutoff) {
+			delete(s.entries, handle)
+			continue
+		}
+		kept = append(kept, handle)
+	}
+	s.order = kept
+}
+
+// newHandle returns a short random handle for a stored result
+func newHandle() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("res_%d", time.Now().UnixNano())
+	}
+	return "res_" + hex.EncodeToString(b)
+}
+
+// runeStart moves i back to the start of the UTF-8 character containing it
+func runeStart(s string, i int) int {
+	for i > 0 && i < len(s) && !utf8.RuneStart(s[i]) {
+		i--
+	}
+	return i
+}
@@ -0,0 +1,100 @@
+package results
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore_PutRead(t *testing.T) {
+	store := NewStore(0, 0)
+	handle := store.Put("github__search", "hello world")
+	assert.True(t, strings.HasPrefix(handle, "res_"))
+
+	page, err := store.Read(handle, 0, 5)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", page.Text)
+	assert.Equal(t, "github__search", page.Tool)
+	assert.Equal(t, 5, page.NextOffset)
+	assert.True(t, page.HasMore())
+
+	page, err = store.Read(handle, page.NextOffset, 100)
+	require.NoError(t, err)
+	assert.Equal(t, " world", page.Text)
+	assert.False(t, page.HasMore())
+}
+
+func TestStore_ReadErrors(t *testing.T) {
+	store := NewStore(0, 0)
+	handle := store.Put("t", "abc")
+
+	_, err := store.Read("res_missing", 0, 10)
+	assert.ErrorContains(t, err, "not found")
+
+	_, err = store.Read(handle, 10, 10)
+	assert.ErrorContains(t, err, "past the end")
+
+	_, err = store.Read(handle, 0, 0)
+	assert.Error(t, err)
+}
+
+func TestStore_RuneBoundaries(t *testing.T) {
+	store := NewStore(0, 0)
+	handle := store.Put("t", "héllo")
+
+	// "é" is two bytes; a page ending inside it stops before it
+	page, err := store.Read(handle, 0, 2)
+	require.NoError(t, err)
+	assert.Equal(t, "h", page.Text)
+
+	page, err = store.Read(handle, page.NextOffset, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "é", page.Text)
+}
+
+func TestStore_Eviction(t *testing.T) {
+	store := NewStore(2, time.Minute)
+	now := time.Now()
+	store.now = func() time.Time { return now }
+
+	first := store.Put("t", "1")
+	second := store.Put("t", "2")
+	third := store.Put("t", "3")
+
+	_, err := store.Read(first, 0, 1)
+	assert.Error(t, err, "oldest entry should be evicted when full")
+	_, err = store.Read(second, 0, 1)
+	assert.NoError(t, err)
+
+	now = now.Add(2 * time.Minute)
+	_, err = store.Read(third, 0, 1)
+	assert.Error(t, err, "entries should expire after the TTL")
+}
+
+func TestTruncate(t *testing.T) {
+	store := NewStore(0, 0)
+	image := &mcp.ImageContent{Data: []byte("png"), MIMEType: "image/png"}
+	result := &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: strings.Repeat("a", 30)},
+			image,
+			&mcp.TextContent{Text: strings.Repeat("b", 30)},
+		},
+		StructuredContent: map[string]any{"big": true},
+	}
+
+	truncated := store.Truncate("srv__tool", result, 40)
+	require.Len(t, truncated.Content, 3)
+	assert.Equal(t, strings.Repeat("a", 30)+"\n"+strings.Repeat("b", 9), truncated.Content[0].(*mcp.TextContent).Text)
+	assert.Contains(t, truncated.Content[1].(*mcp.TextContent).Text, "showing 40 of 61 bytes")
+	assert.Same(t, image, truncated.Content[2])
+	assert.Nil(t, truncated.StructuredContent)
+
+	// Results within the limit, and a zero limit, pass through untouched
+	assert.Same(t, result, store.Truncate("srv__tool", result, 100))
+	assert.Same(t, result, store.Truncate("srv__tool", result, 0))
+}
@@ -0,0 +1,72 @@
+package results
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Truncate returns result unchanged if its text fits within maxBytes (0 means
+// unlimited). Otherwise the text blocks are joined, stored in full, and replaced
+// by the first maxBytes of text and a note with the handle to read the rest.
+// Non-text blocks are kept; structured content is dropped because it would
+// carry the same oversized payload.
+func (s *Store) Truncate(tool string, result *mcp.CallToolResult, maxBytes int) *mcp.CallToolResult {
+	if result == nil || maxBytes <= 0 {
+		return result
+	}
+
+	var texts []string
+	size := 0
+	for _, c := range result.Content {
+		if text, ok := c.(*mcp.TextContent); ok {
+			texts = append(texts, text.Text)
+			size += len(text.Text)
+		}
+	}
+	if size <= maxBytes {
+		return result
+	}
+
+	head, note := s.TruncateText(tool, strings.Join(texts, "\n"), maxBytes)
+	content := []mcp.Content{
+		&mcp.TextContent{Text: head},
+		&mcp.TextContent{Text: note},
+	}
+	for _, c := range result.Content {
+		if _, ok := c.(*mcp.TextContent); !ok {
+			content = append(content, c)
+		}
+	}
+
+	return &mcp.CallToolResult{
+		Meta:    result.Meta,
+		Content: content,
+		IsError: result.IsError,
+	}
+}
+
+// TruncateText returns text unchanged and an empty note if it fits within
+// maxBytes (0 means unlimited). Otherwise the full text is stored and its first
+// maxBytes are returned with a note naming the handle to read the rest.
+func (s *Store) TruncateText(tool, text string, maxBytes int) (head, note string) {
+	if maxBytes <= 0 || len(text) <= maxBytes {
+		return text, ""
+	}
+	handle := s.Put(tool, text)
+	cut := runeStart(text, maxBytes)
+	return text[:cut], PageNote(handle, cut, len(text))
+}
+
+// PageNote tells the client how much of a stored result it has seen and how to
+// read the next page
+func PageNote(handle string, nextOffset, total int) string {
+	if nextOffset >= total {
+		return fmt.Sprintf("[End of result %s: %d bytes]", handle, total)
+	}
+	return fmt.Sprintf(
+		"[Output truncated: showing %d of %d bytes. Call read with {\"handle\": %q, \"offset\": %d} for the next page.]",
+		nextOffset, total, handle, nextOffset,
+	)
+}
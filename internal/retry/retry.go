@@ -0,0 +1,105 @@
+// Package retry repeats tool calls that failed with a transient error. Errors
+// are sorted into the classes named in config (timeout, connection, server);
+// only classes enabled by the server's retry policy are retried, with a
+// jittered exponential backoff between attempts.
+package retry
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"syscall"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/vaayne/mcphub/internal/config"
+)
+
+// Classify returns the error class of err, or "" if err is not a transient
+// failure of the backend (for example invalid parameters or a canceled call)
+func Classify(err error) string {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return ""
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return config.ErrorClassTimeout
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return config.ErrorClassTimeout
+	}
+
+	if errors.Is(err, mcp.ErrConnectionClosed) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.EPIPE) {
+		return config.ErrorClassConnection
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		return config.ErrorClassConnection
+	}
+
+	// Errors raised by a tool handler arrive without a code; the reserved
+	// -32000..-32099 range is for implementation-defined server errors
+	var rpcErr *jsonrpc.Error
+	if errors.As(err, &rpcErr) {
+		if rpcErr.Code == 0 || rpcErr.Code == jsonrpc.CodeInternalError ||
+			(rpcErr.Code <= -32000 && rpcErr.Code >= -32099) {
+			return config.ErrorClassServer
+		}
+	}
+	return ""
+}
+
+// Backoff returns the delay before retry number attempt (1 = first retry): the
+// initial backoff doubled per retry and capped at the maximum, with the upper
+// half randomized so concurrent callers don't retry in lockstep
+func Backoff(policy *config.RetryConfig, attempt int) time.Duration {
+	delay := policy.GetInitialBackoff()
+	for i := 1; i < attempt && delay < policy.GetMaxBackoff(); i++ {
+		delay *= 2
+	}
+	delay = min(delay, policy.GetMaxBackoff())
+
+	half := delay / 2
+	return half + rand.N(half+1)
+}
+
+// Do calls fn until it succeeds, fails with an error the policy does not retry,
+// or runs out of attempts. onRetry, if set, is called before each retry. A nil
+// policy calls fn once.
+func Do[T any](ctx context.Context, policy *config.RetryConfig, fn func() (T, error), onRetry func(attempt int, err error, delay time.Duration)) (T, error) {
+	result, err := fn()
+	if policy == nil {
+		return result, err
+	}
+
+	for attempt := 1; attempt < policy.GetMaxAttempts(); attempt++ {
+		if err == nil || !policy.RetriesOn(Classify(err)) || ctx.Err() != nil {
+			break
+		}
+
+		delay := Backoff(policy, attempt)
+		if onRetry != nil {
+			onRetry(attempt, err, delay)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return result, err
+		}
+
+		result, err = fn()
+	}
+	return result, err
+}
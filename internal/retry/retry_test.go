@@ -0,0 +1,120 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vaayne/mcphub/internal/config"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"nil", nil, ""},
+		{"deadline", fmt.Errorf("call: %w", context.DeadlineExceeded), config.ErrorClassTimeout},
+		{"canceled", context.Canceled, ""},
+		{"connection closed", fmt.Errorf("%w: calling tools/call", mcp.ErrConnectionClosed), config.ErrorClassConnection},
+		{"eof", io.EOF, config.ErrorClassConnection},
+		{"refused", syscall.ECONNREFUSED, config.ErrorClassConnection},
+		{"handler error", &jsonrpc.Error{Message: "boom"}, config.ErrorClassServer},
+		{"internal error", &jsonrpc.Error{Code: jsonrpc.CodeInternalError}, config.ErrorClassServer},
+		{"invalid params", &jsonrpc.Error{Code: jsonrpc.CodeInvalidParams}, ""},
+		{"other", errors.New("bad input"), ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Classify(tt.err))
+		})
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	policy := &config.RetryConfig{InitialBackoff: 0.1, MaxBackoff: 0.3}
+
+	for range 20 {
+		first := Backoff(policy, 1)
+		assert.GreaterOrEqual(t, first, 50*time.Millisecond)
+		assert.LessOrEqual(t, first, 100*time.Millisecond)
+
+		capped := Backoff(policy, 5)
+		assert.GreaterOrEqual(t, capped, 150*time.Millisecond)
+		assert.LessOrEqual(t, capped, 300*time.Millisecond)
+	}
+}
+
+func TestDo(t *testing.T) {
+	ctx := context.Background()
+	policy := &config.RetryConfig{MaxAttempts: 3, InitialBackoff: 0.001}
+	transient := fmt.Errorf("%w: reset", mcp.ErrConnectionClosed)
+
+	t.Run("retries until success", func(t *testing.T) {
+		calls := 0
+		var retries []int
+		result, err := Do(ctx, policy, func() (string, error) {
+			calls++
+			if calls < 3 {
+				return "", transient
+			}
+			return "ok", nil
+		}, func(attempt int, err error, delay time.Duration) {
+			retries = append(retries, attempt)
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "ok", result)
+		assert.Equal(t, []int{1, 2}, retries)
+	})
+
+	t.Run("gives up after max attempts", func(t *testing.T) {
+		calls := 0
+		_, err := Do(ctx, policy, func() (string, error) {
+			calls++
+			return "", transient
+		}, nil)
+		assert.ErrorIs(t, err, mcp.ErrConnectionClosed)
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("does not retry other errors", func(t *testing.T) {
+		calls := 0
+		_, err := Do(ctx, policy, func() (string, error) {
+			calls++
+			return "", errors.New("bad input")
+		}, nil)
+		assert.Error(t, err)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("respects retryOn", func(t *testing.T) {
+		calls := 0
+		timeoutsOnly := &config.RetryConfig{InitialBackoff: 0.001, RetryOn: []string{config.ErrorClassTimeout}}
+		_, err := Do(ctx, timeoutsOnly, func() (string, error) {
+			calls++
+			return "", transient
+		}, nil)
+		assert.Error(t, err)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("nil policy calls once", func(t *testing.T) {
+		calls := 0
+		_, err := Do(ctx, nil, func() (string, error) {
+			calls++
+			return "", transient
+		}, nil)
+		assert.Error(t, err)
+		assert.Equal(t, 1, calls)
+	})
+}
@@ -0,0 +1,239 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Coercion actions
+const (
+	ActionConverted = "converted" // scalar converted to the declared type
+	ActionParsed    = "parsed"    // JSON-encoded string parsed into an object or array
+	ActionWrapped   = "wrapped"   // single value wrapped in an array
+	ActionDefault   = "default"   // missing property filled from the schema default
+)
+
+// Coercion records a single adjustment made to tool arguments
+type Coercion struct {
+	Path   string `json:"path"`
+	Action string `json:"action"`
+	From   any    `json:"from,omitempty"`
+	To     any    `json:"to"`
+}
+
+// String renders the coercion as a short, model-readable sentence
+func (c Coercion) String() string {
+	switch c.Action {
+	case ActionConverted:
+		return fmt.Sprintf("%s: converted %s to %s", c.Path, describe(c.From), describe(c.To))
+	case ActionParsed:
+		return fmt.Sprintf("%s: parsed JSON string into %s", c.Path, TypeOf(c.To))
+	case ActionWrapped:
+		return fmt.Sprintf("%s: wrapped %s in an array", c.Path, describe(c.From))
+	case ActionDefault:
+		return fmt.Sprintf("%s: filled default %s", c.Path, formatValue(c.To))
+	default:
+		return fmt.Sprintf("%s: %s", c.Path, c.Action)
+	}
+}
+
+// FormatCoercions renders coercions as a note appended to tool results
+func FormatCoercions(coercions []Coercion) string {
+	lines := make([]string, 0, len(coercions)+1)
+	lines = append(lines, "Note: arguments were adjusted to match the tool schema:")
+	for _, c := range coercions {
+		lines = append(lines, "- "+c.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// CoercionPolicy is optionally implemented by tool providers and callers to let
+// individual servers opt out of argument coercion
+type CoercionPolicy interface {
+	// CoercesArgs reports whether arguments for tools on serverID are coerced
+	CoercesArgs(serverID string) bool
+}
+
+// CoercionEnabled reports whether coercion applies to serverID. Providers that
+// do not implement CoercionPolicy always coerce.
+func CoercionEnabled(provider any, serverID string) bool {
+	if p, ok := provider.(CoercionPolicy); ok {
+		return p.CoercesArgs(serverID)
+	}
+	return true
+}
+
+// Coerce adjusts args to match inputSchema the way models usually mean them:
+// scalars are converted to the declared type ("5" -> 5), JSON-encoded strings
+// are parsed into objects and arrays, single values are wrapped into arrays and
+// missing properties are filled from schema defaults. The input map is not
+// modified; the adjusted copy and the list of changes are returned.
+func Coerce(inputSchema any, args map[string]any) (map[string]any, []Coercion) {
+	s := Normalize(inputSchema)
+	if len(s) == 0 {
+		return args, nil
+	}
+
+	c := &coercer{}
+	var value any = args
+	if args == nil {
+		value = map[string]any{}
+	}
+	out, _ := c.coerce(s, value, RootPath).(map[string]any)
+	if len(c.changes) == 0 {
+		return args, nil
+	}
+	return out, c.changes
+}
+
+// CoerceArgs is Coerce for arguments decoded from any JSON value. Arguments
+// sent as a JSON-encoded object string are parsed first, and the parse is
+// recorded like any other coercion. It reports false when args is not an
+// object and cannot be parsed into one.
+func CoerceArgs(inputSchema any, args any) (map[string]any, []Coercion, bool) {
+	var parsed []Coercion
+	if str, ok := args.(string); ok {
+		c := &coercer{}
+		args = c.coerceType([]string{"object"}, str, RootPath)
+		parsed = c.changes
+	}
+	if args == nil {
+		out, changes := Coerce(inputSchema, nil)
+		return out, changes, true
+	}
+	obj, ok := args.(map[string]any)
+	if !ok {
+		return nil, nil, false
+	}
+	out, changes := Coerce(inputSchema, obj)
+	return out, append(parsed, changes...), true
+}
+
+// coercer accumulates changes while walking a schema
+type coercer struct {
+	changes []Coercion
+}
+
+func (c *coercer) record(path, action string, from, to any) {
+	c.changes = append(c.changes, Coercion{Path: path, Action: action, From: from, To: to})
+}
+
+// coerce returns value adjusted to match schema node s
+func (c *coercer) coerce(s map[string]any, value any, path string) any {
+	if types := schemaTypes(s); len(types) > 0 && !matchesType(types, value) {
+		value = c.coerceType(types, value, path)
+	}
+
+	switch val := value.(type) {
+	case map[string]any:
+		return c.coerceObject(s, val, path)
+	case []any:
+		if items, ok := s["items"].(map[string]any); ok {
+			out := make([]any, len(val))
+			for i, item := range val {
+				out[i] = c.coerce(items, item, fmt.Sprintf("%s[%d]", path, i))
+			}
+			return out
+		}
+		return val
+	default:
+		return val
+	}
+}
+
+// coerceType tries each declared type in order and returns the first successful conversion
+func (c *coercer) coerceType(types []string, value any, path string) any {
+	for _, t := range types {
+		switch t {
+		case "integer":
+			if str, ok := value.(string); ok {
+				if n, err := strconv.ParseInt(strings.TrimSpace(str), 10, 64); err == nil {
+					c.record(path, ActionConverted, value, n)
+					return n
+				}
+			}
+		case "number":
+			if str, ok := value.(string); ok {
+				if n, err := strconv.ParseFloat(strings.TrimSpace(str), 64); err == nil {
+					c.record(path, ActionConverted, value, n)
+					return n
+				}
+			}
+		case "boolean":
+			if str, ok := value.(string); ok {
+				if b, err := strconv.ParseBool(strings.TrimSpace(str)); err == nil {
+					c.record(path, ActionConverted, value, b)
+					return b
+				}
+			}
+		case "string":
+			switch value.(type) {
+			case bool:
+				str := fmt.Sprintf("%v", value)
+				c.record(path, ActionConverted, value, str)
+				return str
+			default:
+				if n, ok := toFloat(value); ok {
+					str := strconv.FormatFloat(n, 'f', -1, 64)
+					c.record(path, ActionConverted, value, str)
+					return str
+				}
+			}
+		case "object":
+			if str, ok := value.(string); ok {
+				var obj map[string]any
+				if err := json.Unmarshal([]byte(str), &obj); err == nil && obj != nil {
+					c.record(path, ActionParsed, value, obj)
+					return obj
+				}
+			}
+		case "array":
+			if str, ok := value.(string); ok {
+				var arr []any
+				if err := json.Unmarshal([]byte(str), &arr); err == nil && arr != nil {
+					c.record(path, ActionParsed, value, arr)
+					return arr
+				}
+			}
+			if _, isObject := value.(map[string]any); !isObject && value != nil {
+				arr := []any{value}
+				c.record(path, ActionWrapped, value, arr)
+				return arr
+			}
+		}
+	}
+	return value
+}
+
+// coerceObject coerces known properties and fills missing ones from defaults
+func (c *coercer) coerceObject(s map[string]any, obj map[string]any, path string) map[string]any {
+	props, _ := s["properties"].(map[string]any)
+	if len(props) == 0 {
+		return obj
+	}
+
+	out := make(map[string]any, len(obj))
+	for k, v := range obj {
+		out[k] = v
+	}
+
+	for _, name := range sortedKeys(props) {
+		ps, ok := props[name].(map[string]any)
+		if !ok {
+			continue
+		}
+		propPath := joinPath(path, name)
+		if v, present := out[name]; present {
+			out[name] = c.coerce(ps, v, propPath)
+			continue
+		}
+		if def, hasDefault := ps["default"]; hasDefault {
+			out[name] = def
+			c.record(propPath, ActionDefault, nil, def)
+		}
+	}
+
+	return out
+}
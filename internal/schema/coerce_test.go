@@ -0,0 +1,130 @@
+package schema
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCoerce(t *testing.T) {
+	s := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"query":   map[string]any{"type": "string"},
+			"limit":   map[string]any{"type": "integer", "default": float64(10)},
+			"ratio":   map[string]any{"type": "number"},
+			"verbose": map[string]any{"type": "boolean"},
+			"tags":    map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+			"filter": map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"owner": map[string]any{"type": "string"},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		args     string
+		expected string
+		changes  []string
+	}{
+		{
+			name:     "already valid",
+			args:     `{"query": "mcp", "limit": 5}`,
+			expected: `{"query": "mcp", "limit": 5}`,
+		},
+		{
+			name:     "scalars",
+			args:     `{"query": 42, "limit": "5", "ratio": "0.5", "verbose": "true"}`,
+			expected: `{"query": "42", "limit": 5, "ratio": 0.5, "verbose": true}`,
+			changes: []string{
+				`params.limit: converted string "5" to integer 5`,
+				`params.query: converted integer 42 to string "42"`,
+				`params.ratio: converted string "0.5" to number 0.5`,
+				`params.verbose: converted string "true" to boolean true`,
+			},
+		},
+		{
+			name:     "json strings",
+			args:     `{"limit": 1, "tags": "[\"a\", \"b\"]", "filter": "{\"owner\": \"me\"}"}`,
+			expected: `{"limit": 1, "tags": ["a", "b"], "filter": {"owner": "me"}}`,
+			changes: []string{
+				"params.filter: parsed JSON string into object",
+				"params.tags: parsed JSON string into array",
+			},
+		},
+		{
+			name:     "wrap single value",
+			args:     `{"limit": 1, "tags": "a"}`,
+			expected: `{"limit": 1, "tags": ["a"]}`,
+			changes:  []string{`params.tags: wrapped string "a" in an array`},
+		},
+		{
+			name:     "defaults",
+			args:     `{}`,
+			expected: `{"limit": 10}`,
+			changes:  []string{"params.limit: filled default 10"},
+		},
+		{
+			name:     "unconvertible left for validation",
+			args:     `{"limit": "ten"}`,
+			expected: `{"limit": "ten"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var args map[string]any
+			require.NoError(t, json.Unmarshal([]byte(tt.args), &args))
+
+			out, coercions := Coerce(s, args)
+
+			data, err := json.Marshal(out)
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.expected, string(data))
+
+			changes := make([]string, 0, len(coercions))
+			for _, c := range coercions {
+				changes = append(changes, c.String())
+			}
+			if tt.changes == nil {
+				assert.Empty(t, changes)
+			} else {
+				assert.Equal(t, tt.changes, changes)
+			}
+		})
+	}
+}
+
+func TestCoerce_DoesNotModifyInput(t *testing.T) {
+	s := map[string]any{
+		"type":       "object",
+		"properties": map[string]any{"limit": map[string]any{"type": "integer"}},
+	}
+	args := map[string]any{"limit": "5"}
+
+	out, coercions := Coerce(s, args)
+	require.Len(t, coercions, 1)
+	assert.Equal(t, int64(5), out["limit"])
+	assert.Equal(t, "5", args["limit"])
+}
+
+func TestFormatCoercions(t *testing.T) {
+	note := FormatCoercions([]Coercion{
+		{Path: "params.limit", Action: ActionConverted, From: "5", To: int64(5)},
+	})
+	assert.Equal(t, "Note: arguments were adjusted to match the tool schema:\n- params.limit: converted string \"5\" to integer 5", note)
+}
+
+type fixedCoercionPolicy bool
+
+func (p fixedCoercionPolicy) CoercesArgs(string) bool { return bool(p) }
+
+func TestCoercionEnabled(t *testing.T) {
+	assert.True(t, CoercionEnabled(struct{}{}, "any"))
+	assert.False(t, CoercionEnabled(fixedCoercionPolicy(false), "any"))
+}
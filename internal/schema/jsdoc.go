@@ -1,4 +1,4 @@
-package tools
+package schema
 
 import (
 	"fmt"
@@ -29,9 +29,9 @@ func jsonSchemaTypeToJS(schemaType any) string {
 	}
 }
 
-// schemaToJSDoc generates a JSDoc comment and function stub from a tool's schema.
+// JSDoc generates a JSDoc comment and function stub from a tool's schema.
 // The output includes required markers, enum values, and default values.
-func schemaToJSDoc(toolName, description string, inputSchema map[string]any) string {
+func JSDoc(toolName, description string, inputSchema map[string]any) string {
 	var sb strings.Builder
 
 	sb.WriteString("/**\n")
@@ -0,0 +1,219 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// maxTypeDepth bounds how deeply nested schemas are rendered
+const maxTypeDepth = 8
+
+// identifierRegex matches names usable as TypeScript property names unquoted
+var identifierRegex = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// TypeScript renders a JSON schema as a TypeScript type. Object types are
+// written over several lines, indented by indent, with each property's
+// description as a doc comment. Constructs with no TypeScript equivalent, such
+// as $ref, become unknown.
+func TypeScript(s any, indent string) string {
+	return tsType(s, indent, 0)
+}
+
+// PropertyName returns name as a TypeScript property name, quoted if needed
+func PropertyName(name string) string {
+	if identifierRegex.MatchString(name) {
+		return name
+	}
+	return fmt.Sprintf("%q", name)
+}
+
+// DocComment renders text as a doc comment indented by indent, or returns ""
+// for empty text
+func DocComment(text, indent string) string {
+	text = strings.TrimSpace(strings.ReplaceAll(text, "*/", "*\\/"))
+	if text == "" {
+		return ""
+	}
+	lines := strings.Split(text, "\n")
+	if len(lines) == 1 {
+		return fmt.Sprintf("%s/** %s */\n", indent, lines[0])
+	}
+	var sb strings.Builder
+	sb.WriteString(indent + "/**\n")
+	for _, line := range lines {
+		sb.WriteString(strings.TrimRight(indent+" * "+line, " ") + "\n")
+	}
+	sb.WriteString(indent + " */\n")
+	return sb.String()
+}
+
+func tsType(s any, indent string, depth int) string {
+	m, ok := s.(map[string]any)
+	if !ok || depth > maxTypeDepth {
+		if b, isBool := s.(bool); isBool && !b {
+			return "never"
+		}
+		return "unknown"
+	}
+
+	if c, ok := m["const"]; ok {
+		return literal(c)
+	}
+	if enum, ok := m["enum"].([]any); ok && len(enum) > 0 {
+		parts := make([]string, 0, len(enum))
+		for _, v := range enum {
+			parts = append(parts, literal(v))
+		}
+		return strings.Join(parts, " | ")
+	}
+	for _, key := range []string{"anyOf", "oneOf"} {
+		if variants, ok := m[key].([]any); ok && len(variants) > 0 {
+			return union(variants, indent, depth)
+		}
+	}
+	if all, ok := m["allOf"].([]any); ok && len(all) > 0 {
+		parts := make([]string, 0, len(all))
+		for _, v := range all {
+			parts = append(parts, wrap(tsType(v, indent, depth+1)))
+		}
+		return strings.Join(parts, " & ")
+	}
+
+	switch t := m["type"].(type) {
+	case string:
+		return typeOf(t, m, indent, depth)
+	case []any:
+		parts := make([]string, 0, len(t))
+		for _, v := range t {
+			if name, ok := v.(string); ok {
+				parts = append(parts, typeOf(name, m, indent, depth))
+			}
+		}
+		if len(parts) > 0 {
+			return strings.Join(parts, " | ")
+		}
+	}
+
+	// Untyped schemas with properties are objects
+	if _, ok := m["properties"]; ok {
+		return objectType(m, indent, depth)
+	}
+	return "unknown"
+}
+
+func typeOf(name string, m map[string]any, indent string, depth int) string {
+	switch name {
+	case "string":
+		return "string"
+	case "number", "integer":
+		return "number"
+	case "boolean":
+		return "boolean"
+	case "null":
+		return "null"
+	case "array":
+		if items, ok := m["items"]; ok {
+			return wrap(tsType(items, indent, depth+1)) + "[]"
+		}
+		return "unknown[]"
+	case "object":
+		return objectType(m, indent, depth)
+	}
+	return "unknown"
+}
+
+func objectType(m map[string]any, indent string, depth int) string {
+	properties, _ := m["properties"].(map[string]any)
+	extra := "unknown"
+	switch additional := m["additionalProperties"].(type) {
+	case bool:
+		if !additional {
+			extra = ""
+		}
+	case map[string]any:
+		extra = tsType(additional, indent, depth+1)
+	}
+
+	if len(properties) == 0 {
+		if extra == "" {
+			return "Record<string, never>"
+		}
+		return fmt.Sprintf("Record<string, %s>", extra)
+	}
+
+	required := make(map[string]bool)
+	if list, ok := m["required"].([]any); ok {
+		for _, r := range list {
+			if name, ok := r.(string); ok {
+				required[name] = true
+			}
+		}
+	}
+
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	inner := indent + "  "
+	var sb strings.Builder
+	sb.WriteString("{\n")
+	for _, name := range names {
+		prop := properties[name]
+		if p, ok := prop.(map[string]any); ok {
+			desc, _ := p["description"].(string)
+			if d, ok := p["default"]; ok {
+				desc = strings.TrimSpace(desc + fmt.Sprintf("\n@default %s", literal(d)))
+			}
+			sb.WriteString(DocComment(desc, inner))
+		}
+		optional := "?"
+		if required[name] {
+			optional = ""
+		}
+		sb.WriteString(fmt.Sprintf("%s%s%s: %s;\n", inner, PropertyName(name), optional, tsType(prop, inner, depth+1)))
+	}
+	if extra != "" && m["additionalProperties"] != nil {
+		// Declared properties must fit the index signature, so it stays unknown
+		sb.WriteString(inner + "[key: string]: unknown;\n")
+	}
+	sb.WriteString(indent + "}")
+	return sb.String()
+}
+
+func union(variants []any, indent string, depth int) string {
+	parts := make([]string, 0, len(variants))
+	seen := make(map[string]bool)
+	for _, v := range variants {
+		part := wrap(tsType(v, indent, depth+1))
+		if !seen[part] {
+			seen[part] = true
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, " | ")
+}
+
+// wrap parenthesizes union and intersection types used inside another type
+func wrap(t string) string {
+	if strings.Contains(t, " | ") || strings.Contains(t, " & ") {
+		return "(" + t + ")"
+	}
+	return t
+}
+
+// literal renders a JSON value as a TypeScript literal type
+func literal(v any) string {
+	switch v.(type) {
+	case string, float64, int, int64, bool, nil:
+		data, err := json.Marshal(v)
+		if err == nil {
+			return string(data)
+		}
+	}
+	return "unknown"
+}
@@ -0,0 +1,70 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTypeScript(t *testing.T) {
+	tests := []struct {
+		name     string
+		schema   any
+		expected string
+	}{
+		{"string", map[string]any{"type": "string"}, "string"},
+		{"integer", map[string]any{"type": "integer"}, "number"},
+		{"enum", map[string]any{"type": "string", "enum": []any{"open", "closed"}}, `"open" | "closed"`},
+		{"const", map[string]any{"const": float64(3)}, "3"},
+		{"nullable", map[string]any{"type": []any{"string", "null"}}, "string | null"},
+		{"array of union", map[string]any{"type": "array", "items": map[string]any{"type": []any{"string", "number"}}}, "(string | number)[]"},
+		{"anyOf", map[string]any{"anyOf": []any{map[string]any{"type": "string"}, map[string]any{"type": "boolean"}}}, "string | boolean"},
+		{"map", map[string]any{"type": "object", "additionalProperties": map[string]any{"type": "number"}}, "Record<string, number>"},
+		{"empty object", map[string]any{"type": "object"}, "Record<string, unknown>"},
+		{"ref", map[string]any{"$ref": "#/$defs/x"}, "unknown"},
+		{"false schema", false, "never"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, TypeScript(tt.schema, ""))
+		})
+	}
+}
+
+func TestTypeScript_Object(t *testing.T) {
+	s := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"query": map[string]any{"type": "string", "description": "Search query"},
+			"limit": map[string]any{"type": "integer", "default": float64(10)},
+			"x-id":  map[string]any{"type": "string"},
+			"owner": map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"login": map[string]any{"type": "string"},
+				},
+				"required": []any{"login"},
+			},
+		},
+		"required": []any{"query"},
+	}
+
+	expected := `{
+  /** @default 10 */
+  limit?: number;
+  owner?: {
+    login: string;
+  };
+  /** Search query */
+  query: string;
+  "x-id"?: string;
+}`
+	assert.Equal(t, expected, TypeScript(s, ""))
+}
+
+func TestDocComment(t *testing.T) {
+	assert.Equal(t, "", DocComment("  ", ""))
+	assert.Equal(t, "  /** One line */\n", DocComment("One line", "  "))
+	assert.Equal(t, "/**\n * First\n *\n * Ends *\\/ here\n */\n", DocComment("First\n\nEnds */ here", ""))
+}
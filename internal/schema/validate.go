@@ -0,0 +1,449 @@
+// Package schema validates tool arguments against a tool's JSON Schema inputSchema.
+// It supports the subset of JSON Schema that MCP servers use in practice (types,
+// properties, required, enum, const, items, bounds, pattern and combinators) and
+// produces messages written for models: a path, what was expected and what was sent.
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/vaayne/mcphub/internal/textutil"
+)
+
+// RootPath is the path prefix used for the arguments object in issue messages
+const RootPath = "params"
+
+// maxIssues caps the number of issues reported for a single call
+const maxIssues = 20
+
+// Issue describes a single validation failure
+type Issue struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// ValidationError is returned when arguments do not match the input schema
+type ValidationError struct {
+	Tool   string  `json:"tool,omitempty"`
+	Issues []Issue `json:"issues"`
+}
+
+// Error implements the error interface
+func (e *ValidationError) Error() string {
+	parts := make([]string, 0, len(e.Issues))
+	for _, issue := range e.Issues {
+		parts = append(parts, fmt.Sprintf("%s: %s", issue.Path, issue.Message))
+	}
+	prefix := "invalid arguments"
+	if e.Tool != "" {
+		prefix = fmt.Sprintf("invalid arguments for '%s'", e.Tool)
+	}
+	return fmt.Sprintf("%s: %s", prefix, strings.Join(parts, "; "))
+}
+
+// Policy is optionally implemented by tool providers and callers to let
+// individual servers opt out of argument validation
+type Policy interface {
+	// ValidatesArgs reports whether arguments for tools on serverID are validated
+	ValidatesArgs(serverID string) bool
+}
+
+// ValidationEnabled reports whether validation applies to serverID. Providers that do
+// not implement Policy always validate.
+func ValidationEnabled(provider any, serverID string) bool {
+	if p, ok := provider.(Policy); ok {
+		return p.ValidatesArgs(serverID)
+	}
+	return true
+}
+
+// Normalize converts an inputSchema of any shape (map, *jsonschema.Schema,
+// json.RawMessage) into a plain map. Returns nil if the schema is empty or
+// cannot be decoded.
+func Normalize(inputSchema any) map[string]any {
+	switch s := inputSchema.(type) {
+	case nil:
+		return nil
+	case map[string]any:
+		return s
+	case json.RawMessage:
+		var m map[string]any
+		if err := json.Unmarshal(s, &m); err != nil {
+			return nil
+		}
+		return m
+	default:
+		data, err := json.Marshal(s)
+		if err != nil {
+			return nil
+		}
+		var m map[string]any
+		if err := json.Unmarshal(data, &m); err != nil {
+			return nil
+		}
+		return m
+	}
+}
+
+// Validate checks args against inputSchema and returns a *ValidationError
+// listing every issue found, or nil if the arguments are valid. An empty
+// schema accepts anything.
+func Validate(inputSchema any, args map[string]any) error {
+	s := Normalize(inputSchema)
+	if len(s) == 0 {
+		return nil
+	}
+
+	v := &validator{}
+	var value any = args
+	if args == nil {
+		value = map[string]any{}
+	}
+	v.validate(s, value, RootPath)
+
+	if len(v.issues) == 0 {
+		return nil
+	}
+	return &ValidationError{Issues: v.issues}
+}
+
+// validator accumulates issues while walking a schema
+type validator struct {
+	issues []Issue
+}
+
+func (v *validator) addf(path, format string, args ...any) {
+	if len(v.issues) >= maxIssues {
+		return
+	}
+	v.issues = append(v.issues, Issue{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// validate checks a single value against a schema node
+func (v *validator) validate(s map[string]any, value any, path string) {
+	if len(v.issues) >= maxIssues {
+		return
+	}
+
+	// Combinators: anyOf needs a matching branch, oneOf exactly one, allOf every branch
+	if branches, ok := s["anyOf"].([]any); ok && len(branches) > 0 && v.countMatches(branches, value) == 0 {
+		v.addf(path, "does not match any of the allowed schemas")
+		return
+	}
+	if branches, ok := s["oneOf"].([]any); ok && len(branches) > 0 {
+		switch n := v.countMatches(branches, value); {
+		case n == 0:
+			v.addf(path, "does not match any of the allowed schemas")
+			return
+		case n > 1:
+			v.addf(path, "matches %d of the allowed schemas, expected exactly one", n)
+			return
+		}
+	}
+	if branches, ok := s["allOf"].([]any); ok {
+		for _, b := range branches {
+			if bs, ok := b.(map[string]any); ok {
+				v.validate(bs, value, path)
+			}
+		}
+	}
+
+	if types := schemaTypes(s); len(types) > 0 && !matchesType(types, value) {
+		v.addf(path, "expected %s, got %s", strings.Join(types, " or "), describe(value))
+		return
+	}
+
+	if c, ok := s["const"]; ok && !equalJSON(c, value) {
+		v.addf(path, "must be %s, got %s", formatValue(c), describe(value))
+		return
+	}
+
+	if enum, ok := s["enum"].([]any); ok && len(enum) > 0 {
+		found := false
+		for _, e := range enum {
+			if equalJSON(e, value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			allowed := make([]string, 0, len(enum))
+			for _, e := range enum {
+				allowed = append(allowed, formatValue(e))
+			}
+			v.addf(path, "must be one of %s, got %s", strings.Join(allowed, ", "), describe(value))
+			return
+		}
+	}
+
+	switch val := value.(type) {
+	case map[string]any:
+		v.validateObject(s, val, path)
+	case []any:
+		v.validateArray(s, val, path)
+	case string:
+		v.validateString(s, val, path)
+	default:
+		if n, ok := toFloat(value); ok {
+			v.validateNumber(s, n, path)
+		}
+	}
+}
+
+// countMatches returns the number of branches value validates against
+func (v *validator) countMatches(branches []any, value any) int {
+	matches := 0
+	for _, b := range branches {
+		bs, ok := b.(map[string]any)
+		if !ok {
+			continue
+		}
+		probe := &validator{}
+		probe.validate(bs, value, "")
+		if len(probe.issues) == 0 {
+			matches++
+		}
+	}
+	return matches
+}
+
+func (v *validator) validateObject(s map[string]any, obj map[string]any, path string) {
+	props, _ := s["properties"].(map[string]any)
+
+	for _, name := range requiredFields(s) {
+		if _, ok := obj[name]; !ok {
+			hint := ""
+			if ps, ok := props[name].(map[string]any); ok {
+				if types := schemaTypes(ps); len(types) > 0 {
+					hint = fmt.Sprintf(" (%s)", strings.Join(types, " or "))
+				}
+			}
+			v.addf(joinPath(path, name), "required property is missing%s", hint)
+		}
+	}
+
+	// Walk properties in a stable order for deterministic messages
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		if ps, ok := props[k].(map[string]any); ok {
+			v.validate(ps, obj[k], joinPath(path, k))
+			continue
+		}
+		switch extra := s["additionalProperties"].(type) {
+		case bool:
+			if !extra {
+				v.addf(joinPath(path, k), "unknown property (allowed: %s)", strings.Join(sortedKeys(props), ", "))
+			}
+		case map[string]any:
+			v.validate(extra, obj[k], joinPath(path, k))
+		}
+	}
+}
+
+func (v *validator) validateArray(s map[string]any, arr []any, path string) {
+	if n, ok := toFloat(s["minItems"]); ok && float64(len(arr)) < n {
+		v.addf(path, "must contain at least %v items, got %d", n, len(arr))
+	}
+	if n, ok := toFloat(s["maxItems"]); ok && float64(len(arr)) > n {
+		v.addf(path, "must contain at most %v items, got %d", n, len(arr))
+	}
+	if items, ok := s["items"].(map[string]any); ok {
+		for i, item := range arr {
+			v.validate(items, item, fmt.Sprintf("%s[%d]", path, i))
+		}
+	}
+}
+
+func (v *validator) validateString(s map[string]any, str string, path string) {
+	length := len([]rune(str))
+	if n, ok := toFloat(s["minLength"]); ok && float64(length) < n {
+		v.addf(path, "must be at least %v characters, got %d", n, length)
+	}
+	if n, ok := toFloat(s["maxLength"]); ok && float64(length) > n {
+		v.addf(path, "must be at most %v characters, got %d", n, length)
+	}
+	if pattern, ok := s["pattern"].(string); ok {
+		// Patterns Go cannot compile (e.g. lookaheads) are skipped rather than rejected
+		if re, err := regexp.Compile(pattern); err == nil && !re.MatchString(str) {
+			v.addf(path, "must match pattern %q", pattern)
+		}
+	}
+}
+
+func (v *validator) validateNumber(s map[string]any, n float64, path string) {
+	if limit, ok := toFloat(s["minimum"]); ok && n < limit {
+		v.addf(path, "must be >= %v, got %v", limit, n)
+	}
+	if limit, ok := toFloat(s["maximum"]); ok && n > limit {
+		v.addf(path, "must be <= %v, got %v", limit, n)
+	}
+	if limit, ok := toFloat(s["exclusiveMinimum"]); ok && n <= limit {
+		v.addf(path, "must be > %v, got %v", limit, n)
+	}
+	if limit, ok := toFloat(s["exclusiveMaximum"]); ok && n >= limit {
+		v.addf(path, "must be < %v, got %v", limit, n)
+	}
+}
+
+// schemaTypes returns the declared type(s) of a schema node
+func schemaTypes(s map[string]any) []string {
+	switch t := s["type"].(type) {
+	case string:
+		return []string{t}
+	case []any:
+		types := make([]string, 0, len(t))
+		for _, item := range t {
+			if str, ok := item.(string); ok {
+				types = append(types, str)
+			}
+		}
+		return types
+	case []string:
+		return t
+	default:
+		return nil
+	}
+}
+
+// requiredFields returns the required property names of an object schema
+func requiredFields(s map[string]any) []string {
+	switch r := s["required"].(type) {
+	case []any:
+		names := make([]string, 0, len(r))
+		for _, item := range r {
+			if name, ok := item.(string); ok {
+				names = append(names, name)
+			}
+		}
+		return names
+	case []string:
+		return r
+	default:
+		return nil
+	}
+}
+
+// matchesType reports whether value is an instance of any of the JSON types
+func matchesType(types []string, value any) bool {
+	for _, t := range types {
+		if TypeOf(value) == t {
+			return true
+		}
+		if t == "number" && TypeOf(value) == "integer" {
+			return true
+		}
+	}
+	return false
+}
+
+// TypeOf returns the JSON Schema type name of a decoded JSON value.
+// Whole numbers report "integer".
+func TypeOf(value any) string {
+	switch val := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	default:
+		if n, ok := toFloat(val); ok {
+			if n == math.Trunc(n) && !math.IsInf(n, 0) {
+				return "integer"
+			}
+			return "number"
+		}
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+// toFloat converts any Go numeric type (from JSON or goja) to float64
+func toFloat(value any) (float64, bool) {
+	switch n := value.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// equalJSON compares two decoded JSON values, treating all numeric types alike
+func equalJSON(a, b any) bool {
+	if fa, ok := toFloat(a); ok {
+		fb, ok := toFloat(b)
+		return ok && fa == fb
+	}
+	da, errA := json.Marshal(a)
+	db, errB := json.Marshal(b)
+	return errA == nil && errB == nil && string(da) == string(db)
+}
+
+// describe renders a value's type and a short preview for messages
+func describe(value any) string {
+	t := TypeOf(value)
+	switch value.(type) {
+	case map[string]any, []any, nil:
+		return t
+	default:
+		return fmt.Sprintf("%s %s", t, formatValue(value))
+	}
+}
+
+// formatValue renders a value as compact JSON, truncated for long strings
+func formatValue(value any) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	const maxLen = 40
+	if head, cut := textutil.Truncate(string(data), maxLen); cut {
+		return head + "…"
+	}
+	return string(data)
+}
+
+// joinPath appends a property name to a path
+func joinPath(path, name string) string {
+	return path + "." + name
+}
+
+// sortedKeys returns the keys of a map in sorted order
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
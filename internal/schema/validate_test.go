@@ -0,0 +1,181 @@
+package schema
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var searchSchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"query": map[string]any{"type": "string", "minLength": float64(1)},
+		"limit": map[string]any{"type": "integer", "minimum": float64(1), "maximum": float64(100)},
+		"mode":  map[string]any{"type": "string", "enum": []any{"fast", "deep"}},
+		"tags": map[string]any{
+			"type":  "array",
+			"items": map[string]any{"type": "string"},
+		},
+		"filter": map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"owner": map[string]any{"type": "string"},
+			},
+			"required":             []any{"owner"},
+			"additionalProperties": false,
+		},
+	},
+	"required": []any{"query"},
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     string
+		expected []Issue
+	}{
+		{
+			name: "valid",
+			args: `{"query": "mcp", "limit": 10, "mode": "fast", "tags": ["a"], "filter": {"owner": "me"}}`,
+		},
+		{
+			name:     "missing required",
+			args:     `{}`,
+			expected: []Issue{{Path: "params.query", Message: "required property is missing (string)"}},
+		},
+		{
+			name:     "wrong type",
+			args:     `{"query": "mcp", "limit": "5"}`,
+			expected: []Issue{{Path: "params.limit", Message: `expected integer, got string "5"`}},
+		},
+		{
+			name:     "non-integer number",
+			args:     `{"query": "mcp", "limit": 2.5}`,
+			expected: []Issue{{Path: "params.limit", Message: "expected integer, got number 2.5"}},
+		},
+		{
+			name:     "enum",
+			args:     `{"query": "mcp", "mode": "slow"}`,
+			expected: []Issue{{Path: "params.mode", Message: `must be one of "fast", "deep", got string "slow"`}},
+		},
+		{
+			name: "bounds",
+			args: `{"query": "", "limit": 500}`,
+			expected: []Issue{
+				{Path: "params.limit", Message: "must be <= 100, got 500"},
+				{Path: "params.query", Message: "must be at least 1 characters, got 0"},
+			},
+		},
+		{
+			name:     "array items",
+			args:     `{"query": "mcp", "tags": ["a", 2]}`,
+			expected: []Issue{{Path: "params.tags[1]", Message: "expected string, got integer 2"}},
+		},
+		{
+			name: "nested object",
+			args: `{"query": "mcp", "filter": {"team": "x"}}`,
+			expected: []Issue{
+				{Path: "params.filter.owner", Message: "required property is missing (string)"},
+				{Path: "params.filter.team", Message: "unknown property (allowed: owner)"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var args map[string]any
+			require.NoError(t, json.Unmarshal([]byte(tt.args), &args))
+
+			err := Validate(searchSchema, args)
+			if tt.expected == nil {
+				assert.NoError(t, err)
+				return
+			}
+
+			var verr *ValidationError
+			require.ErrorAs(t, err, &verr)
+			assert.Equal(t, tt.expected, verr.Issues)
+		})
+	}
+}
+
+func TestValidate_Combinators(t *testing.T) {
+	s := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"id": map[string]any{
+				"anyOf": []any{
+					map[string]any{"type": "string"},
+					map[string]any{"type": "integer"},
+				},
+			},
+		},
+	}
+
+	assert.NoError(t, Validate(s, map[string]any{"id": "abc"}))
+	assert.NoError(t, Validate(s, map[string]any{"id": int64(3)}))
+	assert.Error(t, Validate(s, map[string]any{"id": true}))
+
+	// oneOf needs exactly one matching branch
+	oneOf := map[string]any{
+		"properties": map[string]any{
+			"n": map[string]any{
+				"oneOf": []any{
+					map[string]any{"type": "number"},
+					map[string]any{"type": "integer"},
+				},
+			},
+		},
+	}
+	assert.NoError(t, Validate(oneOf, map[string]any{"n": 2.5}))
+	err := Validate(oneOf, map[string]any{"n": int64(3)})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "params.n: matches 2 of the allowed schemas, expected exactly one")
+	assert.Error(t, Validate(oneOf, map[string]any{"n": "x"}))
+}
+
+func TestValidate_EmptySchemaAcceptsAnything(t *testing.T) {
+	assert.NoError(t, Validate(nil, map[string]any{"x": 1}))
+	assert.NoError(t, Validate(map[string]any{}, nil))
+}
+
+// TestValidate_LongValueKeepsUTF8 verifies values quoted in messages are cut
+// without splitting a multi-byte character
+func TestValidate_LongValueKeepsUTF8(t *testing.T) {
+	err := Validate(searchSchema, map[string]any{"query": "x", "limit": strings.Repeat("é", 30)})
+	require.Error(t, err)
+	assert.True(t, utf8.ValidString(err.Error()), "message is not valid UTF-8: %q", err.Error())
+	assert.Contains(t, err.Error(), "…")
+}
+
+func TestValidate_RawSchema(t *testing.T) {
+	raw := json.RawMessage(`{"type": "object", "required": ["a"]}`)
+	err := Validate(raw, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "params.a: required property is missing")
+}
+
+func TestValidationError_Error(t *testing.T) {
+	err := &ValidationError{
+		Tool: "githubSearch",
+		Issues: []Issue{
+			{Path: "params.query", Message: "required property is missing"},
+			{Path: "params.limit", Message: "expected integer, got string \"5\""},
+		},
+	}
+	assert.Equal(t, `invalid arguments for 'githubSearch': params.query: required property is missing; params.limit: expected integer, got string "5"`, err.Error())
+}
+
+type fixedPolicy bool
+
+func (p fixedPolicy) ValidatesArgs(string) bool { return bool(p) }
+
+func TestValidationEnabled(t *testing.T) {
+	assert.True(t, ValidationEnabled(struct{}{}, "any"))
+	assert.True(t, ValidationEnabled(fixedPolicy(true), "any"))
+	assert.False(t, ValidationEnabled(fixedPolicy(false), "any"))
+}
@@ -0,0 +1,229 @@
+// Package scripts manages the script library: a directory of saved exec
+// scripts, run by name, with shared modules in its lib folder that scripts
+// load with require("lib/name").
+package scripts
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/vaayne/mcphub/internal/js"
+)
+
+// nameRegex matches script names: letters, digits, '-' and '_'
+var nameRegex = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]*$`)
+
+// extensions are the file extensions of saved scripts and their languages, in
+// the order Load looks for them
+var extensions = []struct {
+	ext      string
+	language string
+}{
+	{".js", js.LanguageJavaScript},
+	{".ts", js.LanguageTypeScript},
+}
+
+// languageOf returns the language of a saved script file extension
+func languageOf(ext string) (string, bool) {
+	for _, e := range extensions {
+		if e.ext == ext {
+			return e.language, true
+		}
+	}
+	return "", false
+}
+
+// Script is a saved script
+type Script struct {
+	Name        string `json:"name"`
+	Language    string `json:"language"`
+	Description string `json:"description,omitempty"` // the script's leading comment
+	Path        string `json:"path"`
+}
+
+// Library is a script library directory. Saved scripts are the .js and .ts
+// files at its top level, named after the file; modules live in its lib
+// folder.
+type Library struct {
+	dir string
+}
+
+// NewLibrary returns the script library in dir; the directory is created
+// when a script is first saved
+func NewLibrary(dir string) *Library {
+	return &Library{dir: dir}
+}
+
+// Dir returns the library directory
+func (l *Library) Dir() string {
+	return l.dir
+}
+
+// ValidateName checks that name can name a saved script
+func ValidateName(name string) error {
+	if !nameRegex.MatchString(name) || len(name) > 100 {
+		return fmt.Errorf("invalid script name %q: use up to 100 letters, digits, '-' and '_'", name)
+	}
+	return nil
+}
+
+// List returns the saved scripts, sorted by name. A missing library
+// directory has no scripts.
+func (l *Library) List() ([]Script, error) {
+	entries, err := os.ReadDir(l.dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read script library: %w", err)
+	}
+
+	var scripts []Script
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		name := strings.TrimSuffix(entry.Name(), ext)
+		language, ok := languageOf(ext)
+		if !ok || !entry.Type().IsRegular() || ValidateName(name) != nil {
+			continue
+		}
+		script := Script{Name: name, Language: language, Path: filepath.Join(l.dir, entry.Name())}
+		if data, err := os.ReadFile(script.Path); err == nil {
+			script.Description = Description(string(data))
+		}
+		scripts = append(scripts, script)
+	}
+	sort.Slice(scripts, func(i, j int) bool {
+		return scripts[i].Name < scripts[j].Name
+	})
+	return scripts, nil
+}
+
+// Modules returns the names scripts require library modules by, such as
+// "lib/strings", sorted
+func (l *Library) Modules() ([]string, error) {
+	libDir := filepath.Join(l.dir, js.ModulesDir)
+	var modules []string
+	err := filepath.WalkDir(libDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) && path == libDir {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		ext := filepath.Ext(path)
+		if d.IsDir() || (ext != ".js" && ext != ".ts" && ext != ".json") {
+			return nil
+		}
+		rel, err := filepath.Rel(l.dir, path)
+		if err != nil {
+			return err
+		}
+		if ext != ".json" {
+			rel = strings.TrimSuffix(rel, ext)
+		}
+		modules = append(modules, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read script library modules: %w", err)
+	}
+	sort.Strings(modules)
+	return modules, nil
+}
+
+// Load returns a saved script and its code
+func (l *Library) Load(name string) (*Script, string, error) {
+	if err := ValidateName(name); err != nil {
+		return nil, "", err
+	}
+	for _, e := range extensions {
+		path := filepath.Join(l.dir, name+e.ext)
+		data, err := os.ReadFile(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to read script %s: %w", name, err)
+		}
+		code := string(data)
+		return &Script{Name: name, Language: e.language, Description: Description(code), Path: path}, code, nil
+	}
+	return nil, "", fmt.Errorf("script not found: %s (see mh scripts list)", name)
+}
+
+// Save stores code as the saved script name, replacing any script of that
+// name in either language
+func (l *Library) Save(name, code, language string) (*Script, error) {
+	if err := ValidateName(name); err != nil {
+		return nil, err
+	}
+	language, err := js.ParseLanguage(language)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(code) == "" {
+		return nil, fmt.Errorf("code is required")
+	}
+	if len(code) > js.MaxScriptSize {
+		return nil, fmt.Errorf("code exceeds maximum length of %d bytes", js.MaxScriptSize)
+	}
+
+	if err := os.MkdirAll(l.dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create script library: %w", err)
+	}
+	var path string
+	for _, e := range extensions {
+		p := filepath.Join(l.dir, name+e.ext)
+		if e.language == language {
+			path = p
+			continue
+		}
+		if err := os.Remove(p); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("failed to replace script %s: %w", name, err)
+		}
+	}
+	if err := os.WriteFile(path, []byte(code), 0o644); err != nil {
+		return nil, fmt.Errorf("failed to save script %s: %w", name, err)
+	}
+	return &Script{Name: name, Language: language, Description: Description(code), Path: path}, nil
+}
+
+// Description returns the leading comment of a script, line or block, with
+// comment markers removed
+func Description(code string) string {
+	code = strings.TrimLeft(code, " \t\r\n")
+	if strings.HasPrefix(code, "#!") {
+		_, code, _ = strings.Cut(code, "\n")
+		code = strings.TrimLeft(code, " \t\r\n")
+	}
+
+	var lines []string
+	switch {
+	case strings.HasPrefix(code, "/*"):
+		body, _, ok := strings.Cut(code[2:], "*/")
+		if !ok {
+			return ""
+		}
+		for _, line := range strings.Split(body, "\n") {
+			line = strings.TrimSpace(line)
+			line = strings.TrimSpace(strings.TrimPrefix(strings.TrimPrefix(line, "*"), "*"))
+			lines = append(lines, line)
+		}
+	case strings.HasPrefix(code, "//"):
+		for _, line := range strings.Split(code, "\n") {
+			line = strings.TrimSpace(line)
+			text, ok := strings.CutPrefix(line, "//")
+			if !ok {
+				break
+			}
+			lines = append(lines, strings.TrimSpace(text))
+		}
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
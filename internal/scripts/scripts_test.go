@@ -0,0 +1,101 @@
+package scripts
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLibrary_SaveLoadList(t *testing.T) {
+	lib := NewLibrary(filepath.Join(t.TempDir(), "scripts"))
+
+	scripts, err := lib.List()
+	require.NoError(t, err)
+	assert.Empty(t, scripts, "a missing library has no scripts")
+
+	saved, err := lib.Save("greet", "// Greets someone\nparams.name", "")
+	require.NoError(t, err)
+	assert.Equal(t, "javascript", saved.Language)
+	assert.Equal(t, filepath.Join(lib.Dir(), "greet.js"), saved.Path)
+
+	_, err = lib.Save("add", "/**\n * Adds numbers\n * @param a first\n */\nconst a: number = params.a;", "ts")
+	require.NoError(t, err)
+
+	script, code, err := lib.Load("greet")
+	require.NoError(t, err)
+	assert.Equal(t, "// Greets someone\nparams.name", code)
+	assert.Equal(t, "Greets someone", script.Description)
+
+	scripts, err = lib.List()
+	require.NoError(t, err)
+	require.Len(t, scripts, 2)
+	assert.Equal(t, "add", scripts[0].Name)
+	assert.Equal(t, "typescript", scripts[0].Language)
+	assert.Equal(t, "Adds numbers\n@param a first", scripts[0].Description)
+	assert.Equal(t, "greet", scripts[1].Name)
+
+	// Saving in the other language replaces the script
+	_, err = lib.Save("greet", "const name: string = params.name;", "typescript")
+	require.NoError(t, err)
+	script, _, err = lib.Load("greet")
+	require.NoError(t, err)
+	assert.Equal(t, "typescript", script.Language)
+	assert.NoFileExists(t, filepath.Join(lib.Dir(), "greet.js"))
+}
+
+func TestLibrary_Errors(t *testing.T) {
+	lib := NewLibrary(t.TempDir())
+
+	for _, name := range []string{"", "../escape", "a/b", ".hidden", "has space"} {
+		_, err := lib.Save(name, "1", "")
+		assert.Error(t, err, "name %q", name)
+		_, _, err = lib.Load(name)
+		assert.Error(t, err, "name %q", name)
+	}
+
+	_, err := lib.Save("empty", "  ", "")
+	assert.Error(t, err)
+	_, err = lib.Save("python", "print(1)", "python")
+	assert.Error(t, err)
+
+	_, _, err = lib.Load("missing")
+	assert.ErrorContains(t, err, "script not found")
+}
+
+func TestLibrary_Modules(t *testing.T) {
+	lib := NewLibrary(t.TempDir())
+	modules, err := lib.Modules()
+	require.NoError(t, err)
+	assert.Empty(t, modules)
+
+	for _, name := range []string{"lib/strings.js", "lib/math.ts", "lib/text/index.js", "lib/data.json", "lib/README.md", "top.js"} {
+		path := filepath.Join(lib.Dir(), filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte("1"), 0o644))
+	}
+
+	modules, err = lib.Modules()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"lib/data.json", "lib/math", "lib/strings", "lib/text/index"}, modules)
+}
+
+func TestDescription(t *testing.T) {
+	tests := []struct {
+		code string
+		want string
+	}{
+		{"// One line\n// Two lines\ncode()", "One line\nTwo lines"},
+		{"#!/usr/bin/env node\n// After shebang\n", "After shebang"},
+		{"/* Block */ code()", "Block"},
+		{"/**\n * Doc\n */", "Doc"},
+		{"/* unterminated", ""},
+		{"code() // trailing", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, Description(tt.code), "code %q", tt.code)
+	}
+}
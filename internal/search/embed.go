@@ -0,0 +1,126 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"sort"
+	"sync"
+)
+
+// Embedder turns texts into vectors whose cosine similarity reflects how
+// related the texts are
+type Embedder interface {
+	// Embed returns one vector per text, in order
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// EmbedderFactory creates an embedder
+type EmbedderFactory func() (Embedder, error)
+
+// EmbedderHash names the built-in hashed n-gram embedder
+const EmbedderHash = "hash"
+
+var (
+	embeddersMu sync.RWMutex
+	embedders   = map[string]EmbedderFactory{
+		EmbedderHash: func() (Embedder, error) { return NewHashEmbedder(DefaultHashDims), nil },
+	}
+)
+
+// RegisterEmbedder makes an embedder available under name for the "embedder"
+// search setting, replacing any embedder registered under the same name
+func RegisterEmbedder(name string, factory EmbedderFactory) {
+	embeddersMu.Lock()
+	defer embeddersMu.Unlock()
+	embedders[name] = factory
+}
+
+// NewEmbedder creates the embedder registered under name
+func NewEmbedder(name string) (Embedder, error) {
+	embeddersMu.RLock()
+	factory, ok := embedders[name]
+	embeddersMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown search embedder: %s (available: %v)", name, Embedders())
+	}
+	return factory()
+}
+
+// Embedders returns the names of the registered embedders, sorted
+func Embedders() []string {
+	embeddersMu.RLock()
+	defer embeddersMu.RUnlock()
+	names := make([]string, 0, len(embedders))
+	for name := range embedders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// DefaultHashDims is the size of vectors from the built-in hash embedder
+const DefaultHashDims = 512
+
+// HashEmbedder is a local, deterministic embedder. Each term and each
+// character trigram of a term is hashed into one of dims buckets, so texts that
+// share words or word fragments ("file" and "filesystem") get similar vectors.
+// It needs no model, and the same text always yields the same vector.
+type HashEmbedder struct {
+	dims int
+}
+
+// NewHashEmbedder returns a hash embedder producing vectors of dims dimensions
+func NewHashEmbedder(dims int) *HashEmbedder {
+	return &HashEmbedder{dims: max(dims, 1)}
+}
+
+// Embed implements Embedder
+func (e *HashEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		vectors[i] = e.embed(text)
+	}
+	return vectors, nil
+}
+
+func (e *HashEmbedder) embed(text string) []float32 {
+	vector := make([]float32, e.dims)
+	add := func(feature string, weight float32) {
+		h := fnv.New64a()
+		h.Write([]byte(feature))
+		sum := h.Sum64()
+		sign := float32(1)
+		if sum>>63 == 1 {
+			sign = -1
+		}
+		vector[sum%uint64(e.dims)] += sign * weight
+	}
+
+	for _, term := range Tokenize(text) {
+		add("w:"+term, 1)
+		padded := []rune("^" + term + "$")
+		for i := 0; i+3 <= len(padded); i++ {
+			add("g:"+string(padded[i:i+3]), 1)
+		}
+	}
+
+	var norm float64
+	for _, v := range vector {
+		norm += float64(v) * float64(v)
+	}
+	if norm > 0 {
+		scale := float32(1 / math.Sqrt(norm))
+		for i := range vector {
+			vector[i] *= scale
+		}
+	}
+	return vector
+}
+
+// Ensure HashEmbedder implements Embedder
+var _ Embedder = (*HashEmbedder)(nil)
@@ -0,0 +1,303 @@
+// Package search ranks tools against a free-text query. Tools are scored with
+// BM25 over their name, description and parameters; when an embedder is
+// configured, the BM25 score is blended with embedding similarity.
+package search
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/vaayne/mcphub/internal/config"
+)
+
+// BM25 parameters
+const (
+	k1 = 1.2
+	b  = 0.75
+)
+
+// Field weights: a term in a tool's name says more about it than one in its
+// description
+const (
+	nameWeight        = 3.0
+	paramNameWeight   = 1.5
+	descriptionWeight = 1.0
+)
+
+// MinSimilarity is the embedding similarity a document needs to match a query
+// it shares no terms with
+const MinSimilarity = 0.2
+
+// maxVectors bounds the number of cached document embeddings
+const maxVectors = 10_000
+
+// Param is a tool parameter
+type Param struct {
+	Name        string
+	Description string
+}
+
+// Document is a searchable tool
+type Document struct {
+	ID          string
+	Name        string
+	Description string
+	Params      []Param
+}
+
+// ToolDocument builds the document for tool, listed under id
+func ToolDocument(id string, tool *mcp.Tool) Document {
+	doc := Document{ID: id, Name: tool.Name, Description: tool.Description}
+	schema, _ := tool.InputSchema.(map[string]any)
+	properties, _ := schema["properties"].(map[string]any)
+	for name, prop := range properties {
+		param := Param{Name: name}
+		if p, ok := prop.(map[string]any); ok {
+			param.Description, _ = p["description"].(string)
+		}
+		doc.Params = append(doc.Params, param)
+	}
+	sort.Slice(doc.Params, func(i, j int) bool {
+		return doc.Params[i].Name < doc.Params[j].Name
+	})
+	return doc
+}
+
+// text is the document as embedded
+func (d Document) text() string {
+	var sb strings.Builder
+	sb.WriteString(d.Name)
+	sb.WriteString("\n")
+	sb.WriteString(d.Description)
+	for _, p := range d.Params {
+		sb.WriteString("\n")
+		sb.WriteString(p.Name)
+		sb.WriteString(": ")
+		sb.WriteString(p.Description)
+	}
+	return sb.String()
+}
+
+// Hit is a document that matched a query
+type Hit struct {
+	ID    string  `json:"id"`
+	Score float64 `json:"score"`
+}
+
+// Searcher ranks documents against queries. It is safe for concurrent use.
+type Searcher struct {
+	embedder Embedder // nil = BM25 only
+	weight   float64  // share of the score from embedding similarity
+
+	mu      sync.Mutex
+	vectors map[string][]float32 // document text -> embedding
+}
+
+// NewSearcher returns a searcher that blends BM25 with the similarity of
+// embeddings from embedder, weighted by weight (0-1). A nil embedder ranks by
+// BM25 alone.
+func NewSearcher(embedder Embedder, weight float64) *Searcher {
+	if embedder == nil {
+		weight = 0
+	}
+	return &Searcher{
+		embedder: embedder,
+		weight:   min(max(weight, 0), 1),
+		vectors:  make(map[string][]float32),
+	}
+}
+
+// New creates the searcher described by cfg; a nil cfg ranks by BM25 alone
+func New(cfg *config.SearchConfig) (*Searcher, error) {
+	if cfg == nil || cfg.Embedder == "" {
+		return NewSearcher(nil, 0), nil
+	}
+	embedder, err := NewEmbedder(cfg.Embedder)
+	if err != nil {
+		return nil, err
+	}
+	return NewSearcher(embedder, cfg.GetSemanticWeight()), nil
+}
+
+// Search returns the documents matching query, best first, at most limit of
+// them (0 = all). Documents with equal scores are ordered by ID.
+func (s *Searcher) Search(ctx context.Context, docs []Document, query string, limit int) ([]Hit, error) {
+	terms := Tokenize(query)
+	if len(terms) == 0 || len(docs) == 0 {
+		return nil, nil
+	}
+
+	lexical := bm25(docs, terms)
+	maxLexical := 0.0
+	for _, score := range lexical {
+		maxLexical = max(maxLexical, score)
+	}
+
+	var similarity []float64
+	if s.embedder != nil && s.weight > 0 {
+		var err error
+		similarity, err = s.similarity(ctx, docs, query)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	hits := make([]Hit, 0, len(docs))
+	for i, doc := range docs {
+		score := 0.0
+		if maxLexical > 0 {
+			score = (1 - s.weight) * lexical[i] / maxLexical
+		}
+		if similarity != nil {
+			if lexical[i] == 0 && similarity[i] < MinSimilarity {
+				continue
+			}
+			score += s.weight * similarity[i]
+		}
+		if score <= 0 {
+			continue
+		}
+		hits = append(hits, Hit{ID: doc.ID, Score: score})
+	}
+
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].ID < hits[j].ID
+	})
+	if limit > 0 && len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return hits, nil
+}
+
+// bm25 scores every document against terms
+func bm25(docs []Document, terms []string) []float64 {
+	freqs := make([]map[string]float64, len(docs))
+	lengths := make([]float64, len(docs))
+	df := make(map[string]int)
+	total := 0.0
+
+	for i, doc := range docs {
+		tf := make(map[string]float64)
+		add := func(text string, weight float64) {
+			for _, term := range Tokenize(text) {
+				tf[term] += weight
+				lengths[i] += weight
+			}
+		}
+		add(doc.Name, nameWeight)
+		add(doc.Description, descriptionWeight)
+		for _, p := range doc.Params {
+			add(p.Name, paramNameWeight)
+			add(p.Description, descriptionWeight)
+		}
+		for term := range tf {
+			df[term]++
+		}
+		freqs[i] = tf
+		total += lengths[i]
+	}
+
+	avgLength := total / float64(len(docs))
+	if avgLength == 0 {
+		avgLength = 1
+	}
+
+	n := float64(len(docs))
+	scores := make([]float64, len(docs))
+	for _, term := range unique(terms) {
+		if df[term] == 0 {
+			continue
+		}
+		idf := math.Log(1 + (n-float64(df[term])+0.5)/(float64(df[term])+0.5))
+		for i, tf := range freqs {
+			f := tf[term]
+			if f == 0 {
+				continue
+			}
+			scores[i] += idf * f * (k1 + 1) / (f + k1*(1-b+b*lengths[i]/avgLength))
+		}
+	}
+	return scores
+}
+
+// similarity returns the cosine similarity of each document to query.
+// Document embeddings are cached by text, so only new or changed tools are
+// embedded.
+func (s *Searcher) similarity(ctx context.Context, docs []Document, query string) ([]float64, error) {
+	docVectors := make([][]float32, len(docs))
+	var missing []string
+	var missingDocs []int
+	s.mu.Lock()
+	for i, doc := range docs {
+		text := doc.text()
+		if vector, ok := s.vectors[text]; ok {
+			docVectors[i] = vector
+			continue
+		}
+		missing = append(missing, text)
+		missingDocs = append(missingDocs, i)
+	}
+	s.mu.Unlock()
+
+	vectors, err := s.embedder.Embed(ctx, append(missing, query))
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed tools: %w", err)
+	}
+	if len(vectors) != len(missing)+1 {
+		return nil, fmt.Errorf("embedder returned %d vectors for %d texts", len(vectors), len(missing)+1)
+	}
+	queryVector := vectors[len(missing)]
+
+	s.mu.Lock()
+	if len(s.vectors)+len(missing) > maxVectors {
+		s.vectors = make(map[string][]float32)
+	}
+	for i, text := range missing {
+		s.vectors[text] = vectors[i]
+		docVectors[missingDocs[i]] = vectors[i]
+	}
+	s.mu.Unlock()
+
+	similarity := make([]float64, len(docs))
+	for i, vector := range docVectors {
+		similarity[i] = cosine(vector, queryVector)
+	}
+	return similarity, nil
+}
+
+// cosine returns the cosine similarity of a and b, or 0 if either is empty
+func cosine(a, b []float32) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+func unique(terms []string) []string {
+	seen := make(map[string]bool, len(terms))
+	out := terms[:0:0]
+	for _, term := range terms {
+		if !seen[term] {
+			seen[term] = true
+			out = append(out, term)
+		}
+	}
+	return out
+}
@@ -0,0 +1,115 @@
+package search
+
+import (
+	"context"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vaayne/mcphub/internal/config"
+)
+
+func TestTokenize(t *testing.T) {
+	assert.Equal(t, []string{"get", "pull", "request"}, Tokenize("getPullRequests"))
+	assert.Equal(t, []string{"github", "search", "repository"}, Tokenize("github__search_repositories"))
+	assert.Equal(t, []string{"http", "server", "v", "2"}, Tokenize("HTTPServer v2"))
+	assert.Equal(t, []string{"list", "file", "directory"}, Tokenize("List the files in a directory"))
+}
+
+var docs = []Document{
+	ToolDocument("github__create_issue", &mcp.Tool{Name: "github__create_issue", Description: "Create a new issue in a GitHub repository"}),
+	ToolDocument("github__list_issues", &mcp.Tool{Name: "github__list_issues", Description: "List issues in a GitHub repository"}),
+	ToolDocument("fs__read_file", &mcp.Tool{Name: "fs__read_file", Description: "Read the contents of a file", InputSchema: map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"path": map[string]any{"type": "string", "description": "Absolute path"},
+		},
+	}}),
+	ToolDocument("fs__filesystem_info", &mcp.Tool{Name: "fs__filesystem_info", Description: "Describe the mounted volumes"}),
+}
+
+func ids(hits []Hit) []string {
+	out := make([]string, len(hits))
+	for i, hit := range hits {
+		out[i] = hit.ID
+	}
+	return out
+}
+
+func TestSearch_BM25(t *testing.T) {
+	s := NewSearcher(nil, 0)
+
+	hits, err := s.Search(context.Background(), docs, "create issue", 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"github__create_issue", "github__list_issues"}, ids(hits))
+	assert.Greater(t, hits[0].Score, hits[1].Score)
+
+	hits, err = s.Search(context.Background(), docs, "absolute path", 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"fs__read_file"}, ids(hits), "parameters are indexed")
+
+	hits, err = s.Search(context.Background(), docs, "issues", 1)
+	require.NoError(t, err)
+	assert.Len(t, hits, 1)
+
+	hits, err = s.Search(context.Background(), docs, "the", 0)
+	require.NoError(t, err)
+	assert.Empty(t, hits)
+}
+
+func TestSearch_Embeddings(t *testing.T) {
+	s := NewSearcher(NewHashEmbedder(DefaultHashDims), 0.5)
+
+	// "file" shares no term with fs__filesystem_info but shares trigrams
+	hits, err := s.Search(context.Background(), docs, "file", 0)
+	require.NoError(t, err)
+	assert.Equal(t, "fs__read_file", hits[0].ID)
+	assert.Contains(t, ids(hits), "fs__filesystem_info")
+	assert.NotContains(t, ids(hits), "github__list_issues")
+
+	// Results are deterministic and embeddings are cached
+	again, err := s.Search(context.Background(), docs, "file", 0)
+	require.NoError(t, err)
+	assert.Equal(t, hits, again)
+	assert.Len(t, s.vectors, len(docs))
+}
+
+func TestHashEmbedder_Deterministic(t *testing.T) {
+	e := NewHashEmbedder(64)
+	a, err := e.Embed(context.Background(), []string{"read a file", "read a file", "list issues"})
+	require.NoError(t, err)
+	require.Len(t, a, 3)
+	assert.Equal(t, a[0], a[1])
+	assert.InDelta(t, 1, cosine(a[0], a[1]), 1e-6)
+	assert.Less(t, cosine(a[0], a[2]), 0.5)
+}
+
+type staticEmbedder struct{}
+
+func (staticEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i := range texts {
+		vectors[i] = []float32{1, 0}
+	}
+	return vectors, nil
+}
+
+func TestNew(t *testing.T) {
+	s, err := New(nil)
+	require.NoError(t, err)
+	assert.Nil(t, s.embedder)
+
+	_, err = New(&config.SearchConfig{Embedder: "missing"})
+	assert.ErrorContains(t, err, "unknown search embedder")
+
+	RegisterEmbedder("static", func() (Embedder, error) { return staticEmbedder{}, nil })
+	weight := 1.0
+	s, err = New(&config.SearchConfig{Embedder: "static", SemanticWeight: &weight})
+	require.NoError(t, err)
+
+	// With all weight on similarity, every document matches equally
+	hits, err := s.Search(context.Background(), docs, "anything", 0)
+	require.NoError(t, err)
+	assert.Len(t, hits, len(docs))
+}
@@ -0,0 +1,75 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+// stopWords are dropped from documents and queries
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "be": true,
+	"by": true, "for": true, "from": true, "in": true, "is": true, "it": true,
+	"of": true, "on": true, "or": true, "that": true, "the": true, "this": true,
+	"to": true, "with": true,
+}
+
+// Tokenize splits text into lowercase terms. Identifiers are split at
+// underscores, hyphens and camelCase boundaries ("getPullRequest" becomes
+// "get", "pull", "request"), stop words are dropped and plurals are reduced to
+// their singular.
+func Tokenize(text string) []string {
+	var terms []string
+	var word []rune
+	flush := func() {
+		if len(word) == 0 {
+			return
+		}
+		term := stem(strings.ToLower(string(word)))
+		word = word[:0]
+		if !stopWords[term] {
+			terms = append(terms, term)
+		}
+	}
+
+	runes := []rune(text)
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			flush()
+			continue
+		}
+		if i > 0 && len(word) > 0 && isBoundary(runes[i-1], r, runes[i+1:]) {
+			flush()
+		}
+		word = append(word, r)
+	}
+	flush()
+	return terms
+}
+
+// isBoundary reports whether a new word starts at r, given the rune before it
+// and the runes after it
+func isBoundary(prev, r rune, next []rune) bool {
+	switch {
+	case unicode.IsLower(prev) && unicode.IsUpper(r):
+		return true // camelCase
+	case unicode.IsUpper(prev) && unicode.IsUpper(r) && len(next) > 0 && unicode.IsLower(next[0]):
+		return true // HTTPServer -> HTTP, Server
+	case unicode.IsDigit(prev) != unicode.IsDigit(r):
+		return true // v2 -> v, 2
+	}
+	return false
+}
+
+// stem reduces common English plurals to their singular
+func stem(term string) string {
+	switch {
+	case len(term) > 4 && strings.HasSuffix(term, "ies"):
+		return term[:len(term)-3] + "y"
+	case len(term) > 4 && (strings.HasSuffix(term, "sses") || strings.HasSuffix(term, "xes") || strings.HasSuffix(term, "ches") || strings.HasSuffix(term, "shes")):
+		return term[:len(term)-2]
+	case len(term) > 3 && strings.HasSuffix(term, "s") &&
+		!strings.HasSuffix(term, "ss") && !strings.HasSuffix(term, "us") && !strings.HasSuffix(term, "is"):
+		return term[:len(term)-1]
+	}
+	return term
+}
@@ -104,11 +104,15 @@ func TestIntegration_MultipleTools(t *testing.T) {
 
 	// Verify built-in tools are registered
 	builtinTools := server.builtinRegistry.GetAllTools()
-	assert.Len(t, builtinTools, 4)
+	assert.Len(t, builtinTools, 8)
 	assert.Contains(t, builtinTools, "list")
 	assert.Contains(t, builtinTools, "inspect")
 	assert.Contains(t, builtinTools, "invoke")
 	assert.Contains(t, builtinTools, "exec")
+	assert.Contains(t, builtinTools, "read")
+	assert.Contains(t, builtinTools, "status")
+	assert.Contains(t, builtinTools, "types")
+	assert.Contains(t, builtinTools, "run")
 
 	// Test that mock server has tools registered
 	assert.NotNil(t, mockServer)
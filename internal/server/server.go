@@ -5,10 +5,18 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"os"
 	"time"
 
+	"github.com/vaayne/mcphub/internal/cache"
+	"github.com/vaayne/mcphub/internal/catalog"
 	"github.com/vaayne/mcphub/internal/client"
 	"github.com/vaayne/mcphub/internal/config"
+	"github.com/vaayne/mcphub/internal/js"
+	"github.com/vaayne/mcphub/internal/results"
+	"github.com/vaayne/mcphub/internal/search"
+	"github.com/vaayne/mcphub/internal/startup"
+	"github.com/vaayne/mcphub/internal/state"
 	"github.com/vaayne/mcphub/internal/tools"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
@@ -28,6 +36,9 @@ type Server struct {
 	mcpServer       *mcp.Server
 	clientManager   *client.Manager
 	builtinRegistry *tools.BuiltinToolRegistry
+	resultStore     *results.Store   // full output of truncated results
+	searcher        *search.Searcher // ranks list queries
+	stateStore      *state.Store     // mcp.state of exec scripts
 	toolCallTimeout time.Duration
 	httpServer      *http.Server // for graceful shutdown of HTTP/SSE
 }
@@ -37,6 +48,7 @@ func NewServer(cfg *config.Config, logger *slog.Logger) *Server {
 	return &Server{
 		config:          cfg,
 		logger:          logger,
+		resultStore:     results.NewStore(results.DefaultMaxEntries, results.DefaultTTL),
 		toolCallTimeout: 60 * time.Second,
 	}
 }
@@ -50,6 +62,40 @@ func (s *Server) Start(ctx context.Context, transportCfg TransportConfig) error
 	// Initialize client manager
 	s.clientManager = client.NewManager(s.logger)
 
+	// Initialize response cache
+	resultCache, err := cache.New(s.config.Cache)
+	if err != nil {
+		return fmt.Errorf("failed to create cache: %w", err)
+	}
+	if resultCache != nil {
+		s.clientManager.SetCache(resultCache, s.config.Cache.GetTTL())
+		s.logger.Info("Response cache enabled", slog.String("store", s.config.Cache.GetStore()))
+	}
+
+	// Persist what each server offers; lazy servers list their tools from the
+	// catalog until started
+	if catalogCfg := s.config.GetCatalog(); catalogCfg.IsEnabled() {
+		store, err := catalog.NewStore(catalogCfg.GetDir(), catalogCfg.GetTTL())
+		if err != nil {
+			return fmt.Errorf("failed to open tool catalog: %w", err)
+		}
+		s.clientManager.SetCatalog(store)
+	}
+
+	// Initialize tool search
+	searcher, err := search.New(s.config.Search)
+	if err != nil {
+		return fmt.Errorf("failed to create tool search: %w", err)
+	}
+	s.searcher = searcher
+
+	// Initialize exec script state
+	stateStore, err := state.New(s.config.GetState())
+	if err != nil {
+		return fmt.Errorf("failed to open script state: %w", err)
+	}
+	s.stateStore = stateStore
+
 	// Initialize builtin tool registry
 	s.builtinRegistry = tools.NewBuiltinToolRegistry(s.logger)
 
@@ -171,14 +217,43 @@ func (s *Server) Stop() error {
 
 // registerBuiltinTools registers all built-in tools
 func (s *Server) registerBuiltinTools() {
-	// Schema for list tool
-	// Register list tool (no parameters - returns all tools)
+	// Register list tool (all parameters optional - returns all tools)
 	s.builtinRegistry.RegisterTool(config.BuiltinTool{
 		Name:        "list",
 		Description: tools.ListDescription,
 		InputSchema: map[string]any{
-			"type":       "object",
-			"properties": map[string]any{},
+			"type": "object",
+			"properties": map[string]any{
+				"query": map[string]any{
+					"type":        "string",
+					"description": "What the tool should do; matching tools are ranked best first",
+					"maxLength":   1000,
+				},
+				"server": map[string]any{
+					"type":        "string",
+					"description": "Only list tools of this server",
+				},
+				"limit": map[string]any{
+					"type":        "integer",
+					"description": "Maximum number of tools to return (default 100)",
+					"minimum":     1,
+					"maximum":     100,
+				},
+				"offset": map[string]any{
+					"type":        "integer",
+					"description": "Number of matching tools to skip",
+					"minimum":     0,
+				},
+				"detail": map[string]any{
+					"type":        "string",
+					"description": "What to return per tool: names, descriptions (default) or full schemas",
+					"enum":        []string{"names", "descriptions", "full"},
+				},
+				"cursor": map[string]any{
+					"type":        "string",
+					"description": "nextCursor from a previous call, to get the next page (other arguments are ignored)",
+				},
+			},
 		},
 	})
 
@@ -220,53 +295,147 @@ func (s *Server) registerBuiltinTools() {
 		},
 	})
 
-	// Register exec tool
+	// Register exec tool, unless the config disables it
+	if s.config.GetExec().IsEnabled() {
+		s.builtinRegistry.RegisterTool(config.BuiltinTool{
+			Name:        "exec",
+			Description: tools.ExecDescription,
+			InputSchema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"code": map[string]any{
+						"type":        "string",
+						"minLength":   1,
+						"description": "JavaScript (or TypeScript, see language) to execute (async/await, timers, require for node:* built-ins and lib/* script library modules). Use mcp.callTool() for MCP tools.",
+					},
+					"language": map[string]any{
+						"type":        "string",
+						"description": "Language of code; typescript has its type annotations removed before running (default javascript)",
+						"enum":        []string{js.LanguageJavaScript, js.LanguageTypeScript},
+					},
+					"maxConcurrency": map[string]any{
+						"type":        "integer",
+						"description": "mcp.callToolAsync calls in flight at once (default 4)",
+						"minimum":     1,
+						"maximum":     js.MaxConcurrencyLimit,
+					},
+					"trace": map[string]any{
+						"type":        "boolean",
+						"description": "Return a trace of every tool call (arguments, result summary, duration, error) and the JS stack at a failure",
+					},
+				},
+				"required": []string{"code"},
+			},
+		})
+	}
+
+	// Register read tool
 	s.builtinRegistry.RegisterTool(config.BuiltinTool{
-		Name:        "exec",
-		Description: tools.ExecDescription,
+		Name:        "read",
+		Description: tools.ReadDescription,
 		InputSchema: map[string]any{
 			"type": "object",
 			"properties": map[string]any{
-				"code": map[string]any{
+				"handle": map[string]any{
 					"type":        "string",
-					"minLength":   1,
-					"description": "JavaScript to execute (async/await, timers, require for node:* built-ins). Use mcp.callTool() for MCP tools.",
+					"description": "Result handle from a truncation note",
+					"maxLength":   100,
+				},
+				"offset": map[string]any{
+					"type":        "integer",
+					"minimum":     0,
+					"description": "Byte offset to start reading from",
+				},
+				"limit": map[string]any{
+					"type":        "integer",
+					"minimum":     1,
+					"description": "Maximum bytes to return",
 				},
 			},
-			"required": []string{"code"},
+			"required": []string{"handle"},
 		},
 	})
-}
 
-// connectToRemoteServers connects to all configured remote MCP servers
-func (s *Server) connectToRemoteServers() error {
-	var errors []error
+	// Register types tool
+	s.builtinRegistry.RegisterTool(config.BuiltinTool{
+		Name:        "types",
+		Description: tools.TypesDescription,
+		InputSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"server": map[string]any{
+					"type":        "string",
+					"description": "Only declare the tools of this server",
+				},
+			},
+		},
+	})
 
-	for serverID, serverCfg := range s.config.MCPServers {
-		// Skip disabled servers
-		if !serverCfg.IsEnabled() {
-			s.logger.Info("Skipping disabled server", slog.String("serverID", serverID))
-			continue
-		}
+	// Register run tool (without a name it lists the saved scripts), unless the
+	// config disables scripts
+	if s.config.GetExec().IsEnabled() {
+		s.builtinRegistry.RegisterTool(config.BuiltinTool{
+			Name:        "run",
+			Description: tools.RunDescription,
+			InputSchema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"name": map[string]any{
+						"type":        "string",
+						"description": "Saved script to run; omit to list the saved scripts",
+						"maxLength":   100,
+					},
+					"params": map[string]any{
+						"type":        "object",
+						"description": "Parameters exposed to the script as the params global",
+					},
+				},
+			},
+		})
+	}
+
+	// Register status tool
+	s.builtinRegistry.RegisterTool(config.BuiltinTool{
+		Name:        "status",
+		Description: tools.StatusDescription,
+		InputSchema: map[string]any{
+			"type":       "object",
+			"properties": map[string]any{},
+		},
+	})
+}
 
-		s.logger.Info("Connecting to server", slog.String("serverID", serverID))
-		if err := s.clientManager.ConnectToServer(serverID, serverCfg); err != nil {
+// connectToRemoteServers connects to all configured remote MCP servers, several
+// at a time, and writes a summary table of the outcome to stderr
+func (s *Server) connectToRemoteServers() error {
+	startupCfg := s.config.GetStartup()
+	s.clientManager.SetConnectTimeout(startupCfg.GetConnectTimeout())
+
+	report := startup.Run(context.Background(), s.config.MCPServers, startupCfg.GetParallelism(),
+		func(ctx context.Context, serverID string, serverCfg config.MCPServer) error {
+			s.logger.Info("Connecting to server", slog.String("serverID", serverID))
+			return s.clientManager.ConnectToServer(serverID, serverCfg)
+		})
+
+	for _, result := range report.Results {
+		switch result.Status {
+		case startup.StatusFailed:
 			s.logger.Error("Failed to connect to server",
-				slog.String("serverID", serverID),
-				slog.String("error", err.Error()),
+				slog.String("serverID", result.Server),
+				slog.Duration("duration", result.Duration),
+				slog.String("error", result.Note),
 			)
-
-			// If server is required, return error immediately
-			if serverCfg.Required {
-				return fmt.Errorf("required server %s failed to connect: %w", serverID, err)
-			}
-
-			errors = append(errors, fmt.Errorf("server %s: %w", serverID, err))
+		case startup.StatusSkipped:
+			s.logger.Info("Skipping disabled server", slog.String("serverID", result.Server))
 		}
 	}
+	_ = report.WriteTable(os.Stderr)
 
-	if len(errors) > 0 {
-		s.logger.Warn("Some optional servers failed to connect", slog.Int("count", len(errors)))
+	if err := report.Err(); err != nil {
+		return err
+	}
+	if failed := report.Count(startup.StatusFailed); failed > 0 {
+		s.logger.Warn("Some optional servers failed to connect", slog.Int("count", failed))
 	}
 
 	return nil
@@ -309,16 +478,52 @@ func (s *Server) registerBuiltinToolHandler(toolName string, builtinTool config.
 	return nil
 }
 
+// execConfig returns the runtime settings of exec and run scripts called by
+// the client session of req
+func (s *Server) execConfig(req *mcp.CallToolRequest) *js.Config {
+	cfg := tools.ExecRuntimeConfig(s.config.GetExec(), s.config.GetScripts().GetDir())
+	if s.stateStore != nil {
+		if key, ok := sessionKey(req); ok {
+			cfg.State = s.stateStore.Session(key)
+		}
+		cfg.PersistentState = s.stateStore.Persistent()
+	}
+	return cfg
+}
+
+// sessionKey identifies the client session of req, keeping the script state
+// of sessions apart. Sessions without an ID, like stdio, are told apart by
+// identity; requests without a session have no session state.
+func sessionKey(req *mcp.CallToolRequest) (any, bool) {
+	if req.Session == nil {
+		return nil, false
+	}
+	if id := req.Session.ID(); id != "" {
+		return id, true
+	}
+	return req.Session, true
+}
+
+// resultLimits truncates oversized results of scripts and of the tool calls
+// they make
+func (s *Server) resultLimits() *tools.ResultLimits {
+	return &tools.ResultLimits{Store: s.resultStore, MaxBytes: s.config.ResultLimit()}
+}
+
 // handleBuiltinTool handles calls to built-in tools
 func (s *Server) handleBuiltinTool(ctx context.Context, toolName string, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	s.logger.Debug("Handling built-in tool call", slog.String("tool", toolName))
 
-	// Apply timeout to prevent DoS attacks
-	callCtx, cancel := context.WithTimeout(ctx, s.toolCallTimeout)
+	// Apply timeout to prevent DoS attacks; scripts may be configured to run longer
+	timeout := s.toolCallTimeout
+	if execTimeout := s.config.GetExec().GetTimeout(); (toolName == "exec" || toolName == "run") && execTimeout > timeout {
+		timeout = execTimeout
+	}
+	callCtx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
 	// Create ToolProvider adapter for the client manager
-	provider := tools.NewManagerAdapter(s.clientManager)
+	provider := tools.NewManagerAdapter(s.clientManager).WithResultStore(s.resultStore).WithSearcher(s.searcher)
 
 	switch toolName {
 	case "list":
@@ -328,7 +533,15 @@ func (s *Server) handleBuiltinTool(ctx context.Context, toolName string, req *mc
 	case "invoke":
 		return tools.HandleInvokeTool(callCtx, provider, req)
 	case "exec":
-		return tools.HandleExecuteTool(callCtx, s.logger, s.clientManager, req)
+		return tools.HandleExecuteTool(callCtx, s.logger, s.clientManager, s.execConfig(req), s.resultLimits(), req)
+	case "run":
+		return tools.HandleRunTool(callCtx, s.logger, s.clientManager, s.execConfig(req), s.resultLimits(), req)
+	case "read":
+		return tools.HandleReadTool(callCtx, s.resultStore, s.config.ResultLimit(), req)
+	case "types":
+		return tools.HandleTypesTool(callCtx, provider, req)
+	case "status":
+		return tools.HandleStatusTool(callCtx, s.clientManager, req)
 	default:
 		return nil, fmt.Errorf("unknown built-in tool: %s", toolName)
 	}
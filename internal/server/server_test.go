@@ -3,6 +3,8 @@ package server
 import (
 	"context"
 	"encoding/json"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -10,6 +12,7 @@ import (
 	"github.com/vaayne/mcphub/internal/client"
 	"github.com/vaayne/mcphub/internal/config"
 	"github.com/vaayne/mcphub/internal/logging"
+	"github.com/vaayne/mcphub/internal/state"
 	"github.com/vaayne/mcphub/internal/tools"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
@@ -49,7 +52,7 @@ func TestRegisterBuiltinTools(t *testing.T) {
 
 	// Verify all built-in tools are registered
 	allTools := server.builtinRegistry.GetAllTools()
-	assert.Len(t, allTools, 4)
+	assert.Len(t, allTools, 8)
 
 	// Verify list tool
 	listTool, exists := server.builtinRegistry.GetTool("list")
@@ -78,6 +81,34 @@ func TestRegisterBuiltinTools(t *testing.T) {
 	assert.Equal(t, "exec", execTool.Name)
 	assert.Contains(t, execTool.Description, "Execute JavaScript code")
 	assert.NotNil(t, execTool.InputSchema)
+
+	// Verify read tool
+	readTool, exists := server.builtinRegistry.GetTool("read")
+	assert.True(t, exists)
+	assert.Equal(t, "read", readTool.Name)
+	assert.Contains(t, readTool.Description, "Read the next page")
+	assert.NotNil(t, readTool.InputSchema)
+
+	// Verify status tool
+	statusTool, exists := server.builtinRegistry.GetTool("status")
+	assert.True(t, exists)
+	assert.Equal(t, "status", statusTool.Name)
+	assert.Contains(t, statusTool.Description, "rate limit counters")
+	assert.NotNil(t, statusTool.InputSchema)
+
+	// Verify types tool
+	typesTool, exists := server.builtinRegistry.GetTool("types")
+	assert.True(t, exists)
+	assert.Equal(t, "types", typesTool.Name)
+	assert.Contains(t, typesTool.Description, "TypeScript declaration")
+	assert.NotNil(t, typesTool.InputSchema)
+
+	// Verify run tool
+	runTool, exists := server.builtinRegistry.GetTool("run")
+	assert.True(t, exists)
+	assert.Equal(t, "run", runTool.Name)
+	assert.Contains(t, runTool.Description, "saved script")
+	assert.NotNil(t, runTool.InputSchema)
 }
 
 // TestConnectToRemoteServers_EmptyConfig verifies handling of empty config
@@ -240,6 +271,189 @@ func TestHandleBuiltinTool_Exec(t *testing.T) {
 	assert.Len(t, result.Content, 1)
 }
 
+// TestHandleBuiltinTool_Run verifies saved scripts run from the configured
+// script library, with library modules available to them
+func TestHandleBuiltinTool_Run(t *testing.T) {
+	logger := logging.NopLogger()
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "lib"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "lib", "greeting.js"), []byte(`exports.greet = (name) => "hello " + name;`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "greet.js"), []byte("// Greets someone\nrequire(\"lib/greeting\").greet(params.name)"), 0o644))
+	cfg := &config.Config{
+		MCPServers: make(map[string]config.MCPServer),
+		Scripts:    &config.ScriptsConfig{Dir: dir},
+	}
+
+	server := NewServer(cfg, logger)
+	server.clientManager = client.NewManager(logger)
+	server.builtinRegistry = tools.NewBuiltinToolRegistry(logger)
+	defer server.clientManager.DisconnectAll()
+
+	call := func(args map[string]any) *mcp.CallToolResult {
+		argsJSON, err := json.Marshal(args)
+		require.NoError(t, err)
+		result, err := server.handleBuiltinTool(context.Background(), "run", &mcp.CallToolRequest{
+			Params: &mcp.CallToolParamsRaw{Name: "run", Arguments: argsJSON},
+		})
+		require.NoError(t, err)
+		require.Len(t, result.Content, 1)
+		return result
+	}
+
+	result := call(map[string]any{"name": "greet", "params": map[string]any{"name": "world"}})
+	assert.False(t, result.IsError)
+	var response tools.ExecResult
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &response))
+	assert.Equal(t, "hello world", response.Result)
+
+	// Without a name the saved scripts and modules are listed
+	result = call(map[string]any{})
+	text := result.Content[0].(*mcp.TextContent).Text
+	assert.JSONEq(t, `{"scripts":[{"name":"greet","language":"javascript","description":"Greets someone"}],"modules":["lib/greeting"]}`, text)
+}
+
+// TestExecSettings verifies the exec section of the config controls the exec
+// builtin and the runtime of its scripts
+func TestExecSettings(t *testing.T) {
+	logger := logging.NopLogger()
+	disabled := false
+	server := NewServer(&config.Config{
+		MCPServers: make(map[string]config.MCPServer),
+		Exec:       &config.ExecConfig{Enable: &disabled},
+	}, logger)
+	server.builtinRegistry = tools.NewBuiltinToolRegistry(logger)
+	server.registerBuiltinTools()
+	_, exists := server.builtinRegistry.GetTool("exec")
+	assert.False(t, exists, "a disabled exec builtin is not registered")
+	_, exists = server.builtinRegistry.GetTool("run")
+	assert.False(t, exists, "saved scripts are disabled with exec")
+
+	server = NewServer(&config.Config{
+		MCPServers: make(map[string]config.MCPServer),
+		Exec:       &config.ExecConfig{Timeout: 2, MaxLogEntries: 1},
+	}, logger)
+	server.clientManager = client.NewManager(logger)
+	server.builtinRegistry = tools.NewBuiltinToolRegistry(logger)
+	server.toolCallTimeout = 100 * time.Millisecond
+	defer server.clientManager.DisconnectAll()
+
+	// The exec timeout outlasts the builtin call timeout
+	argsJSON, err := json.Marshal(map[string]any{
+		"code": `console.log("a"); console.log("b"); new Promise((resolve) => setTimeout(() => resolve("done"), 300))`,
+	})
+	require.NoError(t, err)
+	result, err := server.handleBuiltinTool(context.Background(), "exec", &mcp.CallToolRequest{
+		Params: &mcp.CallToolParamsRaw{Name: "exec", Arguments: argsJSON},
+	})
+	require.NoError(t, err)
+	var response tools.ExecResult
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &response))
+	assert.Nil(t, response.Error)
+	assert.Equal(t, "done", response.Result)
+	assert.Len(t, response.Logs, 1)
+}
+
+// TestHandleBuiltinTool_ExecState verifies mcp.state lasts between exec calls
+// of a client session and is not shared with other sessions
+func TestHandleBuiltinTool_ExecState(t *testing.T) {
+	logger := logging.NopLogger()
+	server := NewServer(&config.Config{
+		MCPServers: make(map[string]config.MCPServer),
+	}, logger)
+	server.clientManager = client.NewManager(logger)
+	server.builtinRegistry = tools.NewBuiltinToolRegistry(logger)
+	defer server.clientManager.DisconnectAll()
+	stateStore, err := state.New(server.config.GetState())
+	require.NoError(t, err)
+	server.stateStore = stateStore
+	server.mcpServer = mcp.NewServer(&mcp.Implementation{Name: "test", Version: "v0.0.1"}, nil)
+	server.registerBuiltinTools()
+	require.NoError(t, server.registerAllTools())
+
+	ctx := context.Background()
+	connect := func() *mcp.ClientSession {
+		serverTransport, clientTransport := mcp.NewInMemoryTransports()
+		_, err := server.mcpServer.Connect(ctx, serverTransport, nil)
+		require.NoError(t, err)
+		session, err := mcp.NewClient(&mcp.Implementation{Name: "client", Version: "v0.0.1"}, nil).Connect(ctx, clientTransport, nil)
+		require.NoError(t, err)
+		t.Cleanup(func() { session.Close() })
+		return session
+	}
+	exec := func(session *mcp.ClientSession, code string) any {
+		result, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "exec", Arguments: map[string]any{"code": code}})
+		require.NoError(t, err)
+		var response tools.ExecResult
+		require.NoError(t, json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &response))
+		require.Nil(t, response.Error)
+		return response.Result
+	}
+
+	first, second := connect(), connect()
+	exec(first, `mcp.state.set("cursor", "page-2")`)
+	assert.Equal(t, "page-2", exec(first, `mcp.state.get("cursor")`))
+	assert.Equal(t, true, exec(second, `mcp.state.get("cursor") === undefined`))
+}
+
+// TestExecConfig_NoSession verifies requests without a client session get
+// no session state instead of sharing one namespace
+func TestExecConfig_NoSession(t *testing.T) {
+	server := NewServer(&config.Config{
+		MCPServers: make(map[string]config.MCPServer),
+	}, logging.NopLogger())
+	stateStore, err := state.New(server.config.GetState())
+	require.NoError(t, err)
+	server.stateStore = stateStore
+
+	assert.Nil(t, server.execConfig(&mcp.CallToolRequest{}).State)
+}
+
+// TestHandleBuiltinTool_ExecTruncated verifies oversized exec output is truncated and readable
+func TestHandleBuiltinTool_ExecTruncated(t *testing.T) {
+	logger := logging.NopLogger()
+	limit := 50
+	cfg := &config.Config{
+		MCPServers:     make(map[string]config.MCPServer),
+		MaxResultBytes: &limit,
+	}
+
+	server := NewServer(cfg, logger)
+	server.clientManager = client.NewManager(logger)
+	server.builtinRegistry = tools.NewBuiltinToolRegistry(logger)
+	defer server.clientManager.DisconnectAll()
+
+	req := &mcp.CallToolRequest{
+		Params: &mcp.CallToolParamsRaw{
+			Name:      "exec",
+			Arguments: []byte(`{"code": "'x'.repeat(200)"}`),
+		},
+	}
+
+	result, err := server.handleBuiltinTool(context.Background(), "exec", req)
+	require.NoError(t, err)
+	require.Len(t, result.Content, 1)
+
+	// The result is cut, not the JSON around it
+	var execResult tools.ExecResult
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &execResult))
+	assert.Equal(t, strings.Repeat("x", limit), execResult.Result)
+
+	note := execResult.Truncated
+	assert.Contains(t, note, "Output truncated")
+	handle := note[strings.Index(note, "res_") : strings.Index(note, "res_")+20]
+
+	readReq := &mcp.CallToolRequest{
+		Params: &mcp.CallToolParamsRaw{
+			Name:      "read",
+			Arguments: []byte(`{"handle": "` + handle + `", "offset": 50}`),
+		},
+	}
+	page, err := server.handleBuiltinTool(context.Background(), "read", readReq)
+	require.NoError(t, err)
+	assert.False(t, page.IsError)
+	assert.Equal(t, strings.Repeat("x", 50), page.Content[0].(*mcp.TextContent).Text)
+}
+
 // TestHandleBuiltinTool_Inspect verifies inspect tool routing
 func TestHandleBuiltinTool_Inspect(t *testing.T) {
 	logger := logging.NopLogger()
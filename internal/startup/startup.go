@@ -0,0 +1,176 @@
+// Package startup connects to the configured servers concurrently, bounded by a
+// parallelism limit, and reports how each one fared.
+package startup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/vaayne/mcphub/internal/config"
+)
+
+// Server statuses
+const (
+	StatusConnected = "connected"
+	StatusFailed    = "failed"
+	StatusSkipped   = "skipped"
+)
+
+// ConnectFunc connects to one server. Returning an error made by Skip reports
+// the server as skipped rather than failed.
+type ConnectFunc func(ctx context.Context, serverID string, serverCfg config.MCPServer) error
+
+// skipError marks a server that was deliberately not connected
+type skipError struct {
+	reason string
+}
+
+func (e *skipError) Error() string {
+	return e.reason
+}
+
+// Skip returns an error that reports a server as skipped for reason
+func Skip(reason string) error {
+	return &skipError{reason: reason}
+}
+
+// Result is the outcome of starting one server
+type Result struct {
+	Server   string        `json:"server"`
+	Status   string        `json:"status"`
+	Required bool          `json:"required,omitempty"`
+	Duration time.Duration `json:"duration"`
+	Err      error         `json:"-"`
+	Note     string        `json:"note,omitempty"` // error message or skip reason
+}
+
+// Report holds the results of a startup, sorted by server
+type Report struct {
+	Results []Result `json:"servers"`
+}
+
+// Run connects to every enabled server in servers, at most parallelism at a
+// time (0 = one at a time). Disabled servers are reported as skipped.
+func Run(ctx context.Context, servers map[string]config.MCPServer, parallelism int, connect ConnectFunc) *Report {
+	parallelism = max(parallelism, 1)
+
+	report := &Report{Results: make([]Result, 0, len(servers))}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, parallelism)
+
+	for serverID, serverCfg := range servers {
+		if !serverCfg.IsEnabled() {
+			report.Results = append(report.Results, Result{
+				Server: serverID,
+				Status: StatusSkipped,
+				Note:   "disabled",
+			})
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			select {
+			case slots <- struct{}{}:
+				defer func() { <-slots }()
+			case <-ctx.Done():
+				mu.Lock()
+				report.Results = append(report.Results, failed(serverID, serverCfg, 0, ctx.Err()))
+				mu.Unlock()
+				return
+			}
+
+			start := time.Now()
+			err := connect(ctx, serverID, serverCfg)
+			duration := time.Since(start)
+
+			result := Result{
+				Server:   serverID,
+				Status:   StatusConnected,
+				Required: serverCfg.Required,
+				Duration: duration,
+			}
+			var skip *skipError
+			if errors.As(err, &skip) {
+				result.Status = StatusSkipped
+				result.Note = skip.reason
+			} else if err != nil {
+				result = failed(serverID, serverCfg, duration, err)
+			}
+
+			mu.Lock()
+			report.Results = append(report.Results, result)
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	sort.Slice(report.Results, func(i, j int) bool {
+		return report.Results[i].Server < report.Results[j].Server
+	})
+	return report
+}
+
+func failed(serverID string, serverCfg config.MCPServer, duration time.Duration, err error) Result {
+	return Result{
+		Server:   serverID,
+		Status:   StatusFailed,
+		Required: serverCfg.Required,
+		Duration: duration,
+		Err:      err,
+		Note:     err.Error(),
+	}
+}
+
+// Count returns the number of servers with status
+func (r *Report) Count(status string) int {
+	n := 0
+	for _, result := range r.Results {
+		if result.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+// Err returns an error for the first required server that failed to connect
+func (r *Report) Err() error {
+	for _, result := range r.Results {
+		if result.Status == StatusFailed && result.Required {
+			return fmt.Errorf("required server %s failed to connect: %w", result.Server, result.Err)
+		}
+	}
+	return nil
+}
+
+// WriteTable writes the report as a table with one row per server
+func (r *Report) WriteTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SERVER\tSTATUS\tDURATION\tNOTE")
+	for _, result := range r.Results {
+		duration := "-"
+		if result.Status != StatusSkipped || result.Duration > 0 {
+			duration = result.Duration.Round(time.Millisecond).String()
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", result.Server, result.Status, duration, firstLine(result.Note))
+	}
+	fmt.Fprintf(tw, "%d connected, %d failed, %d skipped\n",
+		r.Count(StatusConnected), r.Count(StatusFailed), r.Count(StatusSkipped))
+	return tw.Flush()
+}
+
+// firstLine keeps table rows on one line
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return line
+}
@@ -0,0 +1,82 @@
+package startup
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vaayne/mcphub/internal/config"
+)
+
+func TestRun(t *testing.T) {
+	disabled := false
+	servers := map[string]config.MCPServer{
+		"alpha":   {Command: "a"},
+		"beta":    {Command: "b", Required: true},
+		"gamma":   {Command: "c"},
+		"delta":   {Command: "d"},
+		"epsilon": {Command: "e", Enable: &disabled},
+	}
+
+	var running, peak atomic.Int32
+	report := Run(context.Background(), servers, 2, func(ctx context.Context, serverID string, serverCfg config.MCPServer) error {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+
+		switch serverID {
+		case "beta":
+			return errors.New("connection refused")
+		case "gamma":
+			return Skip("listed from catalog")
+		}
+		return nil
+	})
+
+	assert.LessOrEqual(t, peak.Load(), int32(2))
+	assert.Equal(t, int32(2), peak.Load(), "servers should connect concurrently")
+
+	require.Len(t, report.Results, 5)
+	assert.Equal(t, []string{"alpha", "beta", "delta", "epsilon", "gamma"}, []string{
+		report.Results[0].Server, report.Results[1].Server, report.Results[2].Server,
+		report.Results[3].Server, report.Results[4].Server,
+	})
+	assert.Equal(t, StatusConnected, report.Results[0].Status)
+	assert.Equal(t, StatusFailed, report.Results[1].Status)
+	assert.Equal(t, "disabled", report.Results[3].Note)
+	assert.Equal(t, "listed from catalog", report.Results[4].Note)
+	assert.Equal(t, 2, report.Count(StatusConnected))
+	assert.Equal(t, 1, report.Count(StatusFailed))
+	assert.Equal(t, 2, report.Count(StatusSkipped))
+
+	err := report.Err()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "required server beta failed to connect")
+
+	var buf bytes.Buffer
+	require.NoError(t, report.WriteTable(&buf))
+	assert.Contains(t, buf.String(), "SERVER")
+	assert.Regexp(t, `beta\s+failed\s+\d+ms\s+connection refused`, buf.String())
+	assert.Regexp(t, `epsilon\s+skipped\s+-\s+disabled`, buf.String())
+	assert.Contains(t, buf.String(), "2 connected, 1 failed, 2 skipped")
+}
+
+func TestRun_OptionalFailure(t *testing.T) {
+	servers := map[string]config.MCPServer{"optional": {Command: "a"}}
+	report := Run(context.Background(), servers, 0, func(ctx context.Context, serverID string, serverCfg config.MCPServer) error {
+		return errors.New("boom")
+	})
+	assert.Equal(t, 1, report.Count(StatusFailed))
+	assert.NoError(t, report.Err())
+}
@@ -0,0 +1,192 @@
+package state
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vaayne/mcphub/internal/config"
+)
+
+// entryExt is the file extension of persisted entries
+const entryExt = ".json"
+
+// Disk is a namespace stored as one file per key, shared by every hub and CLI
+// process using the same directory. Quotas are checked against the files
+// present when a value is set.
+type Disk struct {
+	dir   string
+	mu    sync.Mutex // serializes writes of this process
+	quota quota
+	now   func() time.Time
+}
+
+// NewDisk creates a namespace in dir, creating the directory if needed.
+// Non-positive quotas fall back to the defaults.
+func NewDisk(dir string, maxKeys, maxBytes int) (*Disk, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create state directory: %w", err)
+	}
+	if maxKeys <= 0 {
+		maxKeys = config.DefaultStateMaxKeys
+	}
+	if maxBytes <= 0 {
+		maxBytes = config.DefaultStateMaxBytes
+	}
+	return &Disk{
+		dir:   dir,
+		quota: quota{maxKeys: maxKeys, maxBytes: maxBytes},
+		now:   time.Now,
+	}, nil
+}
+
+// Dir returns the state directory
+func (d *Disk) Dir() string {
+	return d.dir
+}
+
+// path returns the file of key; keys are hashed so any key makes a safe name
+func (d *Disk) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(d.dir, hex.EncodeToString(sum[:])+entryExt)
+}
+
+// Get implements Namespace
+func (d *Disk) Get(key string) (json.RawMessage, bool, error) {
+	e, err := d.read(d.path(key))
+	if err != nil || e == nil || e.Key != key {
+		return nil, false, err
+	}
+	return e.Value, true, nil
+}
+
+// Set implements Namespace. Entries are written to a temporary file and
+// renamed so concurrent readers never see a partial entry.
+func (d *Disk) Set(key string, value json.RawMessage, ttl time.Duration) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	e, err := newEntry(key, value, ttl, d.now())
+	if err != nil {
+		return err
+	}
+
+	entries, err := d.entries()
+	if err != nil {
+		return err
+	}
+	keys, bytes := len(entries)+1, e.size()
+	for _, other := range entries {
+		if other.Key == key {
+			keys--
+			continue
+		}
+		bytes += other.size()
+	}
+	if err := d.quota.check(keys, bytes); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to encode state entry: %w", err)
+	}
+	tmp, err := os.CreateTemp(d.dir, "entry.*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write state entry: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write state entry: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write state entry: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), d.path(key)); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write state entry: %w", err)
+	}
+	return nil
+}
+
+// Delete implements Namespace
+func (d *Disk) Delete(key string) (bool, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	e, err := d.read(d.path(key))
+	if err != nil || e == nil || e.Key != key {
+		return false, err
+	}
+	if err := os.Remove(d.path(key)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return false, fmt.Errorf("failed to delete state entry: %w", err)
+	}
+	return true, nil
+}
+
+// List implements Namespace
+func (d *Disk) List(prefix string) ([]string, error) {
+	entries, err := d.entries()
+	if err != nil {
+		return nil, err
+	}
+	keys := []string{}
+	for _, e := range entries {
+		if strings.HasPrefix(e.Key, prefix) {
+			keys = append(keys, e.Key)
+		}
+	}
+	slices.Sort(keys)
+	return keys, nil
+}
+
+// entries reads every live entry, removing expired ones
+func (d *Disk) entries() ([]*entry, error) {
+	files, err := os.ReadDir(d.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state directory: %w", err)
+	}
+	var entries []*entry
+	for _, f := range files {
+		if f.IsDir() || !strings.HasSuffix(f.Name(), entryExt) {
+			continue
+		}
+		e, err := d.read(filepath.Join(d.dir, f.Name()))
+		if err != nil {
+			return nil, err
+		}
+		if e != nil {
+			entries = append(entries, e)
+		}
+	}
+	return entries, nil
+}
+
+// read returns the entry in path, or nil if it is missing, unreadable or
+// expired; expired and corrupt entries are removed
+func (d *Disk) read(path string) (*entry, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state entry: %w", err)
+	}
+	var e entry
+	if err := json.Unmarshal(data, &e); err != nil || e.expired(d.now()) {
+		_ = os.Remove(path)
+		return nil, nil
+	}
+	return &e, nil
+}
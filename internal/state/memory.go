@@ -0,0 +1,118 @@
+package state
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vaayne/mcphub/internal/config"
+)
+
+// Memory is an in-process namespace
+type Memory struct {
+	mu      sync.Mutex
+	entries map[string]*entry
+	bytes   int
+	quota   quota
+	now     func() time.Time
+}
+
+// NewMemory creates a namespace holding at most maxKeys keys and maxBytes
+// bytes of keys and values. Non-positive values fall back to the defaults.
+func NewMemory(maxKeys, maxBytes int) *Memory {
+	if maxKeys <= 0 {
+		maxKeys = config.DefaultStateMaxKeys
+	}
+	if maxBytes <= 0 {
+		maxBytes = config.DefaultStateMaxBytes
+	}
+	return &Memory{
+		entries: make(map[string]*entry),
+		quota:   quota{maxKeys: maxKeys, maxBytes: maxBytes},
+		now:     time.Now,
+	}
+}
+
+// Get implements Namespace
+func (m *Memory) Get(key string) (json.RawMessage, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.evictExpired()
+	e, ok := m.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	return e.Value, true, nil
+}
+
+// Set implements Namespace
+func (m *Memory) Set(key string, value json.RawMessage, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e, err := newEntry(key, value, ttl, m.now())
+	if err != nil {
+		return err
+	}
+
+	m.evictExpired()
+	keys, bytes := len(m.entries)+1, m.bytes+e.size()
+	if old, ok := m.entries[key]; ok {
+		keys, bytes = keys-1, bytes-old.size()
+	}
+	if err := m.quota.check(keys, bytes); err != nil {
+		return err
+	}
+
+	m.entries[key] = e
+	m.bytes = bytes
+	return nil
+}
+
+// Delete implements Namespace
+func (m *Memory) Delete(key string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.evictExpired()
+	e, ok := m.entries[key]
+	if ok {
+		m.remove(e)
+	}
+	return ok, nil
+}
+
+// List implements Namespace
+func (m *Memory) List(prefix string) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.evictExpired()
+	keys := []string{}
+	for key := range m.entries {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+	return keys, nil
+}
+
+// evictExpired removes entries past their TTL; callers must hold m.mu
+func (m *Memory) evictExpired() {
+	now := m.now()
+	for _, e := range m.entries {
+		if e.expired(now) {
+			m.remove(e)
+		}
+	}
+}
+
+// remove deletes an entry; callers must hold m.mu
+func (m *Memory) remove(e *entry) {
+	delete(m.entries, e.Key)
+	m.bytes -= e.size()
+}
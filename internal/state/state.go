@@ -0,0 +1,160 @@
+// Package state keeps the key-value state exec scripts carry between calls,
+// such as pagination cursors or intermediate results. Each client session has
+// its own namespace in memory; an optional persistent namespace is kept on
+// disk and shared by every session. Values are stored as JSON.
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/vaayne/mcphub/internal/config"
+)
+
+// MaxKeyLength is the longest key in bytes
+const MaxKeyLength = 256
+
+// Namespace is a set of keys holding JSON values
+type Namespace interface {
+	// Get returns the value stored under key, if set and not expired
+	Get(key string) (json.RawMessage, bool, error)
+	// Set stores value under key; a zero ttl keeps it until it is deleted
+	Set(key string, value json.RawMessage, ttl time.Duration) error
+	// Delete removes key, reporting whether it was set
+	Delete(key string) (bool, error)
+	// List returns the keys starting with prefix, sorted
+	List(prefix string) ([]string, error)
+}
+
+// entry is the stored form of a value
+type entry struct {
+	Key     string          `json:"key"`
+	Value   json.RawMessage `json:"value"`
+	Expires time.Time       `json:"expires,omitzero"` // zero = never
+}
+
+// expired reports whether the entry is past its TTL at now
+func (e *entry) expired(now time.Time) bool {
+	return !e.Expires.IsZero() && !now.Before(e.Expires)
+}
+
+// size is what the entry counts against the byte quota
+func (e *entry) size() int {
+	return len(e.Key) + len(e.Value)
+}
+
+// newEntry builds the entry of a Set call
+func newEntry(key string, value json.RawMessage, ttl time.Duration, now time.Time) (*entry, error) {
+	if err := validateKey(key); err != nil {
+		return nil, err
+	}
+	if ttl < 0 {
+		return nil, fmt.Errorf("ttl must not be negative")
+	}
+	if !json.Valid(value) {
+		return nil, fmt.Errorf("value of %q is not valid JSON", key)
+	}
+	e := &entry{Key: key, Value: value}
+	if ttl > 0 {
+		e.Expires = now.Add(ttl)
+	}
+	return e, nil
+}
+
+// validateKey checks that key can name a value
+func validateKey(key string) error {
+	if key == "" {
+		return fmt.Errorf("key is required")
+	}
+	if len(key) > MaxKeyLength {
+		return fmt.Errorf("key exceeds maximum length of %d bytes", MaxKeyLength)
+	}
+	if !utf8.ValidString(key) {
+		return fmt.Errorf("key must be valid UTF-8")
+	}
+	return nil
+}
+
+// quota bounds the keys and bytes of a namespace
+type quota struct {
+	maxKeys  int
+	maxBytes int
+}
+
+// check reports an error if a namespace holding keys and bytes is over quota
+func (q quota) check(keys, bytes int) error {
+	if keys > q.maxKeys {
+		return fmt.Errorf("state quota exceeded: at most %d keys", q.maxKeys)
+	}
+	if bytes > q.maxBytes {
+		return fmt.Errorf("state quota exceeded: at most %d bytes of keys and values", q.maxBytes)
+	}
+	return nil
+}
+
+// Store holds the namespaces of exec scripts: one per client session, and the
+// persistent namespace when it is enabled. Sessions unused for the session TTL
+// are dropped with their state.
+type Store struct {
+	mu         sync.Mutex
+	sessions   map[any]*session
+	quota      quota
+	sessionTTL time.Duration
+	persistent Namespace
+	now        func() time.Time
+}
+
+// session is the namespace of a client session
+type session struct {
+	namespace *Memory
+	lastUsed  time.Time
+}
+
+// New creates the store described by cfg, opening the persistent namespace
+// if it is enabled
+func New(cfg *config.StateConfig) (*Store, error) {
+	s := &Store{
+		sessions:   make(map[any]*session),
+		quota:      quota{maxKeys: cfg.GetMaxKeys(), maxBytes: cfg.GetMaxBytes()},
+		sessionTTL: cfg.GetSessionTTL(),
+		now:        time.Now,
+	}
+	if cfg.Persistent {
+		disk, err := NewDisk(cfg.GetDir(), cfg.GetMaxKeys(), cfg.GetMaxBytes())
+		if err != nil {
+			return nil, err
+		}
+		s.persistent = disk
+	}
+	return s, nil
+}
+
+// Session returns the namespace of the client session identified by key, any
+// comparable value, creating it on first use
+func (s *Store) Session(key any) Namespace {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	for k, sess := range s.sessions {
+		if now.Sub(sess.lastUsed) > s.sessionTTL {
+			delete(s.sessions, k)
+		}
+	}
+
+	sess, ok := s.sessions[key]
+	if !ok {
+		sess = &session{namespace: NewMemory(s.quota.maxKeys, s.quota.maxBytes)}
+		s.sessions[key] = sess
+	}
+	sess.lastUsed = now
+	return sess.namespace
+}
+
+// Persistent returns the persistent namespace, or nil if it is not enabled
+func (s *Store) Persistent() Namespace {
+	return s.persistent
+}
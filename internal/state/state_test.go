@@ -0,0 +1,158 @@
+package state
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vaayne/mcphub/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// clock is a settable time source for TTL tests
+type clock struct{ t time.Time }
+
+func (c *clock) now() time.Time { return c.t }
+
+// namespaces returns a memory and a disk namespace sharing a clock
+func namespaces(t *testing.T, maxKeys, maxBytes int) (map[string]Namespace, *clock) {
+	c := &clock{t: time.Now()}
+	memory := NewMemory(maxKeys, maxBytes)
+	memory.now = c.now
+	disk, err := NewDisk(t.TempDir(), maxKeys, maxBytes)
+	require.NoError(t, err)
+	disk.now = c.now
+	return map[string]Namespace{"memory": memory, "disk": disk}, c
+}
+
+func TestNamespace_GetSetDeleteList(t *testing.T) {
+	all, _ := namespaces(t, 0, 0)
+	for name, ns := range all {
+		t.Run(name, func(t *testing.T) {
+			_, ok, err := ns.Get("cursor")
+			require.NoError(t, err)
+			assert.False(t, ok)
+
+			require.NoError(t, ns.Set("cursor", json.RawMessage(`"page-2"`), 0))
+			require.NoError(t, ns.Set("repo/a", json.RawMessage(`{"stars":1}`), 0))
+			require.NoError(t, ns.Set("repo/b", json.RawMessage(`[1,2]`), 0))
+			require.NoError(t, ns.Set("cursor", json.RawMessage(`"page-3"`), 0))
+
+			value, ok, err := ns.Get("cursor")
+			require.NoError(t, err)
+			assert.True(t, ok)
+			assert.JSONEq(t, `"page-3"`, string(value))
+
+			keys, err := ns.List("")
+			require.NoError(t, err)
+			assert.Equal(t, []string{"cursor", "repo/a", "repo/b"}, keys)
+			keys, err = ns.List("repo/")
+			require.NoError(t, err)
+			assert.Equal(t, []string{"repo/a", "repo/b"}, keys)
+
+			deleted, err := ns.Delete("repo/a")
+			require.NoError(t, err)
+			assert.True(t, deleted)
+			deleted, err = ns.Delete("repo/a")
+			require.NoError(t, err)
+			assert.False(t, deleted)
+
+			assert.Error(t, ns.Set("", json.RawMessage(`1`), 0))
+			assert.Error(t, ns.Set(strings.Repeat("k", MaxKeyLength+1), json.RawMessage(`1`), 0))
+			assert.Error(t, ns.Set("bad", json.RawMessage(`{`), 0))
+			assert.Error(t, ns.Set("bad", json.RawMessage(`1`), -time.Second))
+		})
+	}
+}
+
+func TestNamespace_TTL(t *testing.T) {
+	all, c := namespaces(t, 0, 0)
+	for name, ns := range all {
+		t.Run(name, func(t *testing.T) {
+			require.NoError(t, ns.Set("short", json.RawMessage(`1`), time.Minute))
+			require.NoError(t, ns.Set("forever", json.RawMessage(`2`), 0))
+
+			_, ok, err := ns.Get("short")
+			require.NoError(t, err)
+			assert.True(t, ok)
+
+			c.t = c.t.Add(2 * time.Minute)
+			_, ok, err = ns.Get("short")
+			require.NoError(t, err)
+			assert.False(t, ok, "expired values are gone")
+			keys, err := ns.List("")
+			require.NoError(t, err)
+			assert.Equal(t, []string{"forever"}, keys)
+		})
+	}
+}
+
+func TestNamespace_Quota(t *testing.T) {
+	all, c := namespaces(t, 2, 30)
+	for name, ns := range all {
+		t.Run(name, func(t *testing.T) {
+			require.NoError(t, ns.Set("a", json.RawMessage(`"0123456789"`), time.Minute))
+			require.NoError(t, ns.Set("b", json.RawMessage(`1`), 0))
+			assert.ErrorContains(t, ns.Set("c", json.RawMessage(`1`), 0), "at most 2 keys")
+
+			// Replacing a value counts only its new size
+			require.NoError(t, ns.Set("a", json.RawMessage(`"0123456789abcdef"`), time.Minute))
+			assert.ErrorContains(t, ns.Set("a", json.RawMessage(`"0123456789abcdefghijklmnopq"`), 0), "at most 30 bytes")
+
+			// Expired values free their quota
+			c.t = c.t.Add(2 * time.Minute)
+			require.NoError(t, ns.Set("c", json.RawMessage(`1`), 0))
+		})
+	}
+}
+
+func TestDisk_SharedBetweenProcesses(t *testing.T) {
+	dir := t.TempDir()
+	first, err := NewDisk(dir, 0, 0)
+	require.NoError(t, err)
+	second, err := NewDisk(dir, 0, 0)
+	require.NoError(t, err)
+
+	require.NoError(t, first.Set("token", json.RawMessage(`"abc"`), 0))
+	value, ok, err := second.Get("token")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.JSONEq(t, `"abc"`, string(value))
+}
+
+func TestStore_Sessions(t *testing.T) {
+	store, err := New(&config.StateConfig{SessionTTL: 60})
+	require.NoError(t, err)
+	c := &clock{t: time.Now()}
+	store.now = c.now
+	assert.Nil(t, store.Persistent(), "the persistent namespace is off by default")
+
+	a, b := store.Session("a"), store.Session("b")
+	require.NoError(t, a.Set("k", json.RawMessage(`1`), 0))
+	_, ok, _ := b.Get("k")
+	assert.False(t, ok, "sessions are isolated")
+	assert.Same(t, a, store.Session("a"))
+
+	// Sessions unused for the session TTL are dropped
+	c.t = c.t.Add(2 * time.Minute)
+	_, ok, _ = store.Session("a").Get("k")
+	assert.False(t, ok)
+}
+
+func TestStore_Persistent(t *testing.T) {
+	dir := t.TempDir()
+	store, err := New(&config.StateConfig{Persistent: true, Dir: dir})
+	require.NoError(t, err)
+	require.NotNil(t, store.Persistent())
+	require.NoError(t, store.Persistent().Set("k", json.RawMessage(`1`), 0))
+
+	// A new store, as after a restart, sees the same values
+	store, err = New(&config.StateConfig{Persistent: true, Dir: dir})
+	require.NoError(t, err)
+	_, ok, err := store.Persistent().Get("k")
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
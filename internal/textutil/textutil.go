@@ -0,0 +1,26 @@
+// Package textutil provides small string helpers shared across packages.
+package textutil
+
+import "unicode/utf8"
+
+// Truncate cuts s to at most maxBytes bytes without splitting a UTF-8
+// character, reporting whether anything was cut
+func Truncate(s string, maxBytes int) (string, bool) {
+	if len(s) <= maxBytes {
+		return s, false
+	}
+	i := max(maxBytes, 0)
+	for i > 0 && !utf8.RuneStart(s[i]) {
+		i--
+	}
+	return s[:i], true
+}
+
+// Abbreviate cuts s to at most maxBytes bytes, without splitting a UTF-8
+// character, and marks the cut with "..."
+func Abbreviate(s string, maxBytes int) string {
+	if head, cut := Truncate(s, maxBytes); cut {
+		return head + "..."
+	}
+	return s
+}
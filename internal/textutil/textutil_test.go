@@ -0,0 +1,25 @@
+package textutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTruncate(t *testing.T) {
+	head, cut := Truncate("short", 5)
+	assert.Equal(t, "short", head)
+	assert.False(t, cut)
+
+	// "é" is two bytes; it is dropped rather than split
+	head, cut = Truncate("aéb", 2)
+	assert.Equal(t, "a", head)
+	assert.True(t, cut)
+}
+
+func TestAbbreviate(t *testing.T) {
+	assert.Equal(t, "short", Abbreviate("short", 5))
+	assert.Equal(t, "ab...", Abbreviate("abcdef", 2))
+	assert.Equal(t, "a...", Abbreviate("aéb", 2))
+	assert.Equal(t, "aé...", Abbreviate("aébc", 3))
+}
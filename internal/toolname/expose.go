@@ -0,0 +1,47 @@
+package toolname
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/vaayne/mcphub/internal/config"
+)
+
+// Expose returns the tools of a server as the hub exposes them: tools hidden
+// by includeTools/excludeTools are dropped, and the rest are copied with the
+// name and description set by the server's tools settings. A rename to the
+// name of another tool the server offers is an error, since one of the two
+// could no longer be called. There is no passthrough mode that bypasses this:
+// every listing and call of a backend tool goes through the exposed names.
+func Expose(serverCfg config.MCPServer, tools []*mcp.Tool) ([]*mcp.Tool, error) {
+	exposed := make([]*mcp.Tool, 0, len(tools))
+	backends := make(map[string]string, len(tools)) // exposed name -> backend name
+	for _, tool := range tools {
+		if !serverCfg.ExposesTool(tool.Name) {
+			continue
+		}
+
+		name := serverCfg.ExposedToolName(tool.Name)
+		if other, ok := backends[name]; ok {
+			first, second := other, tool.Name
+			if first > second {
+				first, second = second, first
+			}
+			return nil, fmt.Errorf("tools %q and %q are both exposed as %q; change the rename or exclude one of them", first, second, name)
+		}
+		backends[name] = tool.Name
+
+		settings := serverCfg.ToolSettings(tool.Name)
+		copied := *tool
+		copied.Name = name
+		if settings.Description != "" {
+			copied.Description = settings.Description
+		}
+		if settings.Notes != "" {
+			copied.Description = strings.TrimSpace(copied.Description + "\n\n" + settings.Notes)
+		}
+		exposed = append(exposed, &copied)
+	}
+	return exposed, nil
+}
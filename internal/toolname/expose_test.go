@@ -0,0 +1,52 @@
+package toolname
+
+import (
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vaayne/mcphub/internal/config"
+)
+
+func TestExpose(t *testing.T) {
+	tools := []*mcp.Tool{
+		{Name: "search_repositories", Description: "Search repositories"},
+		{Name: "search_code", Description: "Search code"},
+		{Name: "delete_repo", Description: "Delete a repository"},
+	}
+	serverCfg := config.MCPServer{
+		ExcludeTools: []string{"delete_*"},
+		Tools: map[string]config.ToolConfig{
+			"search_repositories": {Rename: "findRepos", Notes: "Use qualifiers like language:go."},
+			"search_*":            {Description: "Search GitHub"},
+		},
+	}
+
+	exposed, err := Expose(serverCfg, tools)
+	require.NoError(t, err)
+	require.Len(t, exposed, 2)
+	assert.Equal(t, "findRepos", exposed[0].Name)
+	assert.Equal(t, "Search GitHub\n\nUse qualifiers like language:go.", exposed[0].Description)
+	assert.Equal(t, "search_code", exposed[1].Name)
+	assert.Equal(t, "Search GitHub", exposed[1].Description)
+
+	// The backend's tools are left untouched
+	assert.Equal(t, "search_repositories", tools[0].Name)
+	assert.Equal(t, "Search repositories", tools[0].Description)
+}
+
+func TestExpose_RenameClash(t *testing.T) {
+	tools := []*mcp.Tool{{Name: "search"}, {Name: "find"}}
+	serverCfg := config.MCPServer{Tools: map[string]config.ToolConfig{"search": {Rename: "find"}}}
+
+	_, err := Expose(serverCfg, tools)
+	assert.EqualError(t, err, `tools "find" and "search" are both exposed as "find"; change the rename or exclude one of them`)
+
+	// Hiding the backend's own tool resolves it
+	serverCfg.ExcludeTools = []string{"find"}
+	exposed, err := Expose(serverCfg, tools)
+	require.NoError(t, err)
+	require.Len(t, exposed, 1)
+	assert.Equal(t, "find", exposed[0].Name)
+}
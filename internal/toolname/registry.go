@@ -0,0 +1,91 @@
+package toolname
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Ref identifies a tool by its server and the name the server's config exposes
+// it as
+type Ref struct {
+	ServerID string
+	ToolName string
+}
+
+// Registry maps qualified tool names (as listed by the hub) to their tools.
+// Names are looked up exactly, so prefixes, separators and tool names may
+// contain any characters, including the separator itself.
+type Registry struct {
+	refs       map[string]Ref
+	collisions map[string][]Ref
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{
+		refs:       make(map[string]Ref),
+		collisions: make(map[string][]Ref),
+	}
+}
+
+// Add registers ref under name. If another tool already has the name, the
+// first one keeps it and an error describing the collision is returned.
+func (r *Registry) Add(name string, ref Ref) error {
+	if existing, ok := r.refs[name]; ok {
+		if existing == ref {
+			return nil
+		}
+		if len(r.collisions[name]) == 0 {
+			r.collisions[name] = []Ref{existing}
+		}
+		r.collisions[name] = append(r.collisions[name], ref)
+		return fmt.Errorf("tool name %q is used by %s and %s", name, describe(existing), describe(ref))
+	}
+	r.refs[name] = ref
+	return nil
+}
+
+// Resolve returns the tool listed as name
+func (r *Registry) Resolve(name string) (Ref, bool) {
+	ref, ok := r.refs[name]
+	return ref, ok
+}
+
+// Names returns the registered names, sorted
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.refs))
+	for name := range r.refs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Collisions returns the names claimed by more than one tool, with every tool
+// that claimed them (the first one keeps the name)
+func (r *Registry) Collisions() map[string][]Ref {
+	return r.collisions
+}
+
+func describe(ref Ref) string {
+	return fmt.Sprintf("server %q tool %q", ref.ServerID, ref.ToolName)
+}
+
+// Resolver is implemented by tool providers that list tools under configurable
+// prefixes and separators, and so can't be parsed as serverID__toolName
+type Resolver interface {
+	// ResolveTool returns the server and tool listed as name
+	ResolveTool(name string) (serverID, toolName string, ok bool)
+}
+
+// Resolve returns the server and tool of a listed tool name. It asks v if it
+// implements Resolver, and otherwise (or for names v doesn't know) parses the
+// default serverID__toolName format.
+func Resolve(v any, name string) (serverID, toolName string, ok bool) {
+	if resolver, isResolver := v.(Resolver); isResolver {
+		if serverID, toolName, ok := resolver.ResolveTool(name); ok {
+			return serverID, toolName, true
+		}
+	}
+	return ParseNamespacedName(name)
+}
@@ -0,0 +1,55 @@
+package toolname
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+	require.NoError(t, r.Add("gh.search", Ref{ServerID: "github", ToolName: "search"}))
+	require.NoError(t, r.Add("get__item", Ref{ServerID: "main", ToolName: "get__item"}))
+	require.NoError(t, r.Add("get__item", Ref{ServerID: "main", ToolName: "get__item"}), "re-adding the same tool is not a collision")
+
+	ref, ok := r.Resolve("get__item")
+	require.True(t, ok)
+	assert.Equal(t, Ref{ServerID: "main", ToolName: "get__item"}, ref)
+	_, ok = r.Resolve("get")
+	assert.False(t, ok)
+
+	err := r.Add("gh.search", Ref{ServerID: "other", ToolName: "gh.search"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `tool name "gh.search" is used by server "github" tool "search" and server "other" tool "gh.search"`)
+	ref, _ = r.Resolve("gh.search")
+	assert.Equal(t, "github", ref.ServerID, "the first tool keeps the name")
+	assert.Len(t, r.Collisions()["gh.search"], 2)
+
+	assert.Equal(t, []string{"get__item", "gh.search"}, r.Names())
+}
+
+type staticResolver map[string]Ref
+
+func (s staticResolver) ResolveTool(name string) (string, string, bool) {
+	ref, ok := s[name]
+	return ref.ServerID, ref.ToolName, ok
+}
+
+func TestResolve(t *testing.T) {
+	resolver := staticResolver{"get__item": {ServerID: "main", ToolName: "get__item"}}
+
+	serverID, toolName, ok := Resolve(resolver, "get__item")
+	assert.True(t, ok)
+	assert.Equal(t, "main", serverID)
+	assert.Equal(t, "get__item", toolName)
+
+	// Unknown names and non-resolvers fall back to parsing serverID__toolName
+	serverID, toolName, ok = Resolve(resolver, "srv__tool")
+	assert.True(t, ok)
+	assert.Equal(t, "srv", serverID)
+	assert.Equal(t, "tool", toolName)
+
+	_, _, ok = Resolve(nil, "plain")
+	assert.False(t, ok)
+}
@@ -8,6 +8,7 @@ import (
 	"unicode"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/vaayne/mcphub/internal/config"
 )
 
 // ToJSName converts a tool name to a valid JS method name (camelCase).
@@ -124,17 +125,13 @@ func (m *Mapper) Resolve(name string) (string, bool) {
 	return name, false
 }
 
-// ParseNamespacedName parses a namespaced tool name (serverID__toolName) into its parts.
-// Returns serverID, toolName, and true if the name contains "__".
-// Returns "", name, false if the name is not namespaced.
+// ParseNamespacedName parses a tool name in the default serverID__toolName
+// format into its parts. Returns "", name, false if the name has no separator.
+// Providers with configured prefixes and separators resolve names through
+// Resolver instead.
 func ParseNamespacedName(name string) (serverID, toolName string, ok bool) {
-	if before, after, found := strings.Cut(name, "__"); found {
+	if before, after, found := strings.Cut(name, config.DefaultSeparator); found {
 		return before, after, true
 	}
 	return "", name, false
 }
-
-// IsNamespaced checks if a tool name is in namespaced format (contains "__").
-func IsNamespaced(name string) bool {
-	return strings.Contains(name, "__")
-}
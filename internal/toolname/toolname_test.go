@@ -167,27 +167,6 @@ func TestParseNamespacedName(t *testing.T) {
 	}
 }
 
-func TestIsNamespaced(t *testing.T) {
-	tests := []struct {
-		input    string
-		expected bool
-	}{
-		{"github__search", true},
-		{"simple", false},
-		{"with_single_underscore", false},
-		{"with__double", true},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.input, func(t *testing.T) {
-			result := IsNamespaced(tt.input)
-			if result != tt.expected {
-				t.Errorf("IsNamespaced(%q) = %v, want %v", tt.input, result, tt.expected)
-			}
-		})
-	}
-}
-
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr || len(s) > 0 && containsAt(s, substr))
 }
@@ -8,7 +8,9 @@ import (
 	"log/slog"
 
 	"github.com/vaayne/mcphub/internal/client"
+	"github.com/vaayne/mcphub/internal/config"
 	"github.com/vaayne/mcphub/internal/js"
+	"github.com/vaayne/mcphub/internal/results"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
@@ -21,6 +23,80 @@ type ExecResult struct {
 	Result any           `json:"result"`
 	Logs   []js.LogEntry `json:"logs"`
 	Error  *ExecError    `json:"error,omitempty"`
+	// Truncated is the note naming the handle of the full result when Result
+	// was cut to the hub's result limit
+	Truncated string `json:"truncated,omitempty"`
+	// Trace holds the tool calls and failure stack of a traced execution
+	Trace *js.Trace `json:"trace,omitempty"`
+	// Content holds MCP content blocks (images, audio, resources) returned by
+	// the script; when set, Result is nil and the blocks are returned natively
+	Content []mcp.Content `json:"-"`
+}
+
+// ResultLimits keeps oversized output of scripts out of the client's context.
+// The results of the tool calls a script makes are truncated to each tool's
+// limit, and the script's own result to MaxBytes; the full text is kept in
+// Store for the read tool.
+type ResultLimits struct {
+	Store    *results.Store
+	MaxBytes int // limit for the script's result, 0 = unlimited
+}
+
+// caller wraps the manager's caller so tool results reach the script truncated
+// to their per-tool limits
+func (l *ResultLimits) caller(manager *client.Manager) js.ToolCaller {
+	caller := js.NewManagerCaller(manager)
+	if l == nil || l.Store == nil {
+		return caller
+	}
+	return &limitedCaller{ManagerCaller: caller, manager: manager, store: l.Store}
+}
+
+// apply truncates the result of an execution. The result is cut as text (JSON
+// for non-string values) and the note with the handle of the full text is set
+// in Truncated, so the execution result stays valid JSON.
+func (l *ResultLimits) apply(tool string, execResult *ExecResult) {
+	if l == nil || l.Store == nil || l.MaxBytes <= 0 {
+		return
+	}
+	if len(execResult.Content) > 0 {
+		limited := l.Store.Truncate(tool, &mcp.CallToolResult{Content: execResult.Content}, l.MaxBytes)
+		execResult.Content = limited.Content
+		return
+	}
+
+	text, ok := execResult.Result.(string)
+	if !ok {
+		data, err := json.Marshal(execResult.Result)
+		if err != nil {
+			return
+		}
+		text = string(data)
+	}
+	head, note := l.Store.TruncateText(tool, text, l.MaxBytes)
+	if note == "" {
+		return
+	}
+	execResult.Result = head
+	execResult.Truncated = note
+}
+
+// limitedCaller truncates the results of a script's tool calls to the limit
+// configured for each tool
+type limitedCaller struct {
+	*js.ManagerCaller
+	manager *client.Manager
+	store   *results.Store
+}
+
+// CallTool implements js.ToolCaller
+func (c *limitedCaller) CallTool(ctx context.Context, serverID, toolName string, params map[string]any) (*mcp.CallToolResult, error) {
+	result, err := c.ManagerCaller.CallTool(ctx, serverID, toolName, params)
+	if err != nil {
+		return nil, err
+	}
+	name := c.manager.QualifiedToolName(serverID, toolName)
+	return c.store.Truncate(name, result, c.manager.ResultLimit(serverID, toolName)), nil
 }
 
 // ExecError represents a structured execution error
@@ -29,29 +105,40 @@ type ExecError struct {
 	Message string `json:"message"`
 }
 
-// ExecuteCode executes JavaScript code using the provided ToolCaller.
+// ExecuteCode executes JavaScript code using the provided ToolCaller and
+// runtime settings (nil = defaults).
 // This is the shared implementation used by both CLI and MCP tool handler.
-func ExecuteCode(ctx context.Context, logger *slog.Logger, caller js.ToolCaller, code string) (*ExecResult, error) {
+func ExecuteCode(ctx context.Context, logger *slog.Logger, caller js.ToolCaller, code string, cfg *js.Config) (*ExecResult, error) {
 	// Validate code
 	if code == "" {
 		return nil, fmt.Errorf("code is required")
 	}
 
 	// Validate code length
-	const maxCodeLength = 100 * 1024 // 100KB
+	maxCodeLength := js.MaxScriptSize
+	if cfg != nil && cfg.MaxScriptSize > 0 {
+		maxCodeLength = cfg.MaxScriptSize
+	}
 	if len(code) > maxCodeLength {
 		return nil, fmt.Errorf("code exceeds maximum length of %d bytes", maxCodeLength)
 	}
 
 	// Create JS runtime
-	runtime := js.NewRuntime(logger, caller, nil)
+	runtime := js.NewRuntime(logger, caller, cfg)
 
 	// Execute code
-	result, logs, err := runtime.Execute(ctx, code)
+	result, logs, trace, err := runtime.ExecuteWithTrace(ctx, code)
 
 	execResult := &ExecResult{
 		Result: result,
 		Logs:   logs,
+		Trace:  trace,
+	}
+
+	// Scripts returning content blocks get them back as native MCP content
+	if content, ok := js.ContentFromResult(result); ok {
+		execResult.Result = nil
+		execResult.Content = content
 	}
 
 	if err != nil {
@@ -71,37 +158,98 @@ func ExecuteCode(ctx context.Context, logger *slog.Logger, caller js.ToolCaller,
 	return execResult, nil
 }
 
-// HandleExecuteTool implements the execute built-in tool (MCP server handler)
-func HandleExecuteTool(ctx context.Context, logger *slog.Logger, manager *client.Manager, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+// HandleExecuteTool implements the execute built-in tool (MCP server handler).
+// cfg holds the hub's runtime settings (nil = defaults); the call's arguments
+// override them. limits truncates oversized results (nil = unlimited).
+func HandleExecuteTool(ctx context.Context, logger *slog.Logger, manager *client.Manager, cfg *js.Config, limits *ResultLimits, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	// Unmarshal arguments
 	var args struct {
-		Code string `json:"code"`
+		Code           string `json:"code"`
+		MaxConcurrency int    `json:"maxConcurrency"`
+		Language       string `json:"language"`
+		Trace          bool   `json:"trace"`
 	}
 	if err := json.Unmarshal(req.Params.Arguments, &args); err != nil {
 		return nil, fmt.Errorf("failed to parse arguments: %w", err)
 	}
+	if args.MaxConcurrency < 0 || args.MaxConcurrency > js.MaxConcurrencyLimit {
+		return nil, fmt.Errorf("maxConcurrency must be between 1 and %d", js.MaxConcurrencyLimit)
+	}
+	language, err := js.ParseLanguage(args.Language)
+	if err != nil {
+		return nil, err
+	}
 
-	// Create caller from manager
-	caller := js.NewManagerCaller(manager)
+	runCfg := runtimeConfig(cfg)
+	if args.MaxConcurrency > 0 {
+		runCfg.MaxConcurrency = args.MaxConcurrency
+	}
+	runCfg.Language = language
+	runCfg.Trace = args.Trace
 
 	// Execute using shared implementation
-	execResult, err := ExecuteCode(ctx, logger, caller, args.Code)
+	execResult, err := ExecuteCode(ctx, logger, limits.caller(manager), args.Code, runCfg)
 	if err != nil {
 		return nil, err
 	}
+	limits.apply("exec", execResult)
+	return execToolResult(execResult)
+}
 
+// ExecRuntimeConfig returns the runtime settings of the exec section of the
+// hub config, loading library modules from the script library in library
+func ExecRuntimeConfig(exec *config.ExecConfig, library string) *js.Config {
+	cfg := &js.Config{
+		Timeout:            exec.GetTimeout(),
+		AllowedTools:       exec.AllowedTools,
+		AllowedModules:     exec.AllowedModules,
+		Library:            library,
+		MaxScriptSize:      exec.MaxScriptSize,
+		MaxLogEntries:      exec.MaxLogEntries,
+		MaxMemory:          exec.MaxMemory,
+		MaxCallDepth:       exec.MaxCallDepth,
+		MaxToolCalls:       exec.MaxToolCalls,
+		MaxToolResultBytes: exec.MaxToolResultBytes,
+	}
+	if exec.FetchEnabled() {
+		cfg.Fetch = &js.FetchPolicy{
+			AllowedHosts:     exec.Fetch.AllowedHosts,
+			AllowedMethods:   exec.Fetch.AllowedMethods,
+			Timeout:          exec.Fetch.GetTimeout(),
+			MaxResponseBytes: exec.Fetch.MaxResponseBytes,
+			Headers:          exec.Fetch.Headers,
+		}
+	}
+	return cfg
+}
+
+// runtimeConfig returns a copy of cfg that a call may change
+func runtimeConfig(cfg *js.Config) *js.Config {
+	if cfg == nil {
+		return &js.Config{}
+	}
+	c := *cfg
+	return &c
+}
+
+// execToolResult builds the tool result of an execution
+func execToolResult(execResult *ExecResult) (*mcp.CallToolResult, error) {
 	// Marshal to JSON
 	jsonBytes, err := json.Marshal(execResult)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal execute result: %w", err)
 	}
 
-	return &mcp.CallToolResult{
-		Content: []mcp.Content{
-			&mcp.TextContent{
-				Text: string(jsonBytes),
-			},
+	// Logs and errors come first as JSON, followed by any content blocks
+	content := []mcp.Content{
+		&mcp.TextContent{
+			Text: string(jsonBytes),
 		},
+	}
+	content = append(content, execResult.Content...)
+
+	return &mcp.CallToolResult{
+		Content: content,
 		IsError: execResult.Error != nil,
 	}, nil
 }
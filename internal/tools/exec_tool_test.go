@@ -3,10 +3,14 @@ package tools
 import (
 	"context"
 	"encoding/json"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/vaayne/mcphub/internal/client"
+	"github.com/vaayne/mcphub/internal/config"
 	"github.com/vaayne/mcphub/internal/logging"
+	"github.com/vaayne/mcphub/internal/results"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/stretchr/testify/assert"
@@ -31,7 +35,7 @@ func TestHandleExecuteTool_Success(t *testing.T) {
 		},
 	}
 
-	result, err := HandleExecuteTool(context.Background(), logger, manager, req)
+	result, err := HandleExecuteTool(context.Background(), logger, manager, nil, nil, req)
 	require.NoError(t, err)
 	assert.NotNil(t, result)
 	assert.Len(t, result.Content, 1)
@@ -65,7 +69,7 @@ func TestHandleExecuteTool_WithLogs(t *testing.T) {
 		},
 	}
 
-	result, err := HandleExecuteTool(context.Background(), logger, manager, req)
+	result, err := HandleExecuteTool(context.Background(), logger, manager, nil, nil, req)
 	require.NoError(t, err)
 
 	textContent, ok := result.Content[0].(*mcp.TextContent)
@@ -99,7 +103,7 @@ func TestHandleExecuteTool_AsyncSuccess(t *testing.T) {
 		},
 	}
 
-	result, err := HandleExecuteTool(context.Background(), logger, manager, req)
+	result, err := HandleExecuteTool(context.Background(), logger, manager, nil, nil, req)
 	require.NoError(t, err)
 	assert.NotNil(t, result)
 
@@ -129,7 +133,7 @@ func TestHandleExecuteTool_MissingCode(t *testing.T) {
 		},
 	}
 
-	_, err = HandleExecuteTool(context.Background(), logger, manager, req)
+	_, err = HandleExecuteTool(context.Background(), logger, manager, nil, nil, req)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "code is required")
 }
@@ -157,7 +161,193 @@ func TestHandleExecuteTool_CodeTooLarge(t *testing.T) {
 		},
 	}
 
-	_, err = HandleExecuteTool(context.Background(), logger, manager, req)
+	_, err = HandleExecuteTool(context.Background(), logger, manager, nil, nil, req)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "exceeds maximum length")
 }
+
+func TestHandleExecuteTool_ContentBlocks(t *testing.T) {
+	logger := logging.NopLogger()
+	manager := client.NewManager(logger)
+	defer manager.DisconnectAll()
+
+	argsJSON, err := json.Marshal(map[string]any{
+		"code": `console.log("rendering"); [mcp.image("aGk=", "image/png"), {type: "text", text: "caption"}]`,
+	})
+	require.NoError(t, err)
+
+	req := &mcp.CallToolRequest{
+		Params: &mcp.CallToolParamsRaw{
+			Name:      "exec",
+			Arguments: argsJSON,
+		},
+	}
+
+	result, err := HandleExecuteTool(context.Background(), logger, manager, nil, nil, req)
+	require.NoError(t, err)
+	assert.False(t, result.IsError)
+	require.Len(t, result.Content, 3)
+
+	textContent, ok := result.Content[0].(*mcp.TextContent)
+	require.True(t, ok)
+	var response ExecResult
+	require.NoError(t, json.Unmarshal([]byte(textContent.Text), &response))
+	assert.Nil(t, response.Result)
+	require.Len(t, response.Logs, 1)
+
+	image, ok := result.Content[1].(*mcp.ImageContent)
+	require.True(t, ok)
+	assert.Equal(t, []byte("hi"), image.Data)
+	assert.Equal(t, "image/png", image.MIMEType)
+
+	caption, ok := result.Content[2].(*mcp.TextContent)
+	require.True(t, ok)
+	assert.Equal(t, "caption", caption.Text)
+}
+
+func TestHandleExecuteTool_TypeScript(t *testing.T) {
+	logger := logging.NopLogger()
+	manager := client.NewManager(logger)
+	defer manager.DisconnectAll()
+
+	run := func(args map[string]any) (*mcp.CallToolResult, error) {
+		argsJSON, err := json.Marshal(args)
+		require.NoError(t, err)
+		req := &mcp.CallToolRequest{
+			Params: &mcp.CallToolParamsRaw{
+				Name:      "execute",
+				Arguments: argsJSON,
+			},
+		}
+		return HandleExecuteTool(context.Background(), logger, manager, nil, nil, req)
+	}
+
+	result, err := run(map[string]any{
+		"code":     "const n: number = 1;\nn + 1",
+		"language": "typescript",
+	})
+	require.NoError(t, err)
+	textContent, ok := result.Content[0].(*mcp.TextContent)
+	require.True(t, ok)
+
+	var response ExecResult
+	require.NoError(t, json.Unmarshal([]byte(textContent.Text), &response))
+	assert.Nil(t, response.Error)
+	assert.Equal(t, float64(2), response.Result)
+
+	_, err = run(map[string]any{"code": "1", "language": "python"})
+	assert.ErrorContains(t, err, "unknown language")
+}
+
+// TestHandleExecuteTool_Trace verifies the trace argument returns the stack
+// of a failure with the script's source line
+func TestHandleExecuteTool_Trace(t *testing.T) {
+	logger := logging.NopLogger()
+	manager := client.NewManager(logger)
+	defer manager.DisconnectAll()
+
+	argsJSON, err := json.Marshal(map[string]any{
+		"code":  "const a = 1;\nnull.x;",
+		"trace": true,
+	})
+	require.NoError(t, err)
+	result, err := HandleExecuteTool(context.Background(), logger, manager, nil, nil, &mcp.CallToolRequest{
+		Params: &mcp.CallToolParamsRaw{Name: "exec", Arguments: argsJSON},
+	})
+	require.NoError(t, err)
+
+	var response ExecResult
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &response))
+	require.NotNil(t, response.Error)
+	require.NotNil(t, response.Trace)
+	assert.Empty(t, response.Trace.Calls)
+	require.NotEmpty(t, response.Trace.Stack)
+	assert.Equal(t, 2, response.Trace.Stack[0].Line)
+	assert.Equal(t, "null.x;", response.Trace.Stack[0].Source)
+}
+
+func TestExecRuntimeConfig(t *testing.T) {
+	cfg := ExecRuntimeConfig(&config.ExecConfig{
+		Timeout:       2,
+		MaxScriptSize: 10,
+		AllowedTools:  map[string][]string{"github": {"search_*"}},
+	}, "/srv/scripts")
+	assert.Equal(t, 2*time.Second, cfg.Timeout)
+	assert.Equal(t, 10, cfg.MaxScriptSize)
+	assert.Equal(t, map[string][]string{"github": {"search_*"}}, cfg.AllowedTools)
+	assert.Nil(t, cfg.AllowedModules)
+	assert.Equal(t, "/srv/scripts", cfg.Library)
+	assert.Nil(t, cfg.Fetch, "scripts have no network access by default")
+
+	fetchCfg := ExecRuntimeConfig(&config.ExecConfig{
+		Fetch: &config.ExecFetchConfig{AllowedHosts: []string{"api.example.com"}, Timeout: 3},
+	}, "")
+	require.NotNil(t, fetchCfg.Fetch)
+	assert.Equal(t, []string{"api.example.com"}, fetchCfg.Fetch.AllowedHosts)
+	assert.Equal(t, 3*time.Second, fetchCfg.Fetch.Timeout)
+
+	_, err := ExecuteCode(context.Background(), logging.NopLogger(), nil, "1 + 1 + 1 + 1", cfg)
+	assert.ErrorContains(t, err, "exceeds maximum length of 10 bytes")
+}
+
+// inMemoryFactory connects a manager to an in-process MCP server
+type inMemoryFactory struct {
+	server *mcp.Server
+}
+
+func (f *inMemoryFactory) CreateTransport(cfg config.MCPServer) (mcp.Transport, error) {
+	clientTransport, serverTransport := mcp.NewInMemoryTransports()
+	if _, err := f.server.Connect(context.Background(), serverTransport, nil); err != nil {
+		return nil, err
+	}
+	return clientTransport, nil
+}
+
+// TestHandleExecuteTool_ResultLimits verifies the tool calls of a script are
+// truncated to their per-tool limit, and the script's result to the hub limit
+// without breaking the JSON around it
+func TestHandleExecuteTool_ResultLimits(t *testing.T) {
+	backend := mcp.NewServer(&mcp.Implementation{Name: "backend", Version: "v1.0.0"}, nil)
+	backend.AddTool(&mcp.Tool{Name: "big", InputSchema: map[string]any{"type": "object"}},
+		func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: strings.Repeat("x", 200)}}}, nil
+		})
+	logger := logging.NopLogger()
+	manager := client.NewManagerWithFactory(logger, &inMemoryFactory{server: backend})
+	defer manager.DisconnectAll()
+	toolLimit := 20
+	require.NoError(t, manager.ConnectToServer("srv", config.MCPServer{
+		Command: "test",
+		Tools:   map[string]config.ToolConfig{"big": {MaxResultBytes: &toolLimit}},
+	}))
+	limits := &ResultLimits{Store: results.NewStore(0, 0), MaxBytes: 200}
+
+	call := func(code string) *ExecResult {
+		result, err := HandleExecuteTool(context.Background(), logger, manager, nil, limits, &mcp.CallToolRequest{
+			Params: &mcp.CallToolParamsRaw{Name: "exec", Arguments: mustJSON(t, map[string]any{"code": code})},
+		})
+		require.NoError(t, err)
+		require.Len(t, result.Content, 1)
+		var execResult ExecResult
+		require.NoError(t, json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &execResult))
+		return &execResult
+	}
+
+	execResult := call(`mcp.callToolRaw("srvBig", {}).content.map((c) => c.text)`)
+	require.Empty(t, execResult.Truncated)
+	texts := execResult.Result.([]any)
+	require.Len(t, texts, 2)
+	assert.Equal(t, strings.Repeat("x", toolLimit), texts[0])
+	assert.Contains(t, texts[1], "Output truncated")
+
+	execResult = call(`"y".repeat(300)`)
+	assert.Equal(t, strings.Repeat("y", 200), execResult.Result)
+	assert.Contains(t, execResult.Truncated, "Output truncated: showing 200 of 300 bytes")
+}
+
+func mustJSON(t *testing.T, v any) []byte {
+	t.Helper()
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	return data
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/vaayne/mcphub/internal/schema"
 	"github.com/vaayne/mcphub/internal/toolname"
 )
 
@@ -55,8 +56,8 @@ func InspectTool(ctx context.Context, provider ToolProvider, name string, mapper
 		return nil, err
 	}
 
-	// Extract server ID from namespaced name (format: serverID__toolName)
-	serverID, _, _ := toolname.ParseNamespacedName(originalName)
+	// Extract server ID from the qualified name (serverID__toolName by default)
+	serverID, _, _ := toolname.Resolve(provider, originalName)
 
 	// Convert InputSchema to map if possible
 	var inputSchema map[string]any
@@ -84,7 +85,7 @@ func InspectTool(ctx context.Context, provider ToolProvider, name string, mapper
 
 // FormatInspectResultAsJSDoc formats the inspect result as a JSDoc function stub
 func FormatInspectResultAsJSDoc(result *InspectResult) string {
-	return schemaToJSDoc(result.Name, result.Description, result.InputSchema)
+	return schema.JSDoc(result.Name, result.Description, result.InputSchema)
 }
 
 // HandleInspectTool handles the inspect tool call (MCP server handler)
@@ -112,8 +113,8 @@ func HandleInspectTool(ctx context.Context, provider ToolProvider, req *mcp.Call
 	// Resolve tool name (accepts both JS name and original name)
 	originalName, found := mapper.Resolve(args.Name)
 	if !found {
-		// If not found in mapper, check if it's a valid namespaced name
-		if !toolname.IsNamespaced(args.Name) {
+		// Otherwise it must be a name the provider resolves to a server and tool
+		if _, _, ok := toolname.Resolve(provider, args.Name); !ok {
 			return nil, fmt.Errorf("tool '%s' not found", args.Name)
 		}
 		originalName = args.Name
@@ -4,9 +4,13 @@ import (
 	"context"
 	_ "embed"
 	"encoding/json"
+	"errors"
 	"fmt"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/vaayne/mcphub/internal/breaker"
+	"github.com/vaayne/mcphub/internal/ratelimit"
+	"github.com/vaayne/mcphub/internal/schema"
 	"github.com/vaayne/mcphub/internal/toolname"
 )
 
@@ -41,44 +45,99 @@ func InvokeTool(ctx context.Context, provider ToolProvider, name string, params
 		originalName = mapper.ToOriginal(name)
 	}
 
+	// Coerce and validate arguments against the tool's inputSchema
+	params, coercions, err := prepareParams(ctx, provider, originalName, params)
+	if err != nil {
+		return nil, err
+	}
+
 	// Call the tool
 	result, err := provider.CallTool(ctx, originalName, params)
 	if err != nil {
 		return nil, err
 	}
 
+	// Keep oversized output out of the client's context
+	if limiter, ok := provider.(ResultLimiter); ok {
+		result = limiter.LimitResult(originalName, result)
+	}
+
+	// Tell the caller what was adjusted so it can send correct arguments next time
+	if len(coercions) > 0 && result != nil {
+		result.Content = append(result.Content, &mcp.TextContent{
+			Text: schema.FormatCoercions(coercions),
+		})
+	}
+
 	return result, nil
 }
 
+// prepareParams coerces params to match the tool's cached inputSchema and then
+// validates them, returning the (possibly rewritten) params and the coercions
+// applied. Tools that cannot be looked up are passed through unchanged, and
+// servers can opt out of either step.
+func prepareParams(ctx context.Context, provider ToolProvider, name string, params json.RawMessage) (json.RawMessage, []schema.Coercion, error) {
+	serverID, _, _ := toolname.Resolve(provider, name)
+	coerce := schema.CoercionEnabled(provider, serverID)
+	validate := schema.ValidationEnabled(provider, serverID)
+	if !coerce && !validate {
+		return params, nil, nil
+	}
+
+	tool, err := provider.GetTool(ctx, name)
+	if err != nil || tool == nil || tool.InputSchema == nil {
+		return params, nil, nil
+	}
+
+	var raw any
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, &raw); err != nil {
+			return params, nil, nil
+		}
+	}
+
+	args, isObject := raw.(map[string]any)
+	var coercions []schema.Coercion
+	if coerce {
+		args, coercions, isObject = schema.CoerceArgs(tool.InputSchema, raw)
+	}
+	if !isObject && raw != nil {
+		// Non-object params are rejected by the provider with its own error
+		return params, nil, nil
+	}
+	if len(coercions) > 0 {
+		data, err := json.Marshal(args)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to marshal params: %w", err)
+		}
+		params = data
+	}
+
+	if validate {
+		if err := schema.Validate(tool.InputSchema, args); err != nil {
+			if verr, ok := err.(*schema.ValidationError); ok {
+				verr.Tool = name
+			}
+			return nil, nil, err
+		}
+	}
+
+	return params, coercions, nil
+}
+
 // HandleInvokeTool handles the invoke tool call (MCP server handler)
 func HandleInvokeTool(ctx context.Context, provider ToolProvider, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	// Parse arguments - handle both object and string params
-	var rawArgs struct {
+	// Parse arguments; params stay raw so a JSON-encoded string is parsed by
+	// the coercion layer and reported with the other coercions
+	var args struct {
 		Name   string          `json:"name"`
 		Params json.RawMessage `json:"params,omitempty"`
 	}
-	if err := json.Unmarshal(req.Params.Arguments, &rawArgs); err != nil {
+	if err := json.Unmarshal(req.Params.Arguments, &args); err != nil {
 		return nil, fmt.Errorf("failed to parse invoke arguments: %w", err)
 	}
-
-	// Parse params - could be object, string, or omitted
-	var args struct {
-		Name   string
-		Params map[string]any
-	}
-	args.Name = rawArgs.Name
-
-	if len(rawArgs.Params) > 0 {
-		// First try to unmarshal as object
-		if err := json.Unmarshal(rawArgs.Params, &args.Params); err != nil {
-			// If that fails, try to unmarshal as string then parse that string as JSON
-			var paramsStr string
-			if err := json.Unmarshal(rawArgs.Params, &paramsStr); err == nil && paramsStr != "" {
-				if err := json.Unmarshal([]byte(paramsStr), &args.Params); err != nil {
-					return nil, fmt.Errorf("failed to parse params string as JSON: %w", err)
-				}
-			}
-		}
+	if string(args.Params) == "null" {
+		args.Params = nil
 	}
 
 	// Validate name first
@@ -96,23 +155,52 @@ func HandleInvokeTool(ctx context.Context, provider ToolProvider, req *mcp.CallT
 	// Resolve tool name (accepts both JS name and original name)
 	originalName, found := mapper.Resolve(args.Name)
 	if !found {
-		// If not found in mapper, check if it's a valid namespaced name
-		if !toolname.IsNamespaced(args.Name) {
+		// Otherwise it must be a name the provider resolves to a server and tool
+		if _, _, ok := toolname.Resolve(provider, args.Name); !ok {
 			return nil, fmt.Errorf("tool '%s' not found", args.Name)
 		}
 		originalName = args.Name
 	}
 
-	// Convert params to JSON
-	var paramsJSON json.RawMessage
-	if args.Params != nil {
-		data, err := json.Marshal(args.Params)
-		if err != nil {
-			return nil, fmt.Errorf("failed to marshal params: %w", err)
+	// Call shared core function
+	result, err := InvokeTool(ctx, provider, originalName, args.Params, mapper)
+	if err != nil {
+		// Report invalid arguments as a tool error so the model can correct the call
+		var verr *schema.ValidationError
+		if errors.As(err, &verr) {
+			verr.Tool = mapper.ToJSName(originalName)
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{
+						Text: verr.Error(),
+					},
+				},
+				IsError: true,
+			}, nil
 		}
-		paramsJSON = data
+		// Rate limit and circuit breaker rejections are tool errors too, so the
+		// model can back off
+		var limitErr *ratelimit.LimitError
+		var openErr *breaker.OpenError
+		var rejection error
+		switch {
+		case errors.As(err, &limitErr):
+			rejection = limitErr
+		case errors.As(err, &openErr):
+			rejection = openErr
+		}
+		if rejection != nil {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{
+						Text: rejection.Error(),
+					},
+				},
+				IsError: true,
+			}, nil
+		}
+		return nil, err
 	}
 
-	// Call shared core function
-	return InvokeTool(ctx, provider, originalName, paramsJSON, mapper)
+	return result, nil
 }
@@ -220,3 +220,132 @@ func TestHandleInvokeTool_ContextCancellation(t *testing.T) {
 	// Error is now wrapped: "failed to list tools: context canceled"
 	assert.Contains(t, err.Error(), "context canceled")
 }
+
+// TestHandleInvokeTool_InvalidArguments tests that arguments are validated against inputSchema
+func TestHandleInvokeTool_InvalidArguments(t *testing.T) {
+	provider := &mockToolProvider{
+		tools: []*mcp.Tool{
+			{
+				Name: "github__search_repos",
+				InputSchema: map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"query": map[string]any{"type": "string"},
+						"sort":  map[string]any{"type": "string", "enum": []any{"stars", "updated"}},
+					},
+					"required": []any{"query"},
+				},
+			},
+		},
+	}
+
+	argsJSON, err := json.Marshal(map[string]any{
+		"name":   "githubSearchRepos",
+		"params": map[string]any{"sort": "name"},
+	})
+	require.NoError(t, err)
+
+	req := &mcp.CallToolRequest{
+		Params: &mcp.CallToolParamsRaw{
+			Name:      "invoke",
+			Arguments: argsJSON,
+		},
+	}
+
+	result, err := HandleInvokeTool(context.Background(), provider, req)
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	require.Len(t, result.Content, 1)
+
+	text := result.Content[0].(*mcp.TextContent).Text
+	assert.Contains(t, text, "invalid arguments for 'githubSearchRepos'")
+	assert.Contains(t, text, "params.query: required property is missing")
+	assert.Contains(t, text, `params.sort: must be one of "stars", "updated", got string "name"`)
+}
+
+// TestHandleInvokeTool_CoercesArguments tests that arguments are coerced and the caller is told
+func TestHandleInvokeTool_CoercesArguments(t *testing.T) {
+	provider := &mockToolProvider{
+		tools: []*mcp.Tool{
+			{
+				Name: "github__search_repos",
+				InputSchema: map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"query": map[string]any{"type": "string"},
+						"limit": map[string]any{"type": "integer"},
+						"sort":  map[string]any{"type": "string", "default": "stars"},
+					},
+					"required": []any{"query"},
+				},
+			},
+		},
+		result: &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "ok"}}},
+	}
+
+	argsJSON, err := json.Marshal(map[string]any{
+		"name":   "githubSearchRepos",
+		"params": map[string]any{"query": "mcp", "limit": "5"},
+	})
+	require.NoError(t, err)
+
+	req := &mcp.CallToolRequest{
+		Params: &mcp.CallToolParamsRaw{
+			Name:      "invoke",
+			Arguments: argsJSON,
+		},
+	}
+
+	result, err := HandleInvokeTool(context.Background(), provider, req)
+	require.NoError(t, err)
+	assert.False(t, result.IsError)
+	assert.JSONEq(t, `{"query": "mcp", "limit": 5, "sort": "stars"}`, string(provider.lastParams))
+
+	require.Len(t, result.Content, 2)
+	note := result.Content[1].(*mcp.TextContent).Text
+	assert.Contains(t, note, `params.limit: converted string "5" to integer 5`)
+	assert.Contains(t, note, `params.sort: filled default "stars"`)
+}
+
+// TestHandleInvokeTool_ParamsAsString tests that params sent as a JSON string are
+// parsed by the coercion layer and reported like any other coercion
+func TestHandleInvokeTool_ParamsAsString(t *testing.T) {
+	provider := &mockToolProvider{
+		tools: []*mcp.Tool{
+			{
+				Name: "github__search_repos",
+				InputSchema: map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"query": map[string]any{"type": "string"},
+						"limit": map[string]any{"type": "integer"},
+					},
+				},
+			},
+		},
+		result: &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "ok"}}},
+	}
+
+	argsJSON, err := json.Marshal(map[string]any{
+		"name":   "githubSearchRepos",
+		"params": `{"query": "mcp", "limit": "5"}`,
+	})
+	require.NoError(t, err)
+
+	req := &mcp.CallToolRequest{
+		Params: &mcp.CallToolParamsRaw{
+			Name:      "invoke",
+			Arguments: argsJSON,
+		},
+	}
+
+	result, err := HandleInvokeTool(context.Background(), provider, req)
+	require.NoError(t, err)
+	assert.False(t, result.IsError)
+	assert.JSONEq(t, `{"query": "mcp", "limit": 5}`, string(provider.lastParams))
+
+	require.Len(t, result.Content, 2)
+	note := result.Content[1].(*mcp.TextContent).Text
+	assert.Contains(t, note, "params: parsed JSON string into object")
+	assert.Contains(t, note, `params.limit: converted string "5" to integer 5`)
+}
@@ -3,11 +3,15 @@ package tools
 import (
 	"context"
 	_ "embed"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"sort"
 	"strings"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/vaayne/mcphub/internal/schema"
+	"github.com/vaayne/mcphub/internal/search"
 	"github.com/vaayne/mcphub/internal/toolname"
 )
 
@@ -17,22 +21,29 @@ var ListDescription string
 // ListOptions contains options for listing tools
 type ListOptions struct {
 	Server            string // Optional: filter by server name
-	Query             string // Optional: comma-separated keywords for search
+	Query             string // Optional: free-text query; matching tools are ranked best first
+	Limit             int    // Optional: maximum number of tools returned (default and cap 100)
+	Offset            int    // Optional: number of matching tools to skip
 	IncludeUnprefixed bool   // If true, include tools without server prefix (for direct server connections)
 }
 
+// maxResults limits the tools returned by one list call to prevent DoS
+const maxResults = 100
+
 // ListToolResult represents a tool in the list result
 type ListToolResult struct {
 	Name        string         `json:"name"`
-	Description string         `json:"description"`
+	Description string         `json:"description,omitempty"`
 	Server      string         `json:"server,omitempty"`
 	InputSchema map[string]any `json:"inputSchema,omitempty"`
 }
 
 // ListResult represents the result of listing tools
 type ListResult struct {
-	Tools []*mcp.Tool `json:"-"` // Internal: original tools
-	Total int         `json:"total"`
+	Tools   []*mcp.Tool       `json:"-"` // Internal: original tools
+	Total   int               `json:"total"`
+	Offset  int               `json:"offset"` // index of the first tool in Tools among all matches
+	servers map[string]string // tool name -> server ID
 }
 
 // ListTools is the shared core function for listing tools.
@@ -50,9 +61,13 @@ func ListTools(ctx context.Context, provider ToolProvider, opts ListOptions) (*L
 		return nil, fmt.Errorf("failed to list tools: %w", err)
 	}
 
-	var results []*mcp.Tool
-	const maxResults = 100 // Limit results to prevent DoS
-	totalMatches := 0
+	limit := maxResults
+	if opts.Limit > 0 {
+		limit = min(opts.Limit, maxResults)
+	}
+
+	var matches []*mcp.Tool
+	servers := make(map[string]string)
 
 	for _, tool := range tools {
 		// Check context cancellation
@@ -62,8 +77,8 @@ func ListTools(ctx context.Context, provider ToolProvider, opts ListOptions) (*L
 		default:
 		}
 
-		// Extract server ID from namespaced name (format: serverID__toolName)
-		serverID, _, isNamespaced := toolname.ParseNamespacedName(tool.Name)
+		// Extract server ID from the qualified name (serverID__toolName by default)
+		serverID, _, isNamespaced := toolname.Resolve(provider, tool.Name)
 
 		// Skip non-namespaced tools unless IncludeUnprefixed is set
 		// (for direct server connections, tools don't have the server prefix)
@@ -76,37 +91,83 @@ func ListTools(ctx context.Context, provider ToolProvider, opts ListOptions) (*L
 			continue
 		}
 
-		// Filter by query keywords if specified
-		if !matchesKeywords(tool.Name, tool.Description, opts.Query) {
-			continue
+		matches = append(matches, tool)
+		if isNamespaced {
+			servers[tool.Name] = serverID
 		}
+	}
 
-		totalMatches++
-
-		if len(results) >= maxResults {
-			continue
+	// Sort by name for consistent output, then rank by the query if there is one
+	sort.Slice(matches, func(i, j int) bool {
+		return matches[i].Name < matches[j].Name
+	})
+	if strings.TrimSpace(opts.Query) != "" {
+		matches, err = rankTools(ctx, provider, matches, opts.Query)
+		if err != nil {
+			return nil, err
 		}
-
-		results = append(results, tool)
 	}
 
-	// Sort results by name for consistent output
-	sort.Slice(results, func(i, j int) bool {
-		return results[i].Name < results[j].Name
-	})
+	offset := min(max(opts.Offset, 0), len(matches))
+	results := matches[offset:]
+	if len(results) > limit {
+		results = results[:limit]
+	}
 
 	return &ListResult{
-		Tools: results,
-		Total: totalMatches,
+		Tools:   results,
+		Total:   len(matches),
+		Offset:  offset,
+		servers: servers,
 	}, nil
 }
 
+// rankTools returns the tools matching query, best first. Tools are ranked by
+// the provider's searcher; tools it does not rank but whose name or description
+// contains one of the comma-separated keywords follow in name order.
+func rankTools(ctx context.Context, provider ToolProvider, tools []*mcp.Tool, query string) ([]*mcp.Tool, error) {
+	var searcher *search.Searcher
+	if s, ok := provider.(ToolSearcher); ok {
+		searcher = s.Searcher()
+	}
+	if searcher == nil {
+		searcher = search.NewSearcher(nil, 0)
+	}
+
+	docs := make([]search.Document, len(tools))
+	byName := make(map[string]*mcp.Tool, len(tools))
+	for i, tool := range tools {
+		docs[i] = search.ToolDocument(tool.Name, tool)
+		byName[tool.Name] = tool
+	}
+
+	hits, err := searcher.Search(ctx, docs, query, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	ranked := make([]*mcp.Tool, 0, len(hits))
+	for _, hit := range hits {
+		ranked = append(ranked, byName[hit.ID])
+		delete(byName, hit.ID)
+	}
+	for _, tool := range tools {
+		if _, ok := byName[tool.Name]; ok && matchesKeywords(tool.Name, tool.Description, query) {
+			ranked = append(ranked, tool)
+		}
+	}
+	return ranked, nil
+}
+
 // FormatListResult formats the list result with JS names for output.
 // Used by both CLI and MCP server handlers for consistent output.
 func FormatListResult(result *ListResult, mapper *toolname.Mapper) []ListToolResult {
 	formatted := make([]ListToolResult, 0, len(result.Tools))
 	for _, tool := range result.Tools {
-		serverID, _, _ := toolname.ParseNamespacedName(tool.Name)
+		serverID, ok := result.servers[tool.Name]
+		if !ok {
+			serverID, _, _ = toolname.ParseNamespacedName(tool.Name)
+		}
 
 		// Convert InputSchema to map if possible
 		var inputSchema map[string]any
@@ -126,10 +187,89 @@ func FormatListResult(result *ListResult, mapper *toolname.Mapper) []ListToolRes
 	return formatted
 }
 
+// List detail levels
+const (
+	DetailNames        = "names"        // tool names only
+	DetailDescriptions = "descriptions" // names and descriptions (default)
+	DetailFull         = "full"         // names, descriptions and input schemas
+)
+
+// ListPage is one page of the list tool's result, returned as structured content
+type ListPage struct {
+	Tools      []ListToolResult `json:"tools"`
+	Total      int              `json:"total"`
+	Offset     int              `json:"offset"`
+	NextCursor string           `json:"nextCursor,omitempty"` // pass as cursor to get the next page
+}
+
+// listCursor records the listing a page belongs to and where the next page starts
+type listCursor struct {
+	Query  string `json:"q,omitempty"`
+	Server string `json:"s,omitempty"`
+	Detail string `json:"d,omitempty"`
+	Limit  int    `json:"l,omitempty"`
+	Offset int    `json:"o"`
+}
+
+func encodeListCursor(c listCursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeListCursor(s string) (listCursor, error) {
+	var c listCursor
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || json.Unmarshal(data, &c) != nil || c.Offset < 0 || c.Limit < 0 {
+		return c, fmt.Errorf("invalid cursor")
+	}
+	return c, nil
+}
+
 // HandleListTool handles the list tool call (MCP server handler)
 func HandleListTool(ctx context.Context, provider ToolProvider, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	// Call shared core function (no parameters - returns all tools)
-	result, err := ListTools(ctx, provider, ListOptions{})
+	// Parse arguments (all optional)
+	var args struct {
+		Query  string `json:"query"`
+		Server string `json:"server"`
+		Limit  int    `json:"limit"`
+		Offset int    `json:"offset"`
+		Detail string `json:"detail"`
+		Cursor string `json:"cursor"`
+	}
+	if len(req.Params.Arguments) > 0 {
+		if err := json.Unmarshal(req.Params.Arguments, &args); err != nil {
+			return nil, fmt.Errorf("failed to parse list arguments: %w", err)
+		}
+	}
+
+	// A cursor continues the listing it came from; other arguments are ignored
+	if args.Cursor != "" {
+		cursor, err := decodeListCursor(args.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		args.Query, args.Server, args.Detail = cursor.Query, cursor.Server, cursor.Detail
+		args.Limit, args.Offset = cursor.Limit, cursor.Offset
+	}
+
+	if args.Limit < 0 || args.Offset < 0 {
+		return nil, fmt.Errorf("limit and offset must not be negative")
+	}
+	detail := args.Detail
+	if detail == "" {
+		detail = DetailDescriptions
+	}
+	if detail != DetailNames && detail != DetailDescriptions && detail != DetailFull {
+		return nil, fmt.Errorf("invalid detail: %s (must be names, descriptions or full)", args.Detail)
+	}
+
+	// Call shared core function
+	result, err := ListTools(ctx, provider, ListOptions{
+		Server: args.Server,
+		Query:  args.Query,
+		Limit:  args.Limit,
+		Offset: args.Offset,
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -137,45 +277,85 @@ func HandleListTool(ctx context.Context, provider ToolProvider, req *mcp.CallToo
 	// Create mapper for name conversion
 	mapper := toolname.NewMapper(result.Tools)
 
-	// Format result with JS names
+	// Format result with JS names, keeping only the requested detail
 	formatted := FormatListResult(result, mapper)
+	for i := range formatted {
+		switch detail {
+		case DetailNames:
+			formatted[i].Description = ""
+			formatted[i].InputSchema = nil
+		case DetailDescriptions:
+			formatted[i].InputSchema = nil
+		}
+	}
 
-	// Build simple text output (same as CLI)
-	output := FormatListResultAsText(formatted, result.Total)
+	page := &ListPage{
+		Tools:  formatted,
+		Total:  result.Total,
+		Offset: result.Offset,
+	}
+	if next := result.Offset + len(result.Tools); len(result.Tools) > 0 && next < result.Total {
+		page.NextCursor = encodeListCursor(listCursor{
+			Query:  args.Query,
+			Server: args.Server,
+			Detail: args.Detail,
+			Limit:  args.Limit,
+			Offset: next,
+		})
+	}
 
 	return &mcp.CallToolResult{
 		Content: []mcp.Content{
 			&mcp.TextContent{
-				Text: output,
+				Text: FormatListResultAsText(page, detail),
 			},
 		},
+		StructuredContent: page,
 	}, nil
 }
 
-// FormatListResultAsText formats the list result as simple text (name: description)
-func FormatListResultAsText(tools []ListToolResult, total int) string {
+// FormatListResultAsText formats a page of the list result as simple text:
+// "name: description" lines, bare names, or JSDoc stubs for DetailFull
+func FormatListResultAsText(page *ListPage, detail string) string {
 	var output strings.Builder
 
-	if len(tools) == 0 {
+	if len(page.Tools) == 0 {
+		if page.Total > 0 {
+			return fmt.Sprintf("Total: %d tools (none from offset %d)", page.Total, page.Offset)
+		}
 		output.WriteString("No tools available")
 		return output.String()
 	}
 
-	output.WriteString(fmt.Sprintf("Total: %d tools", total))
-	if total > len(tools) {
-		output.WriteString(fmt.Sprintf(" (showing first %d)", len(tools)))
+	output.WriteString(fmt.Sprintf("Total: %d tools", page.Total))
+	if page.Offset > 0 {
+		output.WriteString(fmt.Sprintf(" (showing %d-%d)", page.Offset+1, page.Offset+len(page.Tools)))
+	} else if page.Total > len(page.Tools) {
+		output.WriteString(fmt.Sprintf(" (showing first %d)", len(page.Tools)))
 	}
 	output.WriteString("\n\n")
 
-	for _, tool := range tools {
-		desc := tool.Description
-		if strings.TrimSpace(desc) == "" {
-			desc = tool.Name
+	for _, tool := range page.Tools {
+		switch detail {
+		case DetailNames:
+			output.WriteString(fmt.Sprintf("- %s\n", tool.Name))
+		case DetailFull:
+			output.WriteString(schema.JSDoc(tool.Name, tool.Description, tool.InputSchema))
+			output.WriteString("\n\n")
+		default:
+			desc := tool.Description
+			if strings.TrimSpace(desc) == "" {
+				desc = tool.Name
+			}
+			output.WriteString(fmt.Sprintf("- %s: %s\n", tool.Name, TruncateDescription(desc, 50)))
 		}
-		output.WriteString(fmt.Sprintf("- %s: %s\n", tool.Name, TruncateDescription(desc, 50)))
 	}
 
-	return strings.TrimSuffix(output.String(), "\n")
+	if page.NextCursor != "" {
+		output.WriteString(fmt.Sprintf("\nMore tools: call list with cursor %q\n", page.NextCursor))
+	}
+
+	return strings.TrimRight(output.String(), "\n")
 }
 
 // TruncateDescription truncates a description to a maximum number of words
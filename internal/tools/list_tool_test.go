@@ -3,6 +3,7 @@ package tools
 import (
 	"context"
 	"encoding/json"
+	"strings"
 	"testing"
 
 	"github.com/vaayne/mcphub/internal/client"
@@ -76,9 +77,112 @@ func TestListTools_FiltersOutBuiltinTools(t *testing.T) {
 	assert.NotContains(t, toolNames, "exec")
 }
 
+func TestHandleListTool_RankedSearch(t *testing.T) {
+	provider := &mockToolProvider{
+		tools: []*mcp.Tool{
+			{Name: "github__create_issue", Description: "Create a new issue in a repository"},
+			{Name: "github__list_issues", Description: "List issues in a repository"},
+			{Name: "github__search_code", Description: "Search code across repositories"},
+			{Name: "jira__create_ticket", Description: "Open a ticket", InputSchema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"issue_type": map[string]any{"type": "string", "description": "Kind of issue"},
+				},
+			}},
+			{Name: "fs__read_file", Description: "Read a file from disk"},
+		},
+	}
+
+	call := func(args string) string {
+		req := &mcp.CallToolRequest{
+			Params: &mcp.CallToolParamsRaw{Name: "list", Arguments: json.RawMessage(args)},
+		}
+		result, err := HandleListTool(context.Background(), provider, req)
+		require.NoError(t, err)
+		return result.Content[0].(*mcp.TextContent).Text
+	}
+
+	text := call(`{"query": "create issue"}`)
+	assert.Contains(t, text, "Total: 3 tools")
+	assert.Less(t, strings.Index(text, "githubCreateIssue"), strings.Index(text, "githubListIssues"))
+	assert.Contains(t, text, "jiraCreateTicket", "parameters are searched")
+	assert.NotContains(t, text, "fsReadFile")
+
+	text = call(`{"query": "create issue", "server": "jira"}`)
+	assert.Contains(t, text, "Total: 1 tools")
+
+	text = call(`{"query": "issue", "limit": 1}`)
+	assert.Contains(t, text, "(showing first 1)")
+
+	req := &mcp.CallToolRequest{
+		Params: &mcp.CallToolParamsRaw{Name: "list", Arguments: json.RawMessage(`{"limit": -1}`)},
+	}
+	_, err := HandleListTool(context.Background(), provider, req)
+	assert.Error(t, err)
+}
+
+func TestHandleListTool_PagesAndDetail(t *testing.T) {
+	provider := &mockToolProvider{
+		tools: []*mcp.Tool{
+			{Name: "a__one", Description: "First tool"},
+			{Name: "a__two", Description: "Second tool", InputSchema: map[string]any{
+				"type":       "object",
+				"properties": map[string]any{"id": map[string]any{"type": "string", "description": "Item ID"}},
+				"required":   []any{"id"},
+			}},
+			{Name: "a__three", Description: "Third tool"},
+		},
+	}
+
+	call := func(args string) (*mcp.CallToolResult, *ListPage) {
+		req := &mcp.CallToolRequest{
+			Params: &mcp.CallToolParamsRaw{Name: "list", Arguments: json.RawMessage(args)},
+		}
+		result, err := HandleListTool(context.Background(), provider, req)
+		require.NoError(t, err)
+		page, ok := result.StructuredContent.(*ListPage)
+		require.True(t, ok)
+		return result, page
+	}
+
+	// First page of names only
+	result, page := call(`{"limit": 2, "detail": "names"}`)
+	assert.Equal(t, 3, page.Total)
+	require.Len(t, page.Tools, 2)
+	assert.Equal(t, "aOne", page.Tools[0].Name)
+	assert.Empty(t, page.Tools[0].Description)
+	require.NotEmpty(t, page.NextCursor)
+	text := result.Content[0].(*mcp.TextContent).Text
+	assert.Contains(t, text, "- aOne\n")
+	assert.Contains(t, text, page.NextCursor)
+
+	// The cursor keeps the limit and detail of the first call
+	result, page = call(`{"cursor": "` + page.NextCursor + `"}`)
+	assert.Equal(t, 2, page.Offset)
+	require.Len(t, page.Tools, 1)
+	assert.Equal(t, "aTwo", page.Tools[0].Name)
+	assert.Empty(t, page.NextCursor)
+	assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "(showing 3-3)")
+
+	// Full detail includes schemas
+	result, page = call(`{"offset": 2, "detail": "full"}`)
+	assert.NotNil(t, page.Tools[0].InputSchema)
+	assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "@param {string} params.id")
+
+	for _, args := range []string{`{"detail": "everything"}`, `{"cursor": "not a cursor"}`, `{"offset": -1}`} {
+		req := &mcp.CallToolRequest{
+			Params: &mcp.CallToolParamsRaw{Name: "list", Arguments: json.RawMessage(args)},
+		}
+		_, err := HandleListTool(context.Background(), provider, req)
+		assert.Error(t, err, args)
+	}
+}
+
 // mockToolProvider is a simple mock for testing
 type mockToolProvider struct {
-	tools []*mcp.Tool
+	tools      []*mcp.Tool
+	result     *mcp.CallToolResult
+	lastParams json.RawMessage
 }
 
 func (m *mockToolProvider) ListTools(ctx context.Context) ([]*mcp.Tool, error) {
@@ -95,7 +199,8 @@ func (m *mockToolProvider) GetTool(ctx context.Context, name string) (*mcp.Tool,
 }
 
 func (m *mockToolProvider) CallTool(ctx context.Context, name string, params json.RawMessage) (*mcp.CallToolResult, error) {
-	return nil, nil
+	m.lastParams = params
+	return m.result, nil
 }
 
 // Keyword matching helpers
@@ -4,16 +4,21 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"strings"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/vaayne/mcphub/internal/client"
+	"github.com/vaayne/mcphub/internal/results"
+	"github.com/vaayne/mcphub/internal/schema"
+	"github.com/vaayne/mcphub/internal/search"
+	"github.com/vaayne/mcphub/internal/toolname"
 )
 
 // ManagerAdapter adapts client.Manager to implement ToolProvider interface.
 // Used by MCP server handlers to call tools via the shared core functions.
 type ManagerAdapter struct {
-	manager *client.Manager
+	manager  *client.Manager
+	store    *results.Store   // nil = results are never truncated
+	searcher *search.Searcher // nil = list queries are ranked by BM25 alone
 }
 
 // NewManagerAdapter creates a new ManagerAdapter
@@ -21,6 +26,24 @@ func NewManagerAdapter(manager *client.Manager) *ManagerAdapter {
 	return &ManagerAdapter{manager: manager}
 }
 
+// WithResultStore enables truncation of oversized results, keeping the full
+// output in store so it can be paged with the read tool
+func (a *ManagerAdapter) WithResultStore(store *results.Store) *ManagerAdapter {
+	a.store = store
+	return a
+}
+
+// WithSearcher ranks list queries with searcher
+func (a *ManagerAdapter) WithSearcher(searcher *search.Searcher) *ManagerAdapter {
+	a.searcher = searcher
+	return a
+}
+
+// Searcher implements ToolSearcher
+func (a *ManagerAdapter) Searcher() *search.Searcher {
+	return a.searcher
+}
+
 // ListTools returns all available tools from all connected servers
 func (a *ManagerAdapter) ListTools(ctx context.Context) ([]*mcp.Tool, error) {
 	select {
@@ -34,11 +57,9 @@ func (a *ManagerAdapter) ListTools(ctx context.Context) ([]*mcp.Tool, error) {
 
 	for namespacedName, tool := range allTools {
 		// Create a copy with namespaced name
-		tools = append(tools, &mcp.Tool{
-			Name:        namespacedName,
-			Description: tool.Description,
-			InputSchema: tool.InputSchema,
-		})
+		t := *tool
+		t.Name = namespacedName
+		tools = append(tools, &t)
 	}
 
 	return tools, nil
@@ -58,15 +79,13 @@ func (a *ManagerAdapter) GetTool(ctx context.Context, name string) (*mcp.Tool, e
 		return nil, fmt.Errorf("tool '%s' not found", name)
 	}
 
-	// Return with namespaced name
-	return &mcp.Tool{
-		Name:        name,
-		Description: tool.Description,
-		InputSchema: tool.InputSchema,
-	}, nil
+	// Return a copy with namespaced name
+	t := *tool
+	t.Name = name
+	return &t, nil
 }
 
-// CallTool invokes a tool by namespaced name (serverID__toolName)
+// CallTool invokes a tool by its qualified name (serverID__toolName by default)
 func (a *ManagerAdapter) CallTool(ctx context.Context, name string, params json.RawMessage) (*mcp.CallToolResult, error) {
 	select {
 	case <-ctx.Done():
@@ -74,15 +93,16 @@ func (a *ManagerAdapter) CallTool(ctx context.Context, name string, params json.
 	default:
 	}
 
-	// Parse namespaced name
-	before, after, ok := strings.Cut(name, "__")
+	// Listed names are resolved through the manager's registry; anything else
+	// must be namespaced
+	serverID, toolName, ok := a.manager.ResolveTool(name)
 	if !ok {
-		return nil, fmt.Errorf("tool name must be namespaced (serverID__toolName)")
+		serverID, toolName, ok = toolname.ParseNamespacedName(name)
+		if !ok {
+			return nil, fmt.Errorf("tool name must be namespaced (serverID__toolName)")
+		}
 	}
 
-	serverID := before
-	toolName := after
-
 	if serverID == "" {
 		return nil, fmt.Errorf("server ID cannot be empty")
 	}
@@ -90,12 +110,6 @@ func (a *ManagerAdapter) CallTool(ctx context.Context, name string, params json.
 		return nil, fmt.Errorf("tool name cannot be empty")
 	}
 
-	// Get the client session
-	session, err := a.manager.GetClient(serverID)
-	if err != nil {
-		return nil, fmt.Errorf("server not found: %s", serverID)
-	}
-
 	// Parse params
 	var args map[string]any
 	if len(params) > 0 {
@@ -104,13 +118,14 @@ func (a *ManagerAdapter) CallTool(ctx context.Context, name string, params json.
 		}
 	}
 
-	// Call the tool
-	callParams := &mcp.CallToolParams{
-		Name:      toolName,
-		Arguments: args,
+	// Unknown servers get a clear error; the manager reports connection
+	// problems of known ones, retrying where the policy allows
+	if !a.manager.HasServer(serverID) {
+		return nil, fmt.Errorf("server not found: %s", serverID)
 	}
 
-	result, err := session.CallTool(ctx, callParams)
+	// Call the tool
+	result, err := a.manager.CallTool(ctx, serverID, toolName, args)
 	if err != nil {
 		return nil, fmt.Errorf("tool call failed: %w", err)
 	}
@@ -118,5 +133,36 @@ func (a *ManagerAdapter) CallTool(ctx context.Context, name string, params json.
 	return result, nil
 }
 
-// Ensure ManagerAdapter implements ToolProvider
-var _ ToolProvider = (*ManagerAdapter)(nil)
+// ValidatesArgs reports whether tool arguments for a server are validated
+func (a *ManagerAdapter) ValidatesArgs(serverID string) bool {
+	return a.manager.ValidatesArgs(serverID)
+}
+
+// CoercesArgs reports whether tool arguments for a server are coerced
+func (a *ManagerAdapter) CoercesArgs(serverID string) bool {
+	return a.manager.CoercesArgs(serverID)
+}
+
+// LimitResult truncates result to the configured limit for the tool
+func (a *ManagerAdapter) LimitResult(name string, result *mcp.CallToolResult) *mcp.CallToolResult {
+	if a.store == nil {
+		return result
+	}
+	serverID, toolName, _ := toolname.Resolve(a, name)
+	return a.store.Truncate(name, result, a.manager.ResultLimit(serverID, toolName))
+}
+
+// ResolveTool implements toolname.Resolver
+func (a *ManagerAdapter) ResolveTool(name string) (serverID, toolName string, ok bool) {
+	return a.manager.ResolveTool(name)
+}
+
+// Ensure ManagerAdapter implements ToolProvider and the optional policies
+var (
+	_ ToolProvider          = (*ManagerAdapter)(nil)
+	_ ResultLimiter         = (*ManagerAdapter)(nil)
+	_ ToolSearcher          = (*ManagerAdapter)(nil)
+	_ toolname.Resolver     = (*ManagerAdapter)(nil)
+	_ schema.Policy         = (*ManagerAdapter)(nil)
+	_ schema.CoercionPolicy = (*ManagerAdapter)(nil)
+)
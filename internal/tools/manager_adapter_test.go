@@ -0,0 +1,159 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"github.com/vaayne/mcphub/internal/catalog"
+	"github.com/vaayne/mcphub/internal/client"
+	"github.com/vaayne/mcphub/internal/config"
+	"github.com/vaayne/mcphub/internal/js"
+	"github.com/vaayne/mcphub/internal/logging"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingFactory refuses every connection
+type failingFactory struct{}
+
+func (failingFactory) CreateTransport(cfg config.MCPServer) (mcp.Transport, error) {
+	return nil, errors.New("connection refused")
+}
+
+// newSearchBackend returns an in-process server with a "search" tool that
+// counts its calls
+func newSearchBackend() (*mcp.Server, *atomic.Int32) {
+	var calls atomic.Int32
+	backend := mcp.NewServer(&mcp.Implementation{Name: "backend", Version: "v1.0.0"}, nil)
+	backend.AddTool(&mcp.Tool{Name: "search", InputSchema: map[string]any{"type": "object"}},
+		func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			calls.Add(1)
+			return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "ok"}}}, nil
+		})
+	return backend, &calls
+}
+
+// replicaFactory connects each replica URL to its own in-process server
+type replicaFactory map[string]*mcp.Server
+
+func (f replicaFactory) CreateTransport(cfg config.MCPServer) (mcp.Transport, error) {
+	server, ok := f[cfg.URL]
+	if !ok {
+		return nil, fmt.Errorf("unknown replica: %s", cfg.URL)
+	}
+	return (&inMemoryFactory{server: server}).CreateTransport(cfg)
+}
+
+// TestManagerAdapter_CallToolServerErrors verifies only unknown servers are
+// reported as not found; known servers that fail to start give the real error
+func TestManagerAdapter_CallToolServerErrors(t *testing.T) {
+	store, err := catalog.NewStore(t.TempDir(), 0)
+	require.NoError(t, err)
+	serverCfg := config.MCPServer{Command: "test", Lazy: true}
+
+	// Record the server's tools in the catalog so it can be deferred
+	backend, _ := newSearchBackend()
+	first := client.NewManagerWithFactory(logging.NopLogger(), &inMemoryFactory{server: backend})
+	first.SetCatalog(store)
+	require.NoError(t, first.ConnectToServer("srv", serverCfg))
+	first.DisconnectAll()
+
+	manager := client.NewManagerWithFactory(logging.NopLogger(), failingFactory{})
+	defer manager.DisconnectAll()
+	manager.SetCatalog(store)
+	require.NoError(t, manager.ConnectToServer("srv", serverCfg))
+	adapter := NewManagerAdapter(manager)
+	ctx := context.Background()
+
+	_, err = adapter.CallTool(ctx, "srv__search", nil)
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "server not found")
+	assert.Contains(t, err.Error(), "connection refused")
+
+	_, err = adapter.CallTool(ctx, "missing__search", nil)
+	assert.ErrorContains(t, err, "server not found: missing")
+}
+
+// TestManagerAdapter_ReplicasRoundRobin verifies calls through the adapter and
+// the exec caller advance the round robin once each
+func TestManagerAdapter_ReplicasRoundRobin(t *testing.T) {
+	a, aCalls := newSearchBackend()
+	b, bCalls := newSearchBackend()
+	manager := client.NewManagerWithFactory(logging.NopLogger(), replicaFactory{
+		"http://replica0.test/mcp": a,
+		"http://replica1.test/mcp": b,
+	})
+	defer manager.DisconnectAll()
+	require.NoError(t, manager.ConnectToServer("srv", config.MCPServer{
+		Replicas: []config.Replica{{URL: "http://replica0.test/mcp"}, {URL: "http://replica1.test/mcp"}},
+	}))
+	ctx := context.Background()
+
+	adapter := NewManagerAdapter(manager)
+	for range 4 {
+		_, err := adapter.CallTool(ctx, "srv__search", nil)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int32(2), aCalls.Load())
+	assert.Equal(t, int32(2), bCalls.Load())
+
+	caller := js.NewManagerCaller(manager)
+	for range 4 {
+		_, err := caller.CallTool(ctx, "srv", "search", nil)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int32(4), aCalls.Load())
+	assert.Equal(t, int32(4), bCalls.Load())
+
+	_, err := caller.CallTool(ctx, "missing", "search", nil)
+	assert.ErrorContains(t, err, "server 'missing' not found")
+}
+
+// TestManagerAdapter_ToolAnnotations verifies hub listings keep the tool's
+// title and annotations
+func TestManagerAdapter_ToolAnnotations(t *testing.T) {
+	backend := mcp.NewServer(&mcp.Implementation{Name: "backend", Version: "v1.0.0"}, nil)
+	backend.AddTool(&mcp.Tool{
+		Name:        "search",
+		Title:       "Search",
+		InputSchema: map[string]any{"type": "object"},
+		Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true},
+	}, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return &mcp.CallToolResult{}, nil
+	})
+	manager := client.NewManagerWithFactory(logging.NopLogger(), &inMemoryFactory{server: backend})
+	defer manager.DisconnectAll()
+	require.NoError(t, manager.ConnectToServer("srv", config.MCPServer{Command: "test"}))
+	ctx := context.Background()
+
+	check := func(tool *mcp.Tool) {
+		t.Helper()
+		assert.Equal(t, "srv__search", tool.Name)
+		assert.Equal(t, "Search", tool.Title)
+		require.NotNil(t, tool.Annotations)
+		assert.True(t, tool.Annotations.ReadOnlyHint)
+	}
+
+	adapter := NewManagerAdapter(manager)
+	tool, err := adapter.GetTool(ctx, "srv__search")
+	require.NoError(t, err)
+	check(tool)
+
+	listed, err := adapter.ListTools(ctx)
+	require.NoError(t, err)
+	require.Len(t, listed, 1)
+	check(listed[0])
+
+	listed, err = js.NewManagerCaller(manager).ListTools(ctx)
+	require.NoError(t, err)
+	require.Len(t, listed, 1)
+	check(listed[0])
+
+	// The listing is a copy; the manager's tool keeps its own name
+	assert.Equal(t, "search", manager.GetAllTools()["srv__search"].Name)
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/vaayne/mcphub/internal/search"
 )
 
 // ToolProvider is the common interface for tool operations.
@@ -17,3 +18,17 @@ type ToolProvider interface {
 	// CallTool invokes a tool with the given parameters
 	CallTool(ctx context.Context, name string, params json.RawMessage) (*mcp.CallToolResult, error)
 }
+
+// ResultLimiter is optionally implemented by tool providers that bound the size
+// of results returned to the client
+type ResultLimiter interface {
+	// LimitResult returns result, truncated if it exceeds the limit for the named tool
+	LimitResult(name string, result *mcp.CallToolResult) *mcp.CallToolResult
+}
+
+// ToolSearcher is optionally implemented by tool providers with configured
+// search settings; other providers rank list queries by BM25 alone
+type ToolSearcher interface {
+	// Searcher returns the searcher that ranks tools for a list query
+	Searcher() *search.Searcher
+}
@@ -0,0 +1,67 @@
+package tools
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/vaayne/mcphub/internal/config"
+	"github.com/vaayne/mcphub/internal/results"
+)
+
+//go:embed read_description.md
+var ReadDescription string
+
+// HandleReadTool handles the read tool call (MCP server handler).
+// maxBytes caps the page size (0 = no cap beyond the requested limit).
+func HandleReadTool(ctx context.Context, store *results.Store, maxBytes int, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var args struct {
+		Handle string `json:"handle"`
+		Offset int    `json:"offset"`
+		Limit  int    `json:"limit"`
+	}
+	if err := json.Unmarshal(req.Params.Arguments, &args); err != nil {
+		return nil, fmt.Errorf("failed to parse arguments: %w", err)
+	}
+
+	if args.Handle == "" {
+		return nil, fmt.Errorf("handle is required")
+	}
+
+	// Check context cancellation
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	// Pages default to, and never exceed, the hub's result size limit
+	limit := args.Limit
+	if limit <= 0 {
+		limit = maxBytes
+		if limit <= 0 {
+			limit = config.DefaultMaxResultBytes
+		}
+	}
+	if maxBytes > 0 && limit > maxBytes {
+		limit = maxBytes
+	}
+
+	page, err := store.Read(args.Handle, args.Offset, limit)
+	if err != nil {
+		// Expired or unknown handles are reported to the model, not as protocol errors
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
+			IsError: true,
+		}, nil
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: page.Text},
+			&mcp.TextContent{Text: results.PageNote(page.Handle, page.NextOffset, page.Total)},
+		},
+	}, nil
+}
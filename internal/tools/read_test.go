@@ -0,0 +1,50 @@
+package tools
+
+import (
+	"context"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vaayne/mcphub/internal/results"
+)
+
+// TestHandleReadTool tests paging through a stored result
+func TestHandleReadTool(t *testing.T) {
+	store := results.NewStore(0, 0)
+	handle := store.Put("github__search", "0123456789")
+
+	req := &mcp.CallToolRequest{
+		Params: &mcp.CallToolParamsRaw{
+			Name:      "read",
+			Arguments: []byte(`{"handle": "` + handle + `", "offset": 2, "limit": 100}`),
+		},
+	}
+
+	// The hub limit caps the requested page size
+	result, err := HandleReadTool(context.Background(), store, 4, req)
+	require.NoError(t, err)
+	require.Len(t, result.Content, 2)
+	assert.Equal(t, "2345", result.Content[0].(*mcp.TextContent).Text)
+	assert.Contains(t, result.Content[1].(*mcp.TextContent).Text, `"offset": 6`)
+}
+
+// TestHandleReadTool_UnknownHandle tests that unknown handles are reported as tool errors
+func TestHandleReadTool_UnknownHandle(t *testing.T) {
+	req := &mcp.CallToolRequest{
+		Params: &mcp.CallToolParamsRaw{
+			Name:      "read",
+			Arguments: []byte(`{"handle": "res_missing"}`),
+		},
+	}
+
+	result, err := HandleReadTool(context.Background(), results.NewStore(0, 0), 0, req)
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+
+	_, err = HandleReadTool(context.Background(), results.NewStore(0, 0), 0, &mcp.CallToolRequest{
+		Params: &mcp.CallToolParamsRaw{Name: "read", Arguments: []byte(`{}`)},
+	})
+	assert.ErrorContains(t, err, "handle is required")
+}
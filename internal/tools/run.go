@@ -0,0 +1,114 @@
+package tools
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"github.com/vaayne/mcphub/internal/client"
+	"github.com/vaayne/mcphub/internal/js"
+	"github.com/vaayne/mcphub/internal/scripts"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+//go:embed run_description.md
+var RunDescription string
+
+// RunScript runs the saved script name from library with params exposed as
+// the params global, using the runtime settings of cfg (nil = defaults).
+// This is the shared implementation used by both CLI and MCP tool handler.
+func RunScript(ctx context.Context, logger *slog.Logger, caller js.ToolCaller, library *scripts.Library, name string, params map[string]any, cfg *js.Config) (*ExecResult, error) {
+	script, code, err := library.Load(name)
+	if err != nil {
+		return nil, err
+	}
+	if params == nil {
+		params = map[string]any{}
+	}
+
+	runCfg := runtimeConfig(cfg)
+	runCfg.Language = script.Language
+	runCfg.Library = library.Dir()
+	runCfg.Params = params
+	return ExecuteCode(ctx, logger, caller, code, runCfg)
+}
+
+// scriptListing is the run tool's answer when no script is named
+type scriptListing struct {
+	Scripts []scriptSummary `json:"scripts"`
+	Modules []string        `json:"modules"`
+}
+
+type scriptSummary struct {
+	Name        string `json:"name"`
+	Language    string `json:"language"`
+	Description string `json:"description,omitempty"`
+}
+
+// HandleRunTool implements the run built-in tool (MCP server handler). Without
+// a name it lists the saved scripts and library modules. limits truncates
+// oversized results (nil = unlimited).
+func HandleRunTool(ctx context.Context, logger *slog.Logger, manager *client.Manager, cfg *js.Config, limits *ResultLimits, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var args struct {
+		Name   string         `json:"name"`
+		Params map[string]any `json:"params"`
+	}
+	if len(req.Params.Arguments) > 0 {
+		if err := json.Unmarshal(req.Params.Arguments, &args); err != nil {
+			return nil, fmt.Errorf("failed to parse run arguments: %w", err)
+		}
+	}
+
+	var dir string
+	if cfg != nil {
+		dir = cfg.Library
+	}
+	if dir == "" {
+		return nil, fmt.Errorf("no script library is configured")
+	}
+	library := scripts.NewLibrary(dir)
+
+	if args.Name == "" {
+		return listScripts(library)
+	}
+
+	execResult, err := RunScript(ctx, logger, limits.caller(manager), library, args.Name, args.Params, cfg)
+	if err != nil {
+		return nil, err
+	}
+	limits.apply("run", execResult)
+	return execToolResult(execResult)
+}
+
+// listScripts returns the saved scripts and modules of library as JSON
+func listScripts(library *scripts.Library) (*mcp.CallToolResult, error) {
+	saved, err := library.List()
+	if err != nil {
+		return nil, err
+	}
+	modules, err := library.Modules()
+	if err != nil {
+		return nil, err
+	}
+
+	listing := scriptListing{Scripts: []scriptSummary{}, Modules: []string{}}
+	for _, s := range saved {
+		listing.Scripts = append(listing.Scripts, scriptSummary{Name: s.Name, Language: s.Language, Description: s.Description})
+	}
+	listing.Modules = append(listing.Modules, modules...)
+
+	data, err := json.Marshal(listing)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal scripts: %w", err)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: string(data),
+			},
+		},
+	}, nil
+}
@@ -0,0 +1,40 @@
+package tools
+
+import (
+	"context"
+	"testing"
+
+	"github.com/vaayne/mcphub/internal/logging"
+	"github.com/vaayne/mcphub/internal/scripts"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunScript(t *testing.T) {
+	library := scripts.NewLibrary(t.TempDir())
+	_, err := library.Save("count", "const items: string[] = params.items ?? [];\nitems.length", "typescript")
+	require.NoError(t, err)
+
+	result, err := RunScript(context.Background(), logging.NopLogger(), nil, library, "count",
+		map[string]any{"items": []any{"a", "b"}}, nil)
+	require.NoError(t, err)
+	assert.Nil(t, result.Error)
+	assert.Equal(t, int64(2), result.Result)
+
+	// params is defined even when no parameters are given
+	result, err = RunScript(context.Background(), logging.NopLogger(), nil, library, "count", nil, nil)
+	require.NoError(t, err)
+	assert.Nil(t, result.Error)
+	assert.Equal(t, int64(0), result.Result)
+
+	_, err = RunScript(context.Background(), logging.NopLogger(), nil, library, "missing", nil, nil)
+	assert.ErrorContains(t, err, "script not found")
+}
+
+func TestHandleRunTool_NoLibrary(t *testing.T) {
+	req := &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Name: "run"}}
+	_, err := HandleRunTool(context.Background(), logging.NopLogger(), nil, nil, nil, req)
+	assert.ErrorContains(t, err, "no script library")
+}
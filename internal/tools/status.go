@@ -0,0 +1,58 @@
+package tools
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/vaayne/mcphub/internal/cache"
+	"github.com/vaayne/mcphub/internal/client"
+)
+
+//go:embed status_description.md
+var StatusDescription string
+
+// StatusResult represents the result of the status tool
+type StatusResult struct {
+	Servers    []client.ServerStatus `json:"servers"`
+	Collisions []string              `json:"collisions,omitempty"` // tool names claimed by more than one server
+	Cache      *cache.Stats          `json:"cache,omitempty"`
+}
+
+// GetStatus collects the status of the manager's servers, tool name collisions
+// and cache
+func GetStatus(manager *client.Manager) *StatusResult {
+	result := &StatusResult{Servers: manager.Status()}
+	for _, err := range manager.ToolNameCollisions() {
+		result.Collisions = append(result.Collisions, err.Error())
+	}
+	if c := manager.Cache(); c != nil {
+		stats := c.Stats()
+		result.Cache = &stats
+	}
+	return result
+}
+
+// HandleStatusTool handles the status tool call (MCP server handler)
+func HandleStatusTool(ctx context.Context, manager *client.Manager, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	jsonBytes, err := json.Marshal(GetStatus(manager))
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal status: %w", err)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: string(jsonBytes),
+			},
+		},
+	}, nil
+}
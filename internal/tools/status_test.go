@@ -0,0 +1,35 @@
+package tools
+
+import (
+	"context"
+	"testing"
+
+	"github.com/vaayne/mcphub/internal/client"
+	"github.com/vaayne/mcphub/internal/config"
+	"github.com/vaayne/mcphub/internal/logging"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGetStatus_Collisions verifies tool names claimed by two unprefixed
+// servers are reported in the status
+func TestGetStatus_Collisions(t *testing.T) {
+	backend := mcp.NewServer(&mcp.Implementation{Name: "backend", Version: "v1.0.0"}, nil)
+	backend.AddTool(&mcp.Tool{Name: "search", InputSchema: map[string]any{"type": "object"}},
+		func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return &mcp.CallToolResult{}, nil
+		})
+	manager := client.NewManagerWithFactory(logging.NopLogger(), &inMemoryFactory{server: backend})
+	defer manager.DisconnectAll()
+
+	empty := ""
+	require.NoError(t, manager.ConnectToServer("main", config.MCPServer{Command: "test", Prefix: &empty}))
+	assert.Empty(t, GetStatus(manager).Collisions)
+
+	require.NoError(t, manager.ConnectToServer("other", config.MCPServer{Command: "test", Prefix: &empty}))
+	status := GetStatus(manager)
+	require.Len(t, status.Collisions, 1)
+	assert.Contains(t, status.Collisions[0], `tool name "search" is used by server "main"`)
+}
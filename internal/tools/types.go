@@ -0,0 +1,300 @@
+package tools
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/vaayne/mcphub/internal/schema"
+	"github.com/vaayne/mcphub/internal/toolname"
+)
+
+//go:embed types_description.md
+var TypesDescription string
+
+//go:embed types_prelude.d.ts
+var typesPrelude string
+
+// TypesOptions contains options for generating type declarations
+type TypesOptions struct {
+	Server            string // Optional: only declare the tools of this server
+	IncludeUnprefixed bool   // If true, include tools without server prefix (for direct server connections)
+}
+
+// typedTool is a tool as declared in the generated types
+type typedTool struct {
+	tool       *mcp.Tool
+	jsName     string
+	names      []string // names mcp.callTool accepts: JS name and qualified name
+	ref        toolname.Ref
+	paramsType string
+	resultType string
+	optional   bool // params may be omitted
+}
+
+// GenerateTypes is the shared core function for generating a TypeScript
+// declaration file for exec scripts. Used by both CLI and MCP server handlers.
+// Tools are declared under the same names the exec runtime gives them.
+func GenerateTypes(ctx context.Context, provider ToolProvider, opts TypesOptions) (string, error) {
+	allTools, err := provider.ListTools(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to list tools: %w", err)
+	}
+	sort.Slice(allTools, func(i, j int) bool {
+		return allTools[i].Name < allTools[j].Name
+	})
+
+	// The runtime maps names over every listed tool, so the mapper is too
+	mapper := toolname.NewMapper(allTools)
+	typeNames := make(map[string]bool)
+
+	var typed []*typedTool
+	for _, tool := range allTools {
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		default:
+		}
+
+		serverID, toolName, isNamespaced := toolname.Resolve(provider, tool.Name)
+		if !isNamespaced && !opts.IncludeUnprefixed {
+			continue
+		}
+		if opts.Server != "" && isNamespaced && !strings.EqualFold(serverID, opts.Server) {
+			continue
+		}
+		if !isNamespaced {
+			toolName = tool.Name
+		}
+
+		jsName := mapper.ToJSName(tool.Name)
+		if mapper.ToOriginal(jsName) != tool.Name {
+			continue // another tool has the same JS name and takes it
+		}
+
+		t := &typedTool{
+			tool:   tool,
+			jsName: jsName,
+			names:  []string{jsName},
+			ref:    toolname.Ref{ServerID: serverID, ToolName: toolName},
+		}
+		if tool.Name != jsName {
+			t.names = append(t.names, tool.Name)
+		}
+		base := uniqueTypeName(typeNames, jsName)
+		t.paramsType = base + "Params"
+		t.resultType = base + "Result"
+
+		inputSchema := schema.Normalize(tool.InputSchema)
+		required, _ := inputSchema["required"].([]any)
+		t.optional = len(required) == 0
+		typed = append(typed, t)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(typesPrelude)
+
+	// Parameter and result types
+	for _, t := range typed {
+		sb.WriteString("\n")
+		sb.WriteString(schema.DocComment(fmt.Sprintf("Parameters of %s", t.jsName), ""))
+		writeTypeDeclaration(&sb, t.paramsType, paramsSchema(t.tool.InputSchema))
+		if t.tool.OutputSchema != nil {
+			sb.WriteString(schema.DocComment(fmt.Sprintf("Structured result of %s", t.jsName), ""))
+			writeTypeDeclaration(&sb, t.resultType, schema.Normalize(t.tool.OutputSchema))
+		} else {
+			sb.WriteString(fmt.Sprintf("type %s = any;\n", t.resultType))
+		}
+	}
+
+	// Every accepted tool name
+	sb.WriteString("\n/** Names accepted by mcp.callTool and its variants */\n")
+	sb.WriteString("type McpToolName =")
+	if len(typed) == 0 {
+		sb.WriteString(" never")
+	}
+	for _, t := range typed {
+		for _, name := range t.names {
+			sb.WriteString(fmt.Sprintf("\n  | %q", name))
+		}
+	}
+	sb.WriteString(";\n")
+
+	sb.WriteString("\ndeclare namespace mcp {\n")
+	writeCallOverloads(&sb, typed, "callTool", "A single text result is returned as parsed JSON or string; richer results as a result object", "%s")
+	writeCallOverloads(&sb, typed, "callToolAsync", "Like callTool, but runs in the background; await several with Promise.all to run them in parallel", "Promise<%s>")
+	sb.WriteString(`
+  /** Calls a tool and returns the full result */
+  function callToolRaw(name: McpToolName, params?: Record<string, unknown>): McpToolResult;
+  /** Like callToolRaw, but runs in the background */
+  function callToolRawAsync(name: McpToolName, params?: Record<string, unknown>): Promise<McpToolResult>;
+`)
+
+	// mcp.tools
+	sb.WriteString("\n  /** Every listed tool by JS name */\n")
+	sb.WriteString("  const tools: {\n")
+	for _, t := range typed {
+		writeToolMethod(&sb, t, t.jsName, "    ")
+	}
+	sb.WriteString("  };\n")
+
+	// mcp.servers
+	servers := make(map[string][]*typedTool)
+	var serverIDs []string
+	for _, t := range typed {
+		if t.ref.ServerID == "" {
+			continue
+		}
+		if _, ok := servers[t.ref.ServerID]; !ok {
+			serverIDs = append(serverIDs, t.ref.ServerID)
+		}
+		servers[t.ref.ServerID] = append(servers[t.ref.ServerID], t)
+	}
+	sort.Strings(serverIDs)
+	sb.WriteString("\n  /** The same tools grouped by server */\n")
+	sb.WriteString("  const servers: {\n")
+	for _, serverID := range serverIDs {
+		sb.WriteString(fmt.Sprintf("    %s: {\n", schema.PropertyName(serverID)))
+		for _, t := range servers[serverID] {
+			writeToolMethod(&sb, t, toolname.ToJSName(t.ref.ToolName), "      ")
+		}
+		sb.WriteString("    };\n")
+	}
+	sb.WriteString("  };\n")
+
+	sb.WriteString(`
+  /** Records a log entry returned with the exec result */
+  function log(level: McpLogLevel, message: string, fields?: Record<string, unknown>): void;
+  /** Builds an image content block */
+  function image(data: McpBinary, mimeType: string): McpContentBlock;
+  /** Builds an audio content block */
+  function audio(data: McpBinary, mimeType: string): McpContentBlock;
+  /** Builds an embedded resource content block */
+  function resource(resource: { uri: string; mimeType?: string; text?: string; blob?: McpBinary }): McpContentBlock;
+  /** Sends an HTTP request to a host the hub allows; throws when network access is not enabled */
+  function fetch(url: string, options?: McpFetchOptions): McpFetchResponse;
+  /** Like fetch, but runs in the background */
+  function fetchAsync(url: string, options?: McpFetchOptions): Promise<McpFetchResponse>;
+  /** Values kept between executions of this client session; persistent is shared across sessions and restarts */
+  const state: McpState & { persistent: McpState };
+}
+`)
+
+	return sb.String(), nil
+}
+
+// writeCallOverloads writes one overload of fn per tool; format wraps the result type
+func writeCallOverloads(sb *strings.Builder, typed []*typedTool, fn, doc, format string) {
+	sb.WriteString("\n")
+	sb.WriteString(schema.DocComment(doc, "  "))
+	if len(typed) == 0 {
+		sb.WriteString(fmt.Sprintf("  function %s(name: McpToolName, params?: Record<string, unknown>): %s;\n", fn, fmt.Sprintf(format, "any")))
+	}
+	for _, t := range typed {
+		names := make([]string, len(t.names))
+		for i, name := range t.names {
+			names[i] = fmt.Sprintf("%q", name)
+		}
+		sb.WriteString(fmt.Sprintf("  function %s(name: %s, params%s: %s): %s;\n",
+			fn, strings.Join(names, " | "), optionalMark(t), t.paramsType, fmt.Sprintf(format, t.resultType)))
+	}
+}
+
+// writeToolMethod writes the mcp.tools member of a tool under key
+func writeToolMethod(sb *strings.Builder, t *typedTool, key, indent string) {
+	sb.WriteString(schema.DocComment(t.tool.Description, indent))
+	sb.WriteString(fmt.Sprintf("%s%s: { (params%s: %s): %s; readonly doc: string };\n",
+		indent, schema.PropertyName(key), optionalMark(t), t.paramsType, t.resultType))
+}
+
+func optionalMark(t *typedTool) string {
+	if t.optional {
+		return "?"
+	}
+	return ""
+}
+
+// writeTypeDeclaration declares name as the TypeScript type of s, as an
+// interface when s is an object type
+func writeTypeDeclaration(sb *strings.Builder, name string, s map[string]any) {
+	ts := schema.TypeScript(s, "")
+	// A single object type closes once at the start of a line; unions and
+	// intersections of objects close more often
+	if strings.HasPrefix(ts, "{\n") && strings.HasSuffix(ts, "\n}") && strings.Count(ts, "\n}") == 1 {
+		sb.WriteString(fmt.Sprintf("interface %s %s\n", name, ts))
+		return
+	}
+	sb.WriteString(fmt.Sprintf("type %s = %s;\n", name, ts))
+}
+
+// paramsSchema returns a tool's input schema, treating a missing one as an
+// object without properties
+func paramsSchema(inputSchema any) map[string]any {
+	s := schema.Normalize(inputSchema)
+	if s == nil {
+		return map[string]any{"type": "object"}
+	}
+	return s
+}
+
+// uniqueTypeName derives a PascalCase type name from a JS name, numbering it
+// if another tool already uses it
+func uniqueTypeName(used map[string]bool, jsName string) string {
+	var sb strings.Builder
+	upper := true
+	for _, r := range jsName {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if sb.Len() == 0 && unicode.IsDigit(r) {
+			sb.WriteString("T")
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		sb.WriteRune(r)
+	}
+	base := sb.String()
+	if base == "" {
+		base = "Tool"
+	}
+
+	name := base
+	for i := 2; used[name]; i++ {
+		name = fmt.Sprintf("%s%d", base, i)
+	}
+	used[name] = true
+	return name
+}
+
+// HandleTypesTool handles the types tool call (MCP server handler)
+func HandleTypesTool(ctx context.Context, provider ToolProvider, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var args struct {
+		Server string `json:"server"`
+	}
+	if len(req.Params.Arguments) > 0 {
+		if err := json.Unmarshal(req.Params.Arguments, &args); err != nil {
+			return nil, fmt.Errorf("failed to parse types arguments: %w", err)
+		}
+	}
+
+	output, err := GenerateTypes(ctx, provider, TypesOptions{Server: args.Server})
+	if err != nil {
+		return nil, err
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: output,
+			},
+		},
+	}, nil
+}
@@ -0,0 +1,64 @@
+// Type declarations for mcphub exec scripts, generated by "mh types" or the
+// hub's types tool. Regenerate them when servers or tools change.
+
+/** An MCP content block in wire format (binary data is base64-encoded) */
+interface McpContentBlock {
+  type: "text" | "image" | "audio" | "resource" | "resource_link";
+  text?: string;
+  data?: string;
+  mimeType?: string;
+  resource?: { uri: string; mimeType?: string; text?: string; blob?: string };
+  uri?: string;
+  name?: string;
+  title?: string;
+  description?: string;
+}
+
+/** The full result of a tool call, as returned by mcp.callToolRaw */
+interface McpToolResult {
+  content: McpContentBlock[];
+  /** The tool reported an error; callToolRaw returns such results instead of throwing */
+  isError: boolean;
+  structuredContent?: unknown;
+  /** Joins all text blocks with newlines */
+  text(): string;
+  images(): McpContentBlock[];
+  audio(): McpContentBlock[];
+  resources(): McpContentBlock[];
+}
+
+/** Binary data for mcp.image and mcp.audio: base64 text or a Buffer */
+type McpBinary = string | ArrayBuffer | Uint8Array;
+
+type McpLogLevel = "debug" | "info" | "warn" | "error";
+
+/** A namespace of JSON values kept between executions, as mcp.state */
+interface McpState {
+  /** Returns the value of key, or undefined if it is not set */
+  get(key: string): unknown;
+  /** Stores a JSON-serializable value; ttl is in seconds */
+  set(key: string, value: unknown, options?: { ttl?: number }): void;
+  /** Removes key, returning whether it was set */
+  delete(key: string): boolean;
+  /** Returns the keys starting with prefix, sorted */
+  list(prefix?: string): string[];
+}
+
+/** Options of mcp.fetch; a body that is not a string is sent as JSON */
+interface McpFetchOptions {
+  method?: string;
+  headers?: Record<string, string>;
+  body?: unknown;
+}
+
+/** A response of mcp.fetch, read in full */
+interface McpFetchResponse {
+  url: string;
+  status: number;
+  statusText: string;
+  ok: boolean;
+  /** Header values by lower-case name */
+  headers: Record<string, string>;
+  text(): string;
+  json(): any;
+}
@@ -0,0 +1,68 @@
+package tools
+
+import (
+	"context"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateTypes(t *testing.T) {
+	provider := &mockToolProvider{
+		tools: []*mcp.Tool{
+			{Name: "exec", Description: "Execute JS code"}, // builtin - should be skipped
+			{
+				Name:        "github__create_issue",
+				Description: "Create an issue",
+				InputSchema: map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"title":  map[string]any{"type": "string", "description": "Issue title"},
+						"labels": map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+					},
+					"required": []any{"title"},
+				},
+				OutputSchema: map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"number": map[string]any{"type": "integer"},
+					},
+					"required": []any{"number"},
+				},
+			},
+			{Name: "time__now", Description: "Current time"},
+		},
+	}
+
+	output, err := GenerateTypes(context.Background(), provider, TypesOptions{})
+	require.NoError(t, err)
+
+	assert.Contains(t, output, "interface McpToolResult {")
+	assert.Contains(t, output, "const state: McpState & { persistent: McpState };")
+	assert.Contains(t, output, "function fetch(url: string, options?: McpFetchOptions): McpFetchResponse;")
+	assert.Contains(t, output, "interface GithubCreateIssueParams {\n  labels?: string[];\n  /** Issue title */\n  title: string;\n}")
+	assert.Contains(t, output, "interface GithubCreateIssueResult {\n  number: number;\n}")
+	assert.Contains(t, output, "type TimeNowParams = Record<string, unknown>;")
+	assert.Contains(t, output, "type TimeNowResult = any;")
+	assert.Contains(t, output, `function callTool(name: "githubCreateIssue" | "github__create_issue", params: GithubCreateIssueParams): GithubCreateIssueResult;`)
+	assert.Contains(t, output, `function callToolAsync(name: "timeNow" | "time__now", params?: TimeNowParams): Promise<TimeNowResult>;`)
+	assert.Contains(t, output, "    githubCreateIssue: { (params: GithubCreateIssueParams): GithubCreateIssueResult; readonly doc: string };")
+	assert.Contains(t, output, "    github: {\n      /** Create an issue */\n      createIssue: {")
+	assert.NotContains(t, output, `"exec"`)
+
+	// Only one server
+	output, err = GenerateTypes(context.Background(), provider, TypesOptions{Server: "time"})
+	require.NoError(t, err)
+	assert.Contains(t, output, "TimeNowParams")
+	assert.NotContains(t, output, "GithubCreateIssueParams")
+}
+
+func TestGenerateTypes_NoTools(t *testing.T) {
+	output, err := GenerateTypes(context.Background(), &mockToolProvider{}, TypesOptions{})
+	require.NoError(t, err)
+
+	assert.Contains(t, output, "type McpToolName = never;")
+	assert.Contains(t, output, "function callTool(name: McpToolName, params?: Record<string, unknown>): any;")
+}
@@ -36,8 +36,12 @@ with remote MCP services.`,
 			cli.InspectCmd,
 			cli.InvokeCmd,
 			cli.ExecCmd,
+			cli.TypesCmd,
+			cli.ScriptsCmd,
 			cli.UpdateCmd,
 			cli.SkillsCmd,
+			cli.CacheCmd,
+			cli.CatalogCmd,
 		},
 	}
 